@@ -20,6 +20,16 @@ import (
 	"github.com/gravitational/gravity/lib/constants"
 )
 
+const (
+	// MinEncryptionKeyLength is the minimum length of an installer
+	// package encryption key
+	MinEncryptionKeyLength = 16
+
+	// UpdateAvailableExitCode specifies the exit code for the update check
+	// command when a newer application version is available
+	UpdateAvailableExitCode = 100
+)
+
 var (
 	// LicenseOutputFormat represents the output format of the show license command
 	LicenseOutputFormat = constants.EncodingPEM
@@ -49,6 +49,11 @@ var (
 		Name: SAMLConnectorDeletedEvent,
 		Code: SAMLConnectorDeletedCode,
 	}
+	// TrustedClusterDeleted is emitted when a trusted cluster is deleted.
+	TrustedClusterDeleted = events.Event{
+		Name: TrustedClusterDeletedEvent,
+		Code: TrustedClusterDeletedCode,
+	}
 	// EndpointsUpdated is emitted when Gravity Hub endpoints are created/updated.
 	EndpointsUpdated = events.Event{
 		Name: EndpointsUpdatedEvent,
@@ -79,6 +84,11 @@ var (
 		Name: LicenseUpdatedEvent,
 		Code: LicenseUpdatedCode,
 	}
+	// LicenseRevoked is emitted when an issued license is revoked.
+	LicenseRevoked = events.Event{
+		Name: LicenseRevokedEvent,
+		Code: LicenseRevokedCode,
+	}
 	// UpdatesEnabled is emitted when periodic updates are turned on.
 	UpdatesEnabled = events.Event{
 		Name: UpdatesEnabledEvent,
@@ -111,6 +121,8 @@ const (
 	SAMLConnectorCreatedCode = "GE1002I"
 	// SAMLConnectorDeletedCode is the SAML connector deleted event code.
 	SAMLConnectorDeletedCode = "GE2002I"
+	// TrustedClusterDeletedCode is the trusted cluster deleted event code.
+	TrustedClusterDeletedCode = "GE2003I"
 	// EndpointsUpdatedCode is the endpoints created event code.
 	EndpointsUpdatedCode = "GE1003I"
 	// RemoteSupportEnabledCode is the remote support turned on event code.
@@ -123,6 +135,8 @@ const (
 	LicenseExpiredCode = "GE3003I"
 	// LicenseUpdatedCode is the license updated event code.
 	LicenseUpdatedCode = "GE3004I"
+	// LicenseRevokedCode is the license revoked event code.
+	LicenseRevokedCode = "GE3008I"
 	// UpdatesEnabledCode is the periodic updates turned on event code.
 	UpdatesEnabledCode = "GE3005I"
 	// UpdatesDisabledCode is the periodic updates turned off event code.
@@ -144,6 +158,8 @@ const (
 	SAMLConnectorCreatedEvent = "saml.created"
 	// SAMLConnectorDeletedEvent fires when SAML connector is deleted.
 	SAMLConnectorDeletedEvent = "saml.deleted"
+	// TrustedClusterDeletedEvent fires when a trusted cluster is deleted.
+	TrustedClusterDeletedEvent = "trustedcluster.deleted"
 	// EndpointsUpdatedEvent fires when Gravity Hub endpoints are updated.
 	EndpointsUpdatedEvent = "endpoints.updated"
 
@@ -165,6 +181,8 @@ const (
 	LicenseUpdatedEvent = "license.updated"
 	// LicenseGeneratedEvent fires when an Gravity Hub generates a license.
 	LicenseGeneratedEvent = "license.generated"
+	// LicenseRevokedEvent fires when an Gravity Hub revokes a license.
+	LicenseRevokedEvent = "license.revoked"
 )
 
 const (
@@ -0,0 +1,56 @@
+// Copyright 2021 Gravitational Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package license
+
+import (
+	licenseapi "github.com/gravitational/license"
+	"github.com/gravitational/license/authority"
+	"github.com/gravitational/trace"
+)
+
+// Signer generates signed cluster licenses.
+//
+// Implementations control where the CA private key lives: the local signer
+// holds a key pair in memory while alternative implementations can delegate
+// the signing to an external KMS/HSM so the raw key bytes never reach this
+// process.
+type Signer interface {
+	// SignLicense generates a new license signed with the CA private key
+	// this signer controls. The TLSKeyPair field of the provided info is
+	// ignored - the key material is supplied by the signer
+	SignLicense(info licenseapi.NewLicenseInfo) (string, error)
+}
+
+// NewLocalSigner returns a signer that signs licenses with the provided
+// CA key pair held in memory. This is the default used when no external
+// signer has been configured
+func NewLocalSigner(keyPair authority.TLSKeyPair) Signer {
+	return localSigner{keyPair: keyPair}
+}
+
+// localSigner signs licenses with a CA key pair held in memory
+type localSigner struct {
+	keyPair authority.TLSKeyPair
+}
+
+// SignLicense generates a new license signed with the local CA key pair
+func (r localSigner) SignLicense(info licenseapi.NewLicenseInfo) (string, error) {
+	info.TLSKeyPair = r.keyPair
+	license, err := licenseapi.NewLicense(info)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	return license, nil
+}
@@ -0,0 +1,92 @@
+// Copyright 2021 Gravitational Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package license
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudflare/cfssl/csr"
+	licenseapi "github.com/gravitational/license"
+	"github.com/gravitational/license/authority"
+	check "gopkg.in/check.v1"
+)
+
+func TestLicenseSigner(t *testing.T) { check.TestingT(t) }
+
+type signerSuite struct {
+	ca *authority.TLSKeyPair
+}
+
+var _ = check.Suite(&signerSuite{})
+
+func (s *signerSuite) SetUpSuite(c *check.C) {
+	ca, err := authority.GenerateSelfSignedCA(csr.CertificateRequest{
+		CN: "gravity.test",
+	})
+	c.Assert(err, check.IsNil)
+	s.ca = ca
+}
+
+func (s *signerSuite) TestLocalSignerLicenseValidates(c *check.C) {
+	license, err := NewLocalSigner(*s.ca).SignLicense(licenseapi.NewLicenseInfo{
+		MaxNodes: 3,
+		ValidFor: time.Hour,
+	})
+	c.Assert(err, check.IsNil)
+
+	parsed, err := licenseapi.ParseLicense(license)
+	c.Assert(err, check.IsNil)
+	c.Assert(parsed.Verify(s.ca.CertPEM), check.IsNil)
+	c.Assert(parsed.GetPayload().MaxNodes, check.Equals, 3)
+}
+
+func (s *signerSuite) TestExternalSignerLicenseValidates(c *check.C) {
+	signer := newFakeKMSSigner(c)
+	license, err := signer.SignLicense(licenseapi.NewLicenseInfo{
+		MaxNodes: 1,
+		ValidFor: time.Hour,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(signer.requests, check.HasLen, 1)
+
+	parsed, err := licenseapi.ParseLicense(license)
+	c.Assert(err, check.IsNil)
+	// the license validates against the CA the external signer controls,
+	// not against the local one
+	c.Assert(parsed.Verify(signer.ca.CertPEM), check.IsNil)
+	c.Assert(parsed.Verify(s.ca.CertPEM), check.NotNil)
+}
+
+// fakeKMSSigner emulates a signer backed by an external KMS: the CA key
+// pair never leaves the signer and every signing request is recorded
+type fakeKMSSigner struct {
+	ca       *authority.TLSKeyPair
+	requests []licenseapi.NewLicenseInfo
+}
+
+func newFakeKMSSigner(c *check.C) *fakeKMSSigner {
+	ca, err := authority.GenerateSelfSignedCA(csr.CertificateRequest{
+		CN: "kms.test",
+	})
+	c.Assert(err, check.IsNil)
+	return &fakeKMSSigner{ca: ca}
+}
+
+func (r *fakeKMSSigner) SignLicense(info licenseapi.NewLicenseInfo) (string, error) {
+	r.requests = append(r.requests, info)
+	info.TLSKeyPair = *r.ca
+	return licenseapi.NewLicense(info)
+}
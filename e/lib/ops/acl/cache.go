@@ -0,0 +1,135 @@
+// Copyright 2021 Gravitational Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acl
+
+import (
+	"sync"
+	"time"
+
+	teleservices "github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/trace"
+)
+
+// connectorCacheTTL bounds how long auth connector reads are served from
+// the cache before hitting the backend again
+const connectorCacheTTL = 10 * time.Second
+
+// clusterConnectors caches connector reads across ACL operator instances
+// which are constructed per request
+var clusterConnectors = newConnectorCache(connectorCacheTTL)
+
+// connectorCacheKey identifies a cached connector list.
+// The withSecrets flag is part of the key so an entry read with secrets
+// is never served to a request without them
+type connectorCacheKey struct {
+	cluster     string
+	kind        string
+	withSecrets bool
+}
+
+// connectorCache is a short-TTL cache for auth connector reads.
+// Auth connectors are fetched by the login UI on every login attempt so
+// caching them briefly shields the backend during login storms
+type connectorCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[connectorCacheKey]connectorCacheEntry
+}
+
+type connectorCacheEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+func newConnectorCache(ttl time.Duration) *connectorCache {
+	return &connectorCache{
+		ttl:     ttl,
+		entries: make(map[connectorCacheKey]connectorCacheEntry),
+	}
+}
+
+// get returns the value cached for the given key if it has not expired
+func (r *connectorCache) get(key connectorCacheKey) (interface{}, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry, ok := r.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(r.entries, key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+// put caches the value for the given key for the duration of the TTL
+func (r *connectorCache) put(key connectorCacheKey, value interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[key] = connectorCacheEntry{
+		value:   value,
+		expires: time.Now().Add(r.ttl),
+	}
+}
+
+// copyOIDCConnectors returns a deep copy of the connector list made via
+// a marshal round-trip. The cache is shared by concurrent requests so
+// entries are copied on both put and get to keep one caller's mutations
+// from being observed by another
+func copyOIDCConnectors(connectors []teleservices.OIDCConnector) ([]teleservices.OIDCConnector, error) {
+	marshaler := teleservices.GetOIDCConnectorMarshaler()
+	result := make([]teleservices.OIDCConnector, 0, len(connectors))
+	for _, connector := range connectors {
+		bytes, err := marshaler.MarshalOIDCConnector(connector)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		copied, err := marshaler.UnmarshalOIDCConnector(bytes)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		result = append(result, copied)
+	}
+	return result, nil
+}
+
+// copySAMLConnectors returns a deep copy of the connector list made via
+// a marshal round-trip, see copyOIDCConnectors
+func copySAMLConnectors(connectors []teleservices.SAMLConnector) ([]teleservices.SAMLConnector, error) {
+	marshaler := teleservices.GetSAMLConnectorMarshaler()
+	result := make([]teleservices.SAMLConnector, 0, len(connectors))
+	for _, connector := range connectors {
+		bytes, err := marshaler.MarshalSAMLConnector(connector)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		copied, err := marshaler.UnmarshalSAMLConnector(bytes)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		result = append(result, copied)
+	}
+	return result, nil
+}
+
+// invalidate drops the entries of the given connector kind cached for the
+// cluster, both with and without secrets
+func (r *connectorCache) invalidate(cluster, kind string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.entries, connectorCacheKey{cluster: cluster, kind: kind, withSecrets: true})
+	delete(r.entries, connectorCacheKey{cluster: cluster, kind: kind, withSecrets: false})
+}
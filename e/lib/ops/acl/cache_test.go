@@ -0,0 +1,209 @@
+// Copyright 2021 Gravitational Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acl
+
+import (
+	"context"
+	"time"
+
+	"github.com/gravitational/gravity/e/lib/ops"
+	oss "github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
+
+	teleservices "github.com/gravitational/teleport/lib/services"
+	check "gopkg.in/check.v1"
+)
+
+type CacheSuite struct{}
+
+var _ = check.Suite(&CacheSuite{})
+
+var cacheTestKey = oss.SiteKey{AccountID: "account", SiteDomain: "example.com"}
+
+func (s *CacheSuite) TestConnectorReadsAreCached(c *check.C) {
+	operator, backend := s.newCachedOperator(connectorCacheTTL)
+
+	for i := 0; i < 3; i++ {
+		connectors, err := operator.GetOIDCConnectors(cacheTestKey, false)
+		c.Assert(err, check.IsNil)
+		c.Assert(connectors, check.HasLen, 1)
+	}
+	for i := 0; i < 3; i++ {
+		connectors, err := operator.GetSAMLConnectors(cacheTestKey, false)
+		c.Assert(err, check.IsNil)
+		c.Assert(connectors, check.HasLen, 1)
+	}
+	// only the first read of each kind reaches the backend
+	c.Assert(backend.oidcReads, check.Equals, 1)
+	c.Assert(backend.samlReads, check.Equals, 1)
+}
+
+func (s *CacheSuite) TestConnectorCacheRespectsSecretsFlag(c *check.C) {
+	operator, backend := s.newCachedOperator(connectorCacheTTL)
+
+	connectors, err := operator.GetOIDCConnectors(cacheTestKey, true)
+	c.Assert(err, check.IsNil)
+	c.Assert(connectors[0].GetClientSecret(), check.Equals, "oidc-secret")
+
+	// the with-secrets entry is not served to a without-secrets request
+	connectors, err = operator.GetOIDCConnectors(cacheTestKey, false)
+	c.Assert(err, check.IsNil)
+	c.Assert(connectors[0].GetClientSecret(), check.Equals, "")
+	c.Assert(backend.oidcReads, check.Equals, 2)
+
+	// both variants are cached independently
+	connectors, err = operator.GetOIDCConnectors(cacheTestKey, true)
+	c.Assert(err, check.IsNil)
+	c.Assert(connectors[0].GetClientSecret(), check.Equals, "oidc-secret")
+	c.Assert(backend.oidcReads, check.Equals, 2)
+}
+
+func (s *CacheSuite) TestConnectorCacheServesCopies(c *check.C) {
+	operator, backend := s.newCachedOperator(connectorCacheTTL)
+
+	connectors, err := operator.GetOIDCConnectors(cacheTestKey, false)
+	c.Assert(err, check.IsNil)
+	// mutating the returned connector must not affect the cached entry
+	connectors[0].SetDisplay("mutated")
+
+	connectors, err = operator.GetOIDCConnectors(cacheTestKey, false)
+	c.Assert(err, check.IsNil)
+	c.Assert(connectors[0].GetDisplay(), check.Not(check.Equals), "mutated")
+	c.Assert(backend.oidcReads, check.Equals, 1)
+}
+
+func (s *CacheSuite) TestConnectorCacheInvalidatedOnWrite(c *check.C) {
+	operator, backend := s.newCachedOperator(connectorCacheTTL)
+
+	connectors, err := operator.GetOIDCConnectors(cacheTestKey, false)
+	c.Assert(err, check.IsNil)
+	c.Assert(connectors, check.HasLen, 1)
+
+	err = operator.UpsertOIDCConnector(context.TODO(), cacheTestKey,
+		storage.NewOIDCConnector("github", teleservices.OIDCConnectorSpecV2{
+			IssuerURL: "https://github.com",
+			ClientID:  "github-client",
+		}))
+	c.Assert(err, check.IsNil)
+
+	// the upsert invalidates the cached entry
+	connectors, err = operator.GetOIDCConnectors(cacheTestKey, false)
+	c.Assert(err, check.IsNil)
+	c.Assert(connectors, check.HasLen, 2)
+	c.Assert(backend.oidcReads, check.Equals, 2)
+
+	err = operator.DeleteOIDCConnector(context.TODO(), cacheTestKey, "github")
+	c.Assert(err, check.IsNil)
+
+	connectors, err = operator.GetOIDCConnectors(cacheTestKey, false)
+	c.Assert(err, check.IsNil)
+	c.Assert(connectors, check.HasLen, 1)
+	c.Assert(backend.oidcReads, check.Equals, 3)
+}
+
+func (s *CacheSuite) TestConnectorCacheExpires(c *check.C) {
+	operator, backend := s.newCachedOperator(time.Millisecond)
+
+	_, err := operator.GetSAMLConnectors(cacheTestKey, false)
+	c.Assert(err, check.IsNil)
+	time.Sleep(10 * time.Millisecond)
+	_, err = operator.GetSAMLConnectors(cacheTestKey, false)
+	c.Assert(err, check.IsNil)
+	// the entry has expired so the second read reaches the backend
+	c.Assert(backend.samlReads, check.Equals, 2)
+}
+
+// newCachedOperator returns an ACL operator with an allow-all checker, a
+// private connector cache with the given TTL and a connector backend fake
+func (s *CacheSuite) newCachedOperator(ttl time.Duration) (*OperatorACL, *fakeConnectorOperator) {
+	backend := &fakeConnectorOperator{
+		oidcConnectors: []teleservices.OIDCConnector{
+			storage.NewOIDCConnector("google", teleservices.OIDCConnectorSpecV2{
+				IssuerURL:    "https://accounts.google.com",
+				ClientID:     "google-client",
+				ClientSecret: "oidc-secret",
+			}),
+		},
+		samlConnectors: []teleservices.SAMLConnector{
+			storage.NewSAMLConnector("okta", teleservices.SAMLConnectorSpecV2{
+				AssertionConsumerService: "https://localhost/acs",
+				AttributesToRoles: []teleservices.AttributeMapping{
+					{Name: "groups", Value: "admins", Roles: []string{"@teleadmin"}},
+				},
+			}),
+		},
+	}
+	user := storage.NewUser("test@example.com", storage.UserSpecV2{})
+	ossACL := oss.OperatorWithACL(&fakeSiteOperator{}, nil, user, allowingChecker{})
+	operator := OperatorWithACL(ossACL, backend)
+	operator.connectors = newConnectorCache(ttl)
+	return operator, backend
+}
+
+// allowingChecker permits every action so calls reach the wrapped operator
+type allowingChecker struct {
+	teleservices.AccessChecker
+}
+
+func (allowingChecker) CheckAccessToRule(ctx teleservices.RuleContext, namespace, resource, verb string, silent bool) error {
+	return nil
+}
+
+// fakeConnectorOperator implements the auth connector subset of the
+// Operator interface counting backend reads
+type fakeConnectorOperator struct {
+	ops.Operator
+	oidcConnectors []teleservices.OIDCConnector
+	samlConnectors []teleservices.SAMLConnector
+	oidcReads      int
+	samlReads      int
+}
+
+func (r *fakeConnectorOperator) GetOIDCConnectors(key oss.SiteKey, withSecrets bool) ([]teleservices.OIDCConnector, error) {
+	r.oidcReads++
+	if withSecrets {
+		return r.oidcConnectors, nil
+	}
+	var stripped []teleservices.OIDCConnector
+	for _, connector := range r.oidcConnectors {
+		stripped = append(stripped, storage.NewOIDCConnector(
+			connector.GetName(), teleservices.OIDCConnectorSpecV2{
+				IssuerURL: connector.GetIssuerURL(),
+				ClientID:  connector.GetClientID(),
+			}))
+	}
+	return stripped, nil
+}
+
+func (r *fakeConnectorOperator) UpsertOIDCConnector(ctx context.Context, key oss.SiteKey, connector teleservices.OIDCConnector) error {
+	r.oidcConnectors = append(r.oidcConnectors, connector)
+	return nil
+}
+
+func (r *fakeConnectorOperator) DeleteOIDCConnector(ctx context.Context, key oss.SiteKey, name string) error {
+	var kept []teleservices.OIDCConnector
+	for _, connector := range r.oidcConnectors {
+		if connector.GetName() != name {
+			kept = append(kept, connector)
+		}
+	}
+	r.oidcConnectors = kept
+	return nil
+}
+
+func (r *fakeConnectorOperator) GetSAMLConnectors(key oss.SiteKey, withSecrets bool) ([]teleservices.SAMLConnector, error) {
+	r.samlReads++
+	return r.samlConnectors, nil
+}
@@ -16,6 +16,7 @@ package acl
 
 import (
 	"context"
+	"time"
 
 	"github.com/gravitational/gravity/e/lib/ops"
 	"github.com/gravitational/gravity/lib/constants"
@@ -33,6 +34,11 @@ type OperatorACL struct {
 	*oss.OperatorACL
 	// operator is the enterprise operator service
 	operator ops.Operator
+	// verbs optionally overrides the ACL verb required by individual
+	// operator methods
+	verbs VerbOverrides
+	// connectors caches auth connector reads, see connectorCache
+	connectors *connectorCache
 }
 
 // OperatorWithACL returns a new enterprise ACL operator
@@ -40,12 +46,89 @@ func OperatorWithACL(operatorACL *oss.OperatorACL, operator ops.Operator) *Opera
 	return &OperatorACL{
 		OperatorACL: operatorACL,
 		operator:    operator,
+		connectors:  clusterConnectors,
 	}
 }
 
+// OperatorWithACLVerbs returns a new enterprise ACL operator with the
+// provided per-method verb overrides replacing the built-in defaults
+func OperatorWithACLVerbs(operatorACL *oss.OperatorACL, operator ops.Operator, verbs VerbOverrides) (*OperatorACL, error) {
+	if err := verbs.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &OperatorACL{
+		OperatorACL: operatorACL,
+		operator:    operator,
+		verbs:       verbs,
+		connectors:  clusterConnectors,
+	}, nil
+}
+
+// VerbOverrides maps operator method names to the ACL verb they require
+// instead of the built-in default, e.g. making CheckSiteLicense require
+// only the read verb
+type VerbOverrides map[string]string
+
+// Check validates the verb mapping
+func (r VerbOverrides) Check() error {
+	for method, verb := range r {
+		if !overridableMethods[method] {
+			return trace.BadParameter("unknown operator method %q in verb overrides", method)
+		}
+		switch verb {
+		case teleservices.VerbRead, teleservices.VerbList, teleservices.VerbCreate,
+			teleservices.VerbUpdate, teleservices.VerbDelete, storage.VerbRegister,
+			storage.VerbRevoke:
+		default:
+			return trace.BadParameter("unsupported verb %q for operator method %q", verb, method)
+		}
+	}
+	return nil
+}
+
+// verb returns the ACL verb the specified operator method requires,
+// preferring a configured override over the built-in default
+func (o *OperatorACL) verb(method, defaultVerb string) string {
+	if verb, ok := o.verbs[method]; ok {
+		return verb
+	}
+	return defaultVerb
+}
+
+// overridableMethods enumerates the operator methods whose required ACL
+// verb can be overridden via VerbOverrides
+var overridableMethods = map[string]bool{
+	"RegisterAgent":                   true,
+	"RequestClusterCopy":              true,
+	"GetClusterEndpoints":             true,
+	"UpdateClusterEndpoints":          true,
+	"CheckForUpdate":                  true,
+	"ListAvailableUpdates":            true,
+	"DownloadUpdate":                  true,
+	"GetClustersWithAvailableUpdates": true,
+	"EnablePeriodicUpdates":           true,
+	"DisablePeriodicUpdates":          true,
+	"StartPeriodicUpdates":            true,
+	"StopPeriodicUpdates":             true,
+	"PauseUpdatesFor":                 true,
+	"PeriodicUpdatesStatus":           true,
+	"UpsertTrustedCluster":            true,
+	"DeleteTrustedCluster":            true,
+	"GetTrustedClusters":              true,
+	"GetTrustedCluster":               true,
+	"PingTrustedCluster":              true,
+	"AcceptRemoteCluster":             true,
+	"RemoveRemoteCluster":             true,
+	"NewLicense":                      true,
+	"CheckSiteLicense":                true,
+	"UpdateLicense":                   true,
+	"GetLicenseCA":                    true,
+	"RevokeLicense":                   true,
+}
+
 // RegisterAgent registers an install agent
 func (o *OperatorACL) RegisterAgent(req ops.RegisterAgentRequest) (*ops.RegisterAgentResponse, error) {
-	if err := o.ClusterAction(req.ClusterName, storage.KindCluster, teleservices.VerbUpdate); err != nil {
+	if err := o.ClusterAction(req.ClusterName, storage.KindCluster, o.verb("RegisterAgent", teleservices.VerbUpdate)); err != nil {
 		return nil, trace.Wrap(err)
 	}
 	return o.operator.RegisterAgent(req)
@@ -54,7 +137,7 @@ func (o *OperatorACL) RegisterAgent(req ops.RegisterAgentRequest) (*ops.Register
 // RequestClusterCopy replicates the cluster specified in the provided request
 // and its data from the remote Ops Center
 func (o *OperatorACL) RequestClusterCopy(req ops.ClusterCopyRequest) error {
-	if err := o.Action(storage.KindCluster, teleservices.VerbCreate); err != nil {
+	if err := o.Action(storage.KindCluster, o.verb("RequestClusterCopy", teleservices.VerbCreate)); err != nil {
 		return trace.Wrap(err)
 	}
 	return o.operator.RequestClusterCopy(req)
@@ -63,7 +146,7 @@ func (o *OperatorACL) RequestClusterCopy(req ops.ClusterCopyRequest) error {
 // GetClusterEndpoints returns the cluster management endpoints such
 // as control panel advertise address and agents advertise address
 func (o *OperatorACL) GetClusterEndpoints(key oss.SiteKey) (storage.Endpoints, error) {
-	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbRead); err != nil {
+	if err := o.endpointsAction(key.SiteDomain, o.verb("GetClusterEndpoints", teleservices.VerbRead)); err != nil {
 		return nil, trace.Wrap(err)
 	}
 	return o.operator.GetClusterEndpoints(key)
@@ -71,34 +154,78 @@ func (o *OperatorACL) GetClusterEndpoints(key oss.SiteKey) (storage.Endpoints, e
 
 // UpdateClusterEndpoints updates the cluster management endpoints
 func (o *OperatorACL) UpdateClusterEndpoints(ctx context.Context, key oss.SiteKey, endpoints storage.Endpoints) error {
-	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
+	if err := o.endpointsAction(key.SiteDomain, o.verb("UpdateClusterEndpoints", teleservices.VerbUpdate)); err != nil {
 		return trace.Wrap(err)
 	}
 	return o.operator.UpdateClusterEndpoints(ctx, key, endpoints)
 }
 
+// endpointsAction checks access to the dedicated endpoints resource kind
+// so endpoint management can be delegated on its own. It falls back to the
+// same action on the cluster kind so existing roles retain access
+func (o *OperatorACL) endpointsAction(clusterName, verb string) error {
+	err := o.ClusterAction(clusterName, storage.KindEndpoints, verb)
+	if err == nil {
+		return nil
+	}
+	return trace.Wrap(o.ClusterAction(clusterName, storage.KindCluster, verb))
+}
+
 // CheckForUpdates checks with remote OpsCenter if there is a newer version
 // of the installed application
 func (o *OperatorACL) CheckForUpdate(key oss.SiteKey) (*loc.Locator, error) {
-	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbRead); err != nil {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, o.verb("CheckForUpdate", teleservices.VerbRead)); err != nil {
 		return nil, trace.Wrap(err)
 	}
 	return o.operator.CheckForUpdate(key)
 }
 
+// ListAvailableUpdates returns all application versions newer than the
+// installed one available on the remote Ops Center
+func (o *OperatorACL) ListAvailableUpdates(key oss.SiteKey) (*ops.ListAvailableUpdatesResponse, error) {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, o.verb("ListAvailableUpdates", teleservices.VerbRead)); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.ListAvailableUpdates(key)
+}
+
 // DownloadUpdates downloads the provided application version from remote
 // Ops Center
 func (o *OperatorACL) DownloadUpdate(ctx context.Context, req ops.DownloadUpdateRequest) error {
-	if err := o.ClusterAction(req.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
+	if err := o.ClusterAction(req.SiteDomain, storage.KindCluster, o.verb("DownloadUpdate", teleservices.VerbUpdate)); err != nil {
 		return trace.Wrap(err)
 	}
 	return o.operator.DownloadUpdate(ctx, req)
 }
 
+// GetClustersWithAvailableUpdates checks all clusters of the account
+// for updates and returns only those that have a newer application
+// version available. The account-wide list permission is required
+// before the update sweep runs; the read check is then applied to
+// every cluster individually so the listing only includes clusters
+// the user is allowed to view
+func (o *OperatorACL) GetClustersWithAvailableUpdates(accountID string) ([]ops.ClusterUpdateInfo, error) {
+	if err := o.Action(storage.KindCluster, o.verb("GetClustersWithAvailableUpdates", teleservices.VerbList)); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	updates, err := o.operator.GetClustersWithAvailableUpdates(accountID)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var filtered []ops.ClusterUpdateInfo
+	for _, update := range updates {
+		if err := o.ClusterAction(update.ClusterName, storage.KindCluster, o.verb("GetClustersWithAvailableUpdates", teleservices.VerbRead)); err != nil {
+			continue
+		}
+		filtered = append(filtered, update)
+	}
+	return filtered, nil
+}
+
 // EnablePeriodicUpdates turns periodic updates for the cluster on or
 // updates the interval
 func (o *OperatorACL) EnablePeriodicUpdates(ctx context.Context, req ops.EnablePeriodicUpdatesRequest) error {
-	if err := o.ClusterAction(req.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
+	if err := o.ClusterAction(req.SiteDomain, storage.KindCluster, o.verb("EnablePeriodicUpdates", teleservices.VerbUpdate)); err != nil {
 		return trace.Wrap(err)
 	}
 	return o.operator.EnablePeriodicUpdates(ctx, req)
@@ -107,7 +234,7 @@ func (o *OperatorACL) EnablePeriodicUpdates(ctx context.Context, req ops.EnableP
 // DisablePeriodicUpdates turns periodic updates for the cluster off and
 // stops the update fetch loop if it's running
 func (o *OperatorACL) DisablePeriodicUpdates(ctx context.Context, key oss.SiteKey) error {
-	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, o.verb("DisablePeriodicUpdates", teleservices.VerbUpdate)); err != nil {
 		return trace.Wrap(err)
 	}
 	return o.operator.DisablePeriodicUpdates(ctx, key)
@@ -115,7 +242,7 @@ func (o *OperatorACL) DisablePeriodicUpdates(ctx context.Context, key oss.SiteKe
 
 // StartPeriodicUpdates starts periodic updates check
 func (o *OperatorACL) StartPeriodicUpdates(key oss.SiteKey) error {
-	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, o.verb("StartPeriodicUpdates", teleservices.VerbUpdate)); err != nil {
 		return trace.Wrap(err)
 	}
 	return o.operator.StartPeriodicUpdates(key)
@@ -123,15 +250,24 @@ func (o *OperatorACL) StartPeriodicUpdates(key oss.SiteKey) error {
 
 // StopPeriodicUpdates stops periodic updates check without disabling it
 func (o *OperatorACL) StopPeriodicUpdates(key oss.SiteKey) error {
-	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, o.verb("StopPeriodicUpdates", teleservices.VerbUpdate)); err != nil {
 		return trace.Wrap(err)
 	}
 	return o.operator.StopPeriodicUpdates(key)
 }
 
+// PauseUpdatesFor stops the periodic updates check and schedules it to
+// resume automatically after the provided duration
+func (o *OperatorACL) PauseUpdatesFor(ctx context.Context, key oss.SiteKey, duration time.Duration) error {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, o.verb("PauseUpdatesFor", teleservices.VerbUpdate)); err != nil {
+		return trace.Wrap(err)
+	}
+	return o.operator.PauseUpdatesFor(ctx, key, duration)
+}
+
 // PeriodicUpdatesStatus returns the status of periodic updates for the cluster
 func (o *OperatorACL) PeriodicUpdatesStatus(key oss.SiteKey) (*ops.PeriodicUpdatesStatusResponse, error) {
-	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbRead); err != nil {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, o.verb("PeriodicUpdatesStatus", teleservices.VerbRead)); err != nil {
 		return nil, trace.Wrap(err)
 	}
 	return o.operator.PeriodicUpdatesStatus(key)
@@ -139,7 +275,7 @@ func (o *OperatorACL) PeriodicUpdatesStatus(key oss.SiteKey) (*ops.PeriodicUpdat
 
 // UpsertTrustedCluster creates or updates a trusted cluster
 func (o *OperatorACL) UpsertTrustedCluster(ctx context.Context, key oss.SiteKey, cluster storage.TrustedCluster) error {
-	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, o.verb("UpsertTrustedCluster", teleservices.VerbUpdate)); err != nil {
 		return trace.Wrap(err)
 	}
 	return o.operator.UpsertTrustedCluster(ctx, key, cluster)
@@ -147,7 +283,7 @@ func (o *OperatorACL) UpsertTrustedCluster(ctx context.Context, key oss.SiteKey,
 
 // DeleteTrustedCluster deletes a trusted cluster by name
 func (o *OperatorACL) DeleteTrustedCluster(ctx context.Context, req ops.DeleteTrustedClusterRequest) error {
-	if err := o.ClusterAction(req.ClusterName, storage.KindCluster, teleservices.VerbUpdate); err != nil {
+	if err := o.ClusterAction(req.ClusterName, storage.KindCluster, o.verb("DeleteTrustedCluster", teleservices.VerbUpdate)); err != nil {
 		return trace.Wrap(err)
 	}
 	return o.operator.DeleteTrustedCluster(ctx, req)
@@ -155,24 +291,38 @@ func (o *OperatorACL) DeleteTrustedCluster(ctx context.Context, req ops.DeleteTr
 
 // GetTrustedClusters returns a list of configured trusted clusters
 func (o *OperatorACL) GetTrustedClusters(key oss.SiteKey) ([]storage.TrustedCluster, error) {
-	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbRead); err != nil {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, o.verb("GetTrustedClusters", teleservices.VerbRead)); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	clusters, err := o.operator.GetTrustedClusters(key)
+	if err != nil {
 		return nil, trace.Wrap(err)
 	}
-	return o.operator.GetTrustedClusters(key)
+	ops.SortTrustedClusters(clusters)
+	return clusters, nil
 }
 
 // GetTrustedCluster returns trusted cluster by name
 func (o *OperatorACL) GetTrustedCluster(key oss.SiteKey, name string) (storage.TrustedCluster, error) {
-	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbRead); err != nil {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, o.verb("GetTrustedCluster", teleservices.VerbRead)); err != nil {
 		return nil, trace.Wrap(err)
 	}
 	return o.operator.GetTrustedCluster(key, name)
 }
 
+// PingTrustedCluster verifies connectivity to the trusted cluster
+// specified by name
+func (o *OperatorACL) PingTrustedCluster(key oss.SiteKey, name string) (*ops.TrustedClusterPingResponse, error) {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, o.verb("PingTrustedCluster", teleservices.VerbRead)); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.PingTrustedCluster(key, name)
+}
+
 // AcceptRemoteCluster defines the handshake between a remote cluster and this
 // Ops Center
 func (o *OperatorACL) AcceptRemoteCluster(req ops.AcceptRemoteClusterRequest) (*ops.AcceptRemoteClusterResponse, error) {
-	if err := o.Action(storage.KindCluster, storage.VerbRegister); err != nil {
+	if err := o.Action(storage.KindCluster, o.verb("AcceptRemoteCluster", storage.VerbRegister)); err != nil {
 		return nil, trace.Wrap(err)
 	}
 	return o.operator.AcceptRemoteCluster(req)
@@ -180,7 +330,7 @@ func (o *OperatorACL) AcceptRemoteCluster(req ops.AcceptRemoteClusterRequest) (*
 
 // RemoveRemoteCluster removes the cluster entry specified in the request
 func (o *OperatorACL) RemoveRemoteCluster(req ops.RemoveRemoteClusterRequest) error {
-	if err := o.Action(storage.KindCluster, storage.VerbRegister); err != nil {
+	if err := o.Action(storage.KindCluster, o.verb("RemoveRemoteCluster", storage.VerbRegister)); err != nil {
 		return trace.Wrap(err)
 	}
 	return o.operator.RemoveRemoteCluster(req)
@@ -188,7 +338,7 @@ func (o *OperatorACL) RemoveRemoteCluster(req ops.RemoveRemoteClusterRequest) er
 
 // NewLicense generates a new license signed with this Ops Center CA
 func (o *OperatorACL) NewLicense(ctx context.Context, req ops.NewLicenseRequest) (string, error) {
-	if err := o.Action(storage.KindLicense, teleservices.VerbCreate); err != nil {
+	if err := o.Action(storage.KindLicense, o.verb("NewLicense", teleservices.VerbCreate)); err != nil {
 		return "", trace.Wrap(err)
 	}
 	return o.operator.NewLicense(ctx, req)
@@ -197,7 +347,7 @@ func (o *OperatorACL) NewLicense(ctx context.Context, req ops.NewLicenseRequest)
 // CheckSiteLicense makes sure the license installed on cluster is correct
 func (o *OperatorACL) CheckSiteLicense(ctx context.Context, key oss.SiteKey) error {
 	// the "update" permission is required here because license check may deactivate the site
-	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, o.verb("CheckSiteLicense", teleservices.VerbUpdate)); err != nil {
 		return trace.Wrap(err)
 	}
 	return o.operator.CheckSiteLicense(ctx, key)
@@ -205,7 +355,7 @@ func (o *OperatorACL) CheckSiteLicense(ctx context.Context, key oss.SiteKey) err
 
 // UpdateLicense updates license installed on cluster and runs a respective app hook
 func (o *OperatorACL) UpdateLicense(ctx context.Context, req ops.UpdateLicenseRequest) error {
-	if err := o.ClusterAction(req.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
+	if err := o.ClusterAction(req.SiteDomain, storage.KindCluster, o.verb("UpdateLicense", teleservices.VerbUpdate)); err != nil {
 		return trace.Wrap(err)
 	}
 	return o.operator.UpdateLicense(ctx, req)
@@ -213,9 +363,24 @@ func (o *OperatorACL) UpdateLicense(ctx context.Context, req ops.UpdateLicenseRe
 
 // GetLicenseCA returns CA certificate Ops Center uses to sign licenses
 func (o *OperatorACL) GetLicenseCA() ([]byte, error) {
+	if err := o.Action(storage.KindLicense, o.verb("GetLicenseCA", teleservices.VerbRead)); err != nil {
+		// a license-issuance grant implies the ability to view the signing
+		// CA so existing create-only roles keep working
+		if err := o.Action(storage.KindLicense, teleservices.VerbCreate); err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
 	return o.operator.GetLicenseCA()
 }
 
+// RevokeLicense revokes a previously issued license
+func (o *OperatorACL) RevokeLicense(ctx context.Context, req ops.RevokeLicenseRequest) error {
+	if err := o.Action(storage.KindLicense, o.verb("RevokeLicense", storage.VerbRevoke)); err != nil {
+		return trace.Wrap(err)
+	}
+	return o.operator.RevokeLicense(ctx, req)
+}
+
 // UpsertRole creates a new role
 func (o *OperatorACL) UpsertRole(ctx context.Context, key oss.SiteKey, role teleservices.Role) error {
 	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
@@ -249,6 +414,16 @@ func (o *OperatorACL) GetRoles(key oss.SiteKey) ([]teleservices.Role, error) {
 	return o.operator.GetRoles(key)
 }
 
+// GetRolesByLabel returns the roles matching the provided label selector
+func (o *OperatorACL) GetRolesByLabel(key oss.SiteKey, selector string) ([]teleservices.Role, error) {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbRead); err != nil {
+		if err := o.roleActions(teleservices.VerbList, teleservices.VerbRead); err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+	return o.operator.GetRolesByLabel(key, selector)
+}
+
 // DeleteRole deletes a role by name
 func (o *OperatorACL) DeleteRole(ctx context.Context, key oss.SiteKey, name string) error {
 	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
@@ -273,7 +448,11 @@ func (o *OperatorACL) UpsertOIDCConnector(ctx context.Context, key oss.SiteKey,
 			return trace.Wrap(err)
 		}
 	}
-	return o.operator.UpsertOIDCConnector(ctx, key, connector)
+	if err := o.operator.UpsertOIDCConnector(ctx, key, connector); err != nil {
+		return trace.Wrap(err)
+	}
+	o.connectors.invalidate(key.SiteDomain, teleservices.KindOIDCConnector)
+	return nil
 }
 
 // GetOIDCConnector returns an OIDC connector by name
@@ -297,7 +476,32 @@ func (o *OperatorACL) GetOIDCConnectors(key oss.SiteKey, withSecrets bool) ([]te
 			return nil, trace.Wrap(err)
 		}
 	}
-	return o.operator.GetOIDCConnectors(key, withSecrets)
+	cacheKey := connectorCacheKey{
+		cluster:     key.SiteDomain,
+		kind:        teleservices.KindOIDCConnector,
+		withSecrets: withSecrets,
+	}
+	if cached, ok := o.connectors.get(cacheKey); ok {
+		copied, err := copyOIDCConnectors(cached.([]teleservices.OIDCConnector))
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return copied, nil
+	}
+	connectors, err := o.operator.GetOIDCConnectors(key, withSecrets)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	// the connectors have already been stripped of secrets by the backend
+	// when requested without them so the cached value is safe to serve.
+	// A deep copy is cached so the caller's mutations do not leak into
+	// the entries served to other requests
+	copied, err := copyOIDCConnectors(connectors)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	o.connectors.put(cacheKey, copied)
+	return connectors, nil
 }
 
 // DeleteOIDCConnector deletes an OIDC connector by name
@@ -307,7 +511,11 @@ func (o *OperatorACL) DeleteOIDCConnector(ctx context.Context, key oss.SiteKey,
 			return trace.Wrap(err)
 		}
 	}
-	return o.operator.DeleteOIDCConnector(ctx, key, name)
+	if err := o.operator.DeleteOIDCConnector(ctx, key, name); err != nil {
+		return trace.Wrap(err)
+	}
+	o.connectors.invalidate(key.SiteDomain, teleservices.KindOIDCConnector)
+	return nil
 }
 
 // UpsertSAMLConnector creates or updates a SAML connector
@@ -317,7 +525,11 @@ func (o *OperatorACL) UpsertSAMLConnector(ctx context.Context, key oss.SiteKey,
 			return trace.Wrap(err)
 		}
 	}
-	return o.operator.UpsertSAMLConnector(ctx, key, connector)
+	if err := o.operator.UpsertSAMLConnector(ctx, key, connector); err != nil {
+		return trace.Wrap(err)
+	}
+	o.connectors.invalidate(key.SiteDomain, teleservices.KindSAMLConnector)
+	return nil
 }
 
 // GetSAMLConnector returns a SAML connector by name
@@ -341,7 +553,32 @@ func (o *OperatorACL) GetSAMLConnectors(key oss.SiteKey, withSecrets bool) ([]te
 			return nil, trace.Wrap(err)
 		}
 	}
-	return o.operator.GetSAMLConnectors(key, withSecrets)
+	cacheKey := connectorCacheKey{
+		cluster:     key.SiteDomain,
+		kind:        teleservices.KindSAMLConnector,
+		withSecrets: withSecrets,
+	}
+	if cached, ok := o.connectors.get(cacheKey); ok {
+		copied, err := copySAMLConnectors(cached.([]teleservices.SAMLConnector))
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return copied, nil
+	}
+	connectors, err := o.operator.GetSAMLConnectors(key, withSecrets)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	// the connectors have already been stripped of signing keys by the
+	// backend when requested without secrets so the cached value is safe
+	// to serve. A deep copy is cached so the caller's mutations do not
+	// leak into the entries served to other requests
+	copied, err := copySAMLConnectors(connectors)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	o.connectors.put(cacheKey, copied)
+	return connectors, nil
 }
 
 // DeleteSAMLConnector deletes a SAML connector by name
@@ -351,7 +588,43 @@ func (o *OperatorACL) DeleteSAMLConnector(ctx context.Context, key oss.SiteKey,
 			return trace.Wrap(err)
 		}
 	}
-	return o.operator.DeleteSAMLConnector(ctx, key, name)
+	if err := o.operator.DeleteSAMLConnector(ctx, key, name); err != nil {
+		return trace.Wrap(err)
+	}
+	o.connectors.invalidate(key.SiteDomain, teleservices.KindSAMLConnector)
+	return nil
+}
+
+// GetUserCapabilities evaluates the ACL for the context user across the
+// well-known actions and reports which of them the user may perform so
+// UIs can disable controls up front. Only ACL checks are performed,
+// nothing is mutated
+func (o *OperatorACL) GetUserCapabilities(ctx context.Context, key oss.SiteKey) (*ops.UserCapabilities, error) {
+	capabilities := map[string]bool{
+		ops.CapabilityUpgrade: o.ClusterAction(key.SiteDomain, storage.KindCluster,
+			teleservices.VerbUpdate) == nil,
+		ops.CapabilityDeleteCluster: o.ClusterAction(key.SiteDomain, storage.KindCluster,
+			teleservices.VerbDelete) == nil,
+		ops.CapabilityManageRoles: o.roleActions(teleservices.VerbCreate,
+			teleservices.VerbUpdate, teleservices.VerbDelete) == nil,
+		ops.CapabilityManageUsers: o.userActions(teleservices.VerbCreate,
+			teleservices.VerbUpdate, teleservices.VerbDelete) == nil,
+		ops.CapabilityIssueLicense: o.Action(storage.KindLicense,
+			o.verb("NewLicense", teleservices.VerbCreate)) == nil,
+		ops.CapabilityRevokeLicense: o.Action(storage.KindLicense,
+			o.verb("RevokeLicense", storage.VerbRevoke)) == nil,
+	}
+	return &ops.UserCapabilities{Capabilities: capabilities}, nil
+}
+
+// userActions checks access to the specified actions on the "user" resource
+func (o *OperatorACL) userActions(actions ...string) error {
+	for _, action := range actions {
+		if err := o.Action(teleservices.KindUser, action); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
 }
 
 // roleActions checks access to the specified actions on the "role" resource
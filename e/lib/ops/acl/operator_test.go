@@ -0,0 +1,207 @@
+// Copyright 2021 Gravitational Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acl
+
+import (
+	"context"
+
+	"github.com/gravitational/gravity/e/lib/ops"
+	oss "github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
+
+	teleservices "github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/trace"
+	check "gopkg.in/check.v1"
+)
+
+type VerbsSuite struct{}
+
+var _ = check.Suite(&VerbsSuite{})
+
+func (s *VerbsSuite) TestVerbOverridesValidation(c *check.C) {
+	c.Assert(VerbOverrides(nil).Check(), check.IsNil)
+	c.Assert(VerbOverrides{
+		"CheckSiteLicense": teleservices.VerbRead,
+	}.Check(), check.IsNil)
+
+	err := VerbOverrides{"NoSuchMethod": teleservices.VerbRead}.Check()
+	c.Assert(trace.IsBadParameter(err), check.Equals, true,
+		check.Commentf("unexpected error: %v", err))
+
+	err = VerbOverrides{"CheckSiteLicense": "frobnicate"}.Check()
+	c.Assert(trace.IsBadParameter(err), check.Equals, true,
+		check.Commentf("unexpected error: %v", err))
+
+	_, err = OperatorWithACLVerbs(nil, nil, VerbOverrides{"NoSuchMethod": teleservices.VerbRead})
+	c.Assert(trace.IsBadParameter(err), check.Equals, true,
+		check.Commentf("unexpected error: %v", err))
+}
+
+func (s *VerbsSuite) TestVerbOverrideChangesRequiredVerb(c *check.C) {
+	checker := &recordingChecker{}
+	user := storage.NewUser("test@example.com", storage.UserSpecV2{})
+	ossACL := oss.OperatorWithACL(nil, nil, user, checker)
+
+	// the default mapping requires the create verb for NewLicense
+	operator := OperatorWithACL(ossACL, nil)
+	_, err := operator.NewLicense(context.TODO(), ops.NewLicenseRequest{})
+	c.Assert(trace.IsAccessDenied(err), check.Equals, true)
+
+	// the override changes the required verb to read
+	operator, err = OperatorWithACLVerbs(ossACL, nil, VerbOverrides{
+		"NewLicense": teleservices.VerbRead,
+	})
+	c.Assert(err, check.IsNil)
+	_, err = operator.NewLicense(context.TODO(), ops.NewLicenseRequest{})
+	c.Assert(trace.IsAccessDenied(err), check.Equals, true)
+
+	c.Assert(checker.verbs, check.DeepEquals, []string{
+		teleservices.VerbCreate, teleservices.VerbRead,
+	})
+}
+
+func (s *VerbsSuite) TestLicenseVerbs(c *check.C) {
+	checker := &recordingChecker{}
+	user := storage.NewUser("test@example.com", storage.UserSpecV2{})
+	ossACL := oss.OperatorWithACL(nil, nil, user, checker)
+	operator := OperatorWithACL(ossACL, nil)
+
+	// revoking requires the dedicated revoke verb
+	err := operator.RevokeLicense(context.TODO(), ops.RevokeLicenseRequest{License: "license"})
+	c.Assert(trace.IsAccessDenied(err), check.Equals, true)
+	c.Assert(checker.verbs, check.DeepEquals, []string{storage.VerbRevoke})
+
+	// reading the license CA requires the read verb, falling back to
+	// create so license-issuance roles keep working
+	checker.verbs = nil
+	_, err = operator.GetLicenseCA()
+	c.Assert(trace.IsAccessDenied(err), check.Equals, true)
+	c.Assert(checker.verbs, check.DeepEquals, []string{
+		teleservices.VerbRead, teleservices.VerbCreate,
+	})
+}
+
+func (s *VerbsSuite) TestEndpointsVerbs(c *check.C) {
+	checker := &recordingChecker{}
+	user := storage.NewUser("test@example.com", storage.UserSpecV2{})
+	ossACL := oss.OperatorWithACL(&fakeSiteOperator{}, nil, user, checker)
+	operator := OperatorWithACL(ossACL, nil)
+	key := oss.SiteKey{AccountID: "account", SiteDomain: "example.com"}
+
+	// endpoint management checks the dedicated endpoints kind first and
+	// falls back to the cluster kind so existing roles keep working
+	_, err := operator.GetClusterEndpoints(key)
+	c.Assert(trace.IsAccessDenied(err), check.Equals, true)
+	c.Assert(checker.kinds, check.DeepEquals, []string{
+		storage.KindEndpoints, storage.KindCluster,
+	})
+	c.Assert(checker.verbs, check.DeepEquals, []string{
+		teleservices.VerbRead, teleservices.VerbRead,
+	})
+
+	checker.kinds, checker.verbs = nil, nil
+	err = operator.UpdateClusterEndpoints(context.TODO(), key, nil)
+	c.Assert(trace.IsAccessDenied(err), check.Equals, true)
+	c.Assert(checker.kinds, check.DeepEquals, []string{
+		storage.KindEndpoints, storage.KindCluster,
+	})
+	c.Assert(checker.verbs, check.DeepEquals, []string{
+		teleservices.VerbUpdate, teleservices.VerbUpdate,
+	})
+}
+
+func (s *VerbsSuite) TestGetUserCapabilities(c *check.C) {
+	key := oss.SiteKey{AccountID: "account", SiteDomain: "example.com"}
+	user := storage.NewUser("test@example.com", storage.UserSpecV2{})
+
+	// an admin is granted every capability
+	ossACL := oss.OperatorWithACL(&fakeSiteOperator{}, nil, user, allowingChecker{})
+	operator := OperatorWithACL(ossACL, nil)
+	capabilities, err := operator.GetUserCapabilities(context.TODO(), key)
+	c.Assert(err, check.IsNil)
+	c.Assert(capabilities.Capabilities, check.HasLen, len(ops.ClusterCapabilities))
+	for _, capability := range ops.ClusterCapabilities {
+		c.Assert(capabilities.Can(capability), check.Equals, true,
+			check.Commentf("expected capability %v", capability))
+	}
+
+	// a read-only user is granted none
+	ossACL = oss.OperatorWithACL(&fakeSiteOperator{}, nil, user, &recordingChecker{})
+	operator = OperatorWithACL(ossACL, nil)
+	capabilities, err = operator.GetUserCapabilities(context.TODO(), key)
+	c.Assert(err, check.IsNil)
+	for _, capability := range ops.ClusterCapabilities {
+		c.Assert(capabilities.Can(capability), check.Equals, false,
+			check.Commentf("unexpected capability %v", capability))
+	}
+
+	// mixed grants are reflected capability by capability
+	checker := &ruleChecker{allowed: map[string][]string{
+		storage.KindCluster: {teleservices.VerbUpdate},
+		teleservices.KindRole: {
+			teleservices.VerbCreate, teleservices.VerbUpdate, teleservices.VerbDelete,
+		},
+	}}
+	ossACL = oss.OperatorWithACL(&fakeSiteOperator{}, nil, user, checker)
+	operator = OperatorWithACL(ossACL, nil)
+	capabilities, err = operator.GetUserCapabilities(context.TODO(), key)
+	c.Assert(err, check.IsNil)
+	c.Assert(capabilities.Can(ops.CapabilityUpgrade), check.Equals, true)
+	c.Assert(capabilities.Can(ops.CapabilityManageRoles), check.Equals, true)
+	c.Assert(capabilities.Can(ops.CapabilityDeleteCluster), check.Equals, false)
+	c.Assert(capabilities.Can(ops.CapabilityManageUsers), check.Equals, false)
+	c.Assert(capabilities.Can(ops.CapabilityIssueLicense), check.Equals, false)
+	c.Assert(capabilities.Can(ops.CapabilityRevokeLicense), check.Equals, false)
+}
+
+// ruleChecker allows only the verbs listed per resource kind
+type ruleChecker struct {
+	teleservices.AccessChecker
+	allowed map[string][]string
+}
+
+func (r *ruleChecker) CheckAccessToRule(ctx teleservices.RuleContext, namespace, resource, verb string, silent bool) error {
+	for _, allowed := range r.allowed[resource] {
+		if allowed == verb {
+			return nil
+		}
+	}
+	return trace.AccessDenied("denied %v on %v", verb, resource)
+}
+
+// recordingChecker records the resource kinds and verbs requested via
+// CheckAccessToRule and denies every action so the call never reaches the
+// wrapped operator
+type recordingChecker struct {
+	teleservices.AccessChecker
+	kinds []string
+	verbs []string
+}
+
+func (r *recordingChecker) CheckAccessToRule(ctx teleservices.RuleContext, namespace, resource, verb string, silent bool) error {
+	r.kinds = append(r.kinds, resource)
+	r.verbs = append(r.verbs, verb)
+	return trace.AccessDenied("denied %v on %v", verb, resource)
+}
+
+// fakeSiteOperator stubs the cluster lookup performed by cluster-scoped
+// ACL checks
+type fakeSiteOperator struct {
+	oss.Operator
+}
+
+func (r *fakeSiteOperator) GetSiteByDomain(domain string) (*oss.Site, error) {
+	return &oss.Site{AccountID: "account", Domain: domain}, nil
+}
@@ -0,0 +1,165 @@
+// Copyright 2021 Gravitational Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acl
+
+import (
+	"context"
+	"time"
+
+	"github.com/gravitational/gravity/e/lib/ops"
+	oss "github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
+
+	teleservices "github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/trace"
+)
+
+// ReadOnlyOperator wraps an enterprise ACL operator and hard-denies every
+// mutating method regardless of the user's role assignments.
+//
+// It is used as a belt-and-suspenders measure for truly read-only API
+// credentials: read methods pass through to the wrapped ACL operator (and
+// are still subject to its checks) while any method that creates, updates
+// or deletes state returns AccessDenied.
+type ReadOnlyOperator struct {
+	// OperatorACL is the wrapped enterprise ACL operator
+	*OperatorACL
+}
+
+// ReadOnly returns a read-only decorator for the provided ACL operator
+func ReadOnly(operator *OperatorACL) *ReadOnlyOperator {
+	return &ReadOnlyOperator{OperatorACL: operator}
+}
+
+// errReadOnly returns the error all mutating methods reply with
+func errReadOnly() error {
+	return trace.AccessDenied("this operator is read-only")
+}
+
+// RegisterAgent is denied in read-only mode
+func (o *ReadOnlyOperator) RegisterAgent(req ops.RegisterAgentRequest) (*ops.RegisterAgentResponse, error) {
+	return nil, errReadOnly()
+}
+
+// RequestClusterCopy is denied in read-only mode
+func (o *ReadOnlyOperator) RequestClusterCopy(req ops.ClusterCopyRequest) error {
+	return errReadOnly()
+}
+
+// UpdateClusterEndpoints is denied in read-only mode
+func (o *ReadOnlyOperator) UpdateClusterEndpoints(ctx context.Context, key oss.SiteKey, endpoints storage.Endpoints) error {
+	return errReadOnly()
+}
+
+// DownloadUpdate is denied in read-only mode
+func (o *ReadOnlyOperator) DownloadUpdate(ctx context.Context, req ops.DownloadUpdateRequest) error {
+	return errReadOnly()
+}
+
+// EnablePeriodicUpdates is denied in read-only mode
+func (o *ReadOnlyOperator) EnablePeriodicUpdates(ctx context.Context, req ops.EnablePeriodicUpdatesRequest) error {
+	return errReadOnly()
+}
+
+// DisablePeriodicUpdates is denied in read-only mode
+func (o *ReadOnlyOperator) DisablePeriodicUpdates(ctx context.Context, key oss.SiteKey) error {
+	return errReadOnly()
+}
+
+// StartPeriodicUpdates is denied in read-only mode
+func (o *ReadOnlyOperator) StartPeriodicUpdates(key oss.SiteKey) error {
+	return errReadOnly()
+}
+
+// StopPeriodicUpdates is denied in read-only mode
+func (o *ReadOnlyOperator) StopPeriodicUpdates(key oss.SiteKey) error {
+	return errReadOnly()
+}
+
+// PauseUpdatesFor is denied in read-only mode
+func (o *ReadOnlyOperator) PauseUpdatesFor(ctx context.Context, key oss.SiteKey, duration time.Duration) error {
+	return errReadOnly()
+}
+
+// UpsertTrustedCluster is denied in read-only mode
+func (o *ReadOnlyOperator) UpsertTrustedCluster(ctx context.Context, key oss.SiteKey, cluster storage.TrustedCluster) error {
+	return errReadOnly()
+}
+
+// DeleteTrustedCluster is denied in read-only mode
+func (o *ReadOnlyOperator) DeleteTrustedCluster(ctx context.Context, req ops.DeleteTrustedClusterRequest) error {
+	return errReadOnly()
+}
+
+// AcceptRemoteCluster is denied in read-only mode
+func (o *ReadOnlyOperator) AcceptRemoteCluster(req ops.AcceptRemoteClusterRequest) (*ops.AcceptRemoteClusterResponse, error) {
+	return nil, errReadOnly()
+}
+
+// RemoveRemoteCluster is denied in read-only mode
+func (o *ReadOnlyOperator) RemoveRemoteCluster(req ops.RemoveRemoteClusterRequest) error {
+	return errReadOnly()
+}
+
+// NewLicense is denied in read-only mode
+func (o *ReadOnlyOperator) NewLicense(ctx context.Context, req ops.NewLicenseRequest) (string, error) {
+	return "", errReadOnly()
+}
+
+// CheckSiteLicense is denied in read-only mode since a license check may
+// deactivate the cluster
+func (o *ReadOnlyOperator) CheckSiteLicense(ctx context.Context, key oss.SiteKey) error {
+	return errReadOnly()
+}
+
+// UpdateLicense is denied in read-only mode
+func (o *ReadOnlyOperator) UpdateLicense(ctx context.Context, req ops.UpdateLicenseRequest) error {
+	return errReadOnly()
+}
+
+// RevokeLicense is denied in read-only mode
+func (o *ReadOnlyOperator) RevokeLicense(ctx context.Context, req ops.RevokeLicenseRequest) error {
+	return errReadOnly()
+}
+
+// UpsertRole is denied in read-only mode
+func (o *ReadOnlyOperator) UpsertRole(ctx context.Context, key oss.SiteKey, role teleservices.Role) error {
+	return errReadOnly()
+}
+
+// DeleteRole is denied in read-only mode
+func (o *ReadOnlyOperator) DeleteRole(ctx context.Context, key oss.SiteKey, name string) error {
+	return errReadOnly()
+}
+
+// UpsertOIDCConnector is denied in read-only mode
+func (o *ReadOnlyOperator) UpsertOIDCConnector(ctx context.Context, key oss.SiteKey, connector teleservices.OIDCConnector) error {
+	return errReadOnly()
+}
+
+// DeleteOIDCConnector is denied in read-only mode
+func (o *ReadOnlyOperator) DeleteOIDCConnector(ctx context.Context, key oss.SiteKey, name string) error {
+	return errReadOnly()
+}
+
+// UpsertSAMLConnector is denied in read-only mode
+func (o *ReadOnlyOperator) UpsertSAMLConnector(ctx context.Context, key oss.SiteKey, connector teleservices.SAMLConnector) error {
+	return errReadOnly()
+}
+
+// DeleteSAMLConnector is denied in read-only mode
+func (o *ReadOnlyOperator) DeleteSAMLConnector(ctx context.Context, key oss.SiteKey, name string) error {
+	return errReadOnly()
+}
@@ -0,0 +1,83 @@
+// Copyright 2021 Gravitational Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acl
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gravitational/trace"
+	check "gopkg.in/check.v1"
+)
+
+func TestACL(t *testing.T) { check.TestingT(t) }
+
+type ReadOnlySuite struct{}
+
+var _ = check.Suite(&ReadOnlySuite{})
+
+// mutatingMethods enumerates every enterprise operator method that mutates
+// state and hence must be denied by the read-only operator.
+//
+// When adding a new mutating method to the enterprise operator interface,
+// override it in ReadOnlyOperator and add it to this list.
+var mutatingMethods = []string{
+	"RegisterAgent",
+	"RequestClusterCopy",
+	"UpdateClusterEndpoints",
+	"DownloadUpdate",
+	"EnablePeriodicUpdates",
+	"DisablePeriodicUpdates",
+	"StartPeriodicUpdates",
+	"StopPeriodicUpdates",
+	"PauseUpdatesFor",
+	"UpsertTrustedCluster",
+	"DeleteTrustedCluster",
+	"AcceptRemoteCluster",
+	"RemoveRemoteCluster",
+	"NewLicense",
+	"CheckSiteLicense",
+	"UpdateLicense",
+	"RevokeLicense",
+	"UpsertRole",
+	"DeleteRole",
+	"UpsertOIDCConnector",
+	"DeleteOIDCConnector",
+	"UpsertSAMLConnector",
+	"DeleteSAMLConnector",
+}
+
+func (s *ReadOnlySuite) TestDeniesMutatingMethods(c *check.C) {
+	operator := ReadOnly(nil)
+	for _, name := range mutatingMethods {
+		method := reflect.ValueOf(operator).MethodByName(name)
+		c.Assert(method.IsValid(), check.Equals, true, check.Commentf(
+			"method %q is not defined on ReadOnlyOperator", name))
+		// the method must be overridden by the read-only operator itself,
+		// not inherited from the embedded ACL operator
+		_, overridden := reflect.TypeOf(operator).MethodByName(name)
+		c.Assert(overridden, check.Equals, true)
+		args := make([]reflect.Value, method.Type().NumIn())
+		for i := range args {
+			args[i] = reflect.Zero(method.Type().In(i))
+		}
+		results := method.Call(args)
+		err, ok := results[len(results)-1].Interface().(error)
+		c.Assert(ok, check.Equals, true, check.Commentf(
+			"method %q does not return an error", name))
+		c.Assert(trace.IsAccessDenied(err), check.Equals, true, check.Commentf(
+			"method %q is not denied: %v", name, err))
+	}
+}
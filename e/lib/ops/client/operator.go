@@ -19,6 +19,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"time"
 
 	"github.com/gravitational/gravity/e/lib/ops"
 	"github.com/gravitational/gravity/lib/constants"
@@ -129,6 +130,36 @@ func (c *Client) CheckForUpdate(key ossops.SiteKey) (*loc.Locator, error) {
 	return &update, nil
 }
 
+// GetClustersWithAvailableUpdates checks all clusters of the account
+// for updates and returns only those that have a newer application
+// version available
+func (c *Client) GetClustersWithAvailableUpdates(accountID string) ([]ops.ClusterUpdateInfo, error) {
+	out, err := c.Get(context.TODO(), c.Endpoint("accounts", accountID, "updates"), url.Values{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var updates []ops.ClusterUpdateInfo
+	if err := json.Unmarshal(out.Bytes(), &updates); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return updates, nil
+}
+
+// ListAvailableUpdates returns all application versions newer than the
+// installed one available on the remote Ops Center
+func (c *Client) ListAvailableUpdates(key ossops.SiteKey) (*ops.ListAvailableUpdatesResponse, error) {
+	out, err := c.Get(context.TODO(), c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "updates", "available"), url.Values{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var response ops.ListAvailableUpdatesResponse
+	err = json.Unmarshal(out.Bytes(), &response)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &response, nil
+}
+
 // DownloadUpdates downloads the provided application version from remote
 // Ops Center
 func (c *Client) DownloadUpdate(ctx context.Context, req ops.DownloadUpdateRequest) error {
@@ -177,6 +208,17 @@ func (c *Client) StopPeriodicUpdates(key ossops.SiteKey) error {
 	return nil
 }
 
+// PauseUpdatesFor stops the periodic updates check and schedules it to
+// resume automatically after the provided duration
+func (c *Client) PauseUpdatesFor(ctx context.Context, key ossops.SiteKey, duration time.Duration) error {
+	_, err := c.PostJSON(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "periodicupdates", "pause"),
+		ops.PauseUpdatesRequest{Duration: duration})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
 // PeriodicUpdatesStatus returns the status of periodic updates for the cluster
 func (c *Client) PeriodicUpdatesStatus(key ossops.SiteKey) (*ops.PeriodicUpdatesStatusResponse, error) {
 	out, err := c.Get(context.TODO(), c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "periodicupdates"), url.Values{})
@@ -191,6 +233,21 @@ func (c *Client) PeriodicUpdatesStatus(key ossops.SiteKey) (*ops.PeriodicUpdates
 	return &status, nil
 }
 
+// GetUserCapabilities reports which well-known actions the requesting
+// user may perform on the cluster
+func (c *Client) GetUserCapabilities(ctx context.Context, key ossops.SiteKey) (*ops.UserCapabilities, error) {
+	out, err := c.Get(ctx, c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "capabilities"), url.Values{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var capabilities ops.UserCapabilities
+	err = json.Unmarshal(out.Bytes(), &capabilities)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &capabilities, nil
+}
+
 // UpsertTrustedCluster creates or updates a trusted cluster
 func (c *Client) UpsertTrustedCluster(ctx context.Context, key ossops.SiteKey, cluster storage.TrustedCluster) error {
 	bytes, err := teleservices.GetTrustedClusterMarshaler().Marshal(cluster)
@@ -242,6 +299,23 @@ func (c *Client) GetTrustedClusters(key ossops.SiteKey) ([]storage.TrustedCluste
 	return clusters, nil
 }
 
+// PingTrustedCluster verifies connectivity to the trusted cluster
+// specified by name
+func (c *Client) PingTrustedCluster(key ossops.SiteKey, name string) (*ops.TrustedClusterPingResponse, error) {
+	if name == "" {
+		return nil, trace.BadParameter("missing trusted cluster name")
+	}
+	out, err := c.Get(context.TODO(), c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "trustedclusters", name, "ping"), url.Values{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var response ops.TrustedClusterPingResponse
+	if err := json.Unmarshal(out.Bytes(), &response); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &response, nil
+}
+
 // DeleteTrustedCluster deletes a trusted cluster by name
 func (c *Client) DeleteTrustedCluster(ctx context.Context, req ops.DeleteTrustedClusterRequest) error {
 	err := req.Check()
@@ -249,7 +323,10 @@ func (c *Client) DeleteTrustedCluster(ctx context.Context, req ops.DeleteTrusted
 		return trace.Wrap(err)
 	}
 	_, err = c.DeleteWithParams(c.Endpoint("accounts", req.AccountID, "sites", req.ClusterName, "trustedclusters", req.TrustedClusterName),
-		url.Values{"delay": []string{req.Delay.String()}})
+		url.Values{
+			"delay":  []string{req.Delay.String()},
+			"reason": []string{req.Reason},
+		})
 	if err != nil {
 		return trace.Wrap(err)
 	}
@@ -306,6 +383,18 @@ func (c *Client) UpdateLicense(ctx context.Context, req ops.UpdateLicenseRequest
 	return trace.Wrap(err)
 }
 
+// RevokeLicense revokes a previously issued license
+func (c *Client) RevokeLicense(ctx context.Context, req ops.RevokeLicenseRequest) error {
+	if err := req.Check(); err != nil {
+		return trace.Wrap(err)
+	}
+	_, err := c.PostJSON(c.Endpoint("license", "revoke"), req)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
 // GetLicenseCA returns CA certificate Ops Center uses to sign licenses
 func (c *Client) GetLicenseCA() ([]byte, error) {
 	out, err := c.Get(context.TODO(), c.Endpoint("license", "ca"), url.Values{})
@@ -370,6 +459,29 @@ func (c *Client) GetRoles(key ossops.SiteKey) ([]teleservices.Role, error) {
 	return roles, nil
 }
 
+// GetRolesByLabel returns the roles matching the provided label selector
+func (c *Client) GetRolesByLabel(key ossops.SiteKey, selector string) ([]teleservices.Role, error) {
+	out, err := c.Get(context.TODO(), c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "roles"), url.Values{
+		"selector": []string{selector},
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(out.Bytes(), &items); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	roles := make([]teleservices.Role, len(items))
+	for i, raw := range items {
+		role, err := teleservices.GetRoleMarshaler().UnmarshalRole(raw)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		roles[i] = role
+	}
+	return roles, nil
+}
+
 // DeleteRole deletes a role by name
 func (c *Client) DeleteRole(ctx context.Context, key ossops.SiteKey, name string) error {
 	if name == "" {
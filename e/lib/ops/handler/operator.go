@@ -59,6 +59,10 @@ func NewWebHandler(ossHandler *opshandler.WebHandler, operator ops.Operator) *We
 	// Updates & periodic updates API
 	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/updates",
 		h.needsAuth(h.checkForUpdate))
+	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/updates/available",
+		h.needsAuth(h.listAvailableUpdates))
+	h.GET("/portal/v1/accounts/:account_id/updates",
+		h.needsAuth(h.getClustersWithAvailableUpdates))
 	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/updates",
 		h.needsAuth(h.downloadUpdate))
 	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/periodicupdates",
@@ -71,6 +75,8 @@ func NewWebHandler(ossHandler *opshandler.WebHandler, operator ops.Operator) *We
 		h.needsAuth(h.periodicUpdatesStart))
 	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/periodicupdates/stop",
 		h.needsAuth(h.periodicUpdatesStop))
+	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/periodicupdates/pause",
+		h.needsAuth(h.periodicUpdatesPause))
 
 	// Role management API
 	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/roles",
@@ -111,6 +117,12 @@ func NewWebHandler(ossHandler *opshandler.WebHandler, operator ops.Operator) *We
 		h.needsAuth(h.getTrustedClusters))
 	h.DELETE("/portal/v1/accounts/:account_id/sites/:site_domain/trustedclusters/:name",
 		h.needsAuth(h.deleteTrustedCluster))
+	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/trustedclusters/:name/ping",
+		h.needsAuth(h.pingTrustedCluster))
+
+	// User capabilities API
+	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/capabilities",
+		h.needsAuth(h.getUserCapabilities))
 
 	// Remote support API
 	h.PUT("/portal/v1/accounts/:account_id/sites/:site_domain/accept",
@@ -120,6 +132,7 @@ func NewWebHandler(ossHandler *opshandler.WebHandler, operator ops.Operator) *We
 
 	// License API
 	h.POST("/portal/v1/license/new", h.needsAuth(h.newLicense))
+	h.POST("/portal/v1/license/revoke", h.needsAuth(h.revokeLicense))
 	h.GET("/portal/v1/license/ca", h.needsAuth(h.getLicenseCA))
 	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/license/check",
 		h.needsAuth(h.checkSiteLicense))
@@ -129,6 +142,24 @@ func NewWebHandler(ossHandler *opshandler.WebHandler, operator ops.Operator) *We
 	return h
 }
 
+/* getUserCapabilities reports which well-known actions the requesting
+   user may perform on the cluster
+
+     GET /portal/v1/accounts/:account_id/sites/:site_domain/capabilities
+
+   Success Response:
+
+     ops.UserCapabilities
+*/
+func (h *WebHandler) getUserCapabilities(w http.ResponseWriter, r *http.Request, p httprouter.Params, ctx *handlerContext) error {
+	capabilities, err := ctx.Operator.GetUserCapabilities(r.Context(), siteKey(p))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, capabilities)
+	return nil
+}
+
 /* registerAgent registers install agent
 
    PUT /portal/v1/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/register
@@ -230,6 +261,33 @@ func (h *WebHandler) checkForUpdate(w http.ResponseWriter, r *http.Request, p ht
 	return nil
 }
 
+/* listAvailableUpdates returns all newer versions available on the Ops Center
+
+   GET /portal/v1/accounts/:account_id/sites/:site_domain/updates/available
+*/
+func (h *WebHandler) listAvailableUpdates(w http.ResponseWriter, r *http.Request, p httprouter.Params, ctx *handlerContext) error {
+	response, err := ctx.Operator.ListAvailableUpdates(siteKey(p))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, response)
+	return nil
+}
+
+/* getClustersWithAvailableUpdates returns the account clusters that have
+   a newer application version available
+
+   GET /portal/v1/accounts/:account_id/updates
+*/
+func (h *WebHandler) getClustersWithAvailableUpdates(w http.ResponseWriter, r *http.Request, p httprouter.Params, ctx *handlerContext) error {
+	updates, err := ctx.Operator.GetClustersWithAvailableUpdates(p.ByName("account_id"))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, updates)
+	return nil
+}
+
 /* downloadUpdate downloads a new version to site
 
    POST /portal/v1/accounts/:account_id/sites/:site_domain/updates
@@ -315,6 +373,27 @@ func (h *WebHandler) periodicUpdatesStop(w http.ResponseWriter, r *http.Request,
 	return nil
 }
 
+/* periodicUpdatesPause stops periodic updates and schedules them to resume
+   after the provided duration
+
+   POST /portal/v1/accounts/:account_id/sites/:site_domain/periodicupdates/pause
+
+   Input: ops.PauseUpdatesRequest
+*/
+func (h *WebHandler) periodicUpdatesPause(w http.ResponseWriter, r *http.Request, p httprouter.Params, ctx *handlerContext) error {
+	d := json.NewDecoder(r.Body)
+	var req ops.PauseUpdatesRequest
+	if err := d.Decode(&req); err != nil {
+		return trace.BadParameter(err.Error())
+	}
+	err := ctx.Operator.PauseUpdatesFor(r.Context(), siteKey(p), req.Duration)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, statusOK("ok"))
+	return nil
+}
+
 /* periodicUpdatesStatus returns the status of periodic updates
 
    GET /portal/v1/accounts/:account_id/sites/:site_domain/periodicupdates/status
@@ -394,6 +473,30 @@ func (h *WebHandler) newLicense(w http.ResponseWriter, r *http.Request, p httpro
 	return nil
 }
 
+/*  revokeLicense revokes a previously issued license
+
+    POST /portal/v1/license/revoke
+
+    Input: ops.RevokeLicenseRequest
+
+    Success response:
+    {
+      "message": "license revoked"
+    }
+*/
+func (h *WebHandler) revokeLicense(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *handlerContext) error {
+	d := json.NewDecoder(r.Body)
+	var req ops.RevokeLicenseRequest
+	if err := d.Decode(&req); err != nil {
+		return trace.BadParameter(err.Error())
+	}
+	if err := context.Operator.RevokeLicense(r.Context(), req); err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, statusOK("license revoked"))
+	return nil
+}
+
 /*  checkSiteLicense verifies the license installed on the site
 
     POST /portal/v1/accounts/:account_id/sites/:site_domain/license/check
@@ -68,9 +68,17 @@ func (h *WebHandler) getRole(w http.ResponseWriter, r *http.Request, p httproute
 /* getRoles returns all cluster roles
 
    GET /portal/v1/accounts/:account_id/sites/:site_domain/roles
+
+   The optional "selector" query parameter filters the roles by label
 */
 func (h *WebHandler) getRoles(w http.ResponseWriter, r *http.Request, p httprouter.Params, ctx *handlerContext) error {
-	roles, err := ctx.Operator.GetRoles(siteKey(p))
+	var roles []services.Role
+	var err error
+	if selector := r.URL.Query().Get("selector"); selector != "" {
+		roles, err = ctx.Operator.GetRolesByLabel(siteKey(p), selector)
+	} else {
+		roles, err = ctx.Operator.GetRoles(siteKey(p))
+	}
 	if err != nil {
 		return trace.Wrap(err)
 	}
@@ -103,6 +103,7 @@ func (h *WebHandler) deleteTrustedCluster(w http.ResponseWriter, r *http.Request
 		ClusterName:        p.ByName("site_domain"),
 		TrustedClusterName: p.ByName("name"),
 		Delay:              delay,
+		Reason:             r.URL.Query().Get("reason"),
 	}
 	if err := ctx.Operator.DeleteTrustedCluster(r.Context(), req); err != nil {
 		return trace.Wrap(err)
@@ -112,6 +113,21 @@ func (h *WebHandler) deleteTrustedCluster(w http.ResponseWriter, r *http.Request
 	return nil
 }
 
+/*  pingTrustedCluster verifies connectivity to the trusted cluster
+
+    GET /portal/v1/accounts/:account_id/sites/:site_domain/trustedclusters/:name/ping
+
+    Success response: ops.TrustedClusterPingResponse
+*/
+func (h *WebHandler) pingTrustedCluster(w http.ResponseWriter, r *http.Request, p httprouter.Params, ctx *handlerContext) error {
+	response, err := ctx.Operator.PingTrustedCluster(siteKey(p), p.ByName("name"))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, response)
+	return nil
+}
+
 // parseDuration parses the specified query string parameter as duration
 func parseDuration(r *http.Request, name string) (time.Duration, error) {
 	s := r.URL.Query().Get(name)
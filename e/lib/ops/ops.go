@@ -47,6 +47,56 @@ type Operator interface {
 	Licenses
 	// Identity provides methods for managing roles and auth connectors
 	Identity
+	// Capabilities provides methods for introspecting user permissions
+	Capabilities
+}
+
+// Capabilities defines an interface for introspecting what actions the
+// requesting user is allowed to perform
+type Capabilities interface {
+	// GetUserCapabilities evaluates the ACL for the requesting user and
+	// reports which well-known actions the user may perform on the
+	// cluster so UIs can disable controls without attempting the actions
+	GetUserCapabilities(ctx context.Context, key ops.SiteKey) (*UserCapabilities, error)
+}
+
+// UserCapabilities describes the actions the requesting user is allowed
+// to perform on a cluster
+type UserCapabilities struct {
+	// Capabilities maps a capability name, see Capability* constants,
+	// to whether the user is allowed to perform it
+	Capabilities map[string]bool `json:"capabilities"`
+}
+
+// Can returns whether the named capability is granted
+func (r UserCapabilities) Can(capability string) bool {
+	return r.Capabilities[capability]
+}
+
+const (
+	// CapabilityUpgrade is the capability to upgrade the cluster
+	CapabilityUpgrade = "can-upgrade"
+	// CapabilityDeleteCluster is the capability to delete the cluster
+	CapabilityDeleteCluster = "can-delete-cluster"
+	// CapabilityManageRoles is the capability to manage roles
+	CapabilityManageRoles = "can-manage-roles"
+	// CapabilityManageUsers is the capability to manage users
+	CapabilityManageUsers = "can-manage-users"
+	// CapabilityIssueLicense is the capability to issue new licenses
+	CapabilityIssueLicense = "can-issue-license"
+	// CapabilityRevokeLicense is the capability to revoke issued licenses
+	CapabilityRevokeLicense = "can-revoke-license"
+)
+
+// ClusterCapabilities lists the capability names reported by
+// GetUserCapabilities
+var ClusterCapabilities = []string{
+	CapabilityUpgrade,
+	CapabilityDeleteCluster,
+	CapabilityManageRoles,
+	CapabilityManageUsers,
+	CapabilityIssueLicense,
+	CapabilityRevokeLicense,
 }
 
 // OpsCenter defines methods specific to installation via Ops Center
@@ -78,6 +128,9 @@ type RegisterAgentRequest struct {
 	AgentID string `json:"agent_id"`
 	// AdvertiseIP is the advertise IP of the registering agent
 	AdvertiseIP string `json:"advertise_ip"`
+	// JoinToken is the pre-shared token the agent authenticates the
+	// registration with, validated against the stored provisioning tokens
+	JoinToken string `json:"join_token,omitempty"`
 }
 
 // SiteOperationKey makes an operation key from this request
@@ -123,6 +176,16 @@ type ClusterCopyRequest struct {
 	OpsToken string `json:"ops_token"`
 }
 
+// SiteOperationKey makes an operation key from this request so the progress
+// of the replicated install operation can be polled
+func (r ClusterCopyRequest) SiteOperationKey() ops.SiteOperationKey {
+	return ops.SiteOperationKey{
+		AccountID:   r.AccountID,
+		SiteDomain:  r.ClusterName,
+		OperationID: r.OperationID,
+	}
+}
+
 // Endpoints defines cluster endpoints management interface
 type Endpoints interface {
 	// GetClusterEndpoints returns the cluster management endpoints such
@@ -146,15 +209,25 @@ type PeriodicUpdates interface {
 	// StopPeriodicUpdates stops periodic updates check without disabling it
 	// (so they will be resumed when the process restarts for example)
 	StopPeriodicUpdates(ops.SiteKey) error
+	// PauseUpdatesFor stops the periodic updates check and schedules it to
+	// resume automatically after the provided duration
+	PauseUpdatesFor(context.Context, ops.SiteKey, time.Duration) error
 	// PeriodicUpdatesStatus returns the status of periodic updates for the
 	// cluster
 	PeriodicUpdatesStatus(ops.SiteKey) (*PeriodicUpdatesStatusResponse, error)
 	// CheckForUpdates checks with remote OpsCenter if there is a newer version
 	// of the installed application
 	CheckForUpdate(ops.SiteKey) (*loc.Locator, error)
+	// ListAvailableUpdates returns all application versions newer than the
+	// installed one available on the remote Ops Center
+	ListAvailableUpdates(ops.SiteKey) (*ListAvailableUpdatesResponse, error)
 	// DownloadUpdates downloads the provided application version from remote
 	// Ops Center
 	DownloadUpdate(context.Context, DownloadUpdateRequest) error
+	// GetClustersWithAvailableUpdates checks all clusters of the account
+	// for updates and returns only those that have a newer application
+	// version available
+	GetClustersWithAvailableUpdates(accountID string) ([]ClusterUpdateInfo, error)
 }
 
 // EnablePeriodicUpdatesRequest is a request to turn periodic updates on or update the interval
@@ -185,6 +258,13 @@ func (r *EnablePeriodicUpdatesRequest) CheckAndSetDefaults() error {
 	return nil
 }
 
+// PauseUpdatesRequest is a request to temporarily pause periodic updates
+type PauseUpdatesRequest struct {
+	// Duration is how long the periodic updates stay paused before
+	// resuming automatically
+	Duration time.Duration `json:"duration"`
+}
+
 // PeriodicUpdatesStatusResponse describes periodic updates status for a site
 type PeriodicUpdatesStatusResponse struct {
 	// Enabled is whether the periodic updates are enabled
@@ -195,6 +275,39 @@ type PeriodicUpdatesStatusResponse struct {
 	NextCheck time.Time `json:"next_check"`
 }
 
+// ListAvailableUpdatesResponse describes application versions available
+// for upgrade on the remote Ops Center
+type ListAvailableUpdatesResponse struct {
+	// InstalledVersion is the currently installed application version
+	InstalledVersion loc.Locator `json:"installed_version"`
+	// Updates is the list of newer application versions
+	Updates []AvailableUpdate `json:"updates"`
+}
+
+// AvailableUpdate describes a single application version available for upgrade
+type AvailableUpdate struct {
+	// Application is the application package locator
+	Application loc.Locator `json:"application"`
+	// SizeBytes is the application package size in bytes
+	SizeBytes int64 `json:"size_bytes"`
+	// Created is the application package release date
+	Created time.Time `json:"created"`
+}
+
+// ClusterUpdateInfo describes a cluster that has a newer application
+// version available, or whose update check failed
+type ClusterUpdateInfo struct {
+	// ClusterName is the cluster domain name
+	ClusterName string `json:"cluster_name"`
+	// CurrentVersion is the locator of the installed application
+	CurrentVersion loc.Locator `json:"current_version"`
+	// AvailableVersion is the locator of the newer available version
+	AvailableVersion loc.Locator `json:"available_version"`
+	// Error describes the failure if the update check for this cluster
+	// failed
+	Error string `json:"error,omitempty"`
+}
+
 // DownloadUpdateRequest is a request to download a newer app version to gravity site
 type DownloadUpdateRequest struct {
 	// AccountID is the site account ID
@@ -221,6 +334,27 @@ type TrustedClusters interface {
 	GetTrustedClusters(ops.SiteKey) ([]storage.TrustedCluster, error)
 	// GetTrustedCluster returns trusted cluster by name
 	GetTrustedCluster(key ops.SiteKey, name string) (storage.TrustedCluster, error)
+	// PingTrustedCluster verifies connectivity to the trusted cluster
+	// specified by name
+	PingTrustedCluster(key ops.SiteKey, name string) (*TrustedClusterPingResponse, error)
+}
+
+// TrustedClusterPingResponse is the result of a trusted cluster
+// connectivity check
+type TrustedClusterPingResponse struct {
+	// Reachable indicates whether the trusted cluster could be reached
+	Reachable bool `json:"reachable"`
+	// Latency is the time it took to establish a connection to the
+	// trusted cluster
+	Latency time.Duration `json:"latency,omitempty"`
+	// Error is the connection error for an unreachable trusted cluster
+	Error string `json:"error,omitempty"`
+}
+
+// String returns the response's string representation
+func (r TrustedClusterPingResponse) String() string {
+	return fmt.Sprintf("TrustedClusterPingResponse(Reachable=%v, Latency=%v)",
+		r.Reachable, r.Latency)
 }
 
 // DeleteTrustedClusterRequest is a request to delete a trusted cluster
@@ -234,6 +368,9 @@ type DeleteTrustedClusterRequest struct {
 	// Delay, if not zero, specifies TTL for trusted cluster and
 	// all related objects instead of deleting immediately
 	Delay time.Duration `json:"delay"`
+	// Reason optionally describes why the trust is being removed,
+	// it is recorded in the audit log
+	Reason string `json:"reason,omitempty"`
 }
 
 // Check makes sure the request is valid
@@ -260,8 +397,8 @@ func (r *DeleteTrustedClusterRequest) SiteKey() ops.SiteKey {
 
 // String returns the request's string representation
 func (r DeleteTrustedClusterRequest) String() string {
-	return fmt.Sprintf("DeleteTrustedClusterRequest(ClusterName=%v, TrustedClusterName=%v, Delay=%v",
-		r.ClusterName, r.TrustedClusterName, r.Delay)
+	return fmt.Sprintf("DeleteTrustedClusterRequest(ClusterName=%v, TrustedClusterName=%v, Delay=%v, Reason=%q",
+		r.ClusterName, r.TrustedClusterName, r.Delay, r.Reason)
 }
 
 // RemoteSupport interface manages remote access to this Ops Center
@@ -366,6 +503,9 @@ type Licenses interface {
 	UpdateLicense(context.Context, UpdateLicenseRequest) error
 	// GetLicenseCA returns CA certificate Ops Center uses to sign licenses
 	GetLicenseCA() ([]byte, error)
+	// RevokeLicense revokes a previously issued license so clusters
+	// installed with it fail the license check
+	RevokeLicense(context.Context, RevokeLicenseRequest) error
 }
 
 // NewLicenseRequest is a request to generate a new license.
@@ -376,6 +516,26 @@ type NewLicenseRequest struct {
 	ValidFor time.Duration `json:"valid_for"`
 	// StopApp indicates whether an application should be stopped when license expires
 	StopApp bool `json:"stop_app"`
+	// ClusterDomain optionally binds the license to the cluster with this
+	// domain so it cannot be reused on other clusters
+	ClusterDomain string `json:"cluster_domain,omitempty"`
+}
+
+// RevokeLicenseRequest is a request to revoke an issued license
+type RevokeLicenseRequest struct {
+	// License is the license string to revoke
+	License string `json:"license"`
+	// Reason optionally describes why the license is being revoked,
+	// it is recorded in the audit log
+	Reason string `json:"reason,omitempty"`
+}
+
+// Check makes sure the request is valid
+func (r RevokeLicenseRequest) Check() error {
+	if r.License == "" {
+		return trace.BadParameter("missing License")
+	}
+	return nil
 }
 
 // CheckLicenseRequest is a request to check a license
@@ -415,6 +575,8 @@ type Identity interface {
 	GetRole(key ops.SiteKey, name string) (services.Role, error)
 	// GetRoles returns all roles
 	GetRoles(key ops.SiteKey) ([]services.Role, error)
+	// GetRolesByLabel returns the roles matching the provided label selector
+	GetRolesByLabel(key ops.SiteKey, selector string) ([]services.Role, error)
 	// DeleteRole deletes a role by name
 	DeleteRole(ctx context.Context, key ops.SiteKey, name string) error
 	// UpsertOIDCConnector creates or updates an OIDC connector
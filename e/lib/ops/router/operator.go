@@ -16,6 +16,7 @@ package router
 
 import (
 	"context"
+	"time"
 
 	"github.com/gravitational/gravity/e/lib/ops"
 	"github.com/gravitational/gravity/e/lib/ops/client"
@@ -84,6 +85,23 @@ func (r *Router) CheckForUpdate(key ossops.SiteKey) (*loc.Locator, error) {
 	return client.CheckForUpdate(key)
 }
 
+// ListAvailableUpdates returns all application versions newer than the
+// installed one available on the remote Ops Center
+func (r *Router) ListAvailableUpdates(key ossops.SiteKey) (*ops.ListAvailableUpdatesResponse, error) {
+	client, err := r.pickClient(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.ListAvailableUpdates(key)
+}
+
+// GetClustersWithAvailableUpdates checks all clusters of the account
+// for updates and returns only those that have a newer application
+// version available
+func (r *Router) GetClustersWithAvailableUpdates(accountID string) ([]ops.ClusterUpdateInfo, error) {
+	return r.Local.GetClustersWithAvailableUpdates(accountID)
+}
+
 // DownloadUpdates downloads the provided application version from remote
 // Ops Center
 func (r *Router) DownloadUpdate(ctx context.Context, req ops.DownloadUpdateRequest) error {
@@ -132,6 +150,26 @@ func (r *Router) StopPeriodicUpdates(key ossops.SiteKey) error {
 	return client.StopPeriodicUpdates(key)
 }
 
+// PauseUpdatesFor stops the periodic updates check and schedules it to
+// resume automatically after the provided duration
+func (r *Router) PauseUpdatesFor(ctx context.Context, key ossops.SiteKey, duration time.Duration) error {
+	client, err := r.pickClient(key.SiteDomain)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return client.PauseUpdatesFor(ctx, key, duration)
+}
+
+// GetUserCapabilities reports which well-known actions the requesting
+// user may perform on the cluster
+func (r *Router) GetUserCapabilities(ctx context.Context, key ossops.SiteKey) (*ops.UserCapabilities, error) {
+	client, err := r.pickClient(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.GetUserCapabilities(ctx, key)
+}
+
 // PeriodicUpdatesStatus returns the status of periodic updates for the cluster
 func (r *Router) PeriodicUpdatesStatus(key ossops.SiteKey) (*ops.PeriodicUpdatesStatusResponse, error) {
 	client, err := r.pickClient(key.SiteDomain)
@@ -165,6 +203,12 @@ func (r *Router) GetTrustedCluster(key ossops.SiteKey, name string) (storage.Tru
 	return r.Local.GetTrustedCluster(key, name)
 }
 
+// PingTrustedCluster verifies connectivity to the trusted cluster
+// specified by name
+func (r *Router) PingTrustedCluster(key ossops.SiteKey, name string) (*ops.TrustedClusterPingResponse, error) {
+	return r.Local.PingTrustedCluster(key, name)
+}
+
 // AcceptRemoteCluster defines the handshake between a remote cluster and this
 // Ops Center
 func (r *Router) AcceptRemoteCluster(req ops.AcceptRemoteClusterRequest) (*ops.AcceptRemoteClusterResponse, error) {
@@ -204,6 +248,11 @@ func (r *Router) GetLicenseCA() ([]byte, error) {
 	return r.Local.GetLicenseCA()
 }
 
+// RevokeLicense revokes a previously issued license
+func (r *Router) RevokeLicense(ctx context.Context, req ops.RevokeLicenseRequest) error {
+	return r.Local.RevokeLicense(ctx, req)
+}
+
 // UpsertRole creates a new role
 func (r *Router) UpsertRole(ctx context.Context, key ossops.SiteKey, role teleservices.Role) error {
 	client, err := r.pickClient(key.SiteDomain)
@@ -231,6 +280,15 @@ func (r *Router) GetRoles(key ossops.SiteKey) ([]teleservices.Role, error) {
 	return client.GetRoles(key)
 }
 
+// GetRolesByLabel returns the roles matching the provided label selector
+func (r *Router) GetRolesByLabel(key ossops.SiteKey, selector string) ([]teleservices.Role, error) {
+	client, err := r.pickClient(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.GetRolesByLabel(key, selector)
+}
+
 // DeleteRole deletes a role by name
 func (r *Router) DeleteRole(ctx context.Context, key ossops.SiteKey, name string) error {
 	client, err := r.pickClient(key.SiteDomain)
@@ -0,0 +1,36 @@
+// Copyright 2021 Gravitational Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+
+	"github.com/gravitational/gravity/e/lib/ops"
+	ossops "github.com/gravitational/gravity/lib/ops"
+)
+
+// GetUserCapabilities reports which well-known actions the requesting user
+// may perform on the cluster.
+//
+// The service operator does not enforce access controls itself — those are
+// applied by the ACL layer wrapping it — so direct access reports every
+// capability as granted.
+func (o *Operator) GetUserCapabilities(ctx context.Context, key ossops.SiteKey) (*ops.UserCapabilities, error) {
+	capabilities := make(map[string]bool, len(ops.ClusterCapabilities))
+	for _, capability := range ops.ClusterCapabilities {
+		capabilities[capability] = true
+	}
+	return &ops.UserCapabilities{Capabilities: capabilities}, nil
+}
@@ -0,0 +1,179 @@
+// Copyright 2021 Gravitational Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"crypto/sha512"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/pack"
+	"github.com/gravitational/gravity/lib/pack/webpack"
+
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+)
+
+// downloadsDir is the directory under the operator state directory where
+// partially downloaded packages are kept between download attempts
+const downloadsDir = "downloads"
+
+// resumingPackages wraps the remote Ops Center package service to make
+// package downloads resumable: package contents are first downloaded into
+// a file in the state directory, and if the transfer is interrupted, the
+// next download of the same package continues from the last received byte
+// using an HTTP range request. The downloaded file is verified against the
+// package checksum before it is handed out.
+type resumingPackages struct {
+	*webpack.Client
+	// dir is the directory where partial downloads are kept
+	dir string
+	// FieldLogger allows this service to log messages
+	log.FieldLogger
+}
+
+// newResumingPackages returns a package service that keeps partial
+// downloads in the provided directory
+func newResumingPackages(client *webpack.Client, dir string) *resumingPackages {
+	return &resumingPackages{
+		Client:      client,
+		dir:         dir,
+		FieldLogger: log.WithField(trace.Component, "resume"),
+	}
+}
+
+// ReadPackage downloads the package into the state directory, resuming a
+// previously interrupted download if its partial file is still on disk,
+// and returns a reader for the downloaded contents
+func (r *resumingPackages) ReadPackage(locator loc.Locator) (*pack.PackageEnvelope, io.ReadCloser, error) {
+	envelope, err := r.Client.ReadPackageEnvelope(locator)
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	if err := os.MkdirAll(r.dir, defaults.SharedDirMask); err != nil {
+		return nil, nil, trace.ConvertSystemError(err)
+	}
+	path := filepath.Join(r.dir, fmt.Sprintf("%v_%v_%v.partial",
+		locator.Repository, locator.Name, locator.Version))
+	if err := r.download(locator, *envelope, path); err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, trace.ConvertSystemError(err)
+	}
+	return envelope, &downloadedFile{File: file, path: path}, nil
+}
+
+// download downloads the package into the file at the provided path,
+// continuing from the last received byte if the file already has some
+// data from a previous attempt
+func (r *resumingPackages) download(locator loc.Locator, envelope pack.PackageEnvelope, path string) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, defaults.SharedReadMask)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+
+	offset := info.Size()
+	if offset > envelope.SizeBytes {
+		// the partial file can't belong to this package, start over
+		offset = 0
+	} else if offset == envelope.SizeBytes {
+		if err := verifyChecksum(file, envelope.SHA512); err == nil {
+			r.Infof("Package %v is already fully downloaded.", locator)
+			return nil
+		}
+		r.Warnf("Discarding partial download of %v: checksum mismatch.", locator)
+		offset = 0
+	}
+
+	if offset > 0 {
+		r.Infof("Resuming download of %v at %v/%v bytes.",
+			locator, offset, envelope.SizeBytes)
+	}
+
+	body, offset, err := r.Client.ReadPackageAt(locator, offset)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer body.Close()
+
+	if err := file.Truncate(offset); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	if _, err := io.Copy(file, body); err != nil {
+		// keep the partial file so the next attempt can resume
+		return trace.Wrap(err)
+	}
+
+	if err := verifyChecksum(file, envelope.SHA512); err != nil {
+		if errRemove := os.Remove(path); errRemove != nil {
+			r.Warnf("Failed to remove %v: %v.", path, errRemove)
+		}
+		return trace.Wrap(err)
+	}
+
+	return nil
+}
+
+// verifyChecksum makes sure the contents of the provided file match the
+// expected package checksum
+func verifyChecksum(file *os.File, sum string) error {
+	if sum == "" {
+		return nil
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	hasher := sha512.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return trace.Wrap(err)
+	}
+	actual := fmt.Sprintf("%x", hasher.Sum(nil)[:sha512.Size/2])
+	if actual != sum {
+		return trace.BadParameter(
+			"package checksum mismatch: expected %v, got %v", sum, actual)
+	}
+	return nil
+}
+
+// downloadedFile is a fully downloaded package file that removes its
+// on-disk state once consumed
+type downloadedFile struct {
+	*os.File
+	path string
+}
+
+// Close closes the file and removes it from the state directory
+func (f *downloadedFile) Close() error {
+	err := f.File.Close()
+	if errRemove := os.Remove(f.path); errRemove != nil && !os.IsNotExist(errRemove) {
+		return trace.ConvertSystemError(errRemove)
+	}
+	return trace.Wrap(err)
+}
@@ -0,0 +1,161 @@
+// Copyright 2021 Gravitational Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/pack"
+	"github.com/gravitational/gravity/lib/pack/webpack"
+
+	"github.com/gravitational/trace"
+	check "gopkg.in/check.v1"
+)
+
+type downloadSuite struct {
+	server *httptest.Server
+	// contents is the package data the test server serves
+	contents []byte
+	// envelope describes the served package
+	envelope pack.PackageEnvelope
+	// ranges records the Range headers of received file requests
+	ranges []string
+	locator loc.Locator
+}
+
+var _ = check.Suite(&downloadSuite{})
+
+func (s *downloadSuite) SetUpTest(c *check.C) {
+	s.locator = loc.MustParseLocator("example.com/app:1.0.0")
+	s.contents = bytes.Repeat([]byte("0123456789"), 100)
+	sum := sha512.Sum512(s.contents)
+	s.envelope = pack.PackageEnvelope{
+		Locator:   s.locator,
+		SizeBytes: int64(len(s.contents)),
+		SHA512:    fmt.Sprintf("%x", sum[:sha512.Size/2]),
+	}
+	s.ranges = nil
+	mux := http.NewServeMux()
+	mux.HandleFunc("/pack/v1/repositories/example.com/packages/app/1.0.0/envelope",
+		func(w http.ResponseWriter, r *http.Request) {
+			bytes, err := json.Marshal(s.envelope)
+			c.Assert(err, check.IsNil)
+			w.Write(bytes) //nolint:errcheck
+		})
+	mux.HandleFunc("/pack/v1/repositories/example.com/packages/app/1.0.0/file",
+		func(w http.ResponseWriter, r *http.Request) {
+			s.ranges = append(s.ranges, r.Header.Get("Range"))
+			http.ServeContent(w, r, s.locator.String(), time.Now(),
+				bytes.NewReader(s.contents))
+		})
+	s.server = httptest.NewServer(mux)
+}
+
+func (s *downloadSuite) TearDownTest(c *check.C) {
+	s.server.Close()
+}
+
+func (s *downloadSuite) newPackages(c *check.C, dir string) *resumingPackages {
+	client, err := webpack.NewClient(s.server.URL)
+	c.Assert(err, check.IsNil)
+	return newResumingPackages(client, dir)
+}
+
+// partialPath returns the path of the partial download file for the test
+// package in the provided state directory
+func (s *downloadSuite) partialPath(dir string) string {
+	return filepath.Join(dir, "example.com_app_1.0.0.partial")
+}
+
+func (s *downloadSuite) TestDownload(c *check.C) {
+	dir := c.MkDir()
+	packages := s.newPackages(c, dir)
+
+	envelope, reader, err := packages.ReadPackage(s.locator)
+	c.Assert(err, check.IsNil)
+	c.Assert(envelope.SHA512, check.Equals, s.envelope.SHA512)
+
+	downloaded, err := ioutil.ReadAll(reader)
+	c.Assert(err, check.IsNil)
+	c.Assert(downloaded, check.DeepEquals, s.contents)
+	c.Assert(reader.Close(), check.IsNil)
+
+	// the partial file is removed once the download has been consumed
+	_, err = os.Stat(s.partialPath(dir))
+	c.Assert(os.IsNotExist(err), check.Equals, true)
+}
+
+func (s *downloadSuite) TestResumesDownload(c *check.C) {
+	dir := c.MkDir()
+	packages := s.newPackages(c, dir)
+
+	// simulate an interrupted download by leaving a partial file around
+	err := ioutil.WriteFile(s.partialPath(dir), s.contents[:100], 0644)
+	c.Assert(err, check.IsNil)
+
+	_, reader, err := packages.ReadPackage(s.locator)
+	c.Assert(err, check.IsNil)
+	defer reader.Close()
+
+	downloaded, err := ioutil.ReadAll(reader)
+	c.Assert(err, check.IsNil)
+	c.Assert(downloaded, check.DeepEquals, s.contents)
+
+	// the download should have continued from the last received byte
+	c.Assert(s.ranges, check.DeepEquals, []string{"bytes=100-"})
+}
+
+func (s *downloadSuite) TestRestartsCorruptedDownload(c *check.C) {
+	dir := c.MkDir()
+	packages := s.newPackages(c, dir)
+
+	// a stale partial file larger than the package can't be resumed
+	stale := bytes.Repeat([]byte("x"), len(s.contents)+1)
+	err := ioutil.WriteFile(s.partialPath(dir), stale, 0644)
+	c.Assert(err, check.IsNil)
+
+	_, reader, err := packages.ReadPackage(s.locator)
+	c.Assert(err, check.IsNil)
+	defer reader.Close()
+
+	downloaded, err := ioutil.ReadAll(reader)
+	c.Assert(err, check.IsNil)
+	c.Assert(downloaded, check.DeepEquals, s.contents)
+	c.Assert(s.ranges, check.DeepEquals, []string{""})
+}
+
+func (s *downloadSuite) TestChecksumMismatch(c *check.C) {
+	dir := c.MkDir()
+	packages := s.newPackages(c, dir)
+	s.envelope.SHA512 = "0000"
+
+	_, _, err := packages.ReadPackage(s.locator)
+	c.Assert(trace.IsBadParameter(err), check.Equals, true,
+		check.Commentf("unexpected error: %v", err))
+
+	// the corrupted partial file should have been removed
+	_, err = os.Stat(s.partialPath(dir))
+	c.Assert(os.IsNotExist(err), check.Equals, true)
+}
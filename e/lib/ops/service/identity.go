@@ -18,7 +18,8 @@ import (
 	"context"
 
 	"github.com/gravitational/gravity/e/lib/events"
-	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/e/lib/ops"
+	ossops "github.com/gravitational/gravity/lib/ops"
 	libevents "github.com/gravitational/gravity/lib/ops/events"
 
 	"github.com/gravitational/teleport/lib/services"
@@ -26,7 +27,10 @@ import (
 )
 
 // UpsertRole creates a new role
-func (o *Operator) UpsertRole(ctx context.Context, key ops.SiteKey, role services.Role) error {
+func (o *Operator) UpsertRole(ctx context.Context, key ossops.SiteKey, role services.Role) error {
+	if err := ops.ValidateRole(role); err != nil {
+		return trace.Wrap(err)
+	}
 	if err := o.users().UpsertRole(role, 0); err != nil {
 		return trace.Wrap(err)
 	}
@@ -37,17 +41,28 @@ func (o *Operator) UpsertRole(ctx context.Context, key ops.SiteKey, role service
 }
 
 // GetRole returns a role by name
-func (o *Operator) GetRole(key ops.SiteKey, name string) (services.Role, error) {
+func (o *Operator) GetRole(key ossops.SiteKey, name string) (services.Role, error) {
 	return o.users().GetRole(name)
 }
 
 // GetRoles returns all roles
-func (o *Operator) GetRoles(key ops.SiteKey) ([]services.Role, error) {
+func (o *Operator) GetRoles(key ossops.SiteKey) ([]services.Role, error) {
 	return o.users().GetRoles()
 }
 
+// GetRolesByLabel returns the roles matching the provided label selector.
+// The selector is applied after fetch, see ops.FilterRolesByLabel for the
+// supported syntax
+func (o *Operator) GetRolesByLabel(key ossops.SiteKey, selector string) ([]services.Role, error) {
+	roles, err := o.users().GetRoles()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return ops.FilterRolesByLabel(roles, selector)
+}
+
 // DeleteRole deletes a role by name
-func (o *Operator) DeleteRole(ctx context.Context, key ops.SiteKey, name string) error {
+func (o *Operator) DeleteRole(ctx context.Context, key ossops.SiteKey, name string) error {
 	if err := o.users().DeleteRole(name); err != nil {
 		return trace.Wrap(err)
 	}
@@ -58,7 +73,7 @@ func (o *Operator) DeleteRole(ctx context.Context, key ops.SiteKey, name string)
 }
 
 // UpsertOIDCConnector creates or updates an OIDC connector
-func (o *Operator) UpsertOIDCConnector(ctx context.Context, key ops.SiteKey, connector services.OIDCConnector) error {
+func (o *Operator) UpsertOIDCConnector(ctx context.Context, key ossops.SiteKey, connector services.OIDCConnector) error {
 	if err := o.users().UpsertOIDCConnector(connector); err != nil {
 		return trace.Wrap(err)
 	}
@@ -71,19 +86,19 @@ func (o *Operator) UpsertOIDCConnector(ctx context.Context, key ops.SiteKey, con
 // GetOIDCConnector returns an OIDC connector by name
 //
 // Returned connector exclude client secret unless withSecrets is true.
-func (o *Operator) GetOIDCConnector(key ops.SiteKey, name string, withSecrets bool) (services.OIDCConnector, error) {
+func (o *Operator) GetOIDCConnector(key ossops.SiteKey, name string, withSecrets bool) (services.OIDCConnector, error) {
 	return o.users().GetOIDCConnector(name, withSecrets)
 }
 
 // GetOIDCConnectors returns all OIDC connectors
 //
 // Returned connectors exclude client secret unless withSecrets is true.
-func (o *Operator) GetOIDCConnectors(key ops.SiteKey, withSecrets bool) ([]services.OIDCConnector, error) {
+func (o *Operator) GetOIDCConnectors(key ossops.SiteKey, withSecrets bool) ([]services.OIDCConnector, error) {
 	return o.users().GetOIDCConnectors(withSecrets)
 }
 
 // DeleteOIDCConnector deletes an OIDC connector by name
-func (o *Operator) DeleteOIDCConnector(ctx context.Context, key ops.SiteKey, name string) error {
+func (o *Operator) DeleteOIDCConnector(ctx context.Context, key ossops.SiteKey, name string) error {
 	if err := o.users().DeleteOIDCConnector(name); err != nil {
 		return trace.Wrap(err)
 	}
@@ -94,7 +109,7 @@ func (o *Operator) DeleteOIDCConnector(ctx context.Context, key ops.SiteKey, nam
 }
 
 // UpsertSAMLConnector creates or updates a SAML connector
-func (o *Operator) UpsertSAMLConnector(ctx context.Context, key ops.SiteKey, connector services.SAMLConnector) error {
+func (o *Operator) UpsertSAMLConnector(ctx context.Context, key ossops.SiteKey, connector services.SAMLConnector) error {
 	if err := o.users().UpsertSAMLConnector(connector); err != nil {
 		return trace.Wrap(err)
 	}
@@ -107,19 +122,19 @@ func (o *Operator) UpsertSAMLConnector(ctx context.Context, key ops.SiteKey, con
 // GetSAMLConnector returns a SAML connector by name
 //
 // Returned connector excludes private signing key unless withSecrets is true.
-func (o *Operator) GetSAMLConnector(key ops.SiteKey, name string, withSecrets bool) (services.SAMLConnector, error) {
+func (o *Operator) GetSAMLConnector(key ossops.SiteKey, name string, withSecrets bool) (services.SAMLConnector, error) {
 	return o.users().GetSAMLConnector(name, withSecrets)
 }
 
 // GetSAMLConnectors returns all SAML connectors
 //
 // Returned connectors exclude private signing keys unless withSecrets is true.
-func (o *Operator) GetSAMLConnectors(key ops.SiteKey, withSecrets bool) ([]services.SAMLConnector, error) {
+func (o *Operator) GetSAMLConnectors(key ossops.SiteKey, withSecrets bool) ([]services.SAMLConnector, error) {
 	return o.users().GetSAMLConnectors(withSecrets)
 }
 
 // DeleteSAMLConnector deletes a SAML connector by name
-func (o *Operator) DeleteSAMLConnector(ctx context.Context, key ops.SiteKey, name string) error {
+func (o *Operator) DeleteSAMLConnector(ctx context.Context, key ossops.SiteKey, name string) error {
 	if err := o.users().DeleteSAMLConnector(name); err != nil {
 		return trace.Wrap(err)
 	}
@@ -18,6 +18,7 @@ import (
 	"context"
 
 	"github.com/gravitational/gravity/e/lib/events"
+	liclicense "github.com/gravitational/gravity/e/lib/license"
 	"github.com/gravitational/gravity/e/lib/ops"
 	"github.com/gravitational/gravity/lib/app"
 	"github.com/gravitational/gravity/lib/constants"
@@ -51,17 +52,25 @@ func (o *Operator) NewLicense(ctx context.Context, req ops.NewLicenseRequest) (s
 
 	o.Infof("Generating new license: %v", req)
 
-	ca, err := pack.ReadCertificateAuthority(o.packages())
-	if err != nil {
-		return "", trace.Wrap(err)
+	signer := o.licenseSigner
+	if signer == nil {
+		ca, err := pack.ReadCertificateAuthority(o.packages())
+		if err != nil {
+			return "", trace.Wrap(err)
+		}
+		signer = liclicense.NewLocalSigner(*ca)
 	}
 
-	license, err := licenseapi.NewLicense(licenseapi.NewLicenseInfo{
-		MaxNodes:   req.MaxNodes,
-		ValidFor:   req.ValidFor,
-		StopApp:    req.StopApp,
-		TLSKeyPair: *ca,
-	})
+	info := licenseapi.NewLicenseInfo{
+		MaxNodes: req.MaxNodes,
+		ValidFor: req.ValidFor,
+		StopApp:  req.StopApp,
+	}
+	if req.ClusterDomain != "" {
+		info.CustomerMetadata = ops.LicenseClusterBinding(req.ClusterDomain)
+	}
+
+	license, err := signer.SignLicense(info)
 	if err != nil {
 		return "", trace.Wrap(err)
 	}
@@ -107,6 +116,14 @@ func (o *Operator) CheckSiteLicense(ctx context.Context, key ossops.SiteKey) err
 	}
 
 	verificationError := license.Verify(ca.CertPEM)
+	if verificationError == nil {
+		// a license bound to another cluster fails the check even if the
+		// signature is valid
+		verificationError = ops.CheckLicenseClusterBinding(license.GetPayload(), cluster.Domain)
+	}
+	if verificationError == nil && o.isLicenseRevoked(cluster.License) {
+		verificationError = trace.AccessDenied("cluster license has been revoked")
+	}
 
 	if verificationError != nil {
 		err = o.DeactivateSite(ossops.DeactivateSiteRequest{
@@ -162,6 +179,12 @@ func (o *Operator) UpdateLicense(ctx context.Context, req ops.UpdateLicenseReque
 		return trace.Wrap(err)
 	}
 
+	// make sure the packages remain readable with the new license's
+	// encryption key before committing the license
+	if err := ossops.VerifyPackageAccess(o.packages(), req.License); err != nil {
+		return trace.Wrap(err)
+	}
+
 	cluster.License = req.License
 	if _, err = o.backend().UpdateSite(*cluster); err != nil {
 		return trace.Wrap(err)
@@ -198,6 +221,47 @@ func (o *Operator) UpdateLicense(ctx context.Context, req ops.UpdateLicenseReque
 	return trace.Wrap(err)
 }
 
+// RevokeLicense adds the provided license to the revocation list so
+// clusters installed with it fail the license check
+func (o *Operator) RevokeLicense(ctx context.Context, req ops.RevokeLicenseRequest) error {
+	if !o.isOpsCenter() {
+		return trace.AccessDenied("cannot revoke licenses")
+	}
+	err := req.Check()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	ids := ossops.LicenseIdentifiers(req.License)
+	if len(ids) == 0 {
+		return trace.BadParameter("provided license has no revocable identifiers")
+	}
+	for _, id := range ids {
+		err := o.backend().UpsertRevokedLicense(storage.RevokedLicense{
+			ID:     id,
+			Reason: req.Reason,
+		})
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	fields := libevents.Fields{}
+	if req.Reason != "" {
+		fields[libevents.FieldReason] = req.Reason
+	}
+	libevents.Emit(ctx, o, events.LicenseRevoked, fields)
+	return nil
+}
+
+// isLicenseRevoked returns true if the provided license has been revoked
+func (o *Operator) isLicenseRevoked(license string) bool {
+	for _, id := range ossops.LicenseIdentifiers(license) {
+		if _, err := o.backend().GetRevokedLicense(id); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
 // GetLicenseCA returns CA certificate Ops Center uses to sign licenses
 func (o *Operator) GetLicenseCA() ([]byte, error) {
 	if !o.isOpsCenter() {
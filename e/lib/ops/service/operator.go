@@ -15,6 +15,7 @@
 package service
 
 import (
+	"github.com/gravitational/gravity/e/lib/license"
 	"github.com/gravitational/gravity/lib/app"
 	"github.com/gravitational/gravity/lib/ops"
 	"github.com/gravitational/gravity/lib/ops/opsservice"
@@ -34,6 +35,15 @@ type Operator struct {
 	installGroups map[ops.SiteOperationKey]*installGroup
 	// services is a set of goroutines this operator is maintaining
 	services map[ops.SiteKey]map[string]service
+	// remoteClusterLocks serializes remote cluster handshake and removal
+	// requests per cluster name
+	remoteClusterLocks clusterLocks
+	// licenseSigner, when set, signs generated licenses in place of the
+	// CA key pair read from the local certificate authority package
+	licenseSigner license.Signer
+	// copyJournal tracks the artifacts replicated by cluster copy requests
+	// so interrupted copies can resume without re-copying them
+	copyJournal clusterCopyJournal
 }
 
 // New returns an new enterprise operator
@@ -45,6 +55,14 @@ func New(ossOperator *opsservice.Operator) *Operator {
 	}
 }
 
+// SetLicenseSigner configures the operator to sign generated licenses with
+// the provided signer, e.g. one backed by an external KMS/HSM. When unset,
+// licenses are signed with the CA key pair read from the local certificate
+// authority package
+func (o *Operator) SetLicenseSigner(signer license.Signer) {
+	o.licenseSigner = signer
+}
+
 // isOpsCenter returns true if this process is an Ops Center (i.e. not
 // standalone installer and not a cluster)
 func (o *Operator) isOpsCenter() bool {
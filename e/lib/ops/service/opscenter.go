@@ -15,6 +15,12 @@
 package service
 
 import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
 	"github.com/gravitational/gravity/e/lib/ops"
 	"github.com/gravitational/gravity/lib/httplib"
 	ossops "github.com/gravitational/gravity/lib/ops"
@@ -28,6 +34,9 @@ import (
 // and who's joining agent when installing via Ops Center
 func (o *Operator) RegisterAgent(req ops.RegisterAgentRequest) (*ops.RegisterAgentResponse, error) {
 	o.Infof("%s.", req)
+	if err := o.validateJoinToken(req); err != nil {
+		return nil, trace.Wrap(err)
+	}
 	group, err := o.getInstallGroup(req.SiteOperationKey())
 	if err != nil {
 		return nil, trace.Wrap(err)
@@ -39,6 +48,49 @@ func (o *Operator) RegisterAgent(req ops.RegisterAgentRequest) (*ops.RegisterAge
 	return response, nil
 }
 
+// validateJoinToken validates the pre-shared join token from the provided
+// registration request against the stored provisioning tokens before any
+// credentials are issued
+func (o *Operator) validateJoinToken(req ops.RegisterAgentRequest) error {
+	if req.JoinToken == "" {
+		// the join token is optional for compatibility with older agents
+		return nil
+	}
+	token, err := o.backend().GetProvisioningToken(req.JoinToken)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return trace.AccessDenied("invalid join token")
+		}
+		return trace.Wrap(err)
+	}
+	err = checkJoinToken(*token, time.Now().UTC())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	// the use count is incremented transactionally in the backend so
+	// concurrent registrations cannot exceed the token's use limit
+	_, err = o.backend().IncrementProvisioningTokenUses(token.Token)
+	if err != nil {
+		if trace.IsLimitExceeded(err) {
+			return trace.AccessDenied("join token has exceeded its limit of %v uses", token.MaxUses)
+		}
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// checkJoinToken makes sure the provided join token has neither expired
+// nor exceeded its use limit
+func checkJoinToken(token storage.ProvisioningToken, now time.Time) error {
+	if !token.Expires.IsZero() && now.After(token.Expires) {
+		return trace.AccessDenied("join token expired on %v", token.Expires)
+	}
+	if token.MaxUses != 0 && token.Uses >= token.MaxUses {
+		return trace.AccessDenied("join token has exceeded its limit of %v uses", token.MaxUses)
+	}
+	return nil
+}
+
 // RequestClusterCopy replicates the cluster specified in the provided request
 // and its data from the remote Ops Center
 //
@@ -48,6 +100,12 @@ func (o *Operator) RegisterAgent(req ops.RegisterAgentRequest) (*ops.RegisterAge
 //
 // The following things are replicated: cluster, install operation and its
 // progress entry, both admin and regular cluster agents, expand token.
+//
+// The copy is resumable: every replicated artifact is recorded in a journal
+// together with its checksum, so a copy retried after a failed transfer
+// skips the artifacts that have already been copied and verified. The
+// progress of the replicated operation can be polled using the key returned
+// by req.SiteOperationKey()
 func (o *Operator) RequestClusterCopy(req ops.ClusterCopyRequest) error {
 	if !o.GetConfig().Wizard {
 		return trace.BadParameter("only installer can request cluster copy")
@@ -81,18 +139,25 @@ func (o *Operator) RequestClusterCopy(req ops.ClusterCopyRequest) error {
 	if err != nil {
 		return trace.Wrap(err)
 	}
-	// now insert everything we've got
-	_, err = o.backend().CreateSite(ossops.ConvertOpsSite(*cluster))
+	// now insert everything we've got, skipping the artifacts already
+	// copied by a previous, possibly interrupted, attempt
+	key := req.SiteOperationKey()
+	err = o.replicate(key, "cluster", cluster, func() error {
+		_, err := o.backend().CreateSite(ossops.ConvertOpsSite(*cluster))
+		return trace.Wrap(err)
+	})
 	if err != nil {
 		return trace.Wrap(err)
 	}
-	o.Debugf("Replicated cluster: %v.", cluster)
 	for _, op := range operations {
-		_, err := o.backend().CreateSiteOperation(op)
+		op := op
+		err = o.replicate(key, fmt.Sprintf("operation/%v", op.ID), op, func() error {
+			_, err := o.backend().CreateSiteOperation(op)
+			return trace.Wrap(err)
+		})
 		if err != nil {
 			return trace.Wrap(err)
 		}
-		o.Debugf("Replicated operation: %v.", op)
 		progress, err := client.GetSiteOperationProgress(ossops.SiteOperationKey{
 			AccountID:   op.AccountID,
 			SiteDomain:  op.SiteDomain,
@@ -101,25 +166,103 @@ func (o *Operator) RequestClusterCopy(req ops.ClusterCopyRequest) error {
 		if err != nil {
 			return trace.Wrap(err)
 		}
-		_, err = o.backend().CreateProgressEntry(storage.ProgressEntry(*progress))
+		err = o.replicate(key, fmt.Sprintf("progress/%v", op.ID), progress, func() error {
+			_, err := o.backend().CreateProgressEntry(storage.ProgressEntry(*progress))
+			return trace.Wrap(err)
+		})
 		if err != nil {
 			return trace.Wrap(err)
 		}
-		o.Debugf("Replicated progress entry: %v.", progress)
 	}
-	_, err = o.users().CreateAgentFromLoginEntry(cluster.Domain, *regular, false)
+	err = o.replicate(key, "agent", regular, func() error {
+		_, err := o.users().CreateAgentFromLoginEntry(cluster.Domain, *regular, false)
+		return trace.Wrap(err)
+	})
 	if err != nil {
 		return trace.Wrap(err)
 	}
-	o.Debugf("Replicated regular agent: %v.", regular)
-	_, err = o.users().CreateProvisioningToken(*token)
+	err = o.replicate(key, "expand-token", token, func() error {
+		_, err := o.users().CreateProvisioningToken(*token)
+		return trace.Wrap(err)
+	})
 	if err != nil {
 		return trace.Wrap(err)
 	}
-	o.Debugf("Replicated expand token: %v.", token)
 	return nil
 }
 
+// replicate copies the named artifact through the copy journal and logs
+// the outcome
+func (o *Operator) replicate(key ossops.SiteOperationKey, name string, payload interface{}, create func() error) error {
+	copied, err := o.copyJournal.copyArtifact(key, name, payload, create)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if copied {
+		o.Debugf("Replicated %v: %v.", name, payload)
+	} else {
+		o.Debugf("Skipped already replicated %v.", name)
+	}
+	return nil
+}
+
+// clusterCopyJournal tracks the artifacts replicated by RequestClusterCopy
+// keyed by the operation they are copied for, so a retried copy can skip
+// the ones that have already been copied and verified
+type clusterCopyJournal struct {
+	sync.Mutex
+	// artifacts maps operation key to artifact name to the checksum the
+	// artifact had when it was copied
+	artifacts map[ossops.SiteOperationKey]map[string]string
+}
+
+// copyArtifact copies the named artifact with the provided create function
+// unless the journal shows it has already been copied with the same
+// checksum. An artifact that already exists in the backend is treated as
+// copied. Returns copied=false if the copy was skipped
+func (j *clusterCopyJournal) copyArtifact(key ossops.SiteOperationKey, name string, payload interface{}, create func() error) (copied bool, err error) {
+	checksum, err := artifactChecksum(payload)
+	if err != nil {
+		return false, trace.Wrap(err)
+	}
+	if j.isCopied(key, name, checksum) {
+		return false, nil
+	}
+	if err := create(); err != nil && !trace.IsAlreadyExists(err) {
+		return false, trace.Wrap(err)
+	}
+	j.record(key, name, checksum)
+	return true, nil
+}
+
+func (j *clusterCopyJournal) isCopied(key ossops.SiteOperationKey, name, checksum string) bool {
+	j.Lock()
+	defer j.Unlock()
+	return j.artifacts[key][name] == checksum
+}
+
+func (j *clusterCopyJournal) record(key ossops.SiteOperationKey, name, checksum string) {
+	j.Lock()
+	defer j.Unlock()
+	if j.artifacts == nil {
+		j.artifacts = make(map[ossops.SiteOperationKey]map[string]string)
+	}
+	if j.artifacts[key] == nil {
+		j.artifacts[key] = make(map[string]string)
+	}
+	j.artifacts[key][name] = checksum
+}
+
+// artifactChecksum returns the checksum of the artifact's JSON encoding
+// used to verify that a previously copied artifact has not changed
+func artifactChecksum(payload interface{}) (string, error) {
+	bytes, err := json.Marshal(payload)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(bytes)), nil
+}
+
 func (o *Operator) getInstallGroup(key ossops.SiteOperationKey) (*installGroup, error) {
 	o.Lock()
 	defer o.Unlock()
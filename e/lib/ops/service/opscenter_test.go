@@ -0,0 +1,167 @@
+// Copyright 2021 Gravitational Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"time"
+
+	ossops "github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	check "gopkg.in/check.v1"
+)
+
+type joinTokenSuite struct{}
+
+var _ = check.Suite(&joinTokenSuite{})
+
+func (s *joinTokenSuite) TestValidJoinToken(c *check.C) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	token := storage.ProvisioningToken{
+		Token:   "token",
+		Expires: now.Add(time.Hour),
+		MaxUses: 3,
+	}
+	c.Assert(checkJoinToken(token, now), check.IsNil)
+	token.Uses = 2
+	c.Assert(checkJoinToken(token, now), check.IsNil)
+	// a token without expiry or use limit is always valid
+	c.Assert(checkJoinToken(storage.ProvisioningToken{Token: "token", Uses: 100}, now), check.IsNil)
+}
+
+func (s *joinTokenSuite) TestExpiredJoinToken(c *check.C) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	token := storage.ProvisioningToken{
+		Token:   "token",
+		Expires: now.Add(-time.Minute),
+	}
+	err := checkJoinToken(token, now)
+	c.Assert(trace.IsAccessDenied(err), check.Equals, true,
+		check.Commentf("unexpected error: %v", err))
+}
+
+func (s *joinTokenSuite) TestOverusedJoinToken(c *check.C) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	token := storage.ProvisioningToken{
+		Token:   "token",
+		Expires: now.Add(time.Hour),
+		MaxUses: 2,
+		Uses:    2,
+	}
+	err := checkJoinToken(token, now)
+	c.Assert(trace.IsAccessDenied(err), check.Equals, true,
+		check.Commentf("unexpected error: %v", err))
+}
+
+type copyJournalSuite struct{}
+
+var _ = check.Suite(&copyJournalSuite{})
+
+func (s *copyJournalSuite) TestResumesInterruptedCopy(c *check.C) {
+	key := ossops.SiteOperationKey{
+		AccountID:   "account",
+		SiteDomain:  "example.com",
+		OperationID: "op",
+	}
+	journal := &clusterCopyJournal{}
+	artifacts := []struct {
+		name    string
+		payload interface{}
+	}{
+		{"cluster", "cluster-data"},
+		{"operation/op", "operation-data"},
+		{"expand-token", "token-data"},
+	}
+	copies := map[string]int{}
+	copyAll := func(failAt string) error {
+		for _, artifact := range artifacts {
+			artifact := artifact
+			_, err := journal.copyArtifact(key, artifact.name, artifact.payload, func() error {
+				if artifact.name == failAt {
+					return trace.ConnectionProblem(nil, "link dropped")
+				}
+				copies[artifact.name]++
+				return nil
+			})
+			if err != nil {
+				return trace.Wrap(err)
+			}
+		}
+		return nil
+	}
+
+	// the first attempt is interrupted while copying the expand token
+	err := copyAll("expand-token")
+	c.Assert(err, check.ErrorMatches, "(?s).*link dropped.*")
+	c.Assert(copies, check.DeepEquals, map[string]int{
+		"cluster":      1,
+		"operation/op": 1,
+	})
+
+	// the retry skips the verified artifacts and completes the copy
+	err = copyAll("")
+	c.Assert(err, check.IsNil)
+	c.Assert(copies, check.DeepEquals, map[string]int{
+		"cluster":      1,
+		"operation/op": 1,
+		"expand-token": 1,
+	})
+}
+
+func (s *copyJournalSuite) TestRecopiesChangedArtifact(c *check.C) {
+	key := ossops.SiteOperationKey{
+		AccountID:   "account",
+		SiteDomain:  "example.com",
+		OperationID: "op",
+	}
+	journal := &clusterCopyJournal{}
+	copies := 0
+	copy := func(payload interface{}) bool {
+		copied, err := journal.copyArtifact(key, "cluster", payload, func() error {
+			copies++
+			return nil
+		})
+		c.Assert(err, check.IsNil)
+		return copied
+	}
+	c.Assert(copy("v1"), check.Equals, true)
+	// the same payload verifies against the recorded checksum and is skipped
+	c.Assert(copy("v1"), check.Equals, false)
+	// a payload with a different checksum is copied again
+	c.Assert(copy("v2"), check.Equals, true)
+	c.Assert(copies, check.Equals, 2)
+}
+
+func (s *copyJournalSuite) TestTreatsExistingArtifactAsCopied(c *check.C) {
+	key := ossops.SiteOperationKey{
+		AccountID:   "account",
+		SiteDomain:  "example.com",
+		OperationID: "op",
+	}
+	journal := &clusterCopyJournal{}
+	copied, err := journal.copyArtifact(key, "cluster", "data", func() error {
+		return trace.AlreadyExists("cluster already exists")
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(copied, check.Equals, true)
+	// the artifact is recorded as copied so the retry skips it
+	copied, err = journal.copyArtifact(key, "cluster", "data", func() error {
+		c.Fatal("create should not be invoked for a copied artifact")
+		return nil
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(copied, check.Equals, false)
+}
@@ -17,6 +17,8 @@ package service
 import (
 	"context"
 	"fmt"
+	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/gravitational/gravity/e/lib/events"
@@ -79,6 +81,88 @@ func (o *Operator) CheckForUpdate(key ossops.SiteKey) (*loc.Locator, error) {
 		"no update for %v installed on %v found", site.App, site.Domain)
 }
 
+// ListAvailableUpdates returns all application versions newer than the
+// installed one available on the remote Ops Center
+func (o *Operator) ListAvailableUpdates(key ossops.SiteKey) (*ops.ListAvailableUpdatesResponse, error) {
+	site, err := o.backend().GetSite(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	cluster, err := ops.GetTrustedCluster(key, o)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	opsPackages, err := o.remotePackagesClient(site.Domain, cluster)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	installed, err := pack.FindLatestPackage(o.packages(), site.App.Locator())
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	envelopes, err := opsPackages.GetPackages(installed.Repository)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	updates, err := collectAvailableUpdates(*installed, envelopes)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	return &ops.ListAvailableUpdatesResponse{
+		InstalledVersion: *installed,
+		Updates:          updates,
+	}, nil
+}
+
+// GetClustersWithAvailableUpdates checks all clusters of the specified
+// account for updates and returns only those that have a newer application
+// version available
+func (o *Operator) GetClustersWithAvailableUpdates(accountID string) ([]ops.ClusterUpdateInfo, error) {
+	clusters, err := o.backend().GetSites(accountID)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return ops.ClustersWithAvailableUpdates(o, accountID, clusters), nil
+}
+
+// collectAvailableUpdates returns the subset of the provided package
+// envelopes that are newer versions of the installed application, most
+// recent version first
+func collectAvailableUpdates(installed loc.Locator, envelopes []pack.PackageEnvelope) ([]ops.AvailableUpdate, error) {
+	var updates []ops.AvailableUpdate
+	for _, envelope := range envelopes {
+		if envelope.Locator.Name != installed.Name {
+			continue
+		}
+		isNewer, err := envelope.Locator.IsNewerThan(installed)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if !isNewer {
+			continue
+		}
+		updates = append(updates, ops.AvailableUpdate{
+			Application: envelope.Locator,
+			SizeBytes:   envelope.SizeBytes,
+			Created:     envelope.Created,
+		})
+	}
+	sort.Slice(updates, func(i, j int) bool {
+		isNewer, err := updates[i].Application.IsNewerThan(updates[j].Application)
+		if err != nil {
+			return false
+		}
+		return isNewer
+	})
+	return updates, nil
+}
+
 // DownloadUpdates downloads the provided application version from remote Ops Center
 func (o *Operator) DownloadUpdate(ctx context.Context, req ops.DownloadUpdateRequest) error {
 	site, err := o.backend().GetSite(req.SiteDomain)
@@ -101,8 +185,13 @@ func (o *Operator) DownloadUpdate(ctx context.Context, req ops.DownloadUpdateReq
 		return trace.Wrap(err)
 	}
 
+	// wrap the remote package service so an interrupted download can be
+	// resumed from the last received byte on the next attempt
+	srcPackages := newResumingPackages(opsPackages,
+		filepath.Join(o.GetConfig().StateDir, downloadsDir))
+
 	_, err = appservice.PullApp(appservice.AppPullRequest{
-		SrcPack: opsPackages,
+		SrcPack: srcPackages,
 		SrcApp:  opsApps,
 		DstPack: o.packages(),
 		DstApp:  o.apps(),
@@ -233,6 +322,52 @@ func (o *Operator) StopPeriodicUpdates(key ossops.SiteKey) error {
 	return nil
 }
 
+// PauseUpdatesFor stops the periodic updates check and schedules it to resume
+// automatically after the provided duration. Unlike a separate stop/start
+// pair, the resumption is guaranteed to happen even after the call returns
+func (o *Operator) PauseUpdatesFor(ctx context.Context, key ossops.SiteKey, duration time.Duration) error {
+	return o.pauseUpdates(key, duration,
+		func() error { return o.StopPeriodicUpdates(key) },
+		func() error { return o.StartPeriodicUpdates(key) })
+}
+
+// pauseUpdates stops the periodic updates check using the provided stop
+// function and schedules resume to run after the provided duration
+func (o *Operator) pauseUpdates(key ossops.SiteKey, duration time.Duration, stop, resume func() error) error {
+	if duration <= 0 {
+		return trace.BadParameter(
+			"pause duration must be positive, got: %v", duration)
+	}
+
+	if err := stop(); err != nil {
+		return trace.Wrap(err)
+	}
+
+	// replace the resumption possibly pending from a previous pause so the
+	// new window starts counting from now
+	err := o.stopService(key, resumeUpdatesService)
+	if err != nil && !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+
+	o.Infof("Pausing periodic updates for %v for %v.", key.SiteDomain, duration)
+	o.startService(key, resumeUpdatesService, func(ctx context.Context) {
+		select {
+		case <-time.After(duration):
+		case <-ctx.Done():
+			o.Infof("Canceling scheduled periodic updates resumption for %v.",
+				key.SiteDomain)
+			return
+		}
+		o.Infof("Resuming periodic updates for %v.", key.SiteDomain)
+		if err := resume(); err != nil {
+			o.Error(trace.DebugReport(err))
+		}
+	})
+
+	return nil
+}
+
 // PeriodicUpdatesStatus returns the status of periodic updates for the cluster
 func (o *Operator) PeriodicUpdatesStatus(key ossops.SiteKey) (*ops.PeriodicUpdatesStatusResponse, error) {
 	cluster, err := ops.GetTrustedCluster(key, o)
@@ -340,6 +475,10 @@ func (o *Operator) updatePeriodicUpdatesStatus(key ossops.SiteKey, enabled bool,
 // periodicUpdatesService is an identifier for the periodic updates goroutine
 const periodicUpdatesService = "periodicupdates"
 
+// resumeUpdatesService is an identifier for the goroutine that resumes
+// periodic updates after a pause
+const resumeUpdatesService = "resumeupdates"
+
 // remotePackagesClient returns remote Ops Center package service client
 func (o *Operator) remotePackagesClient(clusterName string, cluster teleservices.TrustedCluster) (*webpack.Client, error) {
 	_, token, err := users.GetOpsCenterAgent(
@@ -0,0 +1,136 @@
+// Copyright 2021 Gravitational Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"time"
+
+	"github.com/gravitational/gravity/e/lib/ops"
+	"github.com/gravitational/gravity/lib/loc"
+	ossops "github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/ops/opsservice"
+	"github.com/gravitational/gravity/lib/pack"
+
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+	check "gopkg.in/check.v1"
+)
+
+type updatesSuite struct{}
+
+var _ = check.Suite(&updatesSuite{})
+
+func (s *updatesSuite) TestCollectsAvailableUpdates(c *check.C) {
+	installed := loc.MustParseLocator("example.com/app:2.0.0")
+	released := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	envelopes := []pack.PackageEnvelope{
+		{Locator: loc.MustParseLocator("example.com/app:1.0.0")},
+		{Locator: loc.MustParseLocator("example.com/app:2.0.0")},
+		{Locator: loc.MustParseLocator("example.com/other:3.0.0")},
+		{
+			Locator:   loc.MustParseLocator("example.com/app:2.1.0"),
+			SizeBytes: 100,
+			Created:   released,
+		},
+		{
+			Locator:   loc.MustParseLocator("example.com/app:3.0.0"),
+			SizeBytes: 200,
+			Created:   released.Add(time.Hour),
+		},
+	}
+	updates, err := collectAvailableUpdates(installed, envelopes)
+	c.Assert(err, check.IsNil)
+	c.Assert(updates, check.DeepEquals, []ops.AvailableUpdate{
+		{
+			Application: loc.MustParseLocator("example.com/app:3.0.0"),
+			SizeBytes:   200,
+			Created:     released.Add(time.Hour),
+		},
+		{
+			Application: loc.MustParseLocator("example.com/app:2.1.0"),
+			SizeBytes:   100,
+			Created:     released,
+		},
+	})
+}
+
+func (s *updatesSuite) TestPauseStopsAndResumesUpdates(c *check.C) {
+	operator := newUpdatesOperator()
+	key := ossops.SiteKey{AccountID: "account", SiteDomain: "example.com"}
+	stopped := make(chan struct{}, 1)
+	resumed := make(chan struct{}, 1)
+	err := operator.pauseUpdates(key, 10*time.Millisecond,
+		func() error { stopped <- struct{}{}; return nil },
+		func() error { resumed <- struct{}{}; return nil })
+	c.Assert(err, check.IsNil)
+	select {
+	case <-stopped:
+	default:
+		c.Fatal("expected the updates loop to be stopped before the call returns")
+	}
+	select {
+	case <-resumed:
+	case <-time.After(5 * time.Second):
+		c.Fatal("expected the updates loop to resume after the pause window")
+	}
+}
+
+func (s *updatesSuite) TestPauseSupersedesScheduledResumption(c *check.C) {
+	operator := newUpdatesOperator()
+	key := ossops.SiteKey{AccountID: "account", SiteDomain: "example.com"}
+	resumed := make(chan struct{}, 2)
+	noop := func() error { return nil }
+	resume := func() error { resumed <- struct{}{}; return nil }
+	// the second pause replaces the resumption scheduled by the first one
+	// so the loop only resumes once
+	c.Assert(operator.pauseUpdates(key, time.Hour, noop, resume), check.IsNil)
+	c.Assert(operator.pauseUpdates(key, 10*time.Millisecond, noop, resume), check.IsNil)
+	select {
+	case <-resumed:
+	case <-time.After(5 * time.Second):
+		c.Fatal("expected the updates loop to resume after the second pause window")
+	}
+	select {
+	case <-resumed:
+		c.Fatal("expected the first scheduled resumption to have been canceled")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func (s *updatesSuite) TestPauseRequiresPositiveDuration(c *check.C) {
+	operator := newUpdatesOperator()
+	key := ossops.SiteKey{AccountID: "account", SiteDomain: "example.com"}
+	fail := func() error { return trace.BadParameter("must not be called") }
+	err := operator.pauseUpdates(key, 0, fail, fail)
+	c.Assert(trace.IsBadParameter(err), check.Equals, true)
+}
+
+// newUpdatesOperator returns an operator suitable for exercising the
+// service goroutine machinery in tests
+func newUpdatesOperator() *Operator {
+	return New(&opsservice.Operator{
+		FieldLogger: log.WithField(trace.Component, "test"),
+	})
+}
+
+func (s *updatesSuite) TestCollectsNoUpdatesWhenUpToDate(c *check.C) {
+	installed := loc.MustParseLocator("example.com/app:2.0.0")
+	updates, err := collectAvailableUpdates(installed, []pack.PackageEnvelope{
+		{Locator: loc.MustParseLocator("example.com/app:1.0.0")},
+		{Locator: loc.MustParseLocator("example.com/app:2.0.0")},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(updates, check.HasLen, 0)
+}
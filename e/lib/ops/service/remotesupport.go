@@ -16,6 +16,7 @@ package service
 
 import (
 	"bytes"
+	"sync"
 	"time"
 
 	"github.com/gravitational/gravity/e/lib/ops"
@@ -29,10 +30,40 @@ import (
 	"github.com/gravitational/trace"
 )
 
+// clusterLocks provides per-cluster-name locking used to serialize
+// concurrent remote cluster handshake and removal requests
+type clusterLocks struct {
+	// Mutex guards the locks map
+	sync.Mutex
+	// locks maps a cluster name to its lock
+	locks map[string]*sync.Mutex
+}
+
+// get returns the lock for the specified cluster name, creating it
+// if necessary
+func (l *clusterLocks) get(name string) *sync.Mutex {
+	l.Lock()
+	defer l.Unlock()
+	if l.locks == nil {
+		l.locks = make(map[string]*sync.Mutex)
+	}
+	lock, ok := l.locks[name]
+	if !ok {
+		lock = &sync.Mutex{}
+		l.locks[name] = lock
+	}
+	return lock
+}
+
 // RemoveRemoteCluster removes the cluster entry specified in the request
 func (o *Operator) RemoveRemoteCluster(req ops.RemoveRemoteClusterRequest) error {
 	o.Infof("%s", req)
 
+	// serialize with a possible concurrent handshake for the same cluster
+	lock := o.remoteClusterLocks.get(req.ClusterName)
+	lock.Lock()
+	defer lock.Unlock()
+
 	// verify handshake token
 	_, err := o.users().GetToken(req.HandshakeToken)
 	if err != nil && !trace.IsNotFound(err) {
@@ -69,6 +100,11 @@ func (o *Operator) AcceptRemoteCluster(req ops.AcceptRemoteClusterRequest) (*ops
 		return nil, trace.Wrap(err)
 	}
 
+	// serialize with a possible concurrent removal of the same cluster
+	lock := o.remoteClusterLocks.get(req.Site.Domain)
+	lock.Lock()
+	defer lock.Unlock()
+
 	// verify handshake token
 	_, err := o.users().GetToken(req.HandshakeToken)
 	if err != nil && !trace.IsNotFound(err) {
@@ -0,0 +1,33 @@
+// Copyright 2021 Gravitational Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	check "gopkg.in/check.v1"
+)
+
+type remoteSupportSuite struct{}
+
+var _ = check.Suite(&remoteSupportSuite{})
+
+func (s *remoteSupportSuite) TestClusterLocks(c *check.C) {
+	var locks clusterLocks
+	// requests for the same cluster share a lock, different clusters
+	// get their own
+	lock := locks.get("example.com")
+	c.Assert(lock, check.NotNil)
+	c.Assert(locks.get("example.com"), check.Equals, lock)
+	c.Assert(locks.get("other.com"), check.Not(check.Equals), lock)
+}
@@ -18,11 +18,14 @@ import (
 	"context"
 	"fmt"
 	"io/ioutil"
+	"net"
+	"time"
 
 	"github.com/gravitational/gravity/e/lib/events"
 	"github.com/gravitational/gravity/e/lib/ops"
 	"github.com/gravitational/gravity/e/lib/ops/client"
 	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/httplib"
 	ossops "github.com/gravitational/gravity/lib/ops"
 	libevents "github.com/gravitational/gravity/lib/ops/events"
@@ -38,6 +41,9 @@ import (
 // UpsertTrustedCluster creates or updates a trusted cluster
 func (o *Operator) UpsertTrustedCluster(ctx context.Context, key ossops.SiteKey, cluster storage.TrustedCluster) error {
 	o.Infof("UpsertTrustedCluster(%s).", cluster)
+	if err := ops.ValidateTrustedCluster(cluster); err != nil {
+		return trace.Wrap(err)
+	}
 	if !o.isInstaller() {
 		local, err := o.GetLocalSite(ctx)
 		if err != nil {
@@ -150,6 +156,7 @@ func (o *Operator) DeleteTrustedCluster(ctx context.Context, req ops.DeleteTrust
 	if err != nil {
 		return trace.Wrap(err)
 	}
+	emitTrustedClusterDeleted(ctx, o, cluster.GetName(), req.Reason)
 	if cluster.GetRegular() {
 		libevents.Emit(ctx, o, events.RemoteSupportDisabled, libevents.Fields{
 			events.FieldOpsCenter: cluster.GetName(),
@@ -166,6 +173,18 @@ func (o *Operator) DeleteTrustedCluster(ctx context.Context, req ops.DeleteTrust
 	return nil
 }
 
+// emitTrustedClusterDeleted records the trusted cluster removal in the audit
+// log, including the optional removal reason
+func emitTrustedClusterDeleted(ctx context.Context, operator ossops.Operator, clusterName, reason string) {
+	fields := libevents.Fields{
+		libevents.FieldName: clusterName,
+	}
+	if reason != "" {
+		fields[libevents.FieldReason] = reason
+	}
+	libevents.Emit(ctx, operator, events.TrustedClusterDeleted, fields)
+}
+
 // GetTrustedClusters returns a list of configured trusted clusters
 func (o *Operator) GetTrustedClusters(key ossops.SiteKey) ([]storage.TrustedCluster, error) {
 	clusters, err := o.users().GetTrustedClusters()
@@ -181,6 +200,7 @@ func (o *Operator) GetTrustedClusters(key ossops.SiteKey) ([]storage.TrustedClus
 		}
 		result = append(result, clusterS)
 	}
+	ops.SortTrustedClusters(result)
 	return result, nil
 }
 
@@ -197,6 +217,38 @@ func (o *Operator) GetTrustedCluster(key ossops.SiteKey, name string) (storage.T
 	return cluster, nil
 }
 
+// PingTrustedCluster attempts a lightweight connection to the trusted cluster
+// specified by name and reports its reachability along with the observed
+// latency
+func (o *Operator) PingTrustedCluster(key ossops.SiteKey, name string) (*ops.TrustedClusterPingResponse, error) {
+	cluster, err := o.getTrustedClusterByName(name)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return pingProxyAddress(cluster.GetProxyAddress())
+}
+
+// pingProxyAddress dials the provided trusted cluster proxy address and
+// returns the reachability result along with the connection latency
+func pingProxyAddress(address string) (*ops.TrustedClusterPingResponse, error) {
+	if address == "" {
+		return nil, trace.BadParameter("trusted cluster does not have a proxy address")
+	}
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return nil, trace.BadParameter("invalid proxy address %q: %v", address, err)
+	}
+	started := time.Now()
+	conn, err := net.DialTimeout("tcp", address, defaults.DialTimeout)
+	if err != nil {
+		return &ops.TrustedClusterPingResponse{Error: err.Error()}, nil
+	}
+	conn.Close()
+	return &ops.TrustedClusterPingResponse{
+		Reachable: true,
+		Latency:   time.Since(started),
+	}, nil
+}
+
 // upsertRemoteCluster makes a request to the Ops Center represented by the
 // provided trusted cluster to create or update a local entry for the cluster
 // with the given key
@@ -0,0 +1,102 @@
+// Copyright 2021 Gravitational Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/gravitational/gravity/e/lib/events"
+	"github.com/gravitational/gravity/lib/constants"
+	ossops "github.com/gravitational/gravity/lib/ops"
+	libevents "github.com/gravitational/gravity/lib/ops/events"
+
+	"github.com/gravitational/trace"
+	check "gopkg.in/check.v1"
+)
+
+func TestService(t *testing.T) { check.TestingT(t) }
+
+type trustedClustersSuite struct{}
+
+var _ = check.Suite(&trustedClustersSuite{})
+
+func (s *trustedClustersSuite) TestPingReachableCluster(c *check.C) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, check.IsNil)
+	defer listener.Close()
+	response, err := pingProxyAddress(listener.Addr().String())
+	c.Assert(err, check.IsNil)
+	c.Assert(response.Reachable, check.Equals, true)
+	c.Assert(response.Latency > 0, check.Equals, true)
+	c.Assert(response.Error, check.Equals, "")
+}
+
+func (s *trustedClustersSuite) TestPingUnreachableCluster(c *check.C) {
+	// grab a port that is not being listened on
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, check.IsNil)
+	address := listener.Addr().String()
+	c.Assert(listener.Close(), check.IsNil)
+	response, err := pingProxyAddress(address)
+	c.Assert(err, check.IsNil)
+	c.Assert(response.Reachable, check.Equals, false)
+	c.Assert(response.Error, check.Not(check.Equals), "")
+}
+
+func (s *trustedClustersSuite) TestPingMisconfiguredCluster(c *check.C) {
+	_, err := pingProxyAddress("")
+	c.Assert(trace.IsBadParameter(err), check.Equals, true)
+	_, err = pingProxyAddress("no-port-here")
+	c.Assert(trace.IsBadParameter(err), check.Equals, true)
+}
+
+func (s *trustedClustersSuite) TestTrustedClusterDeletedAuditEvent(c *check.C) {
+	operator := &fakeAuditOperator{}
+	ctx := context.WithValue(context.TODO(), constants.UserContext, "admin@example.com")
+	emitTrustedClusterDeleted(ctx, operator, "hub.example.com", "security review")
+	c.Assert(operator.events, check.HasLen, 1)
+	event := operator.events[0]
+	c.Assert(event.Event.Name, check.Equals, events.TrustedClusterDeletedEvent)
+	c.Assert(event.Fields[libevents.FieldName], check.Equals, "hub.example.com")
+	c.Assert(event.Fields[libevents.FieldReason], check.Equals, "security review")
+	c.Assert(event.Fields[libevents.FieldUser], check.Equals, "admin@example.com")
+}
+
+func (s *trustedClustersSuite) TestTrustedClusterDeletedAuditEventNoReason(c *check.C) {
+	operator := &fakeAuditOperator{}
+	emitTrustedClusterDeleted(context.TODO(), operator, "hub.example.com", "")
+	c.Assert(operator.events, check.HasLen, 1)
+	event := operator.events[0]
+	c.Assert(event.Event.Name, check.Equals, events.TrustedClusterDeletedEvent)
+	_, ok := event.Fields[libevents.FieldReason]
+	c.Assert(ok, check.Equals, false)
+}
+
+// fakeAuditOperator captures emitted audit events
+type fakeAuditOperator struct {
+	ossops.Operator
+	events []ossops.AuditEventRequest
+}
+
+func (o *fakeAuditOperator) GetLocalSite(context.Context) (*ossops.Site, error) {
+	return &ossops.Site{AccountID: "account", Domain: "example.com"}, nil
+}
+
+func (o *fakeAuditOperator) EmitAuditEvent(ctx context.Context, req ossops.AuditEventRequest) error {
+	o.events = append(o.events, req)
+	return nil
+}
@@ -15,12 +15,458 @@
 package ops
 
 import (
+	"context"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/loc"
 	"github.com/gravitational/gravity/lib/ops"
 	"github.com/gravitational/gravity/lib/storage"
 
+	licenseapi "github.com/gravitational/license"
+	teleservices "github.com/gravitational/teleport/lib/services"
 	"github.com/gravitational/trace"
+	kubelabels "k8s.io/apimachinery/pkg/labels"
+)
+
+// SortTrustedClusters sorts the provided trusted clusters by name to
+// guarantee a deterministic output order
+func SortTrustedClusters(clusters []storage.TrustedCluster) {
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return clusters[i].GetName() < clusters[j].GetName()
+	})
+}
+
+// FilterRolesByLabel returns the subset of the provided roles matching the
+// given label selector. The selector uses the Kubernetes label selector
+// syntax, supporting both equality-based (env=prod) and set-based
+// (env in (dev, staging)) requirements. An empty selector matches every role
+func FilterRolesByLabel(roles []teleservices.Role, selector string) ([]teleservices.Role, error) {
+	parsed, err := kubelabels.Parse(selector)
+	if err != nil {
+		return nil, trace.BadParameter("invalid label selector %q: %v", selector, err)
+	}
+	var matched []teleservices.Role
+	for _, role := range roles {
+		if parsed.Matches(kubelabels.Set(role.GetMetadata().Labels)) {
+			matched = append(matched, role)
+		}
+	}
+	return matched, nil
+}
+
+// AuthConfig is a bundle of cluster auth resources - roles and auth
+// connectors - that can be exported and later re-imported, e.g. for
+// disaster recovery
+type AuthConfig struct {
+	// Roles lists cluster roles
+	Roles []teleservices.Role `json:"roles,omitempty"`
+	// OIDCConnectors lists OIDC authentication connectors
+	OIDCConnectors []teleservices.OIDCConnector `json:"oidc_connectors,omitempty"`
+	// SAMLConnectors lists SAML authentication connectors
+	SAMLConnectors []teleservices.SAMLConnector `json:"saml_connectors,omitempty"`
+	// GithubConnectors lists Github authentication connectors
+	GithubConnectors []teleservices.GithubConnector `json:"github_connectors,omitempty"`
+}
+
+// ExportAuthConfig returns the cluster roles and auth connectors as a single
+// bundle. It composes the existing getters so access is guarded by their
+// read checks.
+//
+// Connector secrets - client secrets and SAML signing keys - are only
+// included if withSecrets is set. A bundle exported without secrets cannot
+// be imported back
+func ExportAuthConfig(key ops.SiteKey, operator Operator, withSecrets bool) (*AuthConfig, error) {
+	roles, err := operator.GetRoles(key)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	oidcConnectors, err := operator.GetOIDCConnectors(key, withSecrets)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	samlConnectors, err := operator.GetSAMLConnectors(key, withSecrets)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	githubConnectors, err := operator.GetGithubConnectors(key, withSecrets)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &AuthConfig{
+		Roles:            roles,
+		OIDCConnectors:   oidcConnectors,
+		SAMLConnectors:   samlConnectors,
+		GithubConnectors: githubConnectors,
+	}, nil
+}
+
+// ImportAuthConfig upserts the roles and auth connectors from the provided
+// bundle. It composes the existing upsert methods so access is guarded by
+// their update checks.
+//
+// System roles are skipped as they are managed by the cluster itself.
+//
+// A bundle that was exported without secrets is rejected upfront to avoid
+// creating broken connectors
+func ImportAuthConfig(ctx context.Context, key ops.SiteKey, operator Operator, config AuthConfig) error {
+	if err := checkAuthConfigSecrets(config); err != nil {
+		return trace.Wrap(err)
+	}
+	for _, role := range config.Roles {
+		if role.GetMetadata().Labels[constants.SystemLabel] == constants.True {
+			continue
+		}
+		if err := operator.UpsertRole(ctx, key, role); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	for _, connector := range config.OIDCConnectors {
+		if err := operator.UpsertOIDCConnector(ctx, key, connector); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	for _, connector := range config.SAMLConnectors {
+		if err := operator.UpsertSAMLConnector(ctx, key, connector); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	for _, connector := range config.GithubConnectors {
+		if err := operator.UpsertGithubConnector(ctx, key, connector); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// checkAuthConfigSecrets verifies the connectors in the bundle include their
+// secrets so the import does not create broken connectors
+func checkAuthConfigSecrets(config AuthConfig) error {
+	for _, connector := range config.OIDCConnectors {
+		if connector.GetClientSecret() == "" {
+			return trace.BadParameter("OIDC connector %q does not include the client secret, the bundle was likely exported without secrets",
+				connector.GetName())
+		}
+	}
+	for _, connector := range config.SAMLConnectors {
+		keyPair := connector.GetSigningKeyPair()
+		if keyPair == nil || keyPair.PrivateKey == "" {
+			return trace.BadParameter("SAML connector %q does not include the signing key pair, the bundle was likely exported without secrets",
+				connector.GetName())
+		}
+	}
+	for _, connector := range config.GithubConnectors {
+		if connector.GetClientSecret() == "" {
+			return trace.BadParameter("Github connector %q does not include the client secret, the bundle was likely exported without secrets",
+				connector.GetName())
+		}
+	}
+	return nil
+}
+
+// licenseClusterBindingPrefix marks the metadata of a license bound to a
+// specific cluster
+const licenseClusterBindingPrefix = "bound-cluster:"
+
+// LicenseClusterBinding encodes a binding to the cluster with the provided
+// domain in the license metadata format
+func LicenseClusterBinding(clusterDomain string) string {
+	return licenseClusterBindingPrefix + clusterDomain
+}
+
+// LicenseBoundCluster returns the domain of the cluster the license with
+// the provided payload is bound to. Returns an empty string for unbound
+// licenses
+func LicenseBoundCluster(payload licenseapi.Payload) string {
+	metadata := strings.TrimSpace(payload.Metadata)
+	if !strings.HasPrefix(metadata, licenseClusterBindingPrefix) {
+		return ""
+	}
+	return strings.TrimPrefix(metadata, licenseClusterBindingPrefix)
+}
+
+// CheckLicenseClusterBinding verifies that the license with the provided
+// payload is usable on the cluster with the provided domain: unbound
+// licenses pass anywhere while bound licenses only pass on the cluster
+// they are bound to
+func CheckLicenseClusterBinding(payload licenseapi.Payload, clusterDomain string) error {
+	bound := LicenseBoundCluster(payload)
+	if bound == "" || bound == clusterDomain {
+		return nil
+	}
+	return trace.AccessDenied("license is bound to cluster %v and cannot "+
+		"be used on cluster %v", bound, clusterDomain)
+}
+
+// ValidateTrustedCluster checks the provided trusted cluster resource for
+// problems an operator would otherwise only discover once the connection is
+// attempted: the required fields, the token format and the address syntax.
+// It is invoked by UpsertTrustedCluster and can be called independently to
+// give immediate feedback on a pasted resource
+func ValidateTrustedCluster(cluster storage.TrustedCluster) error {
+	if cluster.GetName() == "" {
+		return trace.BadParameter("trusted cluster name can't be empty")
+	}
+	if err := validateTrustedClusterToken(cluster.GetToken()); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := validateTrustedClusterAddr("web_proxy_addr", cluster.GetProxyAddress()); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := validateTrustedClusterAddr("tunnel_addr", cluster.GetReverseTunnelAddress()); err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// validateTrustedClusterToken checks that the token looks like a valid
+// authorization token, catching common copy-paste mistakes
+func validateTrustedClusterToken(token string) error {
+	if token == "" {
+		return trace.BadParameter("token can't be empty")
+	}
+	if token != strings.TrimSpace(token) {
+		return trace.BadParameter("token can't have leading or trailing whitespace")
+	}
+	for _, r := range token {
+		if unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return trace.BadParameter("token can't contain whitespace or non-printable characters")
+		}
+	}
+	return nil
+}
+
+// validateTrustedClusterAddr checks that the address is syntactically
+// reachable, i.e. is in the host:port format with a valid port
+func validateTrustedClusterAddr(field, addr string) error {
+	if addr == "" {
+		return trace.BadParameter("%v can't be empty", field)
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return trace.BadParameter("%v must be in the host:port format: %v", field, err)
+	}
+	if host == "" {
+		return trace.BadParameter("%v is missing the host", field)
+	}
+	portNumber, err := strconv.Atoi(port)
+	if err != nil || portNumber < 1 || portNumber > 65535 {
+		return trace.BadParameter("%v has invalid port %q", field, port)
+	}
+	return nil
+}
+
+// ValidateRole checks the role's allow and deny rules for mistakes that
+// would otherwise only surface when the role is evaluated: empty resource
+// or verb lists, unknown verbs and unknown resource kinds. System-labeled
+// roles are rejected as they are managed by the cluster itself. It is
+// invoked by UpsertRole and can be called independently to give immediate
+// feedback on a pasted resource
+func ValidateRole(role teleservices.Role) error {
+	if role.GetMetadata().Labels[constants.SystemLabel] == constants.True {
+		return trace.AccessDenied("creating roles with %v label is prohibited",
+			constants.SystemLabel)
+	}
+	for _, condition := range []struct {
+		name  string
+		rules []teleservices.Rule
+	}{
+		{"allow", role.GetRules(teleservices.Allow)},
+		{"deny", role.GetRules(teleservices.Deny)},
+	} {
+		for i, rule := range condition.rules {
+			if err := validateRoleRule(rule); err != nil {
+				return trace.BadParameter("role %q, %v rule #%v: %v",
+					role.GetName(), condition.name, i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateRoleRule checks a single role rule for empty resource/verb lists
+// and unknown resource kinds or verbs
+func validateRoleRule(rule teleservices.Rule) error {
+	if len(rule.Resources) == 0 {
+		return trace.BadParameter("rule does not specify any resources")
+	}
+	if len(rule.Verbs) == 0 {
+		return trace.BadParameter("rule does not specify any verbs")
+	}
+	for _, resource := range rule.Resources {
+		if !knownRoleResources[resource] {
+			return trace.BadParameter("unknown resource kind %q", resource)
+		}
+	}
+	for _, verb := range rule.Verbs {
+		if !knownRoleVerbs[verb] {
+			return trace.BadParameter("unknown verb %q", verb)
+		}
+	}
+	return nil
+}
+
+// knownRoleVerbs enumerates the verbs a role rule can refer to
+var knownRoleVerbs = stringSet(
+	teleservices.Wildcard,
+	teleservices.VerbList,
+	teleservices.VerbCreate,
+	teleservices.VerbRead,
+	teleservices.VerbReadNoSecrets,
+	teleservices.VerbUpdate,
+	teleservices.VerbDelete,
+	teleservices.VerbRotate,
+	storage.VerbRegister,
+	storage.VerbRevoke,
+	storage.VerbConnect,
+	storage.VerbReadSecrets,
+)
+
+// knownRoleResources enumerates the resource kinds a role rule can refer to
+var knownRoleResources = stringSet(
+	teleservices.Wildcard,
+	storage.KindCluster,
+	storage.KindRepository,
+	storage.KindApp,
+	storage.KindObject,
+	storage.KindAccount,
+	storage.KindToken,
+	storage.KindLicense,
+	storage.KindLogForwarder,
+	storage.KindTLSKeyPair,
+	storage.KindSMTPConfig,
+	storage.KindAlert,
+	storage.KindAlertTarget,
+	storage.KindSystemInfo,
+	storage.KindEndpoints,
+	storage.KindAuthGateway,
+	storage.KindRuntimeEnvironment,
+	storage.KindClusterConfiguration,
+	storage.KindPersistentStorage,
+	storage.KindOperation,
+	storage.KindRelease,
+	storage.KindInvite,
+	teleservices.KindNamespace,
+	teleservices.KindUser,
+	teleservices.KindRole,
+	teleservices.KindOIDC,
+	teleservices.KindSAML,
+	teleservices.KindGithub,
+	teleservices.KindAuthConnector,
+	teleservices.KindSession,
+	teleservices.KindSSHSession,
+	teleservices.KindWebSession,
+	teleservices.KindEvent,
+	teleservices.KindAuthServer,
+	teleservices.KindProxy,
+	teleservices.KindNode,
+	teleservices.KindCertAuthority,
+	teleservices.KindReverseTunnel,
+	teleservices.KindClusterAuthPreference,
+	teleservices.KindClusterConfig,
+	teleservices.KindClusterName,
+	teleservices.KindStaticTokens,
+	teleservices.KindTrustedCluster,
+	teleservices.KindTunnelConnection,
+	teleservices.KindRemoteCluster,
 )
 
+// stringSet builds a lookup set from the provided values
+func stringSet(values ...string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
+
+// checkForUpdatesParallelism bounds the number of concurrent per-cluster
+// update checks so a large fleet does not overwhelm the remote Ops Center
+const checkForUpdatesParallelism = 10
+
+// CheckForUpdateResult describes the outcome of an update check for a
+// single cluster
+type CheckForUpdateResult struct {
+	// Update is the locator of the newer application version, or nil if
+	// the cluster is up-to-date
+	Update *loc.Locator
+	// Error is set if the check for this cluster failed
+	Error error
+}
+
+// CheckForUpdates checks the provided clusters for available updates
+// concurrently and returns a map of cluster domain to the check result.
+// It composes the per-cluster CheckForUpdate so, given an ACL-wrapped
+// operator, the read check is applied to every cluster individually -
+// a denied cluster shows up as an error in its result rather than
+// failing the whole sweep
+func CheckForUpdates(operator Operator, keys []ops.SiteKey) map[string]CheckForUpdateResult {
+	var mu sync.Mutex
+	results := make(map[string]CheckForUpdateResult, len(keys))
+	semaphore := make(chan struct{}, checkForUpdatesParallelism)
+	var wg sync.WaitGroup
+	for _, key := range keys {
+		key := key
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			update, err := operator.CheckForUpdate(key)
+			mu.Lock()
+			defer mu.Unlock()
+			results[key.SiteDomain] = CheckForUpdateResult{
+				Update: update,
+				Error:  err,
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// ClustersWithAvailableUpdates checks the provided clusters for updates
+// via CheckForUpdates and returns only the ones that are behind, with
+// their current and available versions. A cluster whose check failed is
+// included with the error recorded so a single unreachable cluster does
+// not fail the whole sweep
+func ClustersWithAvailableUpdates(operator Operator, accountID string, clusters []storage.Site) []ClusterUpdateInfo {
+	keys := make([]ops.SiteKey, 0, len(clusters))
+	for _, cluster := range clusters {
+		keys = append(keys, ops.SiteKey{
+			AccountID:  accountID,
+			SiteDomain: cluster.Domain,
+		})
+	}
+	results := CheckForUpdates(operator, keys)
+	var updates []ClusterUpdateInfo
+	for _, cluster := range clusters {
+		result := results[cluster.Domain]
+		info := ClusterUpdateInfo{
+			ClusterName:    cluster.Domain,
+			CurrentVersion: cluster.App.Locator(),
+		}
+		switch {
+		case trace.IsNotFound(result.Error):
+			// the check reports NotFound for an up-to-date cluster
+			continue
+		case result.Error != nil:
+			info.Error = result.Error.Error()
+		case result.Update == nil:
+			continue
+		default:
+			info.AvailableVersion = *result.Update
+		}
+		updates = append(updates, info)
+	}
+	return updates
+}
+
 // GetTrustedCluster returns a trusted cluster representing the Ops Center
 // the specified site is connected to, currently only 1 is supported
 func GetTrustedCluster(key ops.SiteKey, operator Operator) (storage.TrustedCluster, error) {
@@ -0,0 +1,539 @@
+// Copyright 2021 Gravitational Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ops
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/loc"
+	ossops "github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
+
+	licenseapi "github.com/gravitational/license"
+	teleservices "github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/trace"
+	"gopkg.in/check.v1"
+)
+
+type UtilsSuite struct{}
+
+var _ = check.Suite(&UtilsSuite{})
+
+func (s *UtilsSuite) TestAuthConfigRoundtrip(c *check.C) {
+	role, err := teleservices.NewRole("developer", teleservices.RoleSpecV3{})
+	c.Assert(err, check.IsNil)
+	systemRole := &teleservices.RoleV3{
+		Kind:    teleservices.KindRole,
+		Version: teleservices.V3,
+		Metadata: teleservices.Metadata{
+			Name:   "system",
+			Labels: map[string]string{constants.SystemLabel: constants.True},
+		},
+	}
+	c.Assert(systemRole.CheckAndSetDefaults(), check.IsNil)
+
+	source := &fakeAuthOperator{
+		roles: []teleservices.Role{role, systemRole},
+		oidcConnectors: []teleservices.OIDCConnector{
+			storage.NewOIDCConnector("google", teleservices.OIDCConnectorSpecV2{
+				IssuerURL:    "https://accounts.google.com",
+				ClientSecret: "oidc-secret",
+			}),
+		},
+		samlConnectors: []teleservices.SAMLConnector{
+			storage.NewSAMLConnector("okta", teleservices.SAMLConnectorSpecV2{
+				AssertionConsumerService: "https://localhost/acs",
+				SigningKeyPair: &teleservices.SigningKeyPair{
+					PrivateKey: "signing-key",
+					Cert:       "signing-cert",
+				},
+			}),
+		},
+		githubConnectors: []teleservices.GithubConnector{
+			storage.NewGithubConnector("github", teleservices.GithubConnectorSpecV3{
+				RedirectURL:  "https://localhost/callback",
+				ClientSecret: "github-secret",
+			}),
+		},
+	}
+	key := ossops.SiteKey{AccountID: "a", SiteDomain: "example.com"}
+	config, err := ExportAuthConfig(key, source, true)
+	c.Assert(err, check.IsNil)
+	c.Assert(config.Roles, check.HasLen, 2)
+	c.Assert(config.OIDCConnectors, check.HasLen, 1)
+	c.Assert(config.SAMLConnectors, check.HasLen, 1)
+	c.Assert(config.GithubConnectors, check.HasLen, 1)
+
+	target := &fakeAuthOperator{}
+	c.Assert(ImportAuthConfig(context.TODO(), key, target, *config), check.IsNil)
+	// the system role is not imported
+	c.Assert(target.roles, check.DeepEquals, []teleservices.Role{role})
+	c.Assert(target.oidcConnectors, check.DeepEquals, source.oidcConnectors)
+	c.Assert(target.samlConnectors, check.DeepEquals, source.samlConnectors)
+	c.Assert(target.githubConnectors, check.DeepEquals, source.githubConnectors)
+
+	// a bundle exported without secrets cannot be imported back
+	config, err = ExportAuthConfig(key, source, false)
+	c.Assert(err, check.IsNil)
+	c.Assert(config.OIDCConnectors[0].GetClientSecret(), check.Equals, "")
+
+	err = ImportAuthConfig(context.TODO(), key, &fakeAuthOperator{}, *config)
+	c.Assert(trace.IsBadParameter(err), check.Equals, true,
+		check.Commentf("unexpected error: %v", err))
+}
+
+func (s *UtilsSuite) TestValidateRole(c *check.C) {
+	role, err := teleservices.NewRole("developer", teleservices.RoleSpecV3{
+		Allow: teleservices.RoleConditions{
+			Rules: []teleservices.Rule{
+				{
+					Resources: []string{storage.KindCluster, storage.KindApp},
+					Verbs:     []string{teleservices.VerbList, teleservices.VerbRead},
+				},
+			},
+		},
+		Deny: teleservices.RoleConditions{
+			Rules: []teleservices.Rule{
+				{
+					Resources: []string{storage.KindLicense},
+					Verbs:     []string{teleservices.Wildcard},
+				},
+			},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(ValidateRole(role), check.IsNil)
+
+	badVerb, err := teleservices.NewRole("bad-verb", teleservices.RoleSpecV3{
+		Allow: teleservices.RoleConditions{
+			Rules: []teleservices.Rule{
+				{
+					Resources: []string{storage.KindCluster},
+					Verbs:     []string{teleservices.VerbRead, "observe"},
+				},
+			},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	err = ValidateRole(badVerb)
+	c.Assert(trace.IsBadParameter(err), check.Equals, true,
+		check.Commentf("unexpected error: %v", err))
+	c.Assert(err, check.ErrorMatches,
+		`(?s).*role "bad-verb", allow rule #1.*unknown verb "observe".*`)
+
+	badResource, err := teleservices.NewRole("bad-resource", teleservices.RoleSpecV3{
+		Deny: teleservices.RoleConditions{
+			Rules: []teleservices.Rule{
+				{
+					Resources: []string{"clusterz"},
+					Verbs:     []string{teleservices.VerbRead},
+				},
+			},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	err = ValidateRole(badResource)
+	c.Assert(trace.IsBadParameter(err), check.Equals, true,
+		check.Commentf("unexpected error: %v", err))
+	c.Assert(err, check.ErrorMatches,
+		`(?s).*role "bad-resource", deny rule #1.*unknown resource kind "clusterz".*`)
+
+	// a role that bypassed client-side defaulting, e.g. unmarshaled from a
+	// request body directly
+	noVerbs := &teleservices.RoleV3{
+		Kind:     teleservices.KindRole,
+		Version:  teleservices.V3,
+		Metadata: teleservices.Metadata{Name: "no-verbs"},
+		Spec: teleservices.RoleSpecV3{
+			Allow: teleservices.RoleConditions{
+				Rules: []teleservices.Rule{
+					{
+						Resources: []string{storage.KindCluster},
+					},
+				},
+			},
+		},
+	}
+	err = ValidateRole(noVerbs)
+	c.Assert(trace.IsBadParameter(err), check.Equals, true,
+		check.Commentf("unexpected error: %v", err))
+	c.Assert(err, check.ErrorMatches,
+		`(?s).*role "no-verbs", allow rule #1.*does not specify any verbs.*`)
+
+	systemRole := &teleservices.RoleV3{
+		Kind:    teleservices.KindRole,
+		Version: teleservices.V3,
+		Metadata: teleservices.Metadata{
+			Name:   "system",
+			Labels: map[string]string{constants.SystemLabel: constants.True},
+		},
+	}
+	c.Assert(systemRole.CheckAndSetDefaults(), check.IsNil)
+	err = ValidateRole(systemRole)
+	c.Assert(trace.IsAccessDenied(err), check.Equals, true,
+		check.Commentf("unexpected error: %v", err))
+}
+
+func (s *UtilsSuite) TestFilterRolesByLabel(c *check.C) {
+	roles := []teleservices.Role{
+		roleWithLabels(c, "dev", map[string]string{"env": "dev", "team": "platform"}),
+		roleWithLabels(c, "staging", map[string]string{"env": "staging"}),
+		roleWithLabels(c, "prod", map[string]string{"env": "prod", "team": "platform"}),
+		roleWithLabels(c, "unlabeled", nil),
+	}
+
+	// an equality-based selector matches a subset
+	matched, err := FilterRolesByLabel(roles, "env=prod")
+	c.Assert(err, check.IsNil)
+	c.Assert(roleNames(matched), check.DeepEquals, []string{"prod"})
+
+	// a set-based selector
+	matched, err = FilterRolesByLabel(roles, "env in (dev, staging)")
+	c.Assert(err, check.IsNil)
+	c.Assert(roleNames(matched), check.DeepEquals, []string{"dev", "staging"})
+
+	// all requirements have to match
+	matched, err = FilterRolesByLabel(roles, "env=dev,team=platform")
+	c.Assert(err, check.IsNil)
+	c.Assert(roleNames(matched), check.DeepEquals, []string{"dev"})
+
+	// an empty selector matches every role
+	matched, err = FilterRolesByLabel(roles, "")
+	c.Assert(err, check.IsNil)
+	c.Assert(matched, check.HasLen, len(roles))
+
+	// an invalid selector is rejected
+	_, err = FilterRolesByLabel(roles, "env===prod")
+	c.Assert(trace.IsBadParameter(err), check.Equals, true,
+		check.Commentf("unexpected error: %v", err))
+}
+
+func roleWithLabels(c *check.C, name string, labels map[string]string) teleservices.Role {
+	role := &teleservices.RoleV3{
+		Kind:    teleservices.KindRole,
+		Version: teleservices.V3,
+		Metadata: teleservices.Metadata{
+			Name:   name,
+			Labels: labels,
+		},
+	}
+	c.Assert(role.CheckAndSetDefaults(), check.IsNil)
+	return role
+}
+
+func roleNames(roles []teleservices.Role) (names []string) {
+	for _, role := range roles {
+		names = append(names, role.GetName())
+	}
+	return names
+}
+
+// fakeAuthOperator implements the role and auth connector subset of the
+// Operator interface for tests
+type fakeAuthOperator struct {
+	Operator
+	roles            []teleservices.Role
+	oidcConnectors   []teleservices.OIDCConnector
+	samlConnectors   []teleservices.SAMLConnector
+	githubConnectors []teleservices.GithubConnector
+}
+
+func (r *fakeAuthOperator) GetRoles(key ossops.SiteKey) ([]teleservices.Role, error) {
+	return r.roles, nil
+}
+
+func (r *fakeAuthOperator) UpsertRole(ctx context.Context, key ossops.SiteKey, role teleservices.Role) error {
+	r.roles = append(r.roles, role)
+	return nil
+}
+
+func (r *fakeAuthOperator) GetOIDCConnectors(key ossops.SiteKey, withSecrets bool) ([]teleservices.OIDCConnector, error) {
+	if withSecrets {
+		return r.oidcConnectors, nil
+	}
+	var stripped []teleservices.OIDCConnector
+	for _, connector := range r.oidcConnectors {
+		stripped = append(stripped, storage.NewOIDCConnector(
+			connector.GetName(), teleservices.OIDCConnectorSpecV2{
+				IssuerURL: connector.GetIssuerURL(),
+			}))
+	}
+	return stripped, nil
+}
+
+func (r *fakeAuthOperator) UpsertOIDCConnector(ctx context.Context, key ossops.SiteKey, connector teleservices.OIDCConnector) error {
+	r.oidcConnectors = append(r.oidcConnectors, connector)
+	return nil
+}
+
+func (r *fakeAuthOperator) GetSAMLConnectors(key ossops.SiteKey, withSecrets bool) ([]teleservices.SAMLConnector, error) {
+	if withSecrets {
+		return r.samlConnectors, nil
+	}
+	var stripped []teleservices.SAMLConnector
+	for _, connector := range r.samlConnectors {
+		stripped = append(stripped, storage.NewSAMLConnector(
+			connector.GetName(), teleservices.SAMLConnectorSpecV2{
+				AssertionConsumerService: connector.GetAssertionConsumerService(),
+			}))
+	}
+	return stripped, nil
+}
+
+func (r *fakeAuthOperator) UpsertSAMLConnector(ctx context.Context, key ossops.SiteKey, connector teleservices.SAMLConnector) error {
+	r.samlConnectors = append(r.samlConnectors, connector)
+	return nil
+}
+
+func (r *fakeAuthOperator) GetGithubConnectors(key ossops.SiteKey, withSecrets bool) ([]teleservices.GithubConnector, error) {
+	if withSecrets {
+		return r.githubConnectors, nil
+	}
+	var stripped []teleservices.GithubConnector
+	for _, connector := range r.githubConnectors {
+		stripped = append(stripped, storage.NewGithubConnector(
+			connector.GetName(), teleservices.GithubConnectorSpecV3{
+				RedirectURL: connector.GetRedirectURL(),
+			}))
+	}
+	return stripped, nil
+}
+
+func (r *fakeAuthOperator) UpsertGithubConnector(ctx context.Context, key ossops.SiteKey, connector teleservices.GithubConnector) error {
+	r.githubConnectors = append(r.githubConnectors, connector)
+	return nil
+}
+
+func (s *UtilsSuite) TestLicenseClusterBinding(c *check.C) {
+	bound := licenseapi.Payload{Metadata: LicenseClusterBinding("example.com")}
+	c.Assert(LicenseBoundCluster(bound), check.Equals, "example.com")
+
+	// a bound license passes on the cluster it is bound to
+	c.Assert(CheckLicenseClusterBinding(bound, "example.com"), check.IsNil)
+
+	// and fails anywhere else
+	err := CheckLicenseClusterBinding(bound, "other.example.com")
+	c.Assert(err, check.ErrorMatches, "(?s).*bound to cluster example.com.*")
+	c.Assert(trace.IsAccessDenied(err), check.Equals, true)
+
+	// unbound licenses keep working on any cluster
+	c.Assert(LicenseBoundCluster(licenseapi.Payload{}), check.Equals, "")
+	c.Assert(CheckLicenseClusterBinding(licenseapi.Payload{}, "example.com"), check.IsNil)
+
+	// unrelated metadata does not constitute a binding
+	notes := licenseapi.Payload{Metadata: "customer notes"}
+	c.Assert(LicenseBoundCluster(notes), check.Equals, "")
+	c.Assert(CheckLicenseClusterBinding(notes, "example.com"), check.IsNil)
+}
+
+func (s *UtilsSuite) TestValidateTrustedCluster(c *check.C) {
+	spec := func(mutate func(*storage.TrustedClusterSpecV2)) storage.TrustedClusterSpecV2 {
+		spec := storage.TrustedClusterSpecV2{
+			Token:                "s3cr3t-t0ken",
+			ProxyAddress:         "hub.example.com:32009",
+			ReverseTunnelAddress: "hub.example.com:3024",
+		}
+		if mutate != nil {
+			mutate(&spec)
+		}
+		return spec
+	}
+	testCases := []struct {
+		cluster storage.TrustedCluster
+		error   string
+		desc    string
+	}{
+		{
+			cluster: storage.NewTrustedCluster("hub.example.com", spec(nil)),
+			desc:    "Valid cluster",
+		},
+		{
+			cluster: storage.NewTrustedCluster("", spec(nil)),
+			error:   "(?s).*name can't be empty.*",
+			desc:    "Missing name",
+		},
+		{
+			cluster: storage.NewTrustedCluster("hub.example.com", spec(
+				func(spec *storage.TrustedClusterSpecV2) { spec.Token = "" })),
+			error: "(?s).*token can't be empty.*",
+			desc:  "Missing token",
+		},
+		{
+			cluster: storage.NewTrustedCluster("hub.example.com", spec(
+				func(spec *storage.TrustedClusterSpecV2) { spec.Token = " s3cr3t\n" })),
+			error: "(?s).*leading or trailing whitespace.*",
+			desc:  "Token with pasted whitespace",
+		},
+		{
+			cluster: storage.NewTrustedCluster("hub.example.com", spec(
+				func(spec *storage.TrustedClusterSpecV2) { spec.Token = "s3c r3t" })),
+			error: "(?s).*whitespace or non-printable.*",
+			desc:  "Token with embedded whitespace",
+		},
+		{
+			cluster: storage.NewTrustedCluster("hub.example.com", spec(
+				func(spec *storage.TrustedClusterSpecV2) { spec.ProxyAddress = "hub.example.com" })),
+			error: "(?s).*web_proxy_addr must be in the host:port format.*",
+			desc:  "Proxy address without port",
+		},
+		{
+			cluster: storage.NewTrustedCluster("hub.example.com", spec(
+				func(spec *storage.TrustedClusterSpecV2) { spec.ReverseTunnelAddress = "hub.example.com:http" })),
+			error: "(?s).*tunnel_addr has invalid port.*",
+			desc:  "Tunnel address with non-numeric port",
+		},
+		{
+			cluster: storage.NewTrustedCluster("hub.example.com", spec(
+				func(spec *storage.TrustedClusterSpecV2) { spec.ReverseTunnelAddress = ":3024" })),
+			error: "(?s).*tunnel_addr is missing the host.*",
+			desc:  "Tunnel address without host",
+		},
+	}
+	for _, tc := range testCases {
+		err := ValidateTrustedCluster(tc.cluster)
+		comment := check.Commentf("Test case %q failed", tc.desc)
+		if tc.error == "" {
+			c.Assert(err, check.IsNil, comment)
+			continue
+		}
+		c.Assert(err, check.ErrorMatches, tc.error, comment)
+		c.Assert(trace.IsBadParameter(err), check.Equals, true, comment)
+	}
+}
+
+func (s *UtilsSuite) TestSortTrustedClusters(c *check.C) {
+	clusters := []storage.TrustedCluster{
+		storage.NewTrustedCluster("charlie", storage.TrustedClusterSpecV2{}),
+		storage.NewTrustedCluster("alpha", storage.TrustedClusterSpecV2{}),
+		storage.NewTrustedCluster("bravo", storage.TrustedClusterSpecV2{}),
+	}
+	SortTrustedClusters(clusters)
+	var names []string
+	for _, cluster := range clusters {
+		names = append(names, cluster.GetName())
+	}
+	c.Assert(names, check.DeepEquals, []string{"alpha", "bravo", "charlie"})
+}
+
+func (s *UtilsSuite) TestCheckForUpdates(c *check.C) {
+	operator := &fakeUpdateOperator{
+		updates: map[string]*loc.Locator{
+			"cluster-1": {Repository: "example.com", Name: "app", Version: "2.0.0"},
+		},
+		denied: map[string]bool{
+			"cluster-2": true,
+		},
+	}
+	var keys []ossops.SiteKey
+	for i := 0; i < 25; i++ {
+		keys = append(keys, ossops.SiteKey{
+			AccountID:  "account",
+			SiteDomain: fmt.Sprintf("cluster-%v", i),
+		})
+	}
+
+	results := CheckForUpdates(operator, keys)
+	c.Assert(results, check.HasLen, len(keys))
+
+	// the cluster with an update reports its locator
+	c.Assert(results["cluster-1"].Error, check.IsNil)
+	c.Assert(results["cluster-1"].Update, check.DeepEquals,
+		&loc.Locator{Repository: "example.com", Name: "app", Version: "2.0.0"})
+
+	// a denied cluster carries its error without failing the sweep
+	c.Assert(trace.IsAccessDenied(results["cluster-2"].Error), check.Equals, true)
+	c.Assert(results["cluster-2"].Update, check.IsNil)
+
+	// up-to-date clusters report not found just like the real operator
+	c.Assert(results["cluster-3"].Update, check.IsNil)
+	c.Assert(trace.IsNotFound(results["cluster-3"].Error), check.Equals, true)
+
+	// the sweep never exceeds the concurrency bound
+	c.Assert(operator.maxActive <= checkForUpdatesParallelism, check.Equals, true,
+		check.Commentf("unexpected concurrency: %v", operator.maxActive))
+}
+
+// fakeUpdateOperator implements the update check subset of the Operator
+// interface for tests, tracking how many checks run concurrently
+type fakeUpdateOperator struct {
+	Operator
+	mu        sync.Mutex
+	active    int
+	maxActive int
+	updates   map[string]*loc.Locator
+	denied    map[string]bool
+}
+
+func (r *fakeUpdateOperator) CheckForUpdate(key ossops.SiteKey) (*loc.Locator, error) {
+	r.mu.Lock()
+	r.active++
+	if r.active > r.maxActive {
+		r.maxActive = r.active
+	}
+	r.mu.Unlock()
+	time.Sleep(time.Millisecond)
+	defer func() {
+		r.mu.Lock()
+		r.active--
+		r.mu.Unlock()
+	}()
+	if r.denied[key.SiteDomain] {
+		return nil, trace.AccessDenied("access denied to cluster %v", key.SiteDomain)
+	}
+	if update := r.updates[key.SiteDomain]; update != nil {
+		return update, nil
+	}
+	return nil, trace.NotFound("no update for cluster %v found", key.SiteDomain)
+}
+
+func (s *UtilsSuite) TestClustersWithAvailableUpdates(c *check.C) {
+	app := func(version string) storage.Package {
+		return storage.Package{
+			Repository: "example.com",
+			Name:       "app",
+			Version:    version,
+		}
+	}
+	operator := &fakeUpdateOperator{
+		updates: map[string]*loc.Locator{
+			"cluster-1": {Repository: "example.com", Name: "app", Version: "2.0.0"},
+		},
+		denied: map[string]bool{
+			"cluster-2": true,
+		},
+	}
+	clusters := []storage.Site{
+		{Domain: "cluster-1", App: app("1.0.0")},
+		{Domain: "cluster-2", App: app("1.0.0")},
+		{Domain: "cluster-3", App: app("2.0.0")},
+	}
+
+	updates := ClustersWithAvailableUpdates(operator, "account", clusters)
+	// the up-to-date cluster is dropped, the cluster behind reports its
+	// versions and the failed check carries its error
+	c.Assert(updates, check.HasLen, 2)
+	c.Assert(updates[0], check.DeepEquals, ClusterUpdateInfo{
+		ClusterName:      "cluster-1",
+		CurrentVersion:   loc.Locator{Repository: "example.com", Name: "app", Version: "1.0.0"},
+		AvailableVersion: loc.Locator{Repository: "example.com", Name: "app", Version: "2.0.0"},
+	})
+	c.Assert(updates[1].ClusterName, check.Equals, "cluster-2")
+	c.Assert(updates[1].Error, check.Matches, "(?s).*access denied to cluster cluster-2.*")
+	c.Assert(updates[1].AvailableVersion.IsEmpty(), check.Equals, true)
+}
@@ -31,6 +31,10 @@ type Application struct {
 	StatusCmd StatusCmd
 	// UpdateDownloadCmd manages new app versions downloads
 	UpdateDownloadCmd UpdateDownloadCmd
+	// UpdateCheckCmd compares installed and available app versions
+	UpdateCheckCmd UpdateCheckCmd
+	// UpdateListCmd lists app versions available for upgrade
+	UpdateListCmd UpdateListCmd
 	// OpsGenerateCmd generates a standalone installer via an Ops Center
 	OpsGenerateCmd OpsGenerateCmd
 	// TunnelCmd combines support tunnel related subcommands
@@ -41,6 +45,8 @@ type Application struct {
 	TunnelDisableCmd TunnelDisableCmd
 	// TunnelStatusCmd shows remote support status
 	TunnelStatusCmd TunnelStatusCmd
+	// ResourceDiffCmd shows changes against a stored resource
+	ResourceDiffCmd ResourceDiffCmd
 	// LicenseCmd combines subcommands for licenses
 	LicenseCmd LicenseCmd
 	// LicenseInstallCmd installs license into cluster
@@ -90,6 +96,22 @@ type UpdateDownloadCmd struct {
 	Every *string
 }
 
+// UpdateCheckCmd compares the installed application version with the
+// latest version available from the Ops Center
+type UpdateCheckCmd struct {
+	*kingpin.CmdClause
+	// Format is output format
+	Format *constants.Format
+}
+
+// UpdateListCmd lists the application versions available for upgrade
+// from the Ops Center
+type UpdateListCmd struct {
+	*kingpin.CmdClause
+	// Format is output format
+	Format *constants.Format
+}
+
 // OpsGenerateCmd generates an installer tarball
 type OpsGenerateCmd struct {
 	*kingpin.CmdClause
@@ -101,8 +123,14 @@ type OpsGenerateCmd struct {
 	CACert *string
 	// EncryptionKey encrypts installer packages
 	EncryptionKey *string
+	// EncryptionKeyFile is the path to a file with the encryption key,
+	// mutually exclusive with EncryptionKey
+	EncryptionKeyFile *string
 	// OpsCenterURL is the operator service URL
 	OpsCenterURL *string
+	// Validate controls upfront validation of the CA certificate and
+	// encryption key
+	Validate *bool
 }
 
 // TunnelCmd combines support tunnel related subcommands
@@ -125,6 +153,20 @@ type TunnelStatusCmd struct {
 	*kingpin.CmdClause
 }
 
+// ResourceDiffCmd shows changes between a stored resource and a local
+// resource definition file
+type ResourceDiffCmd struct {
+	*kingpin.CmdClause
+	// Kind is resource kind
+	Kind *string
+	// Name is resource name
+	Name *string
+	// Filename is path to the resource definition file
+	Filename *string
+	// User is resource owner
+	User *string
+}
+
 // LicenseCmd combines subcommands for licenses
 type LicenseCmd struct {
 	*kingpin.CmdClause
@@ -135,6 +177,8 @@ type LicenseInstallCmd struct {
 	*kingpin.CmdClause
 	// Path is license file path
 	Path *string
+	// Force bypasses the license node limit check
+	Force *bool
 }
 
 // LicenseNewCmd generates new license
@@ -15,46 +15,128 @@
 package cli
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/gravitational/gravity/e/lib/environment"
+	licenselib "github.com/gravitational/gravity/e/lib/license"
 	"github.com/gravitational/gravity/e/lib/ops/service"
+	"github.com/gravitational/gravity/lib/app"
 	"github.com/gravitational/gravity/lib/constants"
 	"github.com/gravitational/gravity/lib/httplib"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/utils"
 
 	"github.com/gravitational/license"
 	"github.com/gravitational/license/authority"
 	"github.com/gravitational/trace"
+	"github.com/mdp/rsc/qr"
 )
 
 // installLicense installs the license from the provided file on site.
+// The file may contain the license itself or its compact token form as
+// produced by "license show --output compact".
 //
 // This command is meant to be run on the deployed site.
-func installLicense(env *environment.Local, path string) error {
-	bytes, err := ioutil.ReadFile(path)
+func installLicense(env *environment.Local, path string, force bool) error {
+	data, err := ioutil.ReadFile(path)
 	if err != nil {
 		return trace.Wrap(err)
 	}
 
+	raw, err := normalizeLicense(string(data))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	parsed, err := license.ParseLicense(raw)
+	if err != nil {
+		return trace.BadParameter("failed to parse license")
+	}
+
 	client, _, err := httplib.GetClusterKubeClient(env.DNS.Addr())
 	if err != nil {
 		return trace.Wrap(err)
 	}
 
-	err = service.InstallLicenseSecret(client, string(bytes))
+	operator, err := env.ClusterOperator()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	cluster, err := operator.GetLocalSite(context.TODO())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	ca, err := operator.GetLicenseCA()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	err = verifyLicense(parsed, ca, len(cluster.ClusterState.Servers), force)
 	if err != nil {
 		return trace.Wrap(err)
 	}
 
+	err = service.InstallLicenseSecret(client, raw)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	// if the application reacts to license updates, run its hook and
+	// stream the progress so success is only reported once the app
+	// has actually picked the new license up
+	if cluster.App.Manifest.HasHook(schema.HookLicenseUpdated) {
+		apps, err := env.SiteApps()
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		env.Printf("Waiting for the %v hook to complete\n", schema.HookLicenseUpdated)
+		_, err = app.StreamAppHook(context.TODO(), apps, app.HookRunRequest{
+			Application: cluster.App.Package,
+			Hook:        schema.HookLicenseUpdated,
+			ServiceUser: cluster.ServiceUser,
+		}, utils.NopWriteCloser(os.Stdout))
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+
 	env.Printf("License has been installed\n")
 	return nil
 }
 
+// verifyLicense verifies the license against the cluster it is about to be
+// installed on: the signature, the expiration date and that the license
+// node limit covers the current cluster size. The node limit check can be
+// bypassed with force.
+func verifyLicense(parsed license.License, ca []byte, nodeCount int, force bool) error {
+	err := parsed.Verify(ca)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if force {
+		return nil
+	}
+	err = parsed.GetPayload().CheckCount(nodeCount)
+	if err != nil {
+		return trace.Wrap(err, "the cluster currently has %v nodes, use --force to install the license anyway", nodeCount)
+	}
+	return nil
+}
+
 // newLicense generates a new license with the provided settings and outputs it.
+// The license is signed by the signer built from the local CA key pair files.
 func newLicense(env *environment.Local, maxNodes int, validFor string, stopApp bool, caKey, caCert, encryptionKey, customerName, customerEmail, customerMetadata, productName, productVersion string) error {
 	duration, err := time.ParseDuration(validFor)
 	if err != nil {
@@ -76,10 +158,9 @@ func newLicense(env *environment.Local, maxNodes int, validFor string, stopApp b
 		ProductName:      productName,
 		ProductVersion:   productVersion,
 		EncryptionKey:    []byte(encryptionKey),
-		TLSKeyPair:       *tlsKeyPair,
 	}
 
-	lic, err := license.NewLicense(info)
+	lic, err := licenselib.NewLocalSigner(*tlsKeyPair).SignLicense(info)
 	if err != nil {
 		return trace.Wrap(err)
 	}
@@ -112,9 +193,104 @@ func showLicense(env *environment.Local, format constants.Format) error {
 			return trace.Wrap(err)
 		}
 		fmt.Printf("%s\n", string(bytes))
+	case constants.EncodingCompact:
+		token, err := compactLicense(site.License.Raw)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		fmt.Printf("%v\n", token)
+	case constants.EncodingQR:
+		token, err := compactLicense(site.License.Raw)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if err := renderQR(os.Stdout, token); err != nil {
+			return trace.Wrap(err)
+		}
 	default:
 		return trace.Errorf("unsupported format: %v", format)
 	}
 
 	return nil
 }
+
+// compactLicense encodes the raw license into a compact base64 token
+// suitable for transferring to an air-gapped machine, e.g. via a QR code
+func compactLicense(raw string) (string, error) {
+	var buf bytes.Buffer
+	zip := gzip.NewWriter(&buf)
+	if _, err := zip.Write([]byte(raw)); err != nil {
+		return "", trace.Wrap(err)
+	}
+	if err := zip.Close(); err != nil {
+		return "", trace.Wrap(err)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// parseCompactLicense decodes a compact license token produced by
+// compactLicense back into the raw license
+func parseCompactLicense(token string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(token))
+	if err != nil {
+		return "", trace.BadParameter("invalid license token: %v", err)
+	}
+	zip, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return "", trace.BadParameter("invalid license token: %v", err)
+	}
+	raw, err := ioutil.ReadAll(zip)
+	if err != nil {
+		return "", trace.BadParameter("invalid license token: %v", err)
+	}
+	if err := zip.Close(); err != nil {
+		return "", trace.Wrap(err)
+	}
+	return string(raw), nil
+}
+
+// normalizeLicense returns the raw license from the provided data which can
+// be either the license itself or its compact token form
+func normalizeLicense(data string) (string, error) {
+	if strings.Contains(data, "-----BEGIN") {
+		return data, nil
+	}
+	raw, err := parseCompactLicense(data)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	return raw, nil
+}
+
+// renderQR writes the provided text to w as a QR code drawn with block
+// characters so it can be scanned straight off the terminal
+func renderQR(w io.Writer, text string) error {
+	code, err := qr.Encode(text, qr.L)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	// surround the code with a quiet zone so scanners can lock onto it
+	const quietZone = 2
+	line := strings.Repeat("██", code.Size+2*quietZone)
+	for i := 0; i < quietZone; i++ {
+		fmt.Fprintln(w, line)
+	}
+	for y := 0; y < code.Size; y++ {
+		fmt.Fprint(w, strings.Repeat("██", quietZone))
+		for x := 0; x < code.Size; x++ {
+			// dark modules are left to the terminal background while
+			// light ones are drawn as full blocks, mirroring how
+			// qrencode renders ANSI output
+			if code.Black(x, y) {
+				fmt.Fprint(w, "  ")
+			} else {
+				fmt.Fprint(w, "██")
+			}
+		}
+		fmt.Fprintln(w, strings.Repeat("██", quietZone))
+	}
+	for i := 0; i < quietZone; i++ {
+		fmt.Fprintln(w, line)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+// Copyright 2021 Gravitational Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cloudflare/cfssl/csr"
+	"github.com/gravitational/license"
+	"github.com/gravitational/license/authority"
+	"github.com/gravitational/trace"
+	check "gopkg.in/check.v1"
+)
+
+type LicenseSuite struct {
+	ca authority.TLSKeyPair
+}
+
+var _ = check.Suite(&LicenseSuite{})
+
+func (s *LicenseSuite) SetUpSuite(c *check.C) {
+	ca, err := authority.GenerateSelfSignedCA(csr.CertificateRequest{
+		CN: "test",
+	})
+	c.Assert(err, check.IsNil)
+	s.ca = *ca
+}
+
+// newTestLicense generates a license signed by the test CA and round-trips
+// it through the parser the way installLicense does.
+func (s *LicenseSuite) newTestLicense(c *check.C, maxNodes int, validFor time.Duration) license.License {
+	raw, err := license.NewLicense(license.NewLicenseInfo{
+		MaxNodes:   maxNodes,
+		ValidFor:   validFor,
+		TLSKeyPair: s.ca,
+	})
+	c.Assert(err, check.IsNil)
+	parsed, err := license.ParseLicense(raw)
+	c.Assert(err, check.IsNil)
+	return parsed
+}
+
+func (s *LicenseSuite) TestCompactLicenseRoundTrip(c *check.C) {
+	raw, err := license.NewLicense(license.NewLicenseInfo{
+		MaxNodes:   3,
+		ValidFor:   time.Hour,
+		TLSKeyPair: s.ca,
+	})
+	c.Assert(err, check.IsNil)
+
+	token, err := compactLicense(raw)
+	c.Assert(err, check.IsNil)
+	// the token is a single base64 line with no PEM markup
+	c.Assert(strings.Contains(token, "-----BEGIN"), check.Equals, false)
+	c.Assert(strings.Contains(token, "\n"), check.Equals, false)
+
+	decoded, err := parseCompactLicense(token)
+	c.Assert(err, check.IsNil)
+	c.Assert(decoded, check.Equals, raw)
+
+	// the decoded license still parses
+	_, err = license.ParseLicense(decoded)
+	c.Assert(err, check.IsNil)
+
+	// surrounding whitespace from copy-paste is tolerated
+	decoded, err = parseCompactLicense("  " + token + "\n")
+	c.Assert(err, check.IsNil)
+	c.Assert(decoded, check.Equals, raw)
+
+	_, err = parseCompactLicense("not-a-license-token")
+	c.Assert(trace.IsBadParameter(err), check.Equals, true,
+		check.Commentf("unexpected error: %v", err))
+}
+
+func (s *LicenseSuite) TestNormalizeLicense(c *check.C) {
+	raw, err := license.NewLicense(license.NewLicenseInfo{
+		MaxNodes:   1,
+		ValidFor:   time.Hour,
+		TLSKeyPair: s.ca,
+	})
+	c.Assert(err, check.IsNil)
+
+	// the license itself passes through unchanged
+	normalized, err := normalizeLicense(raw)
+	c.Assert(err, check.IsNil)
+	c.Assert(normalized, check.Equals, raw)
+
+	// the compact token form is decoded
+	token, err := compactLicense(raw)
+	c.Assert(err, check.IsNil)
+	normalized, err = normalizeLicense(token)
+	c.Assert(err, check.IsNil)
+	c.Assert(normalized, check.Equals, raw)
+}
+
+func (s *LicenseSuite) TestRendersQR(c *check.C) {
+	var buf bytes.Buffer
+	c.Assert(renderQR(&buf, "test-token"), check.IsNil)
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	// the output is square: every line spans the code plus the quiet zone
+	c.Assert(len(lines) > 4, check.Equals, true)
+	for _, line := range lines {
+		c.Assert(utf8.RuneCountInString(line), check.Equals, len(lines)*2)
+	}
+}
+
+func (s *LicenseSuite) TestVerifyLicense(c *check.C) {
+	parsed := s.newTestLicense(c, 3, time.Hour)
+	err := verifyLicense(parsed, s.ca.CertPEM, 3, false)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *LicenseSuite) TestVerifyLicenseExpired(c *check.C) {
+	// an expired license can't be generated so use a payload license
+	// with the expiration date in the past
+	parsed := license.Payload{
+		Expiration: time.Now().UTC().Add(-time.Hour),
+	}
+	err := verifyLicense(parsed, s.ca.CertPEM, 1, false)
+	c.Assert(trace.IsBadParameter(err), check.Equals, true,
+		check.Commentf("unexpected error: %v", err))
+	c.Assert(err, check.ErrorMatches, ".*expired.*")
+}
+
+func (s *LicenseSuite) TestVerifyLicenseUndersized(c *check.C) {
+	parsed := s.newTestLicense(c, 1, time.Hour)
+	err := verifyLicense(parsed, s.ca.CertPEM, 3, false)
+	c.Assert(trace.IsBadParameter(err), check.Equals, true,
+		check.Commentf("unexpected error: %v", err))
+	c.Assert(err, check.ErrorMatches, "(?s).*maximum of 1 nodes.*")
+
+	// force bypasses the node limit check but not the other checks
+	err = verifyLicense(parsed, s.ca.CertPEM, 3, true)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *LicenseSuite) TestVerifyLicenseWrongCA(c *check.C) {
+	otherCA, err := authority.GenerateSelfSignedCA(csr.CertificateRequest{
+		CN: "other",
+	})
+	c.Assert(err, check.IsNil)
+
+	parsed := s.newTestLicense(c, 3, time.Hour)
+	err = verifyLicense(parsed, otherCA.CertPEM, 3, false)
+	c.Assert(err, check.NotNil)
+}
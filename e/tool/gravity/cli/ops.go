@@ -16,19 +16,42 @@ package cli
 
 import (
 	"io/ioutil"
+	"strings"
+	"time"
 
+	edefaults "github.com/gravitational/gravity/e/lib/defaults"
 	"github.com/gravitational/gravity/e/lib/environment"
 	"github.com/gravitational/gravity/lib/archive"
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/loc"
 	"github.com/gravitational/gravity/lib/ops"
 
+	"github.com/gravitational/teleport/lib/tlsca"
 	"github.com/gravitational/trace"
 )
 
 // generateInstaller generates a standalone installer in the specified
 // directory dir for the application given with appPackage
-func generateInstaller(env *environment.Local, appPackage loc.Locator, dir, caCertPath, encryptionKey, opsCenterURL string) error {
+func generateInstaller(env *environment.Local, appPackage loc.Locator, dir, caCertPath, encryptionKey, encryptionKeyFile, opsCenterURL string, validate bool) error {
+	encryptionKey, err := readEncryptionKey(encryptionKey, encryptionKeyFile)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	var caCert []byte
+	if caCertPath != "" {
+		caCert, err = ioutil.ReadFile(caCertPath)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+
+	if validate {
+		if err := validateInstallerParams(caCert, encryptionKey); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+
 	operator, err := env.OperatorService(opsCenterURL)
 	if err != nil {
 		return trace.Wrap(err)
@@ -51,14 +74,6 @@ func generateInstaller(env *environment.Local, appPackage loc.Locator, dir, caCe
 		return trace.NotFound("no system account found")
 	}
 
-	var caCert []byte
-	if caCertPath != "" {
-		caCert, err = ioutil.ReadFile(caCertPath)
-		if err != nil {
-			return trace.Wrap(err)
-		}
-	}
-
 	tarball, err := operator.GetAppInstaller(ops.AppInstallerRequest{
 		AccountID:     accountID,
 		Application:   appPackage,
@@ -77,3 +92,39 @@ func generateInstaller(env *environment.Local, appPackage loc.Locator, dir, caCe
 
 	return nil
 }
+
+// readEncryptionKey returns the encryption key provided either inline or
+// via a file. The two sources are mutually exclusive - the file keeps the
+// key out of shell history and process listings
+func readEncryptionKey(encryptionKey, encryptionKeyFile string) (string, error) {
+	if encryptionKey != "" && encryptionKeyFile != "" {
+		return "", trace.BadParameter(
+			"--encryption-key and --encryption-key-file are mutually exclusive")
+	}
+	if encryptionKeyFile == "" {
+		return encryptionKey, nil
+	}
+	bytes, err := ioutil.ReadFile(encryptionKeyFile)
+	if err != nil {
+		return "", trace.ConvertSystemError(err)
+	}
+	return strings.TrimSpace(string(bytes)), nil
+}
+
+// validateInstallerParams verifies the CA certificate and the encryption key
+// provided to the installer generator before any packaging work begins
+func validateInstallerParams(caCert []byte, encryptionKey string) error {
+	if len(caCert) != 0 {
+		cert, err := tlsca.ParseCertificatePEM(caCert)
+		if err != nil {
+			return trace.BadParameter("certificate provided with --ca-cert is not a valid PEM certificate: %v", err)
+		}
+		if time.Now().After(cert.NotAfter) {
+			return trace.BadParameter("certificate provided with --ca-cert expired on %v", cert.NotAfter)
+		}
+	}
+	if encryptionKey != "" && len(encryptionKey) < edefaults.MinEncryptionKeyLength {
+		return trace.BadParameter("key provided with --encryption-key must be at least %v characters long", edefaults.MinEncryptionKeyLength)
+	}
+	return nil
+}
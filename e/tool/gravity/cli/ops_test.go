@@ -0,0 +1,56 @@
+// Copyright 2021 Gravitational Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/gravitational/trace"
+	check "gopkg.in/check.v1"
+)
+
+type OpsSuite struct{}
+
+var _ = check.Suite(&OpsSuite{})
+
+func (s *OpsSuite) TestReadEncryptionKeyInline(c *check.C) {
+	key, err := readEncryptionKey("secret-encryption-key", "")
+	c.Assert(err, check.IsNil)
+	c.Assert(key, check.Equals, "secret-encryption-key")
+}
+
+func (s *OpsSuite) TestReadEncryptionKeyFromFile(c *check.C) {
+	path := filepath.Join(c.MkDir(), "key")
+	// a trailing newline is common when the file is created by hand
+	err := ioutil.WriteFile(path, []byte("secret-encryption-key\n"), 0600)
+	c.Assert(err, check.IsNil)
+
+	key, err := readEncryptionKey("", path)
+	c.Assert(err, check.IsNil)
+	c.Assert(key, check.Equals, "secret-encryption-key")
+}
+
+func (s *OpsSuite) TestReadEncryptionKeyMutuallyExclusive(c *check.C) {
+	_, err := readEncryptionKey("secret-encryption-key", "/path/to/key")
+	c.Assert(trace.IsBadParameter(err), check.Equals, true,
+		check.Commentf("unexpected error: %v", err))
+}
+
+func (s *OpsSuite) TestReadEncryptionKeyMissingFile(c *check.C) {
+	_, err := readEncryptionKey("", filepath.Join(c.MkDir(), "no-such-file"))
+	c.Assert(trace.IsNotFound(err), check.Equals, true,
+		check.Commentf("unexpected error: %v", err))
+}
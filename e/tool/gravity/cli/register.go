@@ -15,7 +15,11 @@
 package cli
 
 import (
+	"fmt"
+
 	"github.com/gravitational/gravity/e/lib/defaults"
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/modules"
 	"github.com/gravitational/gravity/tool/common"
 	"github.com/gravitational/gravity/tool/gravity/cli"
 
@@ -48,22 +52,39 @@ func RegisterCommands(app *kingpin.Application) *Application {
 	g.UpdateDownloadCmd.CmdClause = g.UpdateCmd.Command("download", "Check for and download newer version of the cluster and application images.").Hidden()
 	g.UpdateDownloadCmd.Every = g.UpdateDownloadCmd.Flag("every", "Enable automatic downloading of new versions at the specified interval.").String()
 
+	g.UpdateCheckCmd.CmdClause = g.UpdateCmd.Command("check", "Compare the installed application version with the latest version available from the Ops Center.").Hidden()
+	g.UpdateCheckCmd.Format = common.Format(g.UpdateCheckCmd.Flag("format", "Output format: text or json.").Default(string(constants.EncodingText)))
+
+	g.UpdateListCmd.CmdClause = g.UpdateCmd.Command("list", "List the application versions available for upgrade from the Ops Center.").Hidden()
+	g.UpdateListCmd.Format = common.Format(g.UpdateListCmd.Flag("format", "Output format: text or json.").Default(string(constants.EncodingText)))
+
 	g.OpsGenerateCmd.CmdClause = g.OpsCmd.Command("create-wizard", "Generate a standalone installer for an application").Hidden()
 	g.OpsGenerateCmd.Package = cli.Locator(g.OpsGenerateCmd.Arg("package", "The application locator").Required())
 	g.OpsGenerateCmd.Dir = g.OpsGenerateCmd.Arg("dir", "Directory where installer files will be written to").Required().String()
 	g.OpsGenerateCmd.CACert = g.OpsGenerateCmd.Flag("ca-cert", "Path to CA certificate file; if not provided, the Gravity Hub's CA will be used").String()
 	g.OpsGenerateCmd.EncryptionKey = g.OpsGenerateCmd.Flag("encryption-key", "Optional key to encrypt installer packages with").String()
+	g.OpsGenerateCmd.EncryptionKeyFile = g.OpsGenerateCmd.Flag("encryption-key-file", "Path to a file with the encryption key, mutually exclusive with --encryption-key").String()
 	g.OpsGenerateCmd.OpsCenterURL = g.OpsGenerateCmd.Flag("ops-url", "URL of the Gravity Hub to use for installer generation").String()
+	g.OpsGenerateCmd.Validate = g.OpsGenerateCmd.Flag("validate", "Validate the CA certificate and encryption key before generating the installer").Default("true").Bool()
 
 	g.TunnelCmd.CmdClause = g.Command("tunnel", "Configure remote access to Gravity Hub.")
 	g.TunnelEnableCmd.CmdClause = g.TunnelCmd.Command("enable", "Enable remote access to the Gravity Hub.")
 	g.TunnelDisableCmd.CmdClause = g.TunnelCmd.Command("disable", "Disable remote access to the Gravity Hub.")
 	g.TunnelStatusCmd.CmdClause = g.TunnelCmd.Command("status", "Check status of the connection to the Gravity Hub.")
 
+	g.ResourceDiffCmd.CmdClause = g.ResourceCmd.Command("diff", fmt.Sprintf("Show changes between a stored configuration resource and a resource definition file, e.g. gravity resource diff oidc google -f oidc.yaml. Supported resources are: %v.",
+		modules.GetResources().SupportedResources()))
+	g.ResourceDiffCmd.Kind = g.ResourceDiffCmd.Arg("kind", fmt.Sprintf("Resource kind. One of: %v.",
+		modules.GetResources().SupportedResources())).Required().String()
+	g.ResourceDiffCmd.Name = g.ResourceDiffCmd.Arg("name", "Optional resource name. Compares against all resources of the kind if omitted.").String()
+	g.ResourceDiffCmd.Filename = g.ResourceDiffCmd.Flag("filename", "Resource definition file to compare against.").Short('f').Required().String()
+	g.ResourceDiffCmd.User = g.ResourceDiffCmd.Flag("user", "User to compare resources for. Defaults to the currently logged in user.").String()
+
 	g.LicenseCmd.CmdClause = g.Command("license", "Operations with cluster licenses").Hidden()
 
 	g.LicenseInstallCmd.CmdClause = g.LicenseCmd.Command("install", "Install (or update) a cluster license").Hidden()
 	g.LicenseInstallCmd.Path = g.LicenseInstallCmd.Flag("from-file", "Path to the license file").Required().String()
+	g.LicenseInstallCmd.Force = g.LicenseInstallCmd.Flag("force", "Install the license even if the cluster exceeds its node limit").Bool()
 
 	g.LicenseNewCmd.CmdClause = g.LicenseCmd.Command("new", "Generate a new license").Hidden()
 	g.LicenseNewCmd.MaxNodes = g.LicenseNewCmd.Flag("max-nodes", "Maximum amount of nodes").Required().Int()
@@ -79,7 +100,7 @@ func RegisterCommands(app *kingpin.Application) *Application {
 	g.LicenseNewCmd.ProductVersion = g.LicenseNewCmd.Flag("product-version", "Version of the product to generate license for").String()
 
 	g.LicenseShowCmd.CmdClause = g.LicenseCmd.Command("show", "Show the cluster license").Hidden()
-	g.LicenseShowCmd.Output = common.Format(g.LicenseShowCmd.Flag("output", "Output format: pem or json").Default(string(defaults.LicenseOutputFormat)))
+	g.LicenseShowCmd.Output = common.Format(g.LicenseShowCmd.Flag("output", "Output format: pem, json, compact or qr. The compact format is a base64 token, qr renders it as a scannable QR code").Default(string(defaults.LicenseOutputFormat)))
 
 	return g
 }
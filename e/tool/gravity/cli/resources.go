@@ -15,7 +15,10 @@
 package cli
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"io/ioutil"
 	"os"
 
 	"github.com/gravitational/gravity/e/lib/environment"
@@ -23,14 +26,18 @@ import (
 	"github.com/gravitational/gravity/lib/constants"
 	"github.com/gravitational/gravity/lib/ops/resources"
 	ossgravity "github.com/gravitational/gravity/lib/ops/resources/gravity"
+	"github.com/gravitational/gravity/lib/storage"
 	"github.com/gravitational/gravity/tool/common"
 	"github.com/gravitational/gravity/tool/gravity/cli"
 
 	"github.com/gravitational/trace"
+	"github.com/pmezard/go-difflib/difflib"
 )
 
-// createResource updates or inserts one or many resources
-func createResource(env *environment.Local, factory cli.LocalEnvironmentFactory, filename string, upsert bool, user string, manual, confirmed bool) error {
+// createResource updates or inserts one or many resources.
+// continueOnError makes a failure to create one document of a multi-document
+// bundle not abort the remaining documents
+func createResource(env *environment.Local, factory cli.LocalEnvironmentFactory, filename string, upsert bool, user string, manual, confirmed, continueOnError bool) error {
 	operator, err := env.ClusterOperator()
 	if err != nil {
 		return trace.Wrap(err)
@@ -61,18 +68,38 @@ func createResource(env *environment.Local, factory cli.LocalEnvironmentFactory,
 		return trace.Wrap(err)
 	}
 	defer reader.Close()
-	req := resources.CreateRequest{
-		SiteKey:   cluster.Key(),
-		Upsert:    upsert,
-		Owner:     user,
-		Manual:    manual,
-		Confirmed: confirmed,
-	}
-	err = resources.NewControl(gravityResources).Create(context.TODO(), reader, req)
+	control := resources.NewControl(gravityResources)
+	var succeeded, failed int
+	var errors []error
+	err = resources.ForEach(reader, func(resource storage.UnknownResource) error {
+		req := resources.CreateRequest{
+			SiteKey:   cluster.Key(),
+			Upsert:    upsert,
+			Owner:     user,
+			Manual:    manual,
+			Confirmed: confirmed,
+		}
+		err := control.Create(context.TODO(), bytes.NewReader(resource.Raw), req)
+		if err == nil {
+			succeeded++
+			return nil
+		}
+		if !continueOnError {
+			return trace.Wrap(err)
+		}
+		failed++
+		env.Printf("Failed to create %v/%v: %v\n", resource.Kind,
+			resource.Metadata.Name, trace.UserMessage(err))
+		errors = append(errors, err)
+		return nil
+	})
 	if err != nil {
 		return trace.Wrap(err)
 	}
-	return trace.Wrap(err)
+	if continueOnError {
+		env.Printf("Created %v resources, %v failed\n", succeeded, failed)
+	}
+	return trace.NewAggregate(errors...)
 }
 
 // removeResource deletes resource by name
@@ -118,6 +145,69 @@ func removeResource(env *environment.Local, factory cli.LocalEnvironmentFactory,
 	return nil
 }
 
+// diffResource displays a unified diff between the stored resource and the
+// resource definition from the provided file
+func diffResource(env *environment.Local, filename, kind, name, user string) error {
+	operator, err := env.ClusterOperator()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	cluster, err := env.LocalCluster()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	ossResources, err := ossgravity.New(ossgravity.Config{
+		Operator:    operator.Client,
+		CurrentUser: env.CurrentUser(),
+		Silent:      env.Silent,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	gravityResources, err := gravity.New(gravity.Config{
+		Resources: ossResources,
+		Operator:  operator,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	var stored bytes.Buffer
+	err = resources.NewControl(gravityResources).Get(&stored, resources.ListRequest{
+		SiteKey: cluster.Key(),
+		Kind:    kind,
+		Name:    name,
+		User:    user,
+	}, constants.EncodingYAML)
+	if err != nil && !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+	reader, err := common.GetReader(filename)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer reader.Close()
+	supplied, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(stored.String()),
+		B:        difflib.SplitLines(string(supplied)),
+		FromFile: "stored",
+		ToFile:   filename,
+		Context:  3,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if diff == "" {
+		env.Println("No changes.")
+		return nil
+	}
+	fmt.Print(diff)
+	return nil
+}
+
 func getResources(env *environment.Local, kind string, name string, withSecrets bool, format constants.Format, user string) error {
 	operator, err := env.ClusterOperator()
 	if err != nil {
@@ -92,6 +92,8 @@ func execute(g *Application, cmd string, extraArgs []string) (err error) {
 	case g.WizardCmd.FullCommand(),
 		g.StatusCmd.FullCommand(),
 		g.UpdateDownloadCmd.FullCommand(),
+		g.UpdateCheckCmd.FullCommand(),
+		g.UpdateListCmd.FullCommand(),
 		g.OpsGenerateCmd.FullCommand(),
 		g.TunnelEnableCmd.FullCommand(),
 		g.TunnelDisableCmd.FullCommand(),
@@ -99,6 +101,7 @@ func execute(g *Application, cmd string, extraArgs []string) (err error) {
 		g.ResourceCreateCmd.FullCommand(),
 		g.ResourceRemoveCmd.FullCommand(),
 		g.ResourceGetCmd.FullCommand(),
+		g.ResourceDiffCmd.FullCommand(),
 		g.LicenseInstallCmd.FullCommand(),
 		g.LicenseNewCmd.FullCommand(),
 		g.LicenseShowCmd.FullCommand(),
@@ -130,13 +133,19 @@ func execute(g *Application, cmd string, extraArgs []string) (err error) {
 		}
 	case g.UpdateDownloadCmd.FullCommand():
 		return updateDownload(localEnv, *g.UpdateDownloadCmd.Every)
+	case g.UpdateCheckCmd.FullCommand():
+		return updateCheck(localEnv, *g.UpdateCheckCmd.Format)
+	case g.UpdateListCmd.FullCommand():
+		return updateList(localEnv, *g.UpdateListCmd.Format)
 	case g.OpsGenerateCmd.FullCommand():
 		return generateInstaller(localEnv,
 			*g.OpsGenerateCmd.Package,
 			*g.OpsGenerateCmd.Dir,
 			*g.OpsGenerateCmd.CACert,
 			*g.OpsGenerateCmd.EncryptionKey,
-			*g.OpsGenerateCmd.OpsCenterURL)
+			*g.OpsGenerateCmd.EncryptionKeyFile,
+			*g.OpsGenerateCmd.OpsCenterURL,
+			*g.OpsGenerateCmd.Validate)
 	case g.TunnelEnableCmd.FullCommand():
 		return updateRemoteAccess(localEnv, true)
 	case g.TunnelDisableCmd.FullCommand():
@@ -149,7 +158,8 @@ func execute(g *Application, cmd string, extraArgs []string) (err error) {
 			*g.ResourceCreateCmd.Upsert,
 			*g.ResourceCreateCmd.User,
 			*g.ResourceCreateCmd.Manual,
-			*g.ResourceCreateCmd.Confirmed)
+			*g.ResourceCreateCmd.Confirmed,
+			*g.ResourceCreateCmd.ContinueOnError)
 	case g.ResourceRemoveCmd.FullCommand():
 		return removeResource(localEnv, g.Application,
 			*g.ResourceRemoveCmd.Kind,
@@ -165,9 +175,16 @@ func execute(g *Application, cmd string, extraArgs []string) (err error) {
 			*g.ResourceGetCmd.WithSecrets,
 			*g.ResourceGetCmd.Format,
 			*g.ResourceGetCmd.User)
+	case g.ResourceDiffCmd.FullCommand():
+		return diffResource(localEnv,
+			*g.ResourceDiffCmd.Filename,
+			*g.ResourceDiffCmd.Kind,
+			*g.ResourceDiffCmd.Name,
+			*g.ResourceDiffCmd.User)
 	case g.LicenseInstallCmd.FullCommand():
 		return installLicense(localEnv,
-			*g.LicenseInstallCmd.Path)
+			*g.LicenseInstallCmd.Path,
+			*g.LicenseInstallCmd.Force)
 	case g.LicenseNewCmd.FullCommand():
 		return newLicense(localEnv,
 			*g.LicenseNewCmd.MaxNodes,
@@ -16,13 +16,21 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
 	"time"
 
+	edefaults "github.com/gravitational/gravity/e/lib/defaults"
 	"github.com/gravitational/gravity/e/lib/environment"
 	"github.com/gravitational/gravity/e/lib/ops"
 	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/loc"
 	ossops "github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/utils"
 
+	"github.com/dustin/go-humanize"
 	"github.com/gravitational/trace"
 )
 
@@ -59,6 +67,126 @@ func updateDownload(env *environment.Local, every string) error {
 	return nil
 }
 
+// updateStatus describes the installed application version relative to the
+// latest version available from the Ops Center
+type updateStatus struct {
+	// Installed is the currently installed application
+	Installed loc.Locator `json:"installed"`
+	// Latest is the latest application version available from the Ops Center
+	Latest loc.Locator `json:"latest"`
+	// UpdateAvailable is true if a newer version is available
+	UpdateAvailable bool `json:"update_available"`
+}
+
+// updateChecker is the subset of the cluster operator used to check for
+// newer application versions
+type updateChecker interface {
+	// GetLocalSite returns the record of the local cluster
+	GetLocalSite(context.Context) (*ossops.Site, error)
+	// CheckForUpdate checks if a newer application version is available
+	CheckForUpdate(ossops.SiteKey) (*loc.Locator, error)
+}
+
+// getUpdateStatus returns the installed and the latest available
+// application versions
+func getUpdateStatus(ctx context.Context, operator updateChecker) (*updateStatus, error) {
+	cluster, err := operator.GetLocalSite(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	status := updateStatus{
+		Installed: cluster.App.Package,
+		Latest:    cluster.App.Package,
+	}
+	update, err := operator.CheckForUpdate(cluster.Key())
+	if err != nil && !trace.IsNotFound(err) {
+		return nil, trace.Wrap(err)
+	}
+	if update != nil {
+		status.Latest = *update
+		status.UpdateAvailable = true
+	}
+	return &status, nil
+}
+
+// updateCheck compares the installed application version against the latest
+// version available from the Ops Center. If an update is available, the
+// command exits with a dedicated exit code so it can be used in cron jobs
+// and monitoring scripts
+func updateCheck(env *environment.Local, format constants.Format) error {
+	operator, err := env.ClusterOperator()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	status, err := getUpdateStatus(context.TODO(), operator)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	switch format {
+	case constants.EncodingJSON:
+		bytes, err := json.Marshal(status)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		fmt.Println(string(bytes))
+	default:
+		env.Printf("Installed:\t%v\n", status.Installed)
+		env.Printf("Latest:\t\t%v\n", status.Latest)
+		if status.UpdateAvailable {
+			env.Println("A newer version is available")
+		} else {
+			env.Println("The cluster is up to date")
+		}
+	}
+	if status.UpdateAvailable {
+		return utils.NewExitCodeError(edefaults.UpdateAvailableExitCode)
+	}
+	return nil
+}
+
+// updateList displays all application versions newer than the installed
+// one available from the Ops Center so an operator can pick a specific
+// version to download instead of always taking the newest
+func updateList(env *environment.Local, format constants.Format) error {
+	operator, err := env.ClusterOperator()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	cluster, err := operator.GetLocalSite(context.TODO())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	response, err := operator.ListAvailableUpdates(cluster.Key())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	switch format {
+	case constants.EncodingJSON:
+		bytes, err := json.Marshal(response)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		fmt.Println(string(bytes))
+	default:
+		env.Printf("Installed:\t%v\n", response.InstalledVersion)
+		if len(response.Updates) == 0 {
+			env.Println("The cluster is up to date")
+			return nil
+		}
+		w := new(tabwriter.Writer)
+		w.Init(os.Stdout, 0, 8, 1, '\t', 0)
+		fmt.Fprintf(w, "Version\tSize\tReleased\n")
+		for _, update := range response.Updates {
+			fmt.Fprintf(w, "%v\t%v\t%v\n",
+				update.Application.Version,
+				humanize.Bytes(uint64(update.SizeBytes)),
+				update.Created.Format(constants.HumanDateFormat))
+		}
+		w.Flush()
+	}
+	return nil
+}
+
 func setPeriodicUpdates(env *environment.Local, operator ops.Operator, cluster ossops.Site, every string) error {
 	if every == constants.PeriodicUpdatesOff {
 		err := operator.DisablePeriodicUpdates(context.TODO(), cluster.Key())
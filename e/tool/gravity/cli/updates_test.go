@@ -0,0 +1,82 @@
+// Copyright 2021 Gravitational Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gravitational/gravity/lib/loc"
+	ossops "github.com/gravitational/gravity/lib/ops"
+
+	"github.com/gravitational/trace"
+	check "gopkg.in/check.v1"
+)
+
+func TestUpdates(t *testing.T) { check.TestingT(t) }
+
+type UpdatesSuite struct{}
+
+var _ = check.Suite(&UpdatesSuite{})
+
+func (s *UpdatesSuite) TestUpdateStatusUpToDate(c *check.C) {
+	installed := loc.MustParseLocator("gravitational.io/app:1.0.0")
+	status, err := getUpdateStatus(context.TODO(), &fakeUpdateOperator{
+		installed: installed,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(status, check.DeepEquals, &updateStatus{
+		Installed: installed,
+		Latest:    installed,
+	})
+}
+
+func (s *UpdatesSuite) TestUpdateStatusBehind(c *check.C) {
+	installed := loc.MustParseLocator("gravitational.io/app:1.0.0")
+	latest := loc.MustParseLocator("gravitational.io/app:2.0.0")
+	status, err := getUpdateStatus(context.TODO(), &fakeUpdateOperator{
+		installed: installed,
+		update:    &latest,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(status, check.DeepEquals, &updateStatus{
+		Installed:       installed,
+		Latest:          latest,
+		UpdateAvailable: true,
+	})
+}
+
+// fakeUpdateOperator implements updateChecker for tests
+type fakeUpdateOperator struct {
+	installed loc.Locator
+	update    *loc.Locator
+}
+
+func (r *fakeUpdateOperator) GetLocalSite(context.Context) (*ossops.Site, error) {
+	return &ossops.Site{
+		AccountID: "account",
+		Domain:    "example.com",
+		App: ossops.Application{
+			Package: r.installed,
+		},
+	}, nil
+}
+
+func (r *fakeUpdateOperator) CheckForUpdate(ossops.SiteKey) (*loc.Locator, error) {
+	if r.update == nil {
+		return nil, trace.NotFound("no newer version available")
+	}
+	return r.update, nil
+}
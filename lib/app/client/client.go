@@ -389,8 +389,11 @@ func (c *Client) DeleteAppHookJob(ctx context.Context, req app.DeleteAppHookJobR
 //
 // GET charts/:name
 func (c *Client) FetchChart(locator loc.Locator) (io.ReadCloser, error) {
-	return c.getFile(c.Endpoint("charts", helmutils.ToChartFilename(
-		locator.Name, locator.Version)), url.Values{})
+	filename, err := helmutils.ToChartFilename(locator.Name, locator.Version)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return c.getFile(c.Endpoint("charts", filename), url.Values{})
 }
 
 // FetchIndexFile returns Helm chart repository index file data.
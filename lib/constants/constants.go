@@ -739,6 +739,8 @@ const (
 var (
 	// EncodingJSON is for the JSON encoding format
 	EncodingJSON Format = "json"
+	// EncodingJSONL is for the newline-delimited JSON encoding format
+	EncodingJSONL Format = "jsonl"
 	// EncodingPEM is for the PEM encoding format
 	EncodingPEM Format = "pem"
 	// EncodingText is for the plaint-text encoding format
@@ -747,11 +749,16 @@ var (
 	EncodingShort Format = "short"
 	// EncodingYAML is for the YAML encoding format
 	EncodingYAML Format = "yaml"
+	// EncodingQR renders the output as a QR code in the terminal
+	EncodingQR Format = "qr"
+	// EncodingCompact is for the compact base64 token format
+	EncodingCompact Format = "compact"
 	// OutputFormats is a list of recognized output formats for gravity CLI commands
 	OutputFormats = []Format{
 		EncodingText,
 		EncodingShort,
 		EncodingJSON,
+		EncodingJSONL,
 		EncodingYAML,
 	}
 )
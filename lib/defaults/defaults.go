@@ -127,6 +127,10 @@ const (
 	// generated during installs
 	ProvisioningTokenBytes = 32
 
+	// JoinTokenGraceWindow is the period the previous join token remains
+	// valid after rotation so in-flight joins are not locked out
+	JoinTokenGraceWindow = 10 * time.Minute
+
 	// InstallTokenBytes is the length of the token generated for a one-time installation
 	InstallTokenBytes = 16
 
@@ -336,6 +340,10 @@ const (
 	// is symlinked to, inside the planet
 	KubectlScript = "/usr/local/bin/kubectl"
 
+	// ChartIndexStaleAfter is the age at which a served chart repository
+	// index is considered stale relative to its generation timestamp
+	ChartIndexStaleAfter = time.Hour
+
 	// HelmBin is the location of helm binary inside planet
 	HelmBin = "/usr/bin/helm"
 
@@ -23,6 +23,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/loc"
@@ -134,6 +135,11 @@ func (r *clusterRepository) GetIndexFile() (io.Reader, error) {
 	}
 	if trace.IsNotFound(err) {
 		indexFile = repo.NewIndexFile()
+	} else if helmutils.IsIndexStale(*indexFile, defaults.ChartIndexStaleAfter) {
+		// surface staleness so operators know the catalog may be behind
+		// the published charts and can rebuild the index on demand
+		r.Warnf("Serving a stale chart repository index generated %v.",
+			indexFile.Generated)
 	}
 	data, err := yaml.Marshal(indexFile)
 	if err != nil {
@@ -180,6 +186,9 @@ func (r *clusterRepository) AddToIndex(locator loc.Locator, upsert bool) error {
 	prevIndexFile := helmutils.CopyIndexFile(*indexFile)
 	indexFile.Add(chart.Metadata, r.chartURL(chart), "", digest)
 	indexFile.SortEntries()
+	// move the generation timestamp forward so clients caching the index
+	// can see it has been updated
+	indexFile.Generated = time.Now()
 	return r.Backend.CompareAndSwapIndexFile(indexFile, prevIndexFile)
 }
 
@@ -211,6 +220,7 @@ L:
 			break L
 		}
 	}
+	indexFile.Generated = time.Now()
 	return r.Backend.CompareAndSwapIndexFile(indexFile, prevIndexFile)
 }
 
@@ -269,8 +279,9 @@ func (r *clusterRepository) chartForLocator(locator loc.Locator) (*chart.Chart,
 
 // chartURL returns URL of the specified chart in the repository.
 func (r *clusterRepository) chartURL(chart *chart.Chart) string {
+	// chart metadata has been validated when the chart was loaded
 	return fmt.Sprintf("%v/charts/%v", r.Packages.PortalURL(),
-		helmutils.ToChartFilename(chart.Metadata.Name, chart.Metadata.Version))
+		helmutils.MustToChartFilename(chart.Metadata.Name, chart.Metadata.Version))
 }
 
 // digest returns a sha256 hash of the specified application data.
@@ -24,6 +24,7 @@ import (
 
 	"github.com/gravitational/gravity/lib/constants"
 	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/version"
 
 	"github.com/coreos/go-semver/semver"
 	"github.com/gravitational/configure/cstrings"
@@ -139,15 +140,15 @@ func (l *Locator) IsNewerThan(other Locator) (bool, error) {
 	if l.Repository != other.Repository || l.Name != other.Name {
 		return false, trace.BadParameter("repository/name mismatch: %v %v", l, other)
 	}
-	ourVer, err := l.SemVer()
+	ourVer, err := version.Parse(l.Version)
 	if err != nil {
 		return false, trace.Wrap(err)
 	}
-	otherVer, err := other.SemVer()
+	otherVer, err := version.Parse(other.Version)
 	if err != nil {
 		return false, trace.Wrap(err)
 	}
-	return otherVer.LessThan(*ourVer), nil
+	return version.IsNewer(*ourVer, *otherVer), nil
 }
 
 func (l *Locator) Set(v string) error {
@@ -61,6 +61,14 @@ const (
 	InstallTerminated             = "terminated"
 	ServiceAccountTokenSecretType = "kubernetes.io/service-account-token"
 
+	// ProgressCodePreflightFailed categorizes an operation that failed
+	// preflight checks
+	ProgressCodePreflightFailed = "PreflightFailed"
+	// ProgressCodeTimeout categorizes an operation that failed due to a timeout
+	ProgressCodeTimeout = "Timeout"
+	// ProgressCodeUserCancelled categorizes an operation cancelled by the user
+	ProgressCodeUserCancelled = "UserCancelled"
+
 	// SiteStateNotInstalled is a state where a site has just been created or uninstalled and
 	// no active operation for it is in progress
 	SiteStateNotInstalled = "not_installed"
@@ -0,0 +1,76 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ops
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gravitational/trace"
+)
+
+// ExportSchemaVersion is the version of the exported operation document
+// schema. Bumped whenever the document layout changes in an incompatible
+// way so consumers can detect documents they do not understand
+const ExportSchemaVersion = 1
+
+// ExportedOperation is a self-contained JSON document describing an
+// operation and its progress, suitable for attaching to a support bundle
+type ExportedOperation struct {
+	// SchemaVersion is the version of this document's schema,
+	// see ExportSchemaVersion
+	SchemaVersion int `json:"schema_version"`
+	// Operation is the operation this document describes
+	Operation SiteOperation `json:"operation"`
+	// Progress is the operation's last progress entry, if available
+	Progress *ProgressEntry `json:"progress,omitempty"`
+	// Duration is the time the operation took, derived the same way
+	// as for the operation timeline
+	Duration time.Duration `json:"duration"`
+	// Outcome describes how the operation ended, one of the
+	// OperationOutcome* values
+	Outcome string `json:"outcome"`
+}
+
+// ExportOperation serializes the provided operation and its progress entry
+// into a stable JSON document suitable for attaching to a support bundle.
+// The progress entry is optional
+func ExportOperation(op *SiteOperation, progress *ProgressEntry) ([]byte, error) {
+	if op == nil {
+		return nil, trace.BadParameter("missing operation")
+	}
+	doc := exportOperation(*op, progress, time.Now().UTC())
+	bytes, err := json.MarshalIndent(doc, "", "\t")
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return bytes, nil
+}
+
+func exportOperation(op SiteOperation, progress *ProgressEntry, now time.Time) ExportedOperation {
+	end := op.Updated
+	if !op.IsFinished() {
+		end = now
+	}
+	return ExportedOperation{
+		SchemaVersion: ExportSchemaVersion,
+		Operation:     op,
+		Progress:      progress,
+		Duration:      end.Sub(op.Created),
+		Outcome:       operationOutcome(op),
+	}
+}
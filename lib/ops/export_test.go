@@ -0,0 +1,77 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ops
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gravitational/trace"
+	check "gopkg.in/check.v1"
+)
+
+type ExportSuite struct{}
+
+var _ = check.Suite(&ExportSuite{})
+
+func (s *ExportSuite) TestExportOperation(c *check.C) {
+	base := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	operation := &SiteOperation{
+		ID:      "op1",
+		Type:    OperationUpdate,
+		Created: base,
+		Updated: base.Add(time.Hour),
+		State:   OperationStateCompleted,
+	}
+	progress := &ProgressEntry{
+		OperationID: "op1",
+		Completion:  100,
+	}
+	bytes, err := ExportOperation(operation, progress)
+	c.Assert(err, check.IsNil)
+
+	var doc ExportedOperation
+	c.Assert(json.Unmarshal(bytes, &doc), check.IsNil)
+	c.Assert(doc.SchemaVersion, check.Equals, ExportSchemaVersion)
+	c.Assert(doc.Operation.ID, check.Equals, "op1")
+	c.Assert(doc.Progress, check.NotNil)
+	c.Assert(doc.Progress.Completion, check.Equals, 100)
+	// derived fields make the document self-contained
+	c.Assert(doc.Duration, check.Equals, time.Hour)
+	c.Assert(doc.Outcome, check.Equals, OperationOutcomeCompleted)
+}
+
+func (s *ExportSuite) TestExportOperationWithoutProgress(c *check.C) {
+	base := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := base.Add(2 * time.Hour)
+	doc := exportOperation(SiteOperation{
+		ID:      "op1",
+		Created: base,
+		Updated: base.Add(time.Hour),
+		State:   OperationStateUpdateInProgress,
+	}, nil, now)
+	c.Assert(doc.Progress, check.IsNil)
+	// a still-running operation uses the current time as the end
+	c.Assert(doc.Duration, check.Equals, 2*time.Hour)
+	c.Assert(doc.Outcome, check.Equals, OperationOutcomeInProgress)
+}
+
+func (s *ExportSuite) TestExportOperationMissingOperation(c *check.C) {
+	_, err := ExportOperation(nil, nil)
+	c.Assert(trace.IsBadParameter(err), check.Equals, true,
+		check.Commentf("unexpected error: %v", err))
+}
@@ -25,6 +25,51 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// ClusterCapacityFromNodes computes aggregate resource capacity from
+// the provided kubernetes nodes. Nodes that have not reported a ready status
+// are marked unreachable and excluded from the totals.
+func ClusterCapacityFromNodes(nodes []v1.Node) *ClusterCapacity {
+	var result ClusterCapacity
+	for _, node := range nodes {
+		capacity := NodeCapacity{
+			Hostname:    node.Name,
+			AdvertiseIP: nodeInternalIP(node),
+		}
+		if !isNodeReady(node) {
+			capacity.Unreachable = true
+			result.Nodes = append(result.Nodes, capacity)
+			continue
+		}
+		resources := node.Status.Capacity
+		capacity.CPUCores = resources.Cpu().Value()
+		capacity.MemoryBytes = resources.Memory().Value()
+		capacity.DiskBytes = resources.StorageEphemeral().Value()
+		result.Total.CPUCores += capacity.CPUCores
+		result.Total.MemoryBytes += capacity.MemoryBytes
+		result.Total.DiskBytes += capacity.DiskBytes
+		result.Nodes = append(result.Nodes, capacity)
+	}
+	return &result
+}
+
+func isNodeReady(node v1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == v1.NodeReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func nodeInternalIP(node v1.Node) string {
+	for _, addr := range node.Status.Addresses {
+		if addr.Type == v1.NodeInternalIP {
+			return addr.Address
+		}
+	}
+	return ""
+}
+
 // MakeClusterInfoMap creates a config map with information about the provided
 // cluster that will be made available to all hooks.
 func MakeClusterInfoMap(cluster storage.Site) *v1.ConfigMap {
@@ -0,0 +1,80 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ops
+
+import (
+	check "gopkg.in/check.v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type KubernetesSuite struct{}
+
+var _ = check.Suite(&KubernetesSuite{})
+
+func (s *KubernetesSuite) TestClusterCapacityFromNodes(c *check.C) {
+	nodes := []v1.Node{
+		testNode("node-1", "192.168.1.1", "4", "8Gi", "100Gi", v1.ConditionTrue),
+		testNode("node-2", "192.168.1.2", "2", "4Gi", "50Gi", v1.ConditionTrue),
+		testNode("node-3", "192.168.1.3", "8", "16Gi", "200Gi", v1.ConditionUnknown),
+	}
+
+	capacity := ClusterCapacityFromNodes(nodes)
+	c.Assert(capacity.Nodes, check.HasLen, 3)
+
+	// the unreachable node is marked and excluded from the totals
+	c.Assert(capacity.Nodes[2], check.DeepEquals, NodeCapacity{
+		Hostname:    "node-3",
+		AdvertiseIP: "192.168.1.3",
+		Unreachable: true,
+	})
+	c.Assert(capacity.Total, check.DeepEquals, NodeCapacity{
+		CPUCores:    6,
+		MemoryBytes: 12 * 1024 * 1024 * 1024,
+		DiskBytes:   150 * 1024 * 1024 * 1024,
+	})
+
+	c.Assert(capacity.Nodes[0], check.DeepEquals, NodeCapacity{
+		Hostname:    "node-1",
+		AdvertiseIP: "192.168.1.1",
+		CPUCores:    4,
+		MemoryBytes: 8 * 1024 * 1024 * 1024,
+		DiskBytes:   100 * 1024 * 1024 * 1024,
+	})
+}
+
+func testNode(name, ip, cpu, memory, disk string, ready v1.ConditionStatus) v1.Node {
+	return v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Status: v1.NodeStatus{
+			Capacity: v1.ResourceList{
+				v1.ResourceCPU:              resource.MustParse(cpu),
+				v1.ResourceMemory:           resource.MustParse(memory),
+				v1.ResourceEphemeralStorage: resource.MustParse(disk),
+			},
+			Conditions: []v1.NodeCondition{
+				{Type: v1.NodeReady, Status: ready},
+			},
+			Addresses: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: ip},
+			},
+		},
+	}
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ops_test
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"path/filepath"
+	"time"
+
+	"github.com/gravitational/gravity/lib/blob/fs"
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/pack"
+	"github.com/gravitational/gravity/lib/pack/encryptedpack"
+	"github.com/gravitational/gravity/lib/pack/localpack"
+	"github.com/gravitational/gravity/lib/storage/keyval"
+
+	"github.com/cloudflare/cfssl/csr"
+	licenseapi "github.com/gravitational/license"
+	"github.com/gravitational/license/authority"
+	"github.com/gravitational/trace"
+	check "gopkg.in/check.v1"
+)
+
+type LicenseSuite struct{}
+
+var _ = check.Suite(&LicenseSuite{})
+
+func (s *LicenseSuite) TestVerifyLicenseWithRevocation(c *check.C) {
+	packages := newTestPackages(c)
+	ca, err := authority.GenerateSelfSignedCA(csr.CertificateRequest{
+		CN: constants.OpsCenterKeyPair,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(pack.CreateCertificateAuthority(pack.CreateCAParams{
+		Packages: packages,
+		KeyPair:  *ca,
+	}), check.IsNil)
+
+	license, err := licenseapi.NewLicense(licenseapi.NewLicenseInfo{
+		MaxNodes:   3,
+		ValidFor:   time.Hour,
+		TLSKeyPair: *ca,
+	})
+	c.Assert(err, check.IsNil)
+
+	// a valid license that is not on the revocation list passes
+	c.Assert(ops.VerifyLicenseWithRevocation(packages, license, nil), check.IsNil)
+
+	// the same license is rejected once its serial number is revoked
+	err = ops.VerifyLicenseWithRevocation(packages, license,
+		map[string]bool{certificateSerial(c, license): true})
+	c.Assert(trace.IsAccessDenied(err), check.Equals, true, check.Commentf(
+		"expected AccessDenied, got %v", err))
+
+	// a license signed by a different authority fails verification outright
+	otherCA, err := authority.GenerateSelfSignedCA(csr.CertificateRequest{
+		CN: constants.OpsCenterKeyPair,
+	})
+	c.Assert(err, check.IsNil)
+	tampered, err := licenseapi.NewLicense(licenseapi.NewLicenseInfo{
+		MaxNodes:   3,
+		ValidFor:   time.Hour,
+		TLSKeyPair: *otherCA,
+	})
+	c.Assert(err, check.IsNil)
+	err = ops.VerifyLicenseWithRevocation(packages, tampered, nil)
+	c.Assert(err, check.NotNil)
+	c.Assert(trace.IsAccessDenied(err), check.Equals, false)
+}
+
+func (s *LicenseSuite) TestVerifyPackageAccess(c *check.C) {
+	ca, err := authority.GenerateSelfSignedCA(csr.CertificateRequest{
+		CN: constants.OpsCenterKeyPair,
+	})
+	c.Assert(err, check.IsNil)
+
+	// a license without an encryption key reads the plain store directly
+	packages := newTestPackages(c)
+	c.Assert(pack.CreateCertificateAuthority(pack.CreateCAParams{
+		Packages: packages,
+		KeyPair:  *ca,
+	}), check.IsNil)
+	license, err := licenseapi.NewLicense(licenseapi.NewLicenseInfo{
+		MaxNodes:   3,
+		ValidFor:   time.Hour,
+		TLSKeyPair: *ca,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(ops.VerifyPackageAccess(packages, license), check.IsNil)
+
+	// a license with an encryption key still reads the plain store since
+	// unencrypted packages pass through the encryption wrapper untouched
+	encryptedLicense, err := licenseapi.NewLicense(licenseapi.NewLicenseInfo{
+		MaxNodes:      3,
+		ValidFor:      time.Hour,
+		TLSKeyPair:    *ca,
+		EncryptionKey: []byte("the-encryption-key"),
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(ops.VerifyPackageAccess(packages, encryptedLicense), check.IsNil)
+
+	// against a store encrypted with the same key the license passes
+	encryptedStore := newTestPackages(c)
+	c.Assert(pack.CreateCertificateAuthority(pack.CreateCAParams{
+		Packages: encryptedpack.New(encryptedStore, "the-encryption-key"),
+		KeyPair:  *ca,
+	}), check.IsNil)
+	c.Assert(ops.VerifyPackageAccess(encryptedStore, encryptedLicense), check.IsNil)
+
+	// while a license with a different key is rejected
+	otherKeyLicense, err := licenseapi.NewLicense(licenseapi.NewLicenseInfo{
+		MaxNodes:      3,
+		ValidFor:      time.Hour,
+		TLSKeyPair:    *ca,
+		EncryptionKey: []byte("a-different-key"),
+	})
+	c.Assert(err, check.IsNil)
+	err = ops.VerifyPackageAccess(encryptedStore, otherKeyLicense)
+	c.Assert(err, check.ErrorMatches, "(?s).*cannot access packages using the encryption key.*")
+	c.Assert(trace.IsBadParameter(err), check.Equals, true)
+
+	// as is a license without a key since the packages cannot be read
+	// unencrypted
+	err = ops.VerifyPackageAccess(encryptedStore, license)
+	c.Assert(err, check.ErrorMatches, "(?s).*cannot access packages using the encryption key.*")
+}
+
+// newTestPackages creates a package service for tests
+func newTestPackages(c *check.C) pack.PackageService {
+	dir := c.MkDir()
+	backend, err := keyval.NewBolt(keyval.BoltConfig{
+		Path: filepath.Join(dir, "storage.db"),
+	})
+	c.Assert(err, check.IsNil)
+	objects, err := fs.New(dir)
+	c.Assert(err, check.IsNil)
+	packages, err := localpack.New(localpack.Config{
+		Backend:     backend,
+		UnpackedDir: filepath.Join(dir, defaults.UnpackedDir),
+		Objects:     objects,
+	})
+	c.Assert(err, check.IsNil)
+	return packages
+}
+
+// certificateSerial returns the serial number of the license certificate
+func certificateSerial(c *check.C, license string) string {
+	block, _ := pem.Decode([]byte(license))
+	c.Assert(block, check.NotNil)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	c.Assert(err, check.IsNil)
+	return cert.SerialNumber.String()
+}
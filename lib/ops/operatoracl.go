@@ -237,6 +237,15 @@ func (o *OperatorACL) GetClusterNodes(key SiteKey) ([]Node, error) {
 	return o.operator.GetClusterNodes(key)
 }
 
+// GetClusterCapacity returns aggregate resource capacity across
+// the cluster's nodes
+func (o *OperatorACL) GetClusterCapacity(ctx context.Context, key SiteKey) (*ClusterCapacity, error) {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbRead); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.GetClusterCapacity(ctx, key)
+}
+
 func (o *OperatorACL) ResetUserPassword(req ResetUserPasswordRequest) (string, error) {
 	if err := o.Action(teleservices.KindUser, teleservices.VerbUpdate); err != nil {
 		return "", trace.Wrap(err)
@@ -292,6 +301,13 @@ func (o *OperatorACL) GetExpandToken(key SiteKey) (*storage.ProvisioningToken, e
 	return o.operator.GetExpandToken(key)
 }
 
+func (o *OperatorACL) RotateJoinToken(ctx context.Context, key SiteKey) (*storage.ProvisioningToken, error) {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.RotateJoinToken(ctx, key)
+}
+
 func (o *OperatorACL) GetTrustedClusterToken(key SiteKey) (storage.Token, error) {
 	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbRead); err != nil {
 		return nil, trace.Wrap(err)
@@ -534,6 +550,15 @@ func (o *OperatorACL) GetSiteOperationLogs(key SiteOperationKey) (io.ReadCloser,
 	return o.operator.GetSiteOperationLogs(key)
 }
 
+// GetOperationLogsArchive returns a tar.gz archive with the operation's
+// progress history, plan and collected agent output
+func (o *OperatorACL) GetOperationLogsArchive(key SiteOperationKey) (io.ReadCloser, error) {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbRead); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.GetOperationLogsArchive(key)
+}
+
 func (o *OperatorACL) CreateLogEntry(key SiteOperationKey, entry LogEntry) error {
 	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
 		return trace.Wrap(err)
@@ -934,6 +959,16 @@ func (o *OperatorACL) GetClusterCertificate(key SiteKey, withSecrets bool) (*Clu
 	return o.operator.GetClusterCertificate(key, withSecrets)
 }
 
+// GetCertificateStatus returns expiry information for the cluster's internal certificates
+func (o *OperatorACL) GetCertificateStatus(key SiteKey) (*ClusterCertificateStatus, error) {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbRead); err != nil {
+		if err := o.ClusterAction(key.SiteDomain, storage.KindTLSKeyPair, teleservices.VerbRead); err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+	return o.operator.GetCertificateStatus(key)
+}
+
 func (o *OperatorACL) UpdateClusterCertificate(ctx context.Context, req UpdateCertificateRequest) (*ClusterCertificate, error) {
 	if err := o.ClusterAction(req.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
 		return nil, trace.Wrap(err)
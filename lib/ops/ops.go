@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"io"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -376,6 +377,10 @@ type Tokens interface {
 	CreateProvisioningToken(storage.ProvisioningToken) error
 	// GetExpandToken returns the cluster's expand token
 	GetExpandToken(SiteKey) (*storage.ProvisioningToken, error)
+	// RotateJoinToken replaces the cluster's expand token with a newly
+	// generated one and returns the new token. The old token remains valid
+	// for a grace window so in-flight joins are not locked out
+	RotateJoinToken(context.Context, SiteKey) (*storage.ProvisioningToken, error)
 	// GetTrustedClusterToken returns the cluster's trusted cluster token
 	GetTrustedClusterToken(SiteKey) (storage.Token, error)
 }
@@ -593,6 +598,10 @@ type Certificates interface {
 	UpdateClusterCertificate(context.Context, UpdateCertificateRequest) (*ClusterCertificate, error)
 	// DeleteClusterCertificate deletes the cluster TLS certificate
 	DeleteClusterCertificate(context.Context, SiteKey) error
+	// GetCertificateStatus returns expiry information for the cluster's
+	// internal certificates such as the planet certificate authority and
+	// the per-node secrets issued from it
+	GetCertificateStatus(key SiteKey) (*ClusterCertificateStatus, error)
 }
 
 // RuntimeEnvironment manages runtime environment variables in cluster
@@ -640,6 +649,24 @@ type ClusterCertificate struct {
 	PrivateKey []byte `json:"private_key"`
 }
 
+// ClusterCertificateStatus describes the expiry of the cluster's internal
+// certificates
+type ClusterCertificateStatus struct {
+	// Certificates lists expiry information for the individual certificates
+	Certificates []CertificateExpiry `json:"certificates"`
+	// SoonestExpiry is the earliest expiry time among Certificates
+	SoonestExpiry time.Time `json:"soonest_expiry"`
+}
+
+// CertificateExpiry describes the expiry of a single cluster certificate
+type CertificateExpiry struct {
+	// Name identifies the certificate within the cluster, e.g. "ca/root"
+	// or "<node advertise IP>/etcd"
+	Name string `json:"name"`
+	// NotAfter is the time the certificate expires
+	NotAfter time.Time `json:"not_after"`
+}
+
 // UpdateCertificateRequest is the request to update the cluster certificate
 type UpdateCertificateRequest struct {
 	// AccountID is the cluster's account ID
@@ -702,10 +729,38 @@ type Status interface {
 	CheckSiteStatus(ctx context.Context, key SiteKey) error
 	// GetClusterNodes returns a real-time information about cluster nodes
 	GetClusterNodes(SiteKey) ([]Node, error)
+	// GetClusterCapacity returns aggregate resource capacity across
+	// the cluster's nodes
+	GetClusterCapacity(context.Context, SiteKey) (*ClusterCapacity, error)
 	// GetVersion returns the gravity binary version information.
 	GetVersion(context.Context) (*rpcproto.Version, error)
 }
 
+// ClusterCapacity aggregates resource capacity across cluster nodes
+type ClusterCapacity struct {
+	// Total sums the resources across all reachable nodes
+	Total NodeCapacity `json:"total"`
+	// Nodes lists the per-node breakdown
+	Nodes []NodeCapacity `json:"nodes"`
+}
+
+// NodeCapacity describes resources of a single cluster node
+type NodeCapacity struct {
+	// Hostname is the node hostname
+	Hostname string `json:"hostname"`
+	// AdvertiseIP is the node advertise IP
+	AdvertiseIP string `json:"advertise_ip,omitempty"`
+	// CPUCores is the number of CPU cores on the node
+	CPUCores int64 `json:"cpu_cores"`
+	// MemoryBytes is the node memory capacity in bytes
+	MemoryBytes int64 `json:"memory_bytes"`
+	// DiskBytes is the node ephemeral storage capacity in bytes
+	DiskBytes int64 `json:"disk_bytes"`
+	// Unreachable is set for a node that has not reported its resources,
+	// such nodes are excluded from the totals
+	Unreachable bool `json:"unreachable,omitempty"`
+}
+
 // Node represents a cluster node information based on Teleport node
 type Node struct {
 	// Hostname is the node hostname
@@ -789,6 +844,11 @@ type Operations interface {
 	// related to this operation periodically
 	GetSiteOperationLogs(SiteOperationKey) (io.ReadCloser, error)
 
+	// GetOperationLogsArchive returns a tar.gz archive with the operation's
+	// progress history, plan and collected agent output packaged for
+	// support bundles
+	GetOperationLogsArchive(SiteOperationKey) (io.ReadCloser, error)
+
 	// CreateLogEntry appends the provided log entry to the operation's log file
 	CreateLogEntry(SiteOperationKey, LogEntry) error
 
@@ -855,7 +915,17 @@ type Operations interface {
 	GetOperationPlan(SiteOperationKey) (*storage.OperationPlan, error)
 }
 
-// OperationsFilter represents a filter to apply to results when listing operations
+const (
+	// OperationsOrderDesc indicates to return operations in the newest-first order
+	OperationsOrderDesc = "desc"
+	// OperationsOrderAsc indicates to return operations in the oldest-first order
+	OperationsOrderAsc = "asc"
+)
+
+// OperationsFilter represents a filter to apply to results when listing operations.
+//
+// Unless Order is set to OperationsOrderAsc, the filtered operations are
+// guaranteed to be returned in the newest-first order.
 type OperationsFilter struct {
 	// Last indicates to only return the last operation
 	Last bool
@@ -874,6 +944,18 @@ type OperationsFilter struct {
 
 	// Types indicates to only return an operation type (ie OperationExpand)
 	Types []string
+
+	// Order specifies the order of the returned operations, one of
+	// OperationsOrderDesc (newest-first, the default) or OperationsOrderAsc
+	// (oldest-first)
+	Order string
+
+	// StartKey is the ID of the operation to resume listing after
+	// (exclusive); an unknown key starts listing from the beginning
+	StartKey string
+
+	// Limit caps the number of returned operations, 0 means no limit
+	Limit int
 }
 
 // URLValues converts the filter to a set of URL values that can be passed via the API
@@ -906,6 +988,18 @@ func (f OperationsFilter) URLValues() (res url.Values) {
 		}
 	}
 
+	if f.Order != "" {
+		res.Add("order", f.Order)
+	}
+
+	if f.StartKey != "" {
+		res.Add("start_key", f.StartKey)
+	}
+
+	if f.Limit != 0 {
+		res.Add("limit", strconv.Itoa(f.Limit))
+	}
+
 	return
 }
 
@@ -937,6 +1031,13 @@ func FilterFromURLValues(v url.Values) (f OperationsFilter) {
 		}
 	}
 
+	f.Order = v.Get("order")
+	f.StartKey = v.Get("start_key")
+
+	if limit := v.Get("limit"); limit != "" {
+		f.Limit, _ = strconv.Atoi(limit)
+	}
+
 	return
 }
 
@@ -995,6 +1096,33 @@ func (filter OperationsFilter) Filter(in SiteOperations) SiteOperations {
 		return SiteOperations{filtered[0]}
 	}
 
+	if filter.Order == OperationsOrderAsc {
+		// backend is guaranteed to return operations in the last-to-first
+		// order so reverse the result to make it chronological
+		reversed := make(SiteOperations, 0, len(filtered))
+		for i := len(filtered) - 1; i >= 0; i-- {
+			reversed = append(reversed, filtered[i])
+		}
+		filtered = reversed
+	}
+
+	if filter.StartKey != "" {
+		for i, operation := range filtered {
+			if operation.ID == filter.StartKey {
+				filtered = filtered[i+1:]
+				break
+			}
+		}
+	}
+
+	if filter.Limit != 0 && len(filtered) > filter.Limit {
+		filtered = filtered[:filter.Limit]
+	}
+
+	if len(filtered) == 0 {
+		return nil
+	}
+
 	return filtered
 }
 
@@ -1372,14 +1500,19 @@ func (s SiteOperationKey) SiteKey() SiteKey {
 
 // Check makes sure the key is valid
 func (s SiteOperationKey) Check() error {
+	var missing []string
 	if s.AccountID == "" {
-		return trace.BadParameter("empty AccountID")
+		missing = append(missing, "AccountID")
 	}
 	if s.SiteDomain == "" {
-		return trace.BadParameter("empty SiteDomain")
+		missing = append(missing, "SiteDomain")
 	}
 	if s.OperationID == "" {
-		return trace.BadParameter("empty OperationID")
+		missing = append(missing, "OperationID")
+	}
+	if len(missing) != 0 {
+		return trace.BadParameter("operation key is missing %v",
+			strings.Join(missing, ", "))
 	}
 	return nil
 }
@@ -1802,11 +1935,16 @@ type SiteKey struct {
 
 // Check checks parameters
 func (k *SiteKey) Check() error {
+	var missing []string
 	if k.AccountID == "" {
-		return trace.BadParameter("missing parameter AccountID")
+		missing = append(missing, "AccountID")
 	}
 	if k.SiteDomain == "" {
-		return trace.BadParameter("missing parameter SiteDomain")
+		missing = append(missing, "SiteDomain")
+	}
+	if len(missing) != 0 {
+		return trace.BadParameter("cluster key is missing %v",
+			strings.Join(missing, ", "))
 	}
 	return nil
 }
@@ -2147,6 +2285,11 @@ type SetOperationStateRequest struct {
 	State string `json:"state"`
 	// Progress is an optional progress entry to create
 	Progress *ProgressEntry `json:"progress,omitempty"`
+	// Code optionally categorizes the operation outcome in a
+	// machine-readable way, see ProgressCode* constants for examples.
+	// If the progress entry does not specify its own code, this value
+	// is copied onto the created entry
+	Code string `json:"code,omitempty"`
 }
 
 // LogForwarders defines the interface to manage log forwarders
@@ -18,6 +18,8 @@ package ops
 
 import (
 	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
 	check "gopkg.in/check.v1"
 )
 
@@ -259,9 +261,168 @@ func (s *OperationsFilterSuite) TestOperationsFilter(c *check.C) {
 				Types: []string{OperationUpdate, OperationShrink},
 			},
 		},
+		{
+			description: "ascending order",
+			in: []storage.SiteOperation{
+				{
+					ID: "op2",
+				},
+				{
+					ID: "op1",
+				},
+			},
+			out: []storage.SiteOperation{
+				{
+					ID: "op1",
+				},
+				{
+					ID: "op2",
+				},
+			},
+			filter: OperationsFilter{
+				Order: OperationsOrderAsc,
+			},
+		},
+		{
+			description: "explicit descending order",
+			in: []storage.SiteOperation{
+				{
+					ID: "op2",
+				},
+				{
+					ID: "op1",
+				},
+			},
+			out: []storage.SiteOperation{
+				{
+					ID: "op2",
+				},
+				{
+					ID: "op1",
+				},
+			},
+			filter: OperationsFilter{
+				Order: OperationsOrderDesc,
+			},
+		},
+		{
+			description: "ascending order combined with type",
+			in: []storage.SiteOperation{
+				{
+					ID:   "op3",
+					Type: OperationUpdate,
+				},
+				{
+					ID:   "op2",
+					Type: OperationShrink,
+				},
+				{
+					ID:   "op1",
+					Type: OperationUpdate,
+				},
+			},
+			out: []storage.SiteOperation{
+				{
+					ID:   "op1",
+					Type: OperationUpdate,
+				},
+				{
+					ID:   "op3",
+					Type: OperationUpdate,
+				},
+			},
+			filter: OperationsFilter{
+				Types: []string{OperationUpdate},
+				Order: OperationsOrderAsc,
+			},
+		},
 	}
 
 	for _, tt := range tests {
 		c.Assert(tt.filter.Filter(tt.in), check.DeepEquals, tt.out, check.Commentf(tt.description))
 	}
 }
+
+type KeySuite struct{}
+
+var _ = check.Suite(&KeySuite{})
+
+func (s *KeySuite) TestSiteKeyCheck(c *check.C) {
+	tests := []struct {
+		key     SiteKey
+		missing []string
+	}{
+		{
+			key: SiteKey{AccountID: "account", SiteDomain: "example.com"},
+		},
+		{
+			key:     SiteKey{SiteDomain: "example.com"},
+			missing: []string{"AccountID"},
+		},
+		{
+			key:     SiteKey{AccountID: "account"},
+			missing: []string{"SiteDomain"},
+		},
+		{
+			key:     SiteKey{},
+			missing: []string{"AccountID", "SiteDomain"},
+		},
+	}
+	for _, tt := range tests {
+		err := tt.key.Check()
+		if len(tt.missing) == 0 {
+			c.Assert(err, check.IsNil)
+			continue
+		}
+		c.Assert(trace.IsBadParameter(err), check.Equals, true,
+			check.Commentf("%v", tt.key))
+		for _, field := range tt.missing {
+			c.Assert(err, check.ErrorMatches, ".*"+field+".*",
+				check.Commentf("%v", tt.key))
+		}
+	}
+}
+
+func (s *KeySuite) TestSiteOperationKeyCheck(c *check.C) {
+	tests := []struct {
+		key     SiteOperationKey
+		missing []string
+	}{
+		{
+			key: SiteOperationKey{AccountID: "account", SiteDomain: "example.com", OperationID: "op"},
+		},
+		{
+			key:     SiteOperationKey{SiteDomain: "example.com", OperationID: "op"},
+			missing: []string{"AccountID"},
+		},
+		{
+			key:     SiteOperationKey{AccountID: "account", OperationID: "op"},
+			missing: []string{"SiteDomain"},
+		},
+		{
+			key:     SiteOperationKey{AccountID: "account", SiteDomain: "example.com"},
+			missing: []string{"OperationID"},
+		},
+		{
+			key:     SiteOperationKey{OperationID: "op"},
+			missing: []string{"AccountID", "SiteDomain"},
+		},
+		{
+			key:     SiteOperationKey{},
+			missing: []string{"AccountID", "SiteDomain", "OperationID"},
+		},
+	}
+	for _, tt := range tests {
+		err := tt.key.Check()
+		if len(tt.missing) == 0 {
+			c.Assert(err, check.IsNil)
+			continue
+		}
+		c.Assert(trace.IsBadParameter(err), check.Equals, true,
+			check.Commentf("%v", tt.key))
+		for _, field := range tt.missing {
+			c.Assert(err, check.ErrorMatches, ".*"+field+".*",
+				check.Commentf("%v", tt.key))
+		}
+	}
+}
@@ -247,6 +247,19 @@ func (c *Client) GetExpandToken(key ops.SiteKey) (*storage.ProvisioningToken, er
 	return &token, nil
 }
 
+func (c *Client) RotateJoinToken(ctx context.Context, key ops.SiteKey) (*storage.ProvisioningToken, error) {
+	out, err := c.PostJSON(c.Endpoint(
+		"accounts", key.AccountID, "sites", key.SiteDomain, "tokens", "expand", "rotate"), key)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var token storage.ProvisioningToken
+	if err = json.Unmarshal(out.Bytes(), &token); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &token, nil
+}
+
 // TODO(r0mant) Move to enterprise.
 func (c *Client) GetTrustedClusterToken(key ops.SiteKey) (storage.Token, error) {
 	out, err := c.Get(context.TODO(), c.Endpoint(
@@ -379,6 +392,21 @@ func (c *Client) GetClusterNodes(key ops.SiteKey) ([]ops.Node, error) {
 	return nodes, nil
 }
 
+// GetClusterCapacity returns aggregate resource capacity across
+// the cluster's nodes
+func (c *Client) GetClusterCapacity(ctx context.Context, key ops.SiteKey) (*ops.ClusterCapacity, error) {
+	out, err := c.Get(ctx, c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "capacity"), url.Values{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var capacity ops.ClusterCapacity
+	err = json.Unmarshal(out.Bytes(), &capacity)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &capacity, nil
+}
+
 func (c *Client) ResetUserPassword(req ops.ResetUserPasswordRequest) (string, error) {
 	out, err := c.PutJSON(c.Endpoint("accounts", req.AccountID, "sites", req.SiteDomain, "reset-password"), req)
 	if err != nil {
@@ -677,6 +705,18 @@ func (c *Client) GetSiteOperationLogs(key ops.SiteOperationKey) (io.ReadCloser,
 	return httplib.SetupWebsocketClient(context.TODO(), &c.Client, endpoint, c.dialer)
 }
 
+// GetOperationLogsArchive returns a tar.gz archive with the operation's
+// progress history, plan and collected agent output
+func (c *Client) GetOperationLogsArchive(key ops.SiteOperationKey) (io.ReadCloser, error) {
+	file, err := c.GetFile(context.TODO(), c.Endpoint(
+		"accounts", key.AccountID, "sites", key.SiteDomain,
+		"operations", "common", key.OperationID, "logs", "archive"), url.Values{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return file.Body(), nil
+}
+
 func (c *Client) CreateLogEntry(key ops.SiteOperationKey, entry ops.LogEntry) error {
 	_, err := c.PostJSON(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "operations", "common", key.OperationID, "logs", "entry"), entry)
 	if err != nil {
@@ -1372,6 +1412,20 @@ func (c *Client) GetClusterCertificate(key ops.SiteKey, withSecrets bool) (*ops.
 	return &info, nil
 }
 
+// GetCertificateStatus returns expiry information for the cluster's internal certificates
+func (c *Client) GetCertificateStatus(key ops.SiteKey) (*ops.ClusterCertificateStatus, error) {
+	out, err := c.Get(context.TODO(), c.Endpoint(
+		"accounts", key.AccountID, "sites", key.SiteDomain, "certificate", "status"), url.Values{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var status ops.ClusterCertificateStatus
+	if err := json.Unmarshal(out.Bytes(), &status); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &status, nil
+}
+
 // UpdateClusterCertificate updates the cluster certificate
 func (c *Client) UpdateClusterCertificate(ctx context.Context, req ops.UpdateCertificateRequest) (*ops.ClusterCertificate, error) {
 	out, err := c.PostJSON(c.Endpoint(
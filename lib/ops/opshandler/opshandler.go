@@ -158,6 +158,7 @@ func NewWebHandler(cfg WebHandlerConfig) (*WebHandler, error) {
 	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/tokens/userresets", h.needsAuth(h.resetUser))
 	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/tokens/provision", h.needsAuth(h.createProvisioningToken))
 	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/tokens/expand", h.needsAuth(h.getExpandToken))
+	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/tokens/expand/rotate", h.needsAuth(h.rotateJoinToken))
 	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/tokens/trustedcluster", h.needsAuth(h.getTrustedClusterToken))
 
 	// Sites API
@@ -174,6 +175,7 @@ func NewWebHandler(cfg WebHandlerConfig) (*WebHandler, error) {
 	h.PUT("/portal/v1/accounts/:account_id/sites/:site_domain/reset-password", h.needsAuth(h.resetUserPassword))
 	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/agent", h.needsAuth(h.getClusterAgent))
 	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/nodes", h.needsAuth(h.getClusterNodes))
+	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/capacity", h.needsAuth(h.getClusterCapacity))
 
 	// Status API
 	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/status", h.needsAuth(h.checkSiteStatus))
@@ -191,6 +193,7 @@ func NewWebHandler(cfg WebHandlerConfig) (*WebHandler, error) {
 
 	// Cluster certificate API
 	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/certificate", h.needsAuth(h.getClusterCert))
+	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/certificate/status", h.needsAuth(h.getClusterCertStatus))
 	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/certificate", h.needsAuth(h.updateClusterCert))
 	h.DELETE("/portal/v1/accounts/:account_id/sites/:site_domain/certificate", h.needsAuth(h.deleteClusterCert))
 
@@ -237,6 +240,7 @@ func NewWebHandler(cfg WebHandlerConfig) (*WebHandler, error) {
 	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/operations/common/:operation_id", h.needsAuth(h.getSiteOperation))
 	h.DELETE("/portal/v1/accounts/:account_id/sites/:site_domain/operations/common/:operation_id", h.needsAuth(h.deleteOperation))
 	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/logs", h.needsAuth(h.getSiteOperationLogs))
+	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/logs/archive", h.needsAuth(h.getOperationLogsArchive))
 	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/logs/entry", h.needsAuth(h.createLogEntry))
 	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/logs", h.needsAuth(h.streamOperationLogs))
 	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/progress", h.needsAuth(h.getSiteOperationProgress))
@@ -831,6 +835,23 @@ func (h *WebHandler) getExpandToken(w http.ResponseWriter, r *http.Request, p ht
 	return nil
 }
 
+/*  rotateJoinToken replaces the site's expand token with a new one
+
+    POST /portal/v1/accounts/:account_id/sites/:site_domain/tokens/expand/rotate
+
+    Success response:
+
+    storage.ProvisioningToken
+*/
+func (h *WebHandler) rotateJoinToken(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	token, err := context.Operator.RotateJoinToken(r.Context(), siteKey(p))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, token)
+	return nil
+}
+
 /*  getTrustedClusterToken returns the cluster's trusted cluster token
 
     GET /portal/v1/accounts/:account_id/tokens/trustedcluster
@@ -1183,6 +1204,23 @@ func (h *WebHandler) getClusterNodes(w http.ResponseWriter, r *http.Request, p h
 	return nil
 }
 
+/*  getClusterCapacity returns aggregate resource capacity across the cluster's nodes
+
+    GET /portal/v1/accounts/:account_id/sites/:site_domain/capacity
+
+    Input: ops.SiteKey
+
+    Success response: ops.ClusterCapacity
+*/
+func (h *WebHandler) getClusterCapacity(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	capacity, err := context.Operator.GetClusterCapacity(r.Context(), siteKey(p))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, capacity)
+	return nil
+}
+
 /*  resetUserPassword resets the user password and returns the new one
 
     PUT /portal/v1/accounts/:account_id/sites/:site_domain/reset-password
@@ -1841,6 +1879,24 @@ func (h *WebHandler) getSiteOperationLogs(w http.ResponseWriter, r *http.Request
 	return getOpLogs(w, r, siteOperationKey(p), context)
 }
 
+/*getOperationLogsArchive returns a tar.gz archive with the operation's
+  progress history, plan and collected agent output
+
+  GET /portal/v1/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/logs/archive
+
+*/
+func (h *WebHandler) getOperationLogsArchive(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	logsArchive, err := context.Operator.GetOperationLogsArchive(siteOperationKey(p))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer logsArchive.Close()
+	w.Header().Set("Content-Type", "application/x-gzip")
+	w.Header().Set("Content-Disposition", "attachment; filename=operation-logs.tar.gz")
+	_, err = io.Copy(w, logsArchive)
+	return trace.Wrap(err)
+}
+
 /* createLogEntry appends the provided log entry to the operation's log file
 
    POST /portal/v1/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/logs/entry
@@ -2323,6 +2379,23 @@ func (h *WebHandler) getClusterCert(w http.ResponseWriter, r *http.Request, p ht
 	return nil
 }
 
+/* getClusterCertStatus returns expiry information for the cluster's internal certificates
+
+     GET /portal/v1/accounts/:account_id/sites/:site_domain/certificate/status
+
+   Success Response:
+
+     ops.ClusterCertificateStatus
+*/
+func (h *WebHandler) getClusterCertStatus(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	status, err := context.Operator.GetCertificateStatus(siteKey(p))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, status)
+	return nil
+}
+
 /* updateClusterCert updates the cluster certificate
 
      POST /portal/v1/accounts/:account_id/sites/:site_domain/certificate
@@ -183,6 +183,16 @@ func (r *Router) GetClusterNodes(key ops.SiteKey) ([]ops.Node, error) {
 	return client.GetClusterNodes(key)
 }
 
+// GetClusterCapacity returns aggregate resource capacity across
+// the cluster's nodes
+func (r *Router) GetClusterCapacity(ctx context.Context, key ops.SiteKey) (*ops.ClusterCapacity, error) {
+	client, err := r.PickClient(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.GetClusterCapacity(ctx, key)
+}
+
 func (r *Router) ResetUserPassword(req ops.ResetUserPasswordRequest) (string, error) {
 	client, err := r.PickClient(req.SiteDomain)
 	if err != nil {
@@ -372,6 +382,16 @@ func (r *Router) GetSiteOperationLogs(key ops.SiteOperationKey) (io.ReadCloser,
 	return client.GetSiteOperationLogs(key)
 }
 
+// GetOperationLogsArchive returns a tar.gz archive with the operation's
+// progress history, plan and collected agent output
+func (r *Router) GetOperationLogsArchive(key ops.SiteOperationKey) (io.ReadCloser, error) {
+	client, err := r.PickOperationClient(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.GetOperationLogsArchive(key)
+}
+
 func (r *Router) CreateLogEntry(key ops.SiteOperationKey, entry ops.LogEntry) error {
 	client, err := r.PickOperationClient(key.SiteDomain)
 	if err != nil {
@@ -742,6 +762,14 @@ func (r *Router) GetExpandToken(key ops.SiteKey) (*storage.ProvisioningToken, er
 	return client.GetExpandToken(key)
 }
 
+func (r *Router) RotateJoinToken(ctx context.Context, key ops.SiteKey) (*storage.ProvisioningToken, error) {
+	client, err := r.PickClient(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.RotateJoinToken(ctx, key)
+}
+
 func (r *Router) GetTrustedClusterToken(key ops.SiteKey) (storage.Token, error) {
 	return r.Local.GetTrustedClusterToken(key)
 }
@@ -769,6 +797,15 @@ func (r *Router) GetClusterCertificate(key ops.SiteKey, withSecrets bool) (*ops.
 	return client.GetClusterCertificate(key, withSecrets)
 }
 
+// GetCertificateStatus returns expiry information for the cluster's internal certificates
+func (r *Router) GetCertificateStatus(key ops.SiteKey) (*ops.ClusterCertificateStatus, error) {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.GetCertificateStatus(key)
+}
+
 // UpdateClusterCertificate updates the cluster certificate
 func (r *Router) UpdateClusterCertificate(ctx context.Context, req ops.UpdateCertificateRequest) (*ops.ClusterCertificate, error) {
 	client, err := r.RemoteClient(req.SiteDomain)
@@ -18,12 +18,18 @@ package opsservice
 
 import (
 	"context"
+	"fmt"
+	"sort"
 
 	"github.com/gravitational/gravity/lib/constants"
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/ops"
 	"github.com/gravitational/gravity/lib/ops/events"
+	"github.com/gravitational/gravity/lib/pack"
+	"github.com/gravitational/gravity/lib/utils"
+
 	"github.com/gravitational/rigging"
+	"github.com/gravitational/teleport/lib/tlsca"
 	"github.com/gravitational/trace"
 
 	v1 "k8s.io/api/core/v1"
@@ -86,6 +92,82 @@ func (o *Operator) UpdateClusterCertificate(ctx context.Context, req ops.UpdateC
 	}, nil
 }
 
+// GetCertificateStatus returns expiry information for the cluster's internal
+// certificates: the planet certificate authority and the certificates from
+// the per-node secrets packages issued from it (apiserver, etcd, kubelet
+// and friends)
+func (o *Operator) GetCertificateStatus(key ops.SiteKey) (*ops.ClusterCertificateStatus, error) {
+	site, err := o.openSite(key)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	caArchive, err := site.readCertAuthorityPackage()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	archives := map[string]utils.TLSArchive{
+		"ca": caArchive,
+	}
+
+	envelopes, err := site.packages().GetPackages(site.siteRepoName())
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, envelope := range envelopes {
+		if !envelope.HasLabel(pack.PurposeLabel, pack.PurposePlanetSecrets) {
+			continue
+		}
+		_, reader, err := site.packages().ReadPackage(envelope.Locator)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		archive, err := utils.ReadTLSArchive(reader)
+		reader.Close()
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		origin := envelope.RuntimeLabels[pack.AdvertiseIPLabel]
+		if origin == "" {
+			origin = envelope.Locator.Name
+		}
+		archives[origin] = archive
+	}
+
+	return clusterCertificateStatus(archives)
+}
+
+// clusterCertificateStatus builds the certificate expiry report from the
+// given TLS archives keyed by their origin: the certificate authority
+// package or a node's secrets package
+func clusterCertificateStatus(archives map[string]utils.TLSArchive) (*ops.ClusterCertificateStatus, error) {
+	status := &ops.ClusterCertificateStatus{}
+	for origin, archive := range archives {
+		for name, keyPair := range archive {
+			if len(keyPair.CertPEM) == 0 {
+				continue
+			}
+			cert, err := tlsca.ParseCertificatePEM(keyPair.CertPEM)
+			if err != nil {
+				return nil, trace.Wrap(err, "failed to parse certificate %v/%v",
+					origin, name)
+			}
+			status.Certificates = append(status.Certificates, ops.CertificateExpiry{
+				Name:     fmt.Sprintf("%v/%v", origin, name),
+				NotAfter: cert.NotAfter,
+			})
+			if status.SoonestExpiry.IsZero() || cert.NotAfter.Before(status.SoonestExpiry) {
+				status.SoonestExpiry = cert.NotAfter
+			}
+		}
+	}
+	sort.Slice(status.Certificates, func(i, j int) bool {
+		return status.Certificates[i].Name < status.Certificates[j].Name
+	})
+	return status, nil
+}
+
 // GetClusterCertificate returns certificate and private key data stored in a secret
 // inside the cluster
 //
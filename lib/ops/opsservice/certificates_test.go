@@ -19,11 +19,14 @@ package opsservice
 import (
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/ops"
 	"github.com/gravitational/gravity/lib/utils"
 
+	"github.com/cloudflare/cfssl/csr"
+	"github.com/gravitational/license/authority"
 	teleutils "github.com/gravitational/teleport/lib/utils"
 	"github.com/gravitational/trace"
 	"gopkg.in/check.v1"
@@ -79,3 +82,63 @@ func (s *CertificatesSuite) TestCertificates(c *check.C) {
 	c.Assert(certBytes, check.DeepEquals, cert.Cert)
 	c.Assert(keyBytes, check.DeepEquals, cert.PrivateKey)
 }
+
+type CertificateStatusSuite struct{}
+
+var _ = check.Suite(&CertificateStatusSuite{})
+
+func (s *CertificateStatusSuite) TestCertificateStatus(c *check.C) {
+	ca, err := authority.GenerateSelfSignedCA(csr.CertificateRequest{
+		CN: "example.com",
+		CA: &csr.CAConfig{
+			Expiry: defaults.CACertificateExpiry.String(),
+		},
+	})
+	c.Assert(err, check.IsNil)
+
+	etcd, err := authority.GenerateCertificate(csr.CertificateRequest{
+		CN:    "etcd",
+		Hosts: []string{"127.0.0.1"},
+	}, ca, nil, time.Hour)
+	c.Assert(err, check.IsNil)
+
+	apiserver, err := authority.GenerateCertificate(csr.CertificateRequest{
+		CN:    "apiserver",
+		Hosts: []string{"127.0.0.1"},
+	}, ca, nil, 24*time.Hour)
+	c.Assert(err, check.IsNil)
+
+	status, err := clusterCertificateStatus(map[string]utils.TLSArchive{
+		"ca": {
+			"root": ca,
+			// key-only entries do not contribute a certificate
+			"spare": {KeyPEM: ca.KeyPEM},
+		},
+		"10.0.0.1": {
+			"etcd":      etcd,
+			"apiserver": apiserver,
+		},
+	})
+	c.Assert(err, check.IsNil)
+
+	names := make([]string, 0, len(status.Certificates))
+	for _, cert := range status.Certificates {
+		names = append(names, cert.Name)
+		c.Assert(cert.NotAfter.IsZero(), check.Equals, false)
+	}
+	c.Assert(names, check.DeepEquals, []string{
+		"10.0.0.1/apiserver", "10.0.0.1/etcd", "ca/root",
+	})
+
+	// the etcd certificate expires first and determines the summary
+	c.Assert(status.SoonestExpiry, check.Equals, status.Certificates[1].NotAfter)
+	c.Assert(status.SoonestExpiry.Before(time.Now().Add(2*time.Hour)), check.Equals, true)
+
+	// invalid certificate data results in an error
+	_, err = clusterCertificateStatus(map[string]utils.TLSArchive{
+		"ca": {
+			"root": {CertPEM: []byte("invalid")},
+		},
+	})
+	c.Assert(err, check.NotNil)
+}
@@ -17,12 +17,17 @@ limitations under the License.
 package opsservice
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 
+	"github.com/gravitational/gravity/lib/archive"
 	"github.com/gravitational/gravity/lib/constants"
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/ops"
@@ -110,6 +115,85 @@ func (s *site) getOperationLogs(key ops.SiteOperationKey) (io.ReadCloser, error)
 	return tailReader, nil
 }
 
+// getOperationLogsArchive packages the operation's progress history, plan
+// and collected agent output into a tar.gz archive for support bundles
+func (s *site) getOperationLogsArchive(key ops.SiteOperationKey) (io.ReadCloser, error) {
+	operation, err := s.backend().GetSiteOperation(key.SiteDomain, key.OperationID)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	items, err := s.operationArchiveItems(key, operation)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var buf bytes.Buffer
+	gzWriter := gzip.NewWriter(&buf)
+	appender := archive.NewTarAppender(gzWriter)
+	if err := appender.Add(items...); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := appender.Close(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := gzWriter.Close(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return ioutil.NopCloser(&buf), nil
+}
+
+// operationArchiveItems collects the contents of the operation logs archive.
+// The progress entry, plan and log file are optional as not every operation
+// has them
+func (s *site) operationArchiveItems(key ops.SiteOperationKey, operation *storage.SiteOperation) (items []*archive.Item, err error) {
+	item, err := jsonArchiveItem("operation.json", operation)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	items = append(items, item)
+
+	progress, err := s.backend().GetLastProgressEntry(key.SiteDomain, key.OperationID)
+	if err != nil && !trace.IsNotFound(err) {
+		return nil, trace.Wrap(err)
+	}
+	if progress != nil {
+		item, err := jsonArchiveItem("progress.json", progress)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		items = append(items, item)
+	}
+
+	plan, err := s.backend().GetOperationPlan(key.SiteDomain, key.OperationID)
+	if err != nil && !trace.IsNotFound(err) {
+		return nil, trace.Wrap(err)
+	}
+	if plan != nil {
+		item, err := jsonArchiveItem("plan.json", plan)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		items = append(items, item)
+	}
+
+	logs, err := ioutil.ReadFile(s.operationLogPath(key))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, trace.ConvertSystemError(err)
+	}
+	if logs != nil {
+		items = append(items, archive.ItemFromString("operation.log", string(logs)))
+	}
+	return items, nil
+}
+
+// jsonArchiveItem returns an archive item with the JSON-encoded value
+func jsonArchiveItem(name string, value interface{}) (*archive.Item, error) {
+	data, err := json.MarshalIndent(value, "", "\t")
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return archive.ItemFromString(name, string(data)), nil
+}
+
 // createLogEntry appends the provided log entry to the operation's log file
 func (s *site) createLogEntry(key ops.SiteOperationKey, entry ops.LogEntry) error {
 	// verify the operation exists
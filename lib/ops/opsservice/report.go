@@ -341,10 +341,37 @@ func collectOperationsLogs(site site, dir string) error {
 		if err != nil {
 			log.Errorf("failed to collect logs for %q: %v", op.Type, trace.DebugReport(err))
 		}
+		err = collectOperationInfo(site, operation, reportWriter)
+		if err != nil {
+			log.Errorf("failed to collect info for %q: %v", op.Type, trace.DebugReport(err))
+		}
 	}
 	return nil
 }
 
+// collectOperationInfo writes the JSON-encoded operation and its progress
+// using the specified writer so the support bundle is self-contained
+func collectOperationInfo(site site, operation ops.SiteOperation, reportWriter report.FileWriter) error {
+	// the progress entry is optional in the exported document so its
+	// absence does not fail the collection
+	progress, err := site.service.GetSiteOperationProgress(operation.Key())
+	if err != nil {
+		log.WithError(err).Warnf("Failed to query progress for %v.", operation.Key())
+	}
+	bytes, err := ops.ExportOperation(&operation, progress)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	w, err := reportWriter.NewWriter(fmt.Sprintf(opInfoFilename, operation.Type, operation.ID))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer w.Close()
+
+	_, err = w.Write(bytes)
+	return trace.Wrap(err)
+}
+
 // collectClusterInfo returns JSON-formatted cluster information
 func collectClusterInfo(s storage.Site) collectorFn {
 	return func(_ context.Context, reportWriter report.FileWriter, _ site) error {
@@ -429,4 +456,7 @@ const (
 	// opLogsFilename defines the file pattern that stores operation log for a particular
 	// cluster operation
 	opLogsFilename = "%v.%v"
+	// opInfoFilename defines the file pattern that stores the JSON-encoded
+	// state of a particular cluster operation
+	opInfoFilename = "%v.%v.json"
 )
@@ -56,6 +56,7 @@ import (
 	"github.com/gravitational/trace"
 	"github.com/mailgun/timetools"
 	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -462,6 +463,49 @@ func (o *Operator) GetExpandToken(key ops.SiteKey) (*storage.ProvisioningToken,
 	return nil, trace.NotFound("join token for %v not found", key.SiteDomain)
 }
 
+// RotateJoinToken generates a new expand token for the cluster and replaces
+// the existing one. The old token is not removed right away but expires
+// after a grace window (defaults.JoinTokenGraceWindow) so agents that are
+// joining with it are not locked out mid-operation
+func (o *Operator) RotateJoinToken(ctx context.Context, key ops.SiteKey) (*storage.ProvisioningToken, error) {
+	if err := key.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	oldToken, err := o.GetExpandToken(key)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	token, err := users.CryptoRandomToken(defaults.ProvisioningTokenBytes)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	newToken := storage.ProvisioningToken{
+		Token:       token,
+		AccountID:   key.AccountID,
+		SiteDomain:  key.SiteDomain,
+		Type:        storage.ProvisioningTokenTypeExpand,
+		OperationID: oldToken.OperationID,
+		UserEmail:   oldToken.UserEmail,
+	}
+	if _, err := o.users().CreateProvisioningToken(newToken); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	// re-create the old token with an expiry so it stays valid only for
+	// the grace window
+	if err := o.backend().DeleteProvisioningToken(oldToken.Token); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	oldToken.Expires = o.cfg.Clock.UtcNow().Add(defaults.JoinTokenGraceWindow)
+	if _, err := o.users().CreateProvisioningToken(*oldToken); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	o.cachedProvisioningTokenMutex.Lock()
+	o.cachedProvisioningToken = newToken.Token
+	o.cachedProvisioningTokenMutex.Unlock()
+	o.Infof("Rotated join token for %v.", key.SiteDomain)
+	return &newToken, nil
+}
+
 func (o *Operator) GetTrustedClusterToken(key ops.SiteKey) (storage.Token, error) {
 	tokens, err := o.cfg.Users.GetAPIKeys(constants.GatekeeperUser)
 	if err != nil {
@@ -1097,7 +1141,11 @@ func (o *Operator) SetOperationState(ctx context.Context, key ops.SiteOperationK
 		return trace.Wrap(err)
 	}
 	if req.Progress != nil {
-		err := o.CreateProgressEntry(key, *req.Progress)
+		progress := *req.Progress
+		if progress.Code == "" {
+			progress.Code = req.Code
+		}
+		err := o.CreateProgressEntry(key, progress)
 		if err != nil {
 			return trace.Wrap(err)
 		}
@@ -1162,6 +1210,16 @@ func (o *Operator) GetSiteOperationLogs(key ops.SiteOperationKey) (io.ReadCloser
 	return site.getOperationLogs(key)
 }
 
+// GetOperationLogsArchive returns a tar.gz archive with the operation's
+// progress history, plan and collected agent output
+func (o *Operator) GetOperationLogsArchive(key ops.SiteOperationKey) (io.ReadCloser, error) {
+	site, err := o.openSite(key.SiteKey())
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return site.getOperationLogsArchive(key)
+}
+
 // CreateLogEntry appends the provided log entry to the operation's log file
 func (o *Operator) CreateLogEntry(key ops.SiteOperationKey, entry ops.LogEntry) error {
 	site, err := o.openSite(key.SiteKey())
@@ -1448,6 +1506,20 @@ func (o *Operator) GetClusterNodes(key ops.SiteKey) ([]ops.Node, error) {
 	return result, nil
 }
 
+// GetClusterCapacity returns aggregate resource capacity across
+// the cluster's nodes
+func (o *Operator) GetClusterCapacity(ctx context.Context, key ops.SiteKey) (*ops.ClusterCapacity, error) {
+	client, err := o.GetKubeClient()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	nodeList, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return ops.ClusterCapacityFromNodes(nodeList.Items), nil
+}
+
 // EmitAuditEvent saves the provided event in the audit log.
 func (o *Operator) EmitAuditEvent(ctx context.Context, req ops.AuditEventRequest) error {
 	err := req.Check()
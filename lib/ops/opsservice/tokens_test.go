@@ -0,0 +1,106 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"context"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/ops/suite"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/storage"
+
+	. "gopkg.in/check.v1"
+)
+
+type TokensSuite struct {
+	backend  storage.Backend
+	operator *Operator
+	cluster  *ops.Site
+}
+
+var _ = Suite(&TokensSuite{})
+
+func (s *TokensSuite) SetUpTest(c *C) {
+	services := SetupTestServices(c)
+	s.backend = services.Backend
+	s.operator = services.Operator
+
+	opsSuite := &suite.OpsSuite{}
+	testApp, err := opsSuite.SetUpTestPackage(services.Apps, services.Packages, c)
+	c.Assert(err, IsNil)
+
+	acct, err := s.operator.CreateAccount(ops.NewAccountRequest{
+		Org: "testing",
+	})
+	c.Assert(err, IsNil)
+
+	s.cluster, err = s.operator.CreateSite(ops.NewSiteRequest{
+		AccountID:  acct.ID,
+		AppPackage: testApp.String(),
+		Provider:   schema.ProvisionerOnPrem,
+		DomainName: "test.localdomain",
+	})
+	c.Assert(err, IsNil)
+
+	_, err = s.operator.CreateSiteInstallOperation(context.TODO(), ops.CreateSiteInstallOperationRequest{
+		AccountID:   acct.ID,
+		SiteDomain:  s.cluster.Domain,
+		Variables:   storage.OperationVariables{},
+		Provisioner: schema.ProvisionerOnPrem,
+	})
+	c.Assert(err, IsNil)
+}
+
+func (s *TokensSuite) TestRotateJoinToken(c *C) {
+	oldToken, err := s.operator.GetExpandToken(s.cluster.Key())
+	c.Assert(err, IsNil)
+
+	newToken, err := s.operator.RotateJoinToken(context.TODO(), s.cluster.Key())
+	c.Assert(err, IsNil)
+	c.Assert(newToken.Token, Not(Equals), oldToken.Token)
+	// the new token is the long-lived expand token now
+	c.Assert(newToken.Expires.IsZero(), Equals, true)
+	token, err := s.operator.GetExpandToken(s.cluster.Key())
+	c.Assert(err, IsNil)
+	c.Assert(token.Token, Equals, newToken.Token)
+}
+
+func (s *TokensSuite) TestRotateJoinTokenGraceWindow(c *C) {
+	oldToken, err := s.operator.GetExpandToken(s.cluster.Key())
+	c.Assert(err, IsNil)
+
+	before := time.Now().UTC()
+	_, err = s.operator.RotateJoinToken(context.TODO(), s.cluster.Key())
+	c.Assert(err, IsNil)
+
+	// the old token is still valid during the grace window so in-flight
+	// joins can complete
+	rotated, err := s.backend.GetProvisioningToken(oldToken.Token)
+	c.Assert(err, IsNil)
+	c.Assert(rotated.Expires.IsZero(), Equals, false)
+	// ...but expires once the grace window has passed
+	c.Assert(rotated.Expires.After(before), Equals, true)
+	c.Assert(rotated.Expires.Before(before.Add(defaults.JoinTokenGraceWindow+time.Minute)), Equals, true)
+}
+
+func (s *TokensSuite) TestRotateJoinTokenInvalidKey(c *C) {
+	_, err := s.operator.RotateJoinToken(context.TODO(), ops.SiteKey{})
+	c.Assert(err, NotNil)
+}
@@ -0,0 +1,254 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ops
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+)
+
+// SubscribeOperationState subscribes the caller to state updates of the
+// operation specified with key. All subscribers of the same operation
+// share a single poller so many concurrent watchers (UI, CLI, logs) do
+// not multiply the load on the backend.
+//
+// The returned channel immediately receives the operation's current state
+// if the shared poller has already observed one, then every subsequent
+// change. The channel is closed once the operation reaches a terminal
+// state or ctx is canceled. The shared poller stops when its last
+// subscriber has left.
+//
+// Unlike WatchProgress, which drives a dedicated poller per caller, this
+// is intended for operations watched by many consumers at once
+func SubscribeOperationState(ctx context.Context, key SiteOperationKey, operator Operator) (<-chan ProgressEntry, error) {
+	if err := key.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return operationStatePollers.subscribe(ctx, key, operator), nil
+}
+
+// operationStatePollers shares a single state poller between all
+// subscribers of the same operation
+var operationStatePollers = &operationStateRegistry{
+	pollers:  make(map[SiteOperationKey]*operationStatePoller),
+	interval: defaults.ProgressPollTimeout,
+}
+
+// operationStateRegistry tracks the active operation state pollers
+// keyed by operation
+type operationStateRegistry struct {
+	mu       sync.Mutex
+	pollers  map[SiteOperationKey]*operationStatePoller
+	interval time.Duration
+}
+
+// subscribe attaches a new subscriber to the poller of the specified
+// operation, starting the poller if the operation has no subscribers yet
+func (r *operationStateRegistry) subscribe(ctx context.Context, key SiteOperationKey, operator Operator) <-chan ProgressEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if poller, ok := r.pollers[key]; ok {
+		if updates, ok := poller.addSubscriber(ctx); ok {
+			return updates
+		}
+		// the poller has already stopped, replace it below
+		delete(r.pollers, key)
+	}
+	poller := newOperationStatePoller(key, operator, r)
+	r.pollers[key] = poller
+	updates, _ := poller.addSubscriber(ctx)
+	return updates
+}
+
+// remove drops the specified poller from the registry once it has stopped.
+// The poller is passed explicitly so a stopped poller cannot remove the
+// fresh one that may have replaced it under the same key
+func (r *operationStateRegistry) remove(key SiteOperationKey, poller *operationStatePoller) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pollers[key] == poller {
+		delete(r.pollers, key)
+	}
+}
+
+// newOperationStatePoller creates and starts a state poller for the
+// specified operation
+func newOperationStatePoller(key SiteOperationKey, operator Operator, registry *operationStateRegistry) *operationStatePoller {
+	poller := &operationStatePoller{
+		key:         key,
+		operator:    operator,
+		registry:    registry,
+		interval:    registry.interval,
+		subscribers: make(map[int64]chan ProgressEntry),
+		stopc:       make(chan struct{}),
+	}
+	go poller.poll()
+	return poller
+}
+
+// operationStatePoller polls the state of a single operation and fans the
+// updates out to all of its subscribers
+type operationStatePoller struct {
+	key      SiteOperationKey
+	operator Operator
+	registry *operationStateRegistry
+	interval time.Duration
+
+	// mu guards the subscriber set and the delivery state below
+	mu          sync.Mutex
+	subscribers map[int64]chan ProgressEntry
+	nextID      int64
+	// last is the most recently observed state, delivered to late
+	// subscribers upon subscription
+	last *ProgressEntry
+	// terminal is set once the operation has finished and the final
+	// state has been delivered
+	terminal bool
+	// stopped is set when the poller is told to stop after its last
+	// subscriber has left
+	stopped bool
+	stopc   chan struct{}
+}
+
+// addSubscriber registers a new subscriber deregistering it when ctx is
+// canceled. Returns ok=false if the poller has already stopped and cannot
+// accept subscribers
+func (p *operationStatePoller) addSubscriber(ctx context.Context) (updates <-chan ProgressEntry, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopped && !p.terminal {
+		return nil, false
+	}
+	// buffer a single entry so a slow subscriber never blocks the fan-out
+	ch := make(chan ProgressEntry, 1)
+	if p.last != nil {
+		// late subscribers receive the current state right away
+		ch <- *p.last
+	}
+	if p.terminal {
+		// the operation has finished: deliver the final state only
+		close(ch)
+		return ch, true
+	}
+	id := p.nextID
+	p.nextID++
+	p.subscribers[id] = ch
+	go func() {
+		select {
+		case <-ctx.Done():
+			p.removeSubscriber(id)
+		case <-p.stopc:
+		}
+	}()
+	return ch, true
+}
+
+// removeSubscriber deregisters the specified subscriber, stopping the
+// poller when no subscribers remain
+func (p *operationStatePoller) removeSubscriber(id int64) {
+	p.mu.Lock()
+	if ch, ok := p.subscribers[id]; ok {
+		delete(p.subscribers, id)
+		close(ch)
+	}
+	stop := !p.terminal && !p.stopped && len(p.subscribers) == 0
+	if stop {
+		p.stopped = true
+		close(p.stopc)
+	}
+	p.mu.Unlock()
+	if stop {
+		p.registry.remove(p.key, p)
+	}
+}
+
+// poll queries the operation state on every tick and broadcasts changed
+// entries to the subscribers until the operation reaches a terminal state
+// or the poller is stopped
+func (p *operationStatePoller) poll() {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		operation, progress, err := GetOperationWithProgress(p.key, p.operator)
+		if err != nil {
+			log.WithError(err).Warn("Failed to query operation progress.")
+		} else {
+			terminal := operation.IsFinished() ||
+				progress.IsCompleted() || progress.IsFailed()
+			p.broadcast(*progress, terminal)
+			if terminal {
+				p.registry.remove(p.key, p)
+				return
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-p.stopc:
+			return
+		}
+	}
+}
+
+// broadcast delivers the entry to every subscriber if it differs from the
+// previously delivered one, closing the subscriber channels when the
+// operation has reached a terminal state
+func (p *operationStatePoller) broadcast(entry ProgressEntry, terminal bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	changed := p.last == nil || !p.last.IsEqual(entry)
+	p.last = &entry
+	for _, ch := range p.subscribers {
+		if changed {
+			sendLatest(ch, entry)
+		}
+		if terminal {
+			close(ch)
+		}
+	}
+	if terminal {
+		p.terminal = true
+		p.subscribers = make(map[int64]chan ProgressEntry)
+		if !p.stopped {
+			// release the subscriber watchdog goroutines
+			p.stopped = true
+			close(p.stopc)
+		}
+	}
+}
+
+// sendLatest places the entry into the subscriber channel replacing the
+// previous entry if the subscriber has not consumed it yet, so slow
+// subscribers observe the latest state instead of blocking the fan-out
+func sendLatest(ch chan ProgressEntry, entry ProgressEntry) {
+	for {
+		select {
+		case ch <- entry:
+			return
+		default:
+			select {
+			case <-ch:
+			default:
+			}
+		}
+	}
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ops
+
+import (
+	"context"
+	"time"
+
+	"github.com/gravitational/trace"
+	"gopkg.in/check.v1"
+)
+
+type SubscriptionSuite struct{}
+
+var _ = check.Suite(&SubscriptionSuite{})
+
+func (s *SubscriptionSuite) TestSubscribersShareOnePoller(c *check.C) {
+	registry := newTestRegistry()
+	operator := &fakePollOperator{
+		entries: []ProgressEntry{
+			{Completion: 10, Message: "pulling packages"},
+			{Completion: 50, Message: "installing"},
+			{Completion: 100, Message: "done", State: OperationStateCompleted},
+		},
+	}
+	first := registry.subscribe(context.TODO(), testOperationKey(), operator)
+	second := registry.subscribe(context.TODO(), testOperationKey(), operator)
+	c.Assert(pollerCount(registry), check.Equals, 1)
+
+	// both subscribers observe updates until the operation completes and
+	// their channels are closed
+	for _, updates := range []<-chan ProgressEntry{first, second} {
+		var last ProgressEntry
+		for entry := range updates {
+			last = entry
+		}
+		c.Assert(last.Message, check.Equals, "done")
+	}
+	waitForEmptyRegistry(c, registry)
+}
+
+func (s *SubscriptionSuite) TestLateSubscriberReceivesCurrentState(c *check.C) {
+	registry := newTestRegistry()
+	operator := &fakePollOperator{
+		// the operation never reaches a terminal state
+		entries: []ProgressEntry{
+			{Completion: 10, Message: "pulling packages"},
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	first := registry.subscribe(ctx, testOperationKey(), operator)
+	entry := receiveEntry(c, first)
+	c.Assert(entry, check.DeepEquals, operator.entries[0])
+
+	// the late subscriber receives the current state right away without
+	// waiting for the next poll
+	second := registry.subscribe(ctx, testOperationKey(), operator)
+	entry = receiveEntry(c, second)
+	c.Assert(entry, check.DeepEquals, operator.entries[0])
+}
+
+func (s *SubscriptionSuite) TestPollerStopsWhenLastSubscriberLeaves(c *check.C) {
+	registry := newTestRegistry()
+	operator := &fakePollOperator{
+		// the operation never reaches a terminal state
+		entries: []ProgressEntry{
+			{Completion: 10, Message: "pulling packages"},
+		},
+	}
+	firstCtx, firstCancel := context.WithCancel(context.Background())
+	secondCtx, secondCancel := context.WithCancel(context.Background())
+	first := registry.subscribe(firstCtx, testOperationKey(), operator)
+	second := registry.subscribe(secondCtx, testOperationKey(), operator)
+
+	// canceling one subscriber closes its channel but keeps the shared
+	// poller running for the other
+	firstCancel()
+	for range first {
+	}
+	c.Assert(pollerCount(registry), check.Equals, 1)
+
+	// once the last subscriber leaves the poller stops and is removed
+	secondCancel()
+	for range second {
+	}
+	waitForEmptyRegistry(c, registry)
+}
+
+func (s *SubscriptionSuite) TestSubscribeOperationState(c *check.C) {
+	_, err := SubscribeOperationState(context.TODO(), SiteOperationKey{}, &fakePollOperator{})
+	c.Assert(trace.IsBadParameter(err), check.Equals, true,
+		check.Commentf("unexpected error: %v", err))
+
+	operator := &fakePollOperator{
+		entries: []ProgressEntry{
+			{Completion: 100, Message: "done", State: OperationStateCompleted},
+		},
+	}
+	updates, err := SubscribeOperationState(context.TODO(), testOperationKey(), operator)
+	c.Assert(err, check.IsNil)
+	entry := receiveEntry(c, updates)
+	c.Assert(entry.Message, check.Equals, "done")
+	for range updates {
+	}
+}
+
+func newTestRegistry() *operationStateRegistry {
+	return &operationStateRegistry{
+		pollers:  make(map[SiteOperationKey]*operationStatePoller),
+		interval: time.Millisecond,
+	}
+}
+
+func pollerCount(registry *operationStateRegistry) int {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	return len(registry.pollers)
+}
+
+// waitForEmptyRegistry waits for the stopped pollers to deregister as the
+// removal happens asynchronously with the subscriber channel closing
+func waitForEmptyRegistry(c *check.C, registry *operationStateRegistry) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if pollerCount(registry) == 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	c.Fatalf("poller registry was not drained: %v pollers left", pollerCount(registry))
+}
+
+// receiveEntry reads a single progress entry from the channel failing the
+// test if none arrives in time
+func receiveEntry(c *check.C, updates <-chan ProgressEntry) ProgressEntry {
+	select {
+	case entry, ok := <-updates:
+		c.Assert(ok, check.Equals, true, check.Commentf("channel closed unexpectedly"))
+		return entry
+	case <-time.After(5 * time.Second):
+		c.Fatal("timeout waiting for a progress entry")
+	}
+	return ProgressEntry{}
+}
@@ -19,11 +19,15 @@ limitations under the License.
 package suite
 
 import (
+	"archive/tar"
+	"compress/gzip"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/url"
+	"path/filepath"
 	"strings"
 
 	"github.com/gravitational/gravity/lib/app"
@@ -142,6 +146,18 @@ func (s *OpsSuite) SitesCRUD(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(logStream.Close(), IsNil)
 
+	// the operation logs archive carries the operation metadata and the
+	// progress history
+	archiveStream, err := s.O.GetOperationLogsArchive(*opKey)
+	c.Assert(err, IsNil)
+	entries := readOperationArchive(c, archiveStream)
+	c.Assert(archiveStream.Close(), IsNil)
+	c.Assert(entries["progress.json"], Not(Equals), "",
+		Commentf("archive entries: %v", entries))
+	var storedOperation storage.SiteOperation
+	c.Assert(json.Unmarshal([]byte(entries["operation.json"]), &storedOperation), IsNil)
+	c.Assert(storedOperation.ID, Equals, opKey.OperationID)
+
 	// download crashreport
 	reportStream, err := s.O.GetSiteReport(context.TODO(), ops.GetClusterReportRequest{SiteKey: opKey.SiteKey()})
 	c.Assert(err, IsNil)
@@ -160,6 +176,30 @@ func (s *OpsSuite) SitesCRUD(c *C) {
 
 }
 
+// readOperationArchive returns the contents of the operation logs archive
+// keyed by entry name
+func readOperationArchive(c *C, source io.Reader) map[string]string {
+	gzReader, err := gzip.NewReader(source)
+	c.Assert(err, IsNil)
+	defer gzReader.Close()
+	entries := map[string]string{}
+	tarReader := tar.NewReader(gzReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		c.Assert(err, IsNil)
+		if header.FileInfo().IsDir() {
+			continue
+		}
+		data, err := ioutil.ReadAll(tarReader)
+		c.Assert(err, IsNil)
+		entries[filepath.Base(header.Name)] = string(data)
+	}
+	return entries
+}
+
 func (s *OpsSuite) InstallInstructions(c *C) {
 	a, err := s.O.CreateAccount(ops.NewAccountRequest{
 		Org: "example.com",
@@ -0,0 +1,102 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ops
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	// OperationOutcomeCompleted means the operation finished successfully
+	OperationOutcomeCompleted = "completed"
+	// OperationOutcomeFailed means the operation finished with an error
+	OperationOutcomeFailed = "failed"
+	// OperationOutcomeInProgress means the operation has not finished yet
+	OperationOutcomeInProgress = "in_progress"
+)
+
+// TimelineEntry describes a single operation in a cluster's operation timeline
+type TimelineEntry struct {
+	// Operation is the operation this entry describes
+	Operation SiteOperation `json:"operation"`
+	// Duration is the time the operation took, derived from its progress
+	// timestamps; for a still-running operation the current time is used
+	// as the end
+	Duration time.Duration `json:"duration"`
+	// Outcome describes how the operation ended, one of the
+	// OperationOutcome* values
+	Outcome string `json:"outcome"`
+	// Gap is the time elapsed between the end of the previous operation
+	// in the timeline and the start of this one; zero for the first entry
+	// and for operations that overlap with the previous one
+	Gap time.Duration `json:"gap"`
+}
+
+// BuildOperationTimeline converts the provided list of operations into a
+// chronological timeline with per-operation durations, outcomes and the
+// idle gaps between consecutive operations.
+//
+// It is a pure function operating on already-fetched operations - the only
+// external input is the current time used as the end for operations that
+// are still in progress.
+func BuildOperationTimeline(operations []SiteOperation) []TimelineEntry {
+	return buildOperationTimeline(operations, time.Now().UTC())
+}
+
+func buildOperationTimeline(operations []SiteOperation, now time.Time) []TimelineEntry {
+	if len(operations) == 0 {
+		return nil
+	}
+	sorted := make([]SiteOperation, len(operations))
+	copy(sorted, operations)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Created.Before(sorted[j].Created)
+	})
+	timeline := make([]TimelineEntry, 0, len(sorted))
+	var previousEnd time.Time
+	for _, operation := range sorted {
+		end := operation.Updated
+		if !operation.IsFinished() {
+			end = now
+		}
+		entry := TimelineEntry{
+			Operation: operation,
+			Duration:  end.Sub(operation.Created),
+			Outcome:   operationOutcome(operation),
+		}
+		if !previousEnd.IsZero() && operation.Created.After(previousEnd) {
+			entry.Gap = operation.Created.Sub(previousEnd)
+		}
+		timeline = append(timeline, entry)
+		if end.After(previousEnd) {
+			previousEnd = end
+		}
+	}
+	return timeline
+}
+
+func operationOutcome(operation SiteOperation) string {
+	switch {
+	case operation.IsCompleted():
+		return OperationOutcomeCompleted
+	case operation.IsFailed():
+		return OperationOutcomeFailed
+	default:
+		return OperationOutcomeInProgress
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ops
+
+import (
+	"time"
+
+	check "gopkg.in/check.v1"
+)
+
+type TimelineSuite struct{}
+
+var _ = check.Suite(&TimelineSuite{})
+
+func (s *TimelineSuite) TestBuildOperationTimeline(c *check.C) {
+	base := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := base.Add(10 * time.Hour)
+	operations := []SiteOperation{
+		{
+			ID:      "op2",
+			Created: base.Add(2 * time.Hour),
+			Updated: base.Add(3 * time.Hour),
+			State:   OperationStateFailed,
+		},
+		{
+			ID:      "op1",
+			Created: base,
+			Updated: base.Add(time.Hour),
+			State:   OperationStateCompleted,
+		},
+		{
+			ID:      "op3",
+			Created: base.Add(4 * time.Hour),
+			Updated: base.Add(5 * time.Hour),
+			State:   OperationStateUpdateInProgress,
+		},
+	}
+	timeline := buildOperationTimeline(operations, now)
+	c.Assert(timeline, check.HasLen, 3)
+	// operations are sorted chronologically
+	c.Assert(timeline[0].Operation.ID, check.Equals, "op1")
+	c.Assert(timeline[1].Operation.ID, check.Equals, "op2")
+	c.Assert(timeline[2].Operation.ID, check.Equals, "op3")
+	// durations come from the progress timestamps, the still-running
+	// operation uses the current time as the end
+	c.Assert(timeline[0].Duration, check.Equals, time.Hour)
+	c.Assert(timeline[1].Duration, check.Equals, time.Hour)
+	c.Assert(timeline[2].Duration, check.Equals, 6*time.Hour)
+	// outcomes reflect the operation states
+	c.Assert(timeline[0].Outcome, check.Equals, OperationOutcomeCompleted)
+	c.Assert(timeline[1].Outcome, check.Equals, OperationOutcomeFailed)
+	c.Assert(timeline[2].Outcome, check.Equals, OperationOutcomeInProgress)
+	// gaps measure the idle time between consecutive operations
+	c.Assert(timeline[0].Gap, check.Equals, time.Duration(0))
+	c.Assert(timeline[1].Gap, check.Equals, time.Hour)
+	c.Assert(timeline[2].Gap, check.Equals, time.Hour)
+}
+
+func (s *TimelineSuite) TestBuildOperationTimelineOverlap(c *check.C) {
+	base := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	operations := []SiteOperation{
+		{
+			ID:      "op1",
+			Created: base,
+			Updated: base.Add(2 * time.Hour),
+			State:   OperationStateCompleted,
+		},
+		{
+			ID:      "op2",
+			Created: base.Add(time.Hour),
+			Updated: base.Add(3 * time.Hour),
+			State:   OperationStateCompleted,
+		},
+	}
+	timeline := buildOperationTimeline(operations, base.Add(4*time.Hour))
+	c.Assert(timeline, check.HasLen, 2)
+	// the second operation started before the first one ended so
+	// there is no gap
+	c.Assert(timeline[1].Gap, check.Equals, time.Duration(0))
+}
+
+func (s *TimelineSuite) TestBuildOperationTimelineEmpty(c *check.C) {
+	c.Assert(BuildOperationTimeline(nil), check.IsNil)
+}
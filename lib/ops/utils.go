@@ -18,8 +18,12 @@ package ops
 
 import (
 	"context"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gravitational/gravity/lib/constants"
@@ -27,13 +31,18 @@ import (
 	"github.com/gravitational/gravity/lib/pack"
 	"github.com/gravitational/gravity/lib/pack/encryptedpack"
 	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/utils"
 
 	licenseapi "github.com/gravitational/license"
 	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
 )
 
 // GetInstallOperation returns an install operation for the specified siteKey
 func GetInstallOperation(siteKey SiteKey, operator Operator) (op *SiteOperation, progress *ProgressEntry, err error) {
+	if err := siteKey.Check(); err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
 	operations, err := operator.GetSiteOperations(siteKey, OperationsFilter{
 		Types: []string{OperationInstall},
 	})
@@ -55,6 +64,9 @@ func GetInstallOperation(siteKey SiteKey, operator Operator) (op *SiteOperation,
 
 // GetLastUninstallOperation returns the last uninstall operation for the specified siteKey
 func GetLastUninstallOperation(siteKey SiteKey, operator Operator) (op *SiteOperation, progress *ProgressEntry, err error) {
+	if err := siteKey.Check(); err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
 	operations, err := operator.GetSiteOperations(siteKey, OperationsFilter{
 		Types: []string{OperationUninstall},
 		Last:  true,
@@ -78,6 +90,9 @@ func GetLastUninstallOperation(siteKey SiteKey, operator Operator) (op *SiteOper
 
 // GetLastCompletedUpdateOperation returns the last completed update operation
 func GetLastCompletedUpdateOperation(siteKey SiteKey, operator Operator) (op *SiteOperation, err error) {
+	if err := siteKey.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
 	operations, err := operator.GetSiteOperations(siteKey, OperationsFilter{
 		Types:    []string{OperationUpdate},
 		Last:     true,
@@ -96,6 +111,9 @@ func GetLastCompletedUpdateOperation(siteKey SiteKey, operator Operator) (op *Si
 
 // GetCompletedInstallOperation returns a completed install operation for the specified site
 func GetCompletedInstallOperation(siteKey SiteKey, operator Operator) (*SiteOperation, error) {
+	if err := siteKey.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
 	operations, err := operator.GetSiteOperations(siteKey, OperationsFilter{
 		Types:    []string{OperationInstall},
 		Last:     true,
@@ -114,6 +132,9 @@ func GetCompletedInstallOperation(siteKey SiteKey, operator Operator) (*SiteOper
 
 // GetLastOperation returns the most recent operation and its progress for the specified site
 func GetLastOperation(siteKey SiteKey, operator Operator) (op *SiteOperation, progress *ProgressEntry, err error) {
+	if err := siteKey.Check(); err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
 	operations, err := operator.GetSiteOperations(siteKey, OperationsFilter{
 		Last: true,
 	})
@@ -137,6 +158,9 @@ func GetLastOperation(siteKey SiteKey, operator Operator) (op *SiteOperation, pr
 
 // GetLastCompletedOperations returns the cluster's last completed operation
 func GetLastFinishedOperation(siteKey SiteKey, operator Operator) (op *SiteOperation, progress *ProgressEntry, err error) {
+	if err := siteKey.Check(); err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
 	operations, err := operator.GetSiteOperations(siteKey, OperationsFilter{
 		Last:     true,
 		Finished: true,
@@ -161,6 +185,9 @@ func GetLastFinishedOperation(siteKey SiteKey, operator Operator) (op *SiteOpera
 
 // GetLastUpgradeOperation returns the most recent upgrade operation or NotFound.
 func GetLastUpgradeOperation(siteKey SiteKey, operator Operator) (*SiteOperation, error) {
+	if err := siteKey.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
 	operations, err := operator.GetSiteOperations(siteKey, OperationsFilter{
 		Types: []string{OperationUpdate},
 		Last:  true,
@@ -180,6 +207,9 @@ func GetLastUpgradeOperation(siteKey SiteKey, operator Operator) (*SiteOperation
 //
 // If there're no operations or the last operation is not of type 'shrink', returns NotFound error
 func GetLastShrinkOperation(siteKey SiteKey, operator Operator) (*SiteOperation, error) {
+	if err := siteKey.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
 	operations, err := operator.GetSiteOperations(siteKey, OperationsFilter{
 		Types: []string{OperationShrink},
 		Last:  true,
@@ -197,6 +227,9 @@ func GetLastShrinkOperation(siteKey SiteKey, operator Operator) (*SiteOperation,
 
 // GetOperationWithProgress returns the operation and its progress for the provided operation key
 func GetOperationWithProgress(opKey SiteOperationKey, operator Operator) (*SiteOperation, *ProgressEntry, error) {
+	if err := opKey.Check(); err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
 	operation, err := operator.GetSiteOperation(opKey)
 	if err != nil {
 		return nil, nil, trace.Wrap(err)
@@ -208,8 +241,121 @@ func GetOperationWithProgress(opKey SiteOperationKey, operator Operator) (*SiteO
 	return operation, progress, nil
 }
 
+// ProgressSource delivers progress updates for a single operation
+type ProgressSource interface {
+	// Progress returns the channel the progress updates are delivered on.
+	// The channel is closed once the operation reaches a terminal state
+	// or the watch context is canceled
+	Progress() <-chan ProgressEntry
+}
+
+// ProgressStreamer is implemented by operators that can push progress
+// updates to the caller without polling
+type ProgressStreamer interface {
+	// StreamOperationProgress streams progress updates for the specified
+	// operation until it reaches a terminal state or the context is
+	// canceled. The implementation closes the returned channel
+	StreamOperationProgress(ctx context.Context, key SiteOperationKey) (<-chan ProgressEntry, error)
+}
+
+// WatchProgress returns a progress source for the specified operation.
+//
+// The transport is selected based on the operator's capability: if the
+// operator implements ProgressStreamer, the updates are pushed by the
+// backend. Otherwise the source degrades to polling the operation with
+// GetOperationWithProgress on the specified interval, skipping entries
+// equal to the previously delivered one. A non-positive interval defaults
+// to defaults.ProgressPollTimeout.
+//
+// With either transport the progress channel is closed once the operation
+// reaches a terminal state or the provided context is canceled, so callers
+// can be written against ProgressSource alone
+func WatchProgress(ctx context.Context, key SiteOperationKey, operator Operator, interval time.Duration) (ProgressSource, error) {
+	if err := key.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if streamer, ok := operator.(ProgressStreamer); ok {
+		updates, err := streamer.StreamOperationProgress(ctx, key)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return streamProgressSource{updates: updates}, nil
+	}
+	if interval <= 0 {
+		interval = defaults.ProgressPollTimeout
+	}
+	source := &pollProgressSource{
+		updates:  make(chan ProgressEntry),
+		key:      key,
+		operator: operator,
+		interval: interval,
+	}
+	go source.poll(ctx)
+	return source, nil
+}
+
+// streamProgressSource adapts a backend push stream to ProgressSource
+type streamProgressSource struct {
+	updates <-chan ProgressEntry
+}
+
+// Progress returns the channel the backend pushes progress updates on
+func (r streamProgressSource) Progress() <-chan ProgressEntry {
+	return r.updates
+}
+
+// pollProgressSource emulates a push stream for backends that cannot
+// stream by polling the operation progress
+type pollProgressSource struct {
+	updates  chan ProgressEntry
+	key      SiteOperationKey
+	operator Operator
+	interval time.Duration
+}
+
+// Progress returns the channel the polled progress updates are delivered on
+func (r *pollProgressSource) Progress() <-chan ProgressEntry {
+	return r.updates
+}
+
+// poll queries the operation progress on every tick and forwards changed
+// entries until the operation reaches a terminal state
+func (r *pollProgressSource) poll(ctx context.Context) {
+	defer close(r.updates)
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	var last *ProgressEntry
+	for {
+		operation, progress, err := GetOperationWithProgress(r.key, r.operator)
+		if err != nil {
+			log.WithError(err).Warn("Failed to query operation progress.")
+		} else {
+			if last == nil || !last.IsEqual(*progress) {
+				select {
+				case r.updates <- *progress:
+					last = progress
+				case <-ctx.Done():
+					return
+				}
+			}
+			if operation.IsCompleted() || operation.IsFailed() ||
+				progress.IsCompleted() || progress.IsFailed() {
+				return
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
 // GetActiveOperations returns a list of currently active cluster operations
 func GetActiveOperations(siteKey SiteKey, operator Operator) (active []SiteOperation, err error) {
+	if err := siteKey.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
 	operations, err := operator.GetSiteOperations(siteKey, OperationsFilter{
 		Active: true,
 	})
@@ -231,6 +377,9 @@ func GetActiveOperations(siteKey SiteKey, operator Operator) (active []SiteOpera
 // GetActiveOperationsByType returns a list of cluster operations of the specified
 // type that are currently in progress
 func GetActiveOperationsByType(siteKey SiteKey, operator Operator, opType string) (active []SiteOperation, err error) {
+	if err := siteKey.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
 	operations, err := operator.GetSiteOperations(siteKey, OperationsFilter{
 		Types:  []string{opType},
 		Active: true,
@@ -250,6 +399,147 @@ func GetActiveOperationsByType(siteKey SiteKey, operator Operator, opType string
 	return active, nil
 }
 
+// HasActiveOperationOfType returns whether the cluster already has an active
+// operation of the specified type, along with the existing operation so
+// callers can refuse to start a duplicate. Unlike GetActiveOperationsByType,
+// the absence of an active operation is not treated as an error.
+// If multiple operations of the type are active, the most recent one is
+// returned
+func HasActiveOperationOfType(siteKey SiteKey, operator Operator, opType string) (bool, *SiteOperation, error) {
+	active, err := GetActiveOperationsByType(siteKey, operator, opType)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return false, nil, nil
+		}
+		return false, nil, trace.Wrap(err)
+	}
+	// backend is guaranteed to return operations in the last-to-first order
+	return true, &active[0], nil
+}
+
+// AccountOperationsGetter defines an interface to enumerate an account's
+// clusters and their operations
+type AccountOperationsGetter interface {
+	// GetSites returns the clusters registered for the account
+	GetSites(accountID string) ([]Site, error)
+	// GetSiteOperations returns operations for the cluster matching the filter
+	GetSiteOperations(SiteKey, OperationsFilter) (SiteOperations, error)
+}
+
+// AccountOperation describes a cluster operation annotated with the domain
+// of the cluster it belongs to
+type AccountOperation struct {
+	SiteOperation
+	// ClusterDomain is the domain name of the operation's cluster
+	ClusterDomain string
+}
+
+// GetAccountOperations aggregates the operations matching the filter across
+// all clusters of the specified account, most recent first. The per-cluster
+// fetches run in parallel. Clusters that fail to report are skipped and the
+// operations collected from the remaining clusters are returned along with
+// the aggregated error so an activity feed stays useful during partial
+// outages
+func GetAccountOperations(accountID string, operator AccountOperationsGetter, filter OperationsFilter) ([]AccountOperation, error) {
+	if accountID == "" {
+		return nil, trace.BadParameter("missing account ID")
+	}
+	clusters, err := operator.GetSites(accountID)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	type result struct {
+		domain     string
+		operations SiteOperations
+		err        error
+	}
+	results := make(chan result, len(clusters))
+	for _, cluster := range clusters {
+		go func(cluster Site) {
+			operations, err := operator.GetSiteOperations(cluster.Key(), filter)
+			results <- result{
+				domain:     cluster.Domain,
+				operations: operations,
+				err:        err,
+			}
+		}(cluster)
+	}
+	var operations []AccountOperation
+	var errors []error
+	for range clusters {
+		result := <-results
+		if result.err != nil {
+			errors = append(errors, trace.Wrap(result.err,
+				"failed to fetch operations for cluster %v", result.domain))
+			continue
+		}
+		for _, op := range result.operations {
+			operations = append(operations, AccountOperation{
+				SiteOperation: SiteOperation(op),
+				ClusterDomain: result.domain,
+			})
+		}
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		return operations[i].Created.After(operations[j].Created)
+	})
+	return operations, trace.NewAggregate(errors...)
+}
+
+// OperationsGetter defines an interface to fetch cluster operations
+type OperationsGetter interface {
+	// GetSiteOperations returns operations for the cluster matching the filter
+	GetSiteOperations(SiteKey, OperationsFilter) (SiteOperations, error)
+}
+
+// GetOperationsPage returns a single page of the cluster's operations along
+// with the continuation token to fetch the next page. The token is empty
+// when there are no more pages.
+func GetOperationsPage(siteKey SiteKey, operator OperationsGetter, pageSize int, pageToken string) (page SiteOperations, nextToken string, err error) {
+	if err := siteKey.Check(); err != nil {
+		return nil, "", trace.Wrap(err)
+	}
+	if pageSize <= 0 {
+		return nil, "", trace.BadParameter("expected a positive page size, got %v", pageSize)
+	}
+	// fetch one operation past the page boundary to learn whether
+	// another page exists
+	operations, err := operator.GetSiteOperations(siteKey, OperationsFilter{
+		StartKey: pageToken,
+		Limit:    pageSize + 1,
+	})
+	if err != nil {
+		return nil, "", trace.Wrap(err)
+	}
+	if len(operations) > pageSize {
+		operations = operations[:pageSize]
+		nextToken = operations[pageSize-1].ID
+	}
+	return operations, nextToken, nil
+}
+
+// IterateOperations streams the cluster's operations to the provided callback
+// in pages of the specified size so long histories do not need to be held
+// in memory all at once
+func IterateOperations(siteKey SiteKey, operator OperationsGetter, pageSize int, fn func(SiteOperations) error) error {
+	var token string
+	for {
+		page, nextToken, err := GetOperationsPage(siteKey, operator, pageSize, token)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if len(page) != 0 {
+			if err := fn(page); err != nil {
+				return trace.Wrap(err)
+			}
+		}
+		if nextToken == "" {
+			return nil
+		}
+		token = nextToken
+	}
+}
+
 // GetWizardOperation returns the install operation assuming that the
 // provided operator talks to an install wizard process
 func GetWizardOperation(operator Operator) (*SiteOperation, error) {
@@ -258,16 +548,45 @@ func GetWizardOperation(operator Operator) (*SiteOperation, error) {
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
-	if len(clusters) != 1 {
-		return nil, trace.BadParameter("expected 1 cluster, got: %v", clusters)
+	cluster, err := exactlyOneCluster(clusters)
+	if err != nil {
+		return nil, trace.Wrap(err)
 	}
-	op, _, err := GetInstallOperation(clusters[0].Key(), operator)
+	op, _, err := GetInstallOperation(cluster.Key(), operator)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
 	return op, nil
 }
 
+// GetInstallOperationAny returns the install operation regardless of whether
+// the provided operator talks to an install wizard process or a regular
+// cluster. The wizard single-cluster path is attempted first; if it does not
+// yield the operation, the clusters registered under the system account are
+// searched directly, so callers do not have to branch on the mode themselves.
+// Returns NotFound if neither mode has an install operation
+func GetInstallOperationAny(operator Operator) (*SiteOperation, error) {
+	op, wizardErr := GetWizardOperation(operator)
+	if wizardErr == nil {
+		return op, nil
+	}
+	clusters, err := operator.GetSites(defaults.SystemAccountID)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for i := range clusters {
+		op, _, err := GetInstallOperation(clusters[i].Key(), operator)
+		if err == nil {
+			return op, nil
+		}
+		if !trace.IsNotFound(err) {
+			return nil, trace.Wrap(err)
+		}
+	}
+	return nil, trace.NotFound(
+		"no install operation found in either wizard or cluster mode: %v", wizardErr)
+}
+
 // GetWizardCluster returns the cluster created by wizard install process
 func GetWizardCluster(operator Operator) (*Site, error) {
 	// in wizard mode there is only 1 cluster
@@ -275,15 +594,241 @@ func GetWizardCluster(operator Operator) (*Site, error) {
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
-	if len(clusters) != 1 {
-		return nil, trace.BadParameter("expected 1 cluster, got: %v", clusters)
+	cluster, err := exactlyOneCluster(clusters)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return cluster, nil
+}
+
+// SitesGetter can enumerate an account's clusters
+type SitesGetter interface {
+	// GetSites returns the clusters registered for the account
+	GetSites(accountID string) ([]Site, error)
+}
+
+// SiteFilter defines the criteria for filtering an account's clusters.
+// Empty fields match any cluster
+type SiteFilter struct {
+	// States optionally lists the cluster states to match
+	States []string
+	// Labels optionally specifies the labels a cluster must all carry
+	Labels map[string]string
+}
+
+// Match returns true if the cluster satisfies the filter
+func (r SiteFilter) Match(cluster Site) bool {
+	if len(r.States) != 0 && !utils.StringInSlice(r.States, cluster.State) {
+		return false
+	}
+	for name, value := range r.Labels {
+		if cluster.Labels[name] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// GetSitesFiltered returns the account's clusters that satisfy the
+// specified filter, e.g. the active clusters or the clusters carrying a
+// given label. The filtering happens client-side on top of GetSites so it
+// works against any operator implementation
+func GetSitesFiltered(accountID string, operator SitesGetter, filter SiteFilter) ([]Site, error) {
+	if accountID == "" {
+		return nil, trace.BadParameter("missing account ID")
+	}
+	clusters, err := operator.GetSites(accountID)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var filtered []Site
+	for _, cluster := range clusters {
+		if filter.Match(cluster) {
+			filtered = append(filtered, cluster)
+		}
+	}
+	return filtered, nil
+}
+
+// exactlyOneCluster enforces the single-cluster wizard invariant on the
+// provided cluster list: the wizard process manages exactly one cluster.
+// The returned error lists the cluster domains rather than dumping the
+// records so wizard bring-up failures are diagnosable
+func exactlyOneCluster(clusters []Site) (*Site, error) {
+	if len(clusters) == 1 {
+		return &clusters[0], nil
+	}
+	if len(clusters) == 0 {
+		return nil, trace.BadParameter("expected exactly one cluster in the " +
+			"wizard state, found none: the wizard has not created its cluster " +
+			"yet or its state directory has been reset")
+	}
+	domains := make([]string, 0, len(clusters))
+	for _, cluster := range clusters {
+		domains = append(domains, cluster.Domain)
+	}
+	return nil, trace.BadParameter("expected exactly one cluster in the "+
+		"wizard state, found %v (%v): the wizard state likely contains "+
+		"leftover clusters from a previous interrupted installation",
+		len(clusters), strings.Join(domains, ", "))
+}
+
+// ResolveWizardCluster returns the cluster created by wizard install process.
+//
+// Unlike GetWizardCluster, it tolerates leftover cluster records from prior
+// aborted runs: when multiple clusters exist, it selects the one with an
+// active install operation and only fails if none or several are found
+func ResolveWizardCluster(operator Operator) (*Site, error) {
+	clusters, err := operator.GetSites(defaults.SystemAccountID)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if len(clusters) == 0 {
+		return nil, trace.NotFound("no clusters found")
+	}
+	if len(clusters) == 1 {
+		return &clusters[0], nil
+	}
+	var matched []*Site
+	for i := range clusters {
+		active, err := GetActiveOperationsByType(clusters[i].Key(), operator, OperationInstall)
+		if err != nil && !trace.IsNotFound(err) {
+			return nil, trace.Wrap(err)
+		}
+		if len(active) != 0 {
+			matched = append(matched, &clusters[i])
+		}
+	}
+	if len(matched) == 0 {
+		return nil, trace.NotFound("no cluster with an active install operation found")
+	}
+	if len(matched) > 1 {
+		return nil, trace.BadParameter("expected 1 cluster with an active install operation, got: %v", matched)
+	}
+	return matched[0], nil
+}
+
+// ResolveWizardOperation returns the install operation assuming that the
+// provided operator talks to an install wizard process.
+//
+// See ResolveWizardCluster for how the cluster is selected when leftover
+// records from prior aborted runs are present
+func ResolveWizardOperation(operator Operator) (*SiteOperation, error) {
+	cluster, err := ResolveWizardCluster(operator)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	op, _, err := GetInstallOperation(cluster.Key(), operator)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return op, nil
+}
+
+// OrphanedOperationsGetter enumerates an account's clusters and operations.
+// It provides the queries FindOrphanedOperations needs to detect operations
+// that reference a missing cluster
+type OrphanedOperationsGetter interface {
+	// GetSites returns the account's clusters
+	GetSites(accountID string) ([]Site, error)
+	// GetAccountOperations returns all operations recorded for the account,
+	// including operations of clusters that no longer exist
+	GetAccountOperations(accountID string) (SiteOperations, error)
+}
+
+// FindOrphanedOperations returns the operations from the specified account
+// whose referenced cluster no longer exists. Such operations can be left
+// behind when a cluster is deleted mid-operation and confuse status
+// reporting until cleaned up. The helper only reports the orphans and does
+// not mutate any state
+func FindOrphanedOperations(accountID string, operator OrphanedOperationsGetter) (orphaned []SiteOperation, err error) {
+	clusters, err := operator.GetSites(accountID)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	domains := make(map[string]bool, len(clusters))
+	for _, cluster := range clusters {
+		domains[cluster.Domain] = true
+	}
+	operations, err := operator.GetAccountOperations(accountID)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, operation := range operations {
+		if !domains[operation.SiteDomain] {
+			orphaned = append(orphaned, SiteOperation(operation))
+		}
+	}
+	return orphaned, nil
+}
+
+// StaleOperationsGetter provides the queries FindStaleOperations needs to
+// detect operations stuck without progress
+type StaleOperationsGetter interface {
+	// GetSiteOperations returns operations for the cluster matching the filter
+	GetSiteOperations(SiteKey, OperationsFilter) (SiteOperations, error)
+	// GetSiteOperationProgress returns the last progress entry of the operation
+	GetSiteOperationProgress(SiteOperationKey) (*ProgressEntry, error)
+}
+
+// StaleOperation describes an active operation that has shown no progress
+// for a while
+type StaleOperation struct {
+	// SiteOperation is the stale operation
+	SiteOperation
+	// LastProgress is when the operation last reported progress
+	LastProgress time.Time
+}
+
+// FindStaleOperations returns the cluster's active operations whose last
+// progress update is older than the olderThan threshold. After a crash such
+// operations linger in progress with no active work behind them; the helper
+// lets recovery tooling identify them so they can be failed explicitly.
+// Operations without a progress entry are judged by their last update time
+func FindStaleOperations(siteKey SiteKey, operator StaleOperationsGetter, olderThan time.Duration) ([]StaleOperation, error) {
+	return findStaleOperations(siteKey, operator, olderThan, time.Now().UTC())
+}
+
+func findStaleOperations(siteKey SiteKey, operator StaleOperationsGetter, olderThan time.Duration, now time.Time) (stale []StaleOperation, err error) {
+	if err := siteKey.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if olderThan <= 0 {
+		return nil, trace.BadParameter("expected a positive staleness threshold, got: %v", olderThan)
+	}
+	operations, err := operator.GetSiteOperations(siteKey, OperationsFilter{
+		Active: true,
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	cutoff := now.Add(-olderThan)
+	for _, operation := range operations {
+		op := SiteOperation(operation)
+		lastProgress := op.Updated
+		progress, err := operator.GetSiteOperationProgress(op.Key())
+		if err != nil && !trace.IsNotFound(err) {
+			return nil, trace.Wrap(err)
+		}
+		if progress != nil {
+			lastProgress = progress.Created
+		}
+		if lastProgress.Before(cutoff) {
+			stale = append(stale, StaleOperation{
+				SiteOperation: op,
+				LastProgress:  lastProgress,
+			})
+		}
 	}
-	return &clusters[0], nil
+	return stale, nil
 }
 
 // FailOperationAndResetCluster completes the specified operation and resets
 // cluster state to active
 func FailOperationAndResetCluster(ctx context.Context, key SiteOperationKey, operator Operator, message string) error {
+	if err := key.Check(); err != nil {
+		return trace.Wrap(err)
+	}
 	err := FailOperation(ctx, key, operator, message)
 	if err != nil {
 		return trace.Wrap(err)
@@ -298,8 +843,48 @@ func FailOperationAndResetCluster(ctx context.Context, key SiteOperationKey, ope
 	return nil
 }
 
+// WaitForClusterState polls the state of the cluster specified with key
+// until it reaches the given state or the provided context expires.
+// Returns the cluster in its final observed state
+func WaitForClusterState(ctx context.Context, key SiteKey, operator ClusterGetter, state string) (*Site, error) {
+	if err := key.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return waitForClusterState(ctx, key, operator, state, defaults.RetryInterval)
+}
+
+func waitForClusterState(ctx context.Context, key SiteKey, operator ClusterGetter, state string, interval time.Duration) (*Site, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		cluster, err := operator.GetSite(key)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if cluster.State == state {
+			return cluster, nil
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return cluster, trace.Wrap(ctx.Err(),
+				"cluster %v did not reach state %q, last state: %q",
+				key.SiteDomain, state, cluster.State)
+		}
+	}
+}
+
+// ClusterGetter defines an interface to fetch a cluster by key
+type ClusterGetter interface {
+	// GetSite returns the cluster specified with the given key
+	GetSite(SiteKey) (*Site, error)
+}
+
 // CompleteOperation marks the specified operation as completed
 func CompleteOperation(ctx context.Context, key SiteOperationKey, operator OperationStateSetter) error {
+	if err := key.Check(); err != nil {
+		return trace.Wrap(err)
+	}
 	return operator.SetOperationState(ctx, key, SetOperationStateRequest{
 		State: OperationStateCompleted,
 		Progress: &ProgressEntry{
@@ -316,25 +901,213 @@ func CompleteOperation(ctx context.Context, key SiteOperationKey, operator Opera
 
 // FailOperation marks the specified operation as failed
 func FailOperation(ctx context.Context, key SiteOperationKey, operator OperationStateSetter, message string) error {
-	if message != "" {
-		message = fmt.Sprintf("Operation failure: %v", message)
+	return FailOperationWithCode(ctx, key, operator, "", message)
+}
+
+// FailOperationWithCode marks the specified operation as failed and tags the
+// progress entry with the specified machine-readable code categorizing the
+// failure cause, see ProgressCode* constants
+func FailOperationWithCode(ctx context.Context, key SiteOperationKey, operator OperationStateSetter, code, message string) error {
+	if err := key.Check(); err != nil {
+		return trace.Wrap(err)
+	}
+	reason := strings.TrimSpace(message)
+	if reason != "" {
+		message = fmt.Sprintf("%v: %v", failureMessagePrefix, reason)
 	} else {
-		message = "Operation failure"
+		message = failureMessagePrefix
 	}
 	return operator.SetOperationState(ctx, key, SetOperationStateRequest{
 		State: OperationStateFailed,
+		Code:  code,
 		Progress: &ProgressEntry{
-			SiteDomain:  key.SiteDomain,
-			OperationID: key.OperationID,
-			Step:        constants.FinalStep,
-			Completion:  constants.Completed,
-			State:       ProgressStateFailed,
-			Message:     strings.TrimSpace(message),
-			Created:     time.Now().UTC(),
+			SiteDomain:    key.SiteDomain,
+			OperationID:   key.OperationID,
+			Step:          constants.FinalStep,
+			Completion:    constants.Completed,
+			State:         ProgressStateFailed,
+			Message:       message,
+			Code:          code,
+			FailureReason: reason,
+			Created:       time.Now().UTC(),
 		},
 	})
 }
 
+// GetOperationFailureReason returns the raw cause the given operation failed
+// with, without the display formatting applied to the progress message.
+// Falls back to stripping the message prefix for progress entries recorded
+// before the structured reason was introduced. Returns an empty string if
+// the operation has not failed
+func GetOperationFailureReason(op SiteOperation, progress ProgressEntry) string {
+	if !op.IsFailed() && !progress.IsFailed() {
+		return ""
+	}
+	if progress.FailureReason != "" {
+		return progress.FailureReason
+	}
+	reason := strings.TrimPrefix(progress.Message, failureMessagePrefix)
+	return strings.TrimSpace(strings.TrimPrefix(reason, ":"))
+}
+
+// failureMessagePrefix prefixes the human-readable message of a failed
+// operation's final progress entry
+const failureMessagePrefix = "Operation failure"
+
+// OperationSummary is a concise description of an operation suitable for
+// rendering into a notification, e.g. a Slack message
+type OperationSummary struct {
+	// Type is the human-friendly operation type, e.g. "install"
+	Type string `json:"type"`
+	// ClusterName names the cluster the operation ran on
+	ClusterName string `json:"cluster_name"`
+	// Outcome describes how the operation ended up, one of the
+	// OperationOutcome* constants
+	Outcome string `json:"outcome"`
+	// Duration is the time between the operation's creation and its
+	// last update, i.e. the time taken so far for an operation that
+	// has not finished yet
+	Duration time.Duration `json:"duration"`
+	// CreatedBy is the user who initiated the operation, if known
+	CreatedBy string `json:"created_by,omitempty"`
+	// FailureReason records the raw cause of the failure for failed
+	// operations
+	FailureReason string `json:"failure_reason,omitempty"`
+	// Code optionally categorizes the outcome in a machine-readable way,
+	// see ProgressCode* constants
+	Code string `json:"code,omitempty"`
+}
+
+// SummarizeOperation condenses the operation and its latest progress entry
+// into the facts a completion notification is built from. The progress
+// entry is optional - without it the failure reason and code are simply
+// left empty
+func SummarizeOperation(op *SiteOperation, progress *ProgressEntry) OperationSummary {
+	summary := OperationSummary{
+		Type:        op.TypeString(),
+		ClusterName: op.SiteDomain,
+		CreatedBy:   op.CreatedBy,
+	}
+	switch {
+	case op.IsCompleted():
+		summary.Outcome = OperationOutcomeCompleted
+	case op.IsFailed():
+		summary.Outcome = OperationOutcomeFailed
+	default:
+		summary.Outcome = OperationOutcomeInProgress
+	}
+	if !op.Created.IsZero() && op.Updated.After(op.Created) {
+		summary.Duration = op.Updated.Sub(op.Created)
+	}
+	if progress != nil {
+		summary.Code = progress.Code
+		summary.FailureReason = GetOperationFailureReason(*op, *progress)
+	}
+	return summary
+}
+
+// CommandCanceler cancels the in-flight remote commands tagged with the
+// given sequence number. Returns true if the agent found at least one such
+// command. Implemented by the RPC agent client
+type CommandCanceler interface {
+	CancelCommand(ctx context.Context, seq int32) (bool, error)
+}
+
+// AgentCommands describes the commands of an operation executing on an agent
+type AgentCommands struct {
+	// Canceler cancels commands on the agent
+	Canceler CommandCanceler
+	// Seqs lists the sequence numbers the operation's commands are
+	// tagged with on the agent
+	Seqs []int32
+}
+
+// CancelOperation marks the operation specified with key as failed and
+// signals the agents executing the operation's commands to cancel them, so
+// a cancelled operation actually stops in-flight node work instead of
+// leaving remote commands running to completion. agents maps the agent
+// advertise address to its in-flight commands. Returns the addresses of
+// the agents that acknowledged cancellation of at least one command, in
+// sorted order
+func CancelOperation(ctx context.Context, key SiteOperationKey, operator OperationStateSetter, agents map[string]AgentCommands) (acked []string, err error) {
+	if err := FailOperation(ctx, key, operator, "operation cancelled"); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var errors []error
+	for addr, commands := range agents {
+		var agentAcked bool
+		for _, seq := range commands.Seqs {
+			found, err := commands.Canceler.CancelCommand(ctx, seq)
+			if err != nil {
+				errors = append(errors, trace.Wrap(err,
+					"failed to cancel command %v on agent %v", seq, addr))
+				continue
+			}
+			if found {
+				agentAcked = true
+			}
+		}
+		if agentAcked {
+			acked = append(acked, addr)
+		}
+	}
+	sort.Strings(acked)
+	return acked, trace.NewAggregate(errors...)
+}
+
+// ETAConfidence indicates how trustworthy an operation ETA estimate is
+type ETAConfidence string
+
+const (
+	// ETAConfidenceHigh indicates an estimate backed by a long steady
+	// progress history
+	ETAConfidenceHigh ETAConfidence = "high"
+	// ETAConfidenceLow indicates an estimate based on only a few samples
+	ETAConfidenceLow ETAConfidence = "low"
+	// ETAConfidenceUnknown indicates that no estimate could be made, e.g.
+	// due to too little data or non-monotonic progress
+	ETAConfidenceUnknown ETAConfidence = "unknown"
+)
+
+// minSamplesForConfidentETA is the number of progress entries needed
+// for a high-confidence ETA estimate
+const minSamplesForConfidentETA = 5
+
+// EstimateOperationETA estimates when the operation will complete by
+// modeling the completion rate from its progress history.
+// The history is expected to be ordered from oldest to newest.
+// Returns a zero time and ETAConfidenceUnknown when there is too little
+// data, the progress has stalled, or is non-monotonic
+func EstimateOperationETA(history []ProgressEntry) (eta time.Time, confidence ETAConfidence) {
+	if len(history) < 2 {
+		return time.Time{}, ETAConfidenceUnknown
+	}
+	for i := 1; i < len(history); i++ {
+		if history[i].Completion < history[i-1].Completion ||
+			history[i].Created.Before(history[i-1].Created) {
+			// progress is regressing
+			return time.Time{}, ETAConfidenceUnknown
+		}
+	}
+	first, last := history[0], history[len(history)-1]
+	if last.Completion >= constants.Completed {
+		// the operation has already completed
+		return last.Created, ETAConfidenceHigh
+	}
+	elapsed := last.Created.Sub(first.Created)
+	if last.Completion <= first.Completion || elapsed <= 0 {
+		// progress has stalled
+		return time.Time{}, ETAConfidenceUnknown
+	}
+	rate := float64(last.Completion-first.Completion) / elapsed.Seconds()
+	remaining := float64(constants.Completed-last.Completion) / rate
+	eta = last.Created.Add(time.Duration(remaining * float64(time.Second)))
+	if len(history) >= minSamplesForConfidentETA {
+		return eta, ETAConfidenceHigh
+	}
+	return eta, ETAConfidenceLow
+}
+
 // OperationStateSetter defines an interface to set/update operation state
 type OperationStateSetter interface {
 	// SetOperationState updates state of the operation
@@ -350,6 +1123,90 @@ func (r OperationStateFunc) SetOperationState(ctx context.Context, key SiteOpera
 // OperationStateFunc is a function handler for setting the operation state
 type OperationStateFunc func(context.Context, SiteOperationKey, SetOperationStateRequest) error
 
+// NewBatchingOperationStateSetter returns an OperationStateSetter that
+// coalesces high-frequency updates to reduce write amplification on busy
+// operations: only the most recent request per operation is kept and
+// flushed to the wrapped setter every interval. Callers must Close the
+// setter when done to flush the remaining updates and stop the background
+// flusher
+func NewBatchingOperationStateSetter(setter OperationStateSetter, interval time.Duration) *BatchingOperationStateSetter {
+	r := &BatchingOperationStateSetter{
+		setter:  setter,
+		pending: make(map[SiteOperationKey]SetOperationStateRequest),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go r.flushLoop(interval)
+	return r
+}
+
+// BatchingOperationStateSetter decorates an OperationStateSetter buffering
+// the latest state update per operation, see
+// NewBatchingOperationStateSetter
+type BatchingOperationStateSetter struct {
+	mu      sync.Mutex
+	setter  OperationStateSetter
+	pending map[SiteOperationKey]SetOperationStateRequest
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// SetOperationState buffers the state update superseding any update
+// buffered for the same operation. Errors from the wrapped setter surface
+// on the subsequent Flush or Close
+func (r *BatchingOperationStateSetter) SetOperationState(ctx context.Context, key SiteOperationKey, req SetOperationStateRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pending[key] = req
+	return nil
+}
+
+// Flush forwards the buffered updates to the wrapped setter
+func (r *BatchingOperationStateSetter) Flush(ctx context.Context) error {
+	r.mu.Lock()
+	pending := r.pending
+	r.pending = make(map[SiteOperationKey]SetOperationStateRequest)
+	r.mu.Unlock()
+	var errors []error
+	for key, req := range pending {
+		if err := r.setter.SetOperationState(ctx, key, req); err != nil {
+			errors = append(errors, trace.Wrap(err))
+		}
+	}
+	return trace.NewAggregate(errors...)
+}
+
+// Close flushes the remaining updates and stops the background flusher
+func (r *BatchingOperationStateSetter) Close(ctx context.Context) error {
+	select {
+	case <-r.done:
+	default:
+		close(r.done)
+		<-r.stopped
+	}
+	return trace.Wrap(r.Flush(ctx))
+}
+
+func (r *BatchingOperationStateSetter) flushLoop(interval time.Duration) {
+	defer close(r.stopped)
+	if interval <= 0 {
+		<-r.done
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := r.Flush(context.TODO()); err != nil {
+				log.WithError(err).Warn("Failed to flush operation state updates.")
+			}
+		case <-r.done:
+			return
+		}
+	}
+}
+
 // VerifyLicense verifies the provided license
 func VerifyLicense(packages pack.PackageService, license string) error {
 	parsed, err := licenseapi.ParseLicense(license)
@@ -367,6 +1224,67 @@ func VerifyLicense(packages pack.PackageService, license string) error {
 	return parsed.Verify(ca.CertPEM)
 }
 
+// VerifyPackageAccess verifies that the provided package store remains
+// readable using the encryption key of the provided license. A license
+// carrying a different encryption key would leave the existing packages
+// unreadable, so this is meant to be invoked before committing a license
+// update
+func VerifyPackageAccess(packages pack.PackageService, license string) error {
+	parsed, err := licenseapi.ParseLicense(license)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if len(parsed.GetPayload().EncryptionKey) != 0 {
+		packages = encryptedpack.New(packages, string(
+			parsed.GetPayload().EncryptionKey))
+	}
+	if _, err := pack.ReadCertificateAuthority(packages); err != nil {
+		return trace.BadParameter("cannot access packages using the encryption key of the provided license, make sure the license was generated with the same encryption key the cluster was installed with: %v", err)
+	}
+	return nil
+}
+
+// VerifyLicenseWithRevocation verifies the provided license like VerifyLicense
+// and additionally rejects licenses found on the provided revocation list.
+// Certificate-based licenses are matched by their certificate serial number,
+// payload-based licenses by the cluster ID or vendor signature.
+// Returns AccessDenied for a revoked license
+func VerifyLicenseWithRevocation(packages pack.PackageService, license string, revoked map[string]bool) error {
+	if err := VerifyLicense(packages, license); err != nil {
+		return trace.Wrap(err)
+	}
+	for _, id := range LicenseIdentifiers(license) {
+		if revoked[id] {
+			return trace.AccessDenied("license %v has been revoked", id)
+		}
+	}
+	return nil
+}
+
+// LicenseIdentifiers returns the identifiers the provided license can be
+// revoked by
+func LicenseIdentifiers(license string) (ids []string) {
+	parsed, err := licenseapi.ParseLicense(license)
+	if err == nil {
+		payload := parsed.GetPayload()
+		if payload.ClusterID != "" {
+			ids = append(ids, payload.ClusterID)
+		}
+		if payload.Signature != "" {
+			ids = append(ids, payload.Signature)
+		}
+	}
+	for block, rest := pem.Decode([]byte(license)); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+			ids = append(ids, cert.SerialNumber.String())
+		}
+	}
+	return ids
+}
+
 // GetExpandOperation returns the first available expand operation from
 // the provided backend
 func GetExpandOperation(backend storage.Backend) (*SiteOperation, error) {
@@ -414,3 +1332,28 @@ func MatchByType(opType string) OperationMatcher {
 
 // OperationMatcher is a function type that matches the given operation
 type OperationMatcher func(SiteOperation) bool
+
+// OperatorFromContext extracts the operator attached to the provided context.
+//
+// Returns NotFound if no operator is attached
+func OperatorFromContext(ctx context.Context) (Operator, error) {
+	operatorI := ctx.Value(constants.OperatorContext)
+	if operatorI == nil {
+		return nil, trace.NotFound("missing operator context")
+	}
+	operator, ok := operatorI.(Operator)
+	if !ok {
+		return nil, trace.BadParameter("unsupported operator type %T", operatorI)
+	}
+	return operator, nil
+}
+
+// OperatorFromContextOr returns the operator attached to the provided context
+// or the given fallback operator if the context does not carry one
+func OperatorFromContextOr(ctx context.Context, fallback Operator) Operator {
+	operator, err := OperatorFromContext(ctx)
+	if err != nil {
+		return fallback
+	}
+	return operator
+}
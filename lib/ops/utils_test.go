@@ -0,0 +1,972 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ops
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	check "gopkg.in/check.v1"
+)
+
+type UtilsSuite struct{}
+
+var _ = check.Suite(&UtilsSuite{})
+
+func (s *UtilsSuite) TestWaitForClusterState(c *check.C) {
+	key := SiteKey{AccountID: "account", SiteDomain: "example.com"}
+	operator := &fakeClusterGetter{
+		states: []string{SiteStateFailed, SiteStateUninstalling, SiteStateActive},
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+	cluster, err := waitForClusterState(ctx, key, operator, SiteStateActive, time.Millisecond)
+	c.Assert(err, check.IsNil)
+	c.Assert(cluster.State, check.Equals, SiteStateActive)
+}
+
+func (s *UtilsSuite) TestWaitForClusterStateExpires(c *check.C) {
+	key := SiteKey{AccountID: "account", SiteDomain: "example.com"}
+	operator := &fakeClusterGetter{
+		states: []string{SiteStateDegraded},
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+	cluster, err := waitForClusterState(ctx, key, operator, SiteStateActive, time.Millisecond)
+	c.Assert(err, check.NotNil)
+	c.Assert(cluster.State, check.Equals, SiteStateDegraded)
+}
+
+func (s *UtilsSuite) TestFailOperationWithCode(c *check.C) {
+	key := SiteOperationKey{
+		AccountID:   "account",
+		SiteDomain:  "example.com",
+		OperationID: "operation",
+	}
+	var captured SetOperationStateRequest
+	operator := OperationStateFunc(func(ctx context.Context, key SiteOperationKey, req SetOperationStateRequest) error {
+		captured = req
+		return nil
+	})
+
+	err := FailOperationWithCode(context.TODO(), key, operator, ProgressCodeTimeout, "context deadline exceeded")
+	c.Assert(err, check.IsNil)
+	c.Assert(captured.State, check.Equals, OperationStateFailed)
+	c.Assert(captured.Code, check.Equals, ProgressCodeTimeout)
+	c.Assert(captured.Progress, check.NotNil)
+	c.Assert(captured.Progress.Code, check.Equals, ProgressCodeTimeout)
+	c.Assert(captured.Progress.Message, check.Equals, "Operation failure: context deadline exceeded")
+	c.Assert(captured.Progress.FailureReason, check.Equals, "context deadline exceeded")
+
+	// the plain helper leaves the code empty
+	err = FailOperation(context.TODO(), key, operator, "")
+	c.Assert(err, check.IsNil)
+	c.Assert(captured.Code, check.Equals, "")
+	c.Assert(captured.Progress.Code, check.Equals, "")
+}
+
+func (s *UtilsSuite) TestOperationFailureReason(c *check.C) {
+	failed := SiteOperation{State: OperationStateFailed}
+
+	// the structured reason takes precedence
+	reason := GetOperationFailureReason(failed, ProgressEntry{
+		State:         ProgressStateFailed,
+		Message:       "Operation failure: disk full",
+		FailureReason: "disk full",
+	})
+	c.Assert(reason, check.Equals, "disk full")
+
+	// entries recorded before the structured reason fall back to
+	// stripping the message prefix
+	reason = GetOperationFailureReason(failed, ProgressEntry{
+		State:   ProgressStateFailed,
+		Message: "Operation failure: agent unreachable",
+	})
+	c.Assert(reason, check.Equals, "agent unreachable")
+
+	reason = GetOperationFailureReason(failed, ProgressEntry{
+		State:   ProgressStateFailed,
+		Message: "Operation failure",
+	})
+	c.Assert(reason, check.Equals, "")
+
+	// non-failed operations have no failure reason
+	reason = GetOperationFailureReason(
+		SiteOperation{State: OperationStateCompleted},
+		ProgressEntry{State: ProgressStateCompleted, Message: "Operation has completed"})
+	c.Assert(reason, check.Equals, "")
+}
+
+func (s *UtilsSuite) TestBatchingOperationStateSetter(c *check.C) {
+	key1 := SiteOperationKey{AccountID: "account", SiteDomain: "example.com", OperationID: "op-1"}
+	key2 := SiteOperationKey{AccountID: "account", SiteDomain: "example.com", OperationID: "op-2"}
+	backend := &countingStateSetter{
+		captured: make(map[SiteOperationKey]SetOperationStateRequest),
+	}
+
+	setter := NewBatchingOperationStateSetter(backend, 0)
+	for i := 1; i <= 10; i++ {
+		err := setter.SetOperationState(context.TODO(), key1, SetOperationStateRequest{
+			State:    OperationStateUpdateInProgress,
+			Progress: &ProgressEntry{Completion: i * 10},
+		})
+		c.Assert(err, check.IsNil)
+	}
+	err := setter.SetOperationState(context.TODO(), key2, SetOperationStateRequest{
+		State: OperationStateCompleted,
+	})
+	c.Assert(err, check.IsNil)
+
+	// nothing has reached the backend yet
+	c.Assert(backend.count(), check.Equals, 0)
+
+	c.Assert(setter.Close(context.TODO()), check.IsNil)
+	// the ten updates of the first operation coalesce into one call
+	// preserving the final state
+	c.Assert(backend.count(), check.Equals, 2)
+	c.Assert(backend.captured[key1].Progress.Completion, check.Equals, 100)
+	c.Assert(backend.captured[key2].State, check.Equals, OperationStateCompleted)
+
+	// closing again flushes nothing further
+	c.Assert(setter.Close(context.TODO()), check.IsNil)
+	c.Assert(backend.count(), check.Equals, 2)
+}
+
+func (s *UtilsSuite) TestBatchingOperationStateSetterFlushesOnInterval(c *check.C) {
+	key := SiteOperationKey{AccountID: "account", SiteDomain: "example.com", OperationID: "op"}
+	backend := &countingStateSetter{
+		captured: make(map[SiteOperationKey]SetOperationStateRequest),
+	}
+
+	setter := NewBatchingOperationStateSetter(backend, 10*time.Millisecond)
+	defer setter.Close(context.TODO())
+	err := setter.SetOperationState(context.TODO(), key, SetOperationStateRequest{
+		State: OperationStateUpdateInProgress,
+	})
+	c.Assert(err, check.IsNil)
+
+	// the background flusher delivers the update without an explicit flush
+	for i := 0; i < 100; i++ {
+		if backend.count() != 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	c.Assert(backend.count(), check.Equals, 1)
+}
+
+// countingStateSetter records state updates for test assertions
+type countingStateSetter struct {
+	mu       sync.Mutex
+	calls    int
+	captured map[SiteOperationKey]SetOperationStateRequest
+}
+
+func (r *countingStateSetter) SetOperationState(ctx context.Context, key SiteOperationKey, req SetOperationStateRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls++
+	r.captured[key] = req
+	return nil
+}
+
+func (r *countingStateSetter) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.calls
+}
+
+func (s *UtilsSuite) TestCancelOperation(c *check.C) {
+	key := SiteOperationKey{
+		AccountID:   "account",
+		SiteDomain:  "example.com",
+		OperationID: "operation",
+	}
+	var captured SetOperationStateRequest
+	operator := OperationStateFunc(func(ctx context.Context, key SiteOperationKey, req SetOperationStateRequest) error {
+		captured = req
+		return nil
+	})
+	node1 := &fakeCanceler{found: map[int32]bool{1: true, 2: true}}
+	node2 := &fakeCanceler{found: map[int32]bool{}}
+	node3 := &fakeCanceler{found: map[int32]bool{3: true}}
+
+	acked, err := CancelOperation(context.TODO(), key, operator, map[string]AgentCommands{
+		"192.168.1.1": {Canceler: node1, Seqs: []int32{1, 2}},
+		"192.168.1.2": {Canceler: node2, Seqs: []int32{1}},
+		"192.168.1.3": {Canceler: node3, Seqs: []int32{3}},
+	})
+	c.Assert(err, check.IsNil)
+	// the operation is failed and only the agents that actually stopped
+	// a command are reported
+	c.Assert(captured.State, check.Equals, OperationStateFailed)
+	c.Assert(acked, check.DeepEquals, []string{"192.168.1.1", "192.168.1.3"})
+	c.Assert(node1.cancelled, check.DeepEquals, []int32{1, 2})
+	c.Assert(node2.cancelled, check.DeepEquals, []int32{1})
+}
+
+func (s *UtilsSuite) TestCancelOperationReportsAgentErrors(c *check.C) {
+	key := SiteOperationKey{
+		AccountID:   "account",
+		SiteDomain:  "example.com",
+		OperationID: "operation",
+	}
+	operator := OperationStateFunc(func(context.Context, SiteOperationKey, SetOperationStateRequest) error {
+		return nil
+	})
+	acked, err := CancelOperation(context.TODO(), key, operator, map[string]AgentCommands{
+		"192.168.1.1": {Canceler: &fakeCanceler{err: fmt.Errorf("agent unreachable")}, Seqs: []int32{1}},
+		"192.168.1.2": {Canceler: &fakeCanceler{found: map[int32]bool{1: true}}, Seqs: []int32{1}},
+	})
+	// the reachable agent still acknowledges while the error is reported
+	c.Assert(err, check.ErrorMatches, "(?s).*agent unreachable.*")
+	c.Assert(acked, check.DeepEquals, []string{"192.168.1.2"})
+}
+
+// fakeCanceler implements CommandCanceler recording the cancelled sequence
+// numbers
+type fakeCanceler struct {
+	found     map[int32]bool
+	err       error
+	cancelled []int32
+}
+
+func (r *fakeCanceler) CancelCommand(ctx context.Context, seq int32) (bool, error) {
+	if r.err != nil {
+		return false, r.err
+	}
+	r.cancelled = append(r.cancelled, seq)
+	return r.found[seq], nil
+}
+
+func (s *UtilsSuite) TestGetOperationsPage(c *check.C) {
+	key := SiteKey{AccountID: "account", SiteDomain: "example.com"}
+	operator := &fakeOperationsPager{
+		operations: testOperations(5),
+	}
+
+	page, token, err := GetOperationsPage(key, operator, 2, "")
+	c.Assert(err, check.IsNil)
+	c.Assert(operationIDs(page), check.DeepEquals, []string{"op5", "op4"})
+	c.Assert(token, check.Equals, "op4")
+
+	page, token, err = GetOperationsPage(key, operator, 2, token)
+	c.Assert(err, check.IsNil)
+	c.Assert(operationIDs(page), check.DeepEquals, []string{"op3", "op2"})
+	c.Assert(token, check.Equals, "op2")
+
+	// the last page has no continuation token
+	page, token, err = GetOperationsPage(key, operator, 2, token)
+	c.Assert(err, check.IsNil)
+	c.Assert(operationIDs(page), check.DeepEquals, []string{"op1"})
+	c.Assert(token, check.Equals, "")
+}
+
+func (s *UtilsSuite) TestIterateOperations(c *check.C) {
+	key := SiteKey{AccountID: "account", SiteDomain: "example.com"}
+	// exercise histories that do and do not divide evenly into pages
+	for _, total := range []int{0, 1, 4, 5} {
+		operator := &fakeOperationsPager{
+			operations: testOperations(total),
+		}
+		var visited []string
+		err := IterateOperations(key, operator, 2, func(page SiteOperations) error {
+			c.Assert(len(page) > 0, check.Equals, true)
+			c.Assert(len(page) <= 2, check.Equals, true)
+			visited = append(visited, operationIDs(page)...)
+			return nil
+		})
+		c.Assert(err, check.IsNil)
+		// every operation is visited exactly once
+		c.Assert(visited, check.DeepEquals, operationIDs(operator.operations),
+			check.Commentf("total %v", total))
+	}
+}
+
+func (s *UtilsSuite) TestIterateOperationsInvalidPageSize(c *check.C) {
+	key := SiteKey{AccountID: "account", SiteDomain: "example.com"}
+	err := IterateOperations(key, &fakeOperationsPager{}, 0, func(SiteOperations) error {
+		c.Fatal("callback should not be invoked")
+		return nil
+	})
+	c.Assert(err, check.NotNil)
+}
+
+// testOperations generates the specified number of operations in the
+// newest-first order the backend returns them in
+func testOperations(total int) (result SiteOperations) {
+	for i := total; i > 0; i-- {
+		result = append(result, storage.SiteOperation{
+			ID: fmt.Sprintf("op%v", i),
+		})
+	}
+	return result
+}
+
+func operationIDs(operations SiteOperations) (ids []string) {
+	for _, operation := range operations {
+		ids = append(ids, operation.ID)
+	}
+	return ids
+}
+
+// fakeOperationsPager serves a static list of operations applying the
+// provided filter
+type fakeOperationsPager struct {
+	operations SiteOperations
+}
+
+func (o *fakeOperationsPager) GetSiteOperations(key SiteKey, filter OperationsFilter) (SiteOperations, error) {
+	return filter.Filter(o.operations), nil
+}
+
+func (s *UtilsSuite) TestFindOrphanedOperations(c *check.C) {
+	operator := &fakeOperationsGetter{
+		sites: []Site{
+			{AccountID: "account", Domain: "existing.example.com"},
+		},
+		operations: SiteOperations{
+			{ID: "op-1", AccountID: "account", SiteDomain: "existing.example.com"},
+			{ID: "op-2", AccountID: "account", SiteDomain: "deleted.example.com"},
+			{ID: "op-3", AccountID: "account", SiteDomain: "existing.example.com"},
+			{ID: "op-4", AccountID: "account", SiteDomain: "gone.example.com"},
+		},
+	}
+
+	orphaned, err := FindOrphanedOperations("account", operator)
+	c.Assert(err, check.IsNil)
+	c.Assert(orphaned, check.HasLen, 2)
+	c.Assert(orphaned[0].ID, check.Equals, "op-2")
+	c.Assert(orphaned[1].ID, check.Equals, "op-4")
+
+	// nothing is orphaned when all operations reference existing clusters
+	operator.operations = operator.operations[:1]
+	orphaned, err = FindOrphanedOperations("account", operator)
+	c.Assert(err, check.IsNil)
+	c.Assert(orphaned, check.HasLen, 0)
+}
+
+func (s *UtilsSuite) TestEstimateOperationETA(c *check.C) {
+	start := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	progressAt := func(minutes, completion int) ProgressEntry {
+		return ProgressEntry{
+			Created:    start.Add(time.Duration(minutes) * time.Minute),
+			Completion: completion,
+		}
+	}
+
+	// steadily advancing progress produces an estimate past the last sample
+	history := []ProgressEntry{
+		progressAt(0, 10), progressAt(1, 20), progressAt(2, 30),
+		progressAt(3, 40), progressAt(4, 50),
+	}
+	eta, confidence := EstimateOperationETA(history)
+	c.Assert(confidence, check.Equals, ETAConfidenceHigh)
+	// 10% per minute, 50% remaining -> 5 more minutes
+	c.Assert(eta, check.Equals, start.Add(9*time.Minute))
+
+	// few samples yield a low-confidence estimate
+	_, confidence = EstimateOperationETA(history[:2])
+	c.Assert(confidence, check.Equals, ETAConfidenceLow)
+
+	// stalled progress cannot be estimated
+	_, confidence = EstimateOperationETA([]ProgressEntry{
+		progressAt(0, 50), progressAt(1, 50), progressAt(2, 50),
+	})
+	c.Assert(confidence, check.Equals, ETAConfidenceUnknown)
+
+	// regressing progress cannot be estimated
+	_, confidence = EstimateOperationETA([]ProgressEntry{
+		progressAt(0, 50), progressAt(1, 40),
+	})
+	c.Assert(confidence, check.Equals, ETAConfidenceUnknown)
+
+	// a single sample is not enough
+	_, confidence = EstimateOperationETA(history[:1])
+	c.Assert(confidence, check.Equals, ETAConfidenceUnknown)
+}
+
+func (s *UtilsSuite) TestFindStaleOperations(c *check.C) {
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	key := SiteKey{AccountID: "account", SiteDomain: "example.com"}
+	operator := &fakeStaleOperationsGetter{
+		operations: SiteOperations{
+			{ID: "op-stale", SiteDomain: "example.com", State: OperationStateInstallInitiated},
+			{ID: "op-fresh", SiteDomain: "example.com", State: OperationStateInstallInitiated},
+			{ID: "op-finished", SiteDomain: "example.com", State: OperationStateCompleted},
+			// has never reported progress, judged by its update time
+			{ID: "op-silent", SiteDomain: "example.com", State: OperationStateInstallInitiated,
+				Updated: now.Add(-2 * time.Hour)},
+		},
+		progress: map[string]ProgressEntry{
+			"op-stale": {Created: now.Add(-time.Hour)},
+			"op-fresh": {Created: now.Add(-time.Minute)},
+		},
+	}
+
+	stale, err := findStaleOperations(key, operator, 30*time.Minute, now)
+	c.Assert(err, check.IsNil)
+	c.Assert(stale, check.HasLen, 2)
+	c.Assert(stale[0].ID, check.Equals, "op-stale")
+	c.Assert(stale[0].LastProgress, check.Equals, now.Add(-time.Hour))
+	c.Assert(stale[1].ID, check.Equals, "op-silent")
+	c.Assert(stale[1].LastProgress, check.Equals, now.Add(-2*time.Hour))
+
+	// nothing is stale with a threshold past the oldest progress
+	stale, err = findStaleOperations(key, operator, 3*time.Hour, now)
+	c.Assert(err, check.IsNil)
+	c.Assert(stale, check.HasLen, 0)
+
+	// the threshold is validated
+	_, err = findStaleOperations(key, operator, 0, now)
+	c.Assert(trace.IsBadParameter(err), check.Equals, true)
+}
+
+func (s *UtilsSuite) TestHasActiveOperationOfType(c *check.C) {
+	key := SiteKey{AccountID: "account", SiteDomain: "example.com"}
+	operator := &fakeActiveOperator{
+		operations: SiteOperations{
+			{ID: "op-finished", SiteDomain: "example.com", Type: OperationUpdate,
+				State: OperationStateCompleted},
+		},
+	}
+
+	// no active operation of the type
+	found, op, err := HasActiveOperationOfType(key, operator, OperationUpdate)
+	c.Assert(err, check.IsNil)
+	c.Assert(found, check.Equals, false)
+	c.Assert(op, check.IsNil)
+
+	// one active operation
+	operator.operations = append(SiteOperations{
+		{ID: "op-active", SiteDomain: "example.com", Type: OperationUpdate,
+			State: OperationStateUpdateInProgress},
+	}, operator.operations...)
+	found, op, err = HasActiveOperationOfType(key, operator, OperationUpdate)
+	c.Assert(err, check.IsNil)
+	c.Assert(found, check.Equals, true)
+	c.Assert(op.ID, check.Equals, "op-active")
+
+	// an active operation of another type does not count
+	found, op, err = HasActiveOperationOfType(key, operator, OperationShrink)
+	c.Assert(err, check.IsNil)
+	c.Assert(found, check.Equals, false)
+	c.Assert(op, check.IsNil)
+
+	// with multiple active operations the most recent one is returned
+	operator.operations = append(SiteOperations{
+		{ID: "op-newer", SiteDomain: "example.com", Type: OperationUpdate,
+			State: OperationStateUpdateInProgress},
+	}, operator.operations...)
+	found, op, err = HasActiveOperationOfType(key, operator, OperationUpdate)
+	c.Assert(err, check.IsNil)
+	c.Assert(found, check.Equals, true)
+	c.Assert(op.ID, check.Equals, "op-newer")
+}
+
+func (s *UtilsSuite) TestGetAccountOperations(c *check.C) {
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	operator := &fakeAccountOperator{
+		clusters: []Site{
+			{AccountID: "account", Domain: "alpha.example.com"},
+			{AccountID: "account", Domain: "bravo.example.com"},
+		},
+		operations: map[string]SiteOperations{
+			"alpha.example.com": {
+				{ID: "op-1", SiteDomain: "alpha.example.com", Type: OperationInstall,
+					State: OperationStateCompleted, Created: now.Add(-time.Hour)},
+				{ID: "op-3", SiteDomain: "alpha.example.com", Type: OperationUpdate,
+					State: OperationStateUpdateInProgress, Created: now},
+			},
+			"bravo.example.com": {
+				{ID: "op-2", SiteDomain: "bravo.example.com", Type: OperationInstall,
+					State: OperationStateCompleted, Created: now.Add(-time.Minute)},
+			},
+		},
+	}
+
+	// operations across the account's clusters come back most recent first
+	operations, err := GetAccountOperations("account", operator, OperationsFilter{})
+	c.Assert(err, check.IsNil)
+	c.Assert(operations, check.HasLen, 3)
+	c.Assert(operations[0].ID, check.Equals, "op-3")
+	c.Assert(operations[0].ClusterDomain, check.Equals, "alpha.example.com")
+	c.Assert(operations[1].ID, check.Equals, "op-2")
+	c.Assert(operations[1].ClusterDomain, check.Equals, "bravo.example.com")
+	c.Assert(operations[2].ID, check.Equals, "op-1")
+
+	// the filter applies to every cluster
+	operations, err = GetAccountOperations("account", operator, OperationsFilter{
+		Types: []string{OperationInstall},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(operations, check.HasLen, 2)
+
+	// a failing cluster is skipped, its error reported alongside the
+	// operations collected from the others
+	operator.errors = map[string]error{
+		"bravo.example.com": trace.ConnectionProblem(nil, "cluster unreachable"),
+	}
+	operations, err = GetAccountOperations("account", operator, OperationsFilter{})
+	c.Assert(err, check.ErrorMatches, "(?s).*cluster unreachable.*")
+	c.Assert(operations, check.HasLen, 2)
+
+	_, err = GetAccountOperations("", operator, OperationsFilter{})
+	c.Assert(trace.IsBadParameter(err), check.Equals, true)
+}
+
+// fakeAccountOperator implements AccountOperationsGetter against static
+// per-cluster operation records
+type fakeAccountOperator struct {
+	clusters   []Site
+	operations map[string]SiteOperations
+	errors     map[string]error
+}
+
+func (r *fakeAccountOperator) GetSites(accountID string) ([]Site, error) {
+	return r.clusters, nil
+}
+
+func (r *fakeAccountOperator) GetSiteOperations(key SiteKey, filter OperationsFilter) (SiteOperations, error) {
+	if err := r.errors[key.SiteDomain]; err != nil {
+		return nil, err
+	}
+	return filter.Filter(r.operations[key.SiteDomain]), nil
+}
+
+// fakeActiveOperator serves a static list of operations applying the
+// provided filter. The remaining Operator methods are not implemented
+type fakeActiveOperator struct {
+	Operator
+	operations SiteOperations
+}
+
+func (r *fakeActiveOperator) GetSiteOperations(key SiteKey, filter OperationsFilter) (SiteOperations, error) {
+	return filter.Filter(r.operations), nil
+}
+
+// fakeStaleOperationsGetter implements StaleOperationsGetter against static
+// operation and progress records
+type fakeStaleOperationsGetter struct {
+	operations SiteOperations
+	progress   map[string]ProgressEntry
+}
+
+func (r *fakeStaleOperationsGetter) GetSiteOperations(key SiteKey, filter OperationsFilter) (SiteOperations, error) {
+	return filter.Filter(r.operations), nil
+}
+
+func (r *fakeStaleOperationsGetter) GetSiteOperationProgress(key SiteOperationKey) (*ProgressEntry, error) {
+	progress, ok := r.progress[key.OperationID]
+	if !ok {
+		return nil, trace.NotFound("no progress for %v", key.OperationID)
+	}
+	return &progress, nil
+}
+
+// fakeOperationsGetter implements OrphanedOperationsGetter against static
+// cluster and operation records
+type fakeOperationsGetter struct {
+	sites      []Site
+	operations SiteOperations
+}
+
+func (r *fakeOperationsGetter) GetSites(accountID string) ([]Site, error) {
+	return r.sites, nil
+}
+
+func (r *fakeOperationsGetter) GetAccountOperations(accountID string) (SiteOperations, error) {
+	return r.operations, nil
+}
+
+// fakeClusterGetter returns the cluster transitioning through the predefined
+// sequence of states, staying in the last one once the sequence is exhausted
+type fakeClusterGetter struct {
+	states []string
+	calls  int
+}
+
+func (r *fakeClusterGetter) GetSite(key SiteKey) (*Site, error) {
+	index := r.calls
+	if index >= len(r.states) {
+		index = len(r.states) - 1
+	}
+	r.calls++
+	return &Site{
+		AccountID: key.AccountID,
+		Domain:    key.SiteDomain,
+		State:     r.states[index],
+	}, nil
+}
+
+func (s *UtilsSuite) TestWatchProgressFallsBackToPolling(c *check.C) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	operator := &fakePollOperator{
+		entries: []ProgressEntry{
+			{Completion: 10, Message: "pulling packages"},
+			{Completion: 10, Message: "pulling packages"},
+			{Completion: 50, Message: "installing"},
+			{Completion: 100, Message: "done", State: OperationStateCompleted},
+		},
+	}
+	source, err := WatchProgress(ctx, testOperationKey(), operator, time.Millisecond)
+	c.Assert(err, check.IsNil)
+	var received []ProgressEntry
+	for entry := range source.Progress() {
+		received = append(received, entry)
+	}
+	// the duplicate entry is skipped and the channel is closed once the
+	// operation completes
+	c.Assert(received, check.DeepEquals, []ProgressEntry{
+		operator.entries[0],
+		operator.entries[2],
+		operator.entries[3],
+	})
+}
+
+func (s *UtilsSuite) TestWatchProgressPollingStopsOnCancel(c *check.C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	operator := &fakePollOperator{
+		// the operation never reaches a terminal state
+		entries: []ProgressEntry{
+			{Completion: 10, Message: "pulling packages"},
+		},
+	}
+	source, err := WatchProgress(ctx, testOperationKey(), operator, time.Millisecond)
+	c.Assert(err, check.IsNil)
+	entry, ok := <-source.Progress()
+	c.Assert(ok, check.Equals, true)
+	c.Assert(entry, check.DeepEquals, operator.entries[0])
+	cancel()
+	for range source.Progress() {
+	}
+}
+
+func (s *UtilsSuite) TestWatchProgressPrefersStreaming(c *check.C) {
+	operator := &fakeStreamOperator{
+		entries: []ProgressEntry{
+			{Completion: 50, Message: "installing"},
+			{Completion: 100, Message: "done", State: OperationStateCompleted},
+		},
+	}
+	// the embedded Operator is nil so any polling attempt would panic
+	source, err := WatchProgress(context.TODO(), testOperationKey(), operator, time.Millisecond)
+	c.Assert(err, check.IsNil)
+	var received []ProgressEntry
+	for entry := range source.Progress() {
+		received = append(received, entry)
+	}
+	c.Assert(received, check.DeepEquals, operator.entries)
+}
+
+func (s *UtilsSuite) TestWatchProgressInvalidKey(c *check.C) {
+	_, err := WatchProgress(context.TODO(), SiteOperationKey{}, &fakePollOperator{}, 0)
+	c.Assert(trace.IsBadParameter(err), check.Equals, true,
+		check.Commentf("unexpected error: %v", err))
+}
+
+func testOperationKey() SiteOperationKey {
+	return SiteOperationKey{
+		AccountID:   "account",
+		SiteDomain:  "example.com",
+		OperationID: "op1",
+	}
+}
+
+// fakePollOperator serves a scripted sequence of progress entries, advancing
+// on every progress query and staying on the last entry once the sequence
+// is exhausted. The remaining Operator methods are not implemented
+type fakePollOperator struct {
+	Operator
+	mu      sync.Mutex
+	entries []ProgressEntry
+	calls   int
+}
+
+func (r *fakePollOperator) GetSiteOperation(key SiteOperationKey) (*SiteOperation, error) {
+	return &SiteOperation{
+		ID:         key.OperationID,
+		AccountID:  key.AccountID,
+		SiteDomain: key.SiteDomain,
+		State:      OperationStateInstallInitiated,
+	}, nil
+}
+
+func (r *fakePollOperator) GetSiteOperationProgress(key SiteOperationKey) (*ProgressEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	index := r.calls
+	if index >= len(r.entries) {
+		index = len(r.entries) - 1
+	}
+	r.calls++
+	entry := r.entries[index]
+	return &entry, nil
+}
+
+// fakeStreamOperator pushes a static list of progress entries over the
+// streaming transport. The embedded Operator is not implemented
+type fakeStreamOperator struct {
+	Operator
+	entries []ProgressEntry
+}
+
+func (r *fakeStreamOperator) StreamOperationProgress(ctx context.Context, key SiteOperationKey) (<-chan ProgressEntry, error) {
+	updates := make(chan ProgressEntry, len(r.entries))
+	for _, entry := range r.entries {
+		updates <- entry
+	}
+	close(updates)
+	return updates, nil
+}
+
+func (s *UtilsSuite) TestSummarizeOperation(c *check.C) {
+	created := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	operation := &SiteOperation{
+		ID:         "op",
+		SiteDomain: "example.com",
+		Type:       OperationInstall,
+		State:      OperationStateCompleted,
+		Created:    created,
+		Updated:    created.Add(10 * time.Minute),
+		CreatedBy:  "admin@example.com",
+	}
+
+	// a completed operation
+	summary := SummarizeOperation(operation, &ProgressEntry{
+		State:      ProgressStateCompleted,
+		Completion: constants.Completed,
+	})
+	c.Assert(summary, check.DeepEquals, OperationSummary{
+		Type:        "install",
+		ClusterName: "example.com",
+		Outcome:     OperationOutcomeCompleted,
+		Duration:    10 * time.Minute,
+		CreatedBy:   "admin@example.com",
+	})
+
+	// a failed operation picks up the failure reason and code
+	operation.State = OperationStateFailed
+	summary = SummarizeOperation(operation, &ProgressEntry{
+		State:         ProgressStateFailed,
+		Completion:    constants.Completed,
+		Message:       "Operation failure: preflight checks failed",
+		FailureReason: "preflight checks failed",
+		Code:          ProgressCodePreflightFailed,
+	})
+	c.Assert(summary.Outcome, check.Equals, OperationOutcomeFailed)
+	c.Assert(summary.FailureReason, check.Equals, "preflight checks failed")
+	c.Assert(summary.Code, check.Equals, ProgressCodePreflightFailed)
+
+	// an operation that is still running, possibly without a progress entry
+	operation.State = OperationStateInstallInitiated
+	summary = SummarizeOperation(operation, nil)
+	c.Assert(summary.Outcome, check.Equals, OperationOutcomeInProgress)
+	c.Assert(summary.FailureReason, check.Equals, "")
+	c.Assert(summary.Duration, check.Equals, 10*time.Minute)
+}
+
+func (s *UtilsSuite) TestOperatorFromContext(c *check.C) {
+	contextOperator := &fakeContextOperator{name: "context"}
+	fallback := &fakeContextOperator{name: "fallback"}
+
+	// an empty context carries no operator
+	_, err := OperatorFromContext(context.TODO())
+	c.Assert(trace.IsNotFound(err), check.Equals, true)
+	c.Assert(OperatorFromContextOr(context.TODO(), fallback), check.Equals, Operator(fallback))
+
+	// the context operator takes precedence over the fallback
+	ctx := context.WithValue(context.TODO(), constants.OperatorContext, Operator(contextOperator))
+	operator, err := OperatorFromContext(ctx)
+	c.Assert(err, check.IsNil)
+	c.Assert(operator, check.Equals, Operator(contextOperator))
+	c.Assert(OperatorFromContextOr(ctx, fallback), check.Equals, Operator(contextOperator))
+
+	// a value of an unexpected type is rejected
+	ctx = context.WithValue(context.TODO(), constants.OperatorContext, "not an operator")
+	_, err = OperatorFromContext(ctx)
+	c.Assert(trace.IsBadParameter(err), check.Equals, true)
+	c.Assert(OperatorFromContextOr(ctx, fallback), check.Equals, Operator(fallback))
+}
+
+// fakeContextOperator implements just enough of the Operator interface
+// to be attached to a context in tests
+type fakeContextOperator struct {
+	Operator
+	name string
+}
+
+func (s *UtilsSuite) TestGetWizardClusterEnforcesSingleCluster(c *check.C) {
+	// no clusters: the error explains the wizard state is empty
+	operator := &fakeWizardOperator{}
+	_, err := GetWizardCluster(operator)
+	c.Assert(trace.IsBadParameter(err), check.Equals, true)
+	c.Assert(err, check.ErrorMatches, "(?s).*found none.*not created its cluster.*")
+
+	// multiple clusters: the error lists the cluster domains and hints
+	// at leftover state
+	operator.clusters = []Site{
+		{Domain: "first.example.com"},
+		{Domain: "second.example.com"},
+	}
+	_, err = GetWizardCluster(operator)
+	c.Assert(trace.IsBadParameter(err), check.Equals, true)
+	c.Assert(err, check.ErrorMatches,
+		"(?s).*found 2 \\(first.example.com, second.example.com\\).*leftover clusters.*")
+
+	// exactly one cluster satisfies the invariant
+	operator.clusters = []Site{{Domain: "single.example.com"}}
+	cluster, err := GetWizardCluster(operator)
+	c.Assert(err, check.IsNil)
+	c.Assert(cluster.Domain, check.Equals, "single.example.com")
+}
+
+// fakeWizardOperator serves a static cluster list standing in for the
+// wizard process operator
+type fakeWizardOperator struct {
+	Operator
+	clusters []Site
+}
+
+func (r *fakeWizardOperator) GetSites(accountID string) ([]Site, error) {
+	return r.clusters, nil
+}
+
+func (s *UtilsSuite) TestGetInstallOperationAny(c *check.C) {
+	// wizard mode: a single cluster with an install operation
+	operator := &fakeInstallOperator{
+		clusters: []Site{
+			{AccountID: "system", Domain: "wizard.example.com"},
+		},
+		operations: map[string]SiteOperations{
+			"wizard.example.com": {
+				{ID: "install-1", Type: OperationInstall, SiteDomain: "wizard.example.com"},
+			},
+		},
+	}
+	op, err := GetInstallOperationAny(operator)
+	c.Assert(err, check.IsNil)
+	c.Assert(op.ID, check.Equals, "install-1")
+
+	// cluster mode with leftover records: the wizard single-cluster
+	// invariant fails but the fallback searches all clusters, skipping
+	// the ones without an install operation
+	operator.clusters = []Site{
+		{AccountID: "system", Domain: "leftover.example.com"},
+		{AccountID: "system", Domain: "wizard.example.com"},
+	}
+	op, err = GetInstallOperationAny(operator)
+	c.Assert(err, check.IsNil)
+	c.Assert(op.ID, check.Equals, "install-1")
+
+	// no install operation in either mode
+	operator.operations = nil
+	_, err = GetInstallOperationAny(operator)
+	c.Assert(trace.IsNotFound(err), check.Equals, true,
+		check.Commentf("unexpected error: %v", err))
+	c.Assert(err, check.ErrorMatches,
+		"(?s).*no install operation found in either wizard or cluster mode.*")
+}
+
+// fakeInstallOperator serves static cluster and operation records standing
+// in for both wizard and regular cluster mode operators
+type fakeInstallOperator struct {
+	Operator
+	clusters   []Site
+	operations map[string]SiteOperations
+}
+
+func (r *fakeInstallOperator) GetSites(accountID string) ([]Site, error) {
+	return r.clusters, nil
+}
+
+func (r *fakeInstallOperator) GetSiteOperations(key SiteKey, filter OperationsFilter) (SiteOperations, error) {
+	return filter.Filter(r.operations[key.SiteDomain]), nil
+}
+
+func (r *fakeInstallOperator) GetSiteOperationProgress(key SiteOperationKey) (*ProgressEntry, error) {
+	return &ProgressEntry{SiteDomain: key.SiteDomain, OperationID: key.OperationID}, nil
+}
+
+func (s *UtilsSuite) TestGetSitesFiltered(c *check.C) {
+	operator := &fakeWizardOperator{clusters: []Site{
+		{
+			Domain: "active.example.com",
+			State:  SiteStateActive,
+			Labels: map[string]string{"env": "production"},
+		},
+		{
+			Domain: "degraded.example.com",
+			State:  SiteStateDegraded,
+			Labels: map[string]string{"env": "production", "team": "dev"},
+		},
+		{
+			Domain: "staging.example.com",
+			State:  SiteStateActive,
+			Labels: map[string]string{"env": "staging"},
+		},
+	}}
+
+	// an empty filter matches every cluster
+	clusters, err := GetSitesFiltered("account", operator, SiteFilter{})
+	c.Assert(err, check.IsNil)
+	c.Assert(domains(clusters), check.DeepEquals,
+		[]string{"active.example.com", "degraded.example.com", "staging.example.com"})
+
+	// filter by state
+	clusters, err = GetSitesFiltered("account", operator, SiteFilter{
+		States: []string{SiteStateActive},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(domains(clusters), check.DeepEquals,
+		[]string{"active.example.com", "staging.example.com"})
+
+	// filter by label
+	clusters, err = GetSitesFiltered("account", operator, SiteFilter{
+		Labels: map[string]string{"env": "production"},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(domains(clusters), check.DeepEquals,
+		[]string{"active.example.com", "degraded.example.com"})
+
+	// all criteria have to match
+	clusters, err = GetSitesFiltered("account", operator, SiteFilter{
+		States: []string{SiteStateActive},
+		Labels: map[string]string{"env": "production", "team": "dev"},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(clusters, check.HasLen, 0)
+
+	_, err = GetSitesFiltered("", operator, SiteFilter{})
+	c.Assert(trace.IsBadParameter(err), check.Equals, true,
+		check.Commentf("unexpected error: %v", err))
+}
+
+func domains(clusters []Site) []string {
+	var domains []string
+	for _, cluster := range clusters {
+		domains = append(domains, cluster.Domain)
+	}
+	return domains
+}
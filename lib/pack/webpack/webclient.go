@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"time"
@@ -225,6 +226,38 @@ func (c *Client) ReadPackage(loc loc.Locator) (*pack.PackageEnvelope, io.ReadClo
 	return envelope, re.Body(), nil
 }
 
+// ReadPackageAt returns the package contents starting at the given offset.
+// It also returns the offset the body actually starts at: the server is
+// free to ignore the range request in which case the body contains the
+// whole package and the returned offset is 0.
+func (c *Client) ReadPackageAt(loc loc.Locator, offset int64) (io.ReadCloser, int64, error) {
+	endpoint := c.Endpoint("repositories", loc.Repository, "packages", loc.Name, loc.Version, "file")
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, 0, trace.Wrap(err)
+	}
+	c.SetAuthHeader(req.Header)
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%v-", offset))
+	}
+	resp, err := c.HTTPClient().Do(req)
+	if err != nil {
+		return nil, 0, trace.Wrap(err)
+	}
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		return resp.Body, offset, nil
+	case http.StatusOK:
+		return resp.Body, 0, nil
+	}
+	defer resp.Body.Close()
+	bytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, trace.Wrap(err)
+	}
+	return nil, 0, trace.ReadError(resp.StatusCode, bytes)
+}
+
 func (c *Client) ReadPackageEnvelope(loc loc.Locator) (*pack.PackageEnvelope, error) {
 	out, err := c.Get(
 		c.Endpoint("repositories", loc.Repository,
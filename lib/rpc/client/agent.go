@@ -17,8 +17,11 @@ limitations under the License.
 package client
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"fmt"
+	"hash"
 	"io"
 	"io/ioutil"
 
@@ -27,6 +30,7 @@ import (
 
 	"github.com/gravitational/satellite/agent/proto/agentpb"
 	"github.com/gravitational/trace"
+	"github.com/gravitational/trace/trail"
 
 	"github.com/gogo/protobuf/types"
 	"github.com/sirupsen/logrus"
@@ -60,6 +64,16 @@ func (c *client) Validate(ctx context.Context, req *validationpb.ValidateRequest
 	return nil, trace.Wrap(err)
 }
 
+// CancelCommand cancels the in-flight remote commands tagged with the given
+// sequence number. Returns true if the agent found at least one such command
+func (c *client) CancelCommand(ctx context.Context, seq int32) (bool, error) {
+	resp, err := c.agent.CancelCommand(ctx, &pb.CancelCommandRequest{Seq: seq})
+	if err != nil {
+		return false, trail.FromGRPC(err)
+	}
+	return resp.Found, nil
+}
+
 // Shutdown requests remote agent to quit
 func (c *client) Shutdown(ctx context.Context, req *pb.ShutdownRequest) error {
 	_, err := c.agent.Shutdown(ctx, req)
@@ -96,11 +110,21 @@ func (c *client) command(ctx context.Context, log logrus.FieldLogger, stdout, st
 
 type streamContext struct {
 	commands map[int32][]string
+	// checksums accumulate digests of the received command output
+	// for commands executed with checksumming enabled
+	checksums map[int32]hash.Hash
+	// received counts the bytes of command output received per command
+	received map[int32]int64
 	log      logrus.FieldLogger
 }
 
 func processStream(stream pb.IncomingMessageStream, log logrus.FieldLogger, stdout, stderr io.Writer) error {
-	streamCtx := &streamContext{map[int32][]string{}, log}
+	streamCtx := &streamContext{
+		commands:  map[int32][]string{},
+		checksums: map[int32]hash.Hash{},
+		received:  map[int32]int64{},
+		log:       log,
+	}
 	if stdout == nil {
 		stdout = ioutil.Discard
 	}
@@ -127,6 +151,10 @@ func processStream(stream pb.IncomingMessageStream, log logrus.FieldLogger, stdo
 			err = trace.Wrap(streamCtx.processLogEntry(elem.LogEntry))
 		case *pb.Message_Error:
 			err = trace.Wrap(streamCtx.processError(elem.Error))
+		case *pb.Message_ExecHeartbeat:
+			err = trace.Wrap(streamCtx.processExecHeartbeat(elem.ExecHeartbeat))
+		case *pb.Message_ExecTruncated:
+			err = trace.Wrap(streamCtx.processExecTruncated(elem.ExecTruncated))
 		default:
 			err = trace.BadParameter("unexpected message %+v", msg.Element)
 		}
@@ -145,6 +173,20 @@ func (s *streamContext) processExecOutput(msg *pb.ExecOutput, stdout, stderr io.
 		entry = s.log.WithField("CMD", fmt.Sprintf("%s#%d", args[0], msg.Seq))
 	}
 
+	if msg.Compressed {
+		data, err := pb.UncompressData(msg.Data)
+		if err != nil {
+			return trace.Wrap(err, "failed to uncompress command output")
+		}
+		msg.Data = data
+	}
+
+	if _, ok := s.checksums[msg.Seq]; !ok {
+		s.checksums[msg.Seq] = sha256.New()
+	}
+	s.checksums[msg.Seq].Write(msg.Data)
+	s.received[msg.Seq] += int64(len(msg.Data))
+
 	switch msg.Fd {
 	case pb.ExecOutput_STDOUT:
 		if _, err := stdout.Write(msg.Data); err != nil {
@@ -164,17 +206,72 @@ func (s *streamContext) processExecOutput(msg *pb.ExecOutput, stdout, stderr io.
 
 func (s *streamContext) processExecStarted(msg *pb.ExecStarted) error {
 	s.commands[msg.Seq] = msg.Args
-	s.log.WithFields(logrus.Fields{trace.Component: "rpc",
+	fields := logrus.Fields{trace.Component: "rpc",
 		"seq": msg.Seq,
-	}).Debugf("Run %q.", msg.Args)
+	}
+	if msg.OperationSeq != 0 {
+		fields["operation_seq"] = msg.OperationSeq
+	}
+	s.log.WithFields(fields).Debugf("Run %q.", msg.Args)
 	return nil
 }
 
 func (s *streamContext) processExecCompleted(msg *pb.ExecCompleted) error {
-	s.log.WithFields(logrus.Fields{trace.Component: "rpc",
+	fields := logrus.Fields{trace.Component: "rpc",
 		"seq":  msg.Seq,
 		"exit": msg.ExitCode,
-	}).Debug("Completed.")
+	}
+	if msg.OperationSeq != 0 {
+		fields["operation_seq"] = msg.OperationSeq
+	}
+	s.log.WithFields(fields).Debug("Completed.")
+	if len(msg.OutputDigest) != 0 {
+		return trace.Wrap(s.verifyOutput(msg))
+	}
+	return nil
+}
+
+// verifyOutput verifies the received command output against the digest
+// and byte count reported by the agent to detect mid-stream truncation
+func (s *streamContext) verifyOutput(msg *pb.ExecCompleted) error {
+	if s.received[msg.Seq] != msg.OutputBytes {
+		return trace.BadParameter(
+			"command output truncated: received %v out of %v bytes",
+			s.received[msg.Seq], msg.OutputBytes)
+	}
+	checksum, ok := s.checksums[msg.Seq]
+	if !ok {
+		// the command did not produce any output
+		checksum = sha256.New()
+	}
+	digest := checksum.Sum(nil)
+	if !bytes.Equal(digest, msg.OutputDigest) {
+		return trace.BadParameter(
+			"command output checksum mismatch: received %x, expected %x",
+			digest, msg.OutputDigest)
+	}
+	return nil
+}
+
+// processExecTruncated handles a notification that the command's output
+// exceeded the requested cap. The command keeps running with the rest of
+// its output dropped, so the event is surfaced as a warning
+func (s *streamContext) processExecTruncated(msg *pb.ExecTruncated) error {
+	s.log.WithFields(logrus.Fields{trace.Component: "rpc",
+		"seq":   msg.Seq,
+		"limit": msg.Limit,
+		"total": msg.TotalBytes,
+	}).Warn("Command output truncated by the output cap.")
+	return nil
+}
+
+// processExecHeartbeat handles a heartbeat of a still running command.
+// Heartbeats only exist to keep the stream from going idle during quiet
+// phases and to indicate liveness, so there is nothing to do beyond logging
+func (s *streamContext) processExecHeartbeat(msg *pb.ExecHeartbeat) error {
+	s.log.WithFields(logrus.Fields{trace.Component: "rpc",
+		"seq": msg.Seq,
+	}).Debug("Heartbeat.")
 	return nil
 }
 
@@ -49,14 +49,29 @@ type Client interface {
 	GetRuntimeConfig(context.Context) (*pb.RuntimeConfig, error)
 	// GetCurrentTime returns agent's current time as UTC timestamp
 	GetCurrentTime(context.Context) (*time.Time, error)
+	// EstimateClockOffset estimates the clock offset between this node
+	// and the remote agent over the specified number of time samples.
+	// Returns the estimated offset along with the jitter of the measurements
+	EstimateClockOffset(ctx context.Context, samples int) (offset, jitter time.Duration, err error)
 	// GetVersion returns agent's version information
 	GetVersion(context.Context) (*pb.Version, error)
+	// GetCapabilities returns agent's capabilities for feature detection
+	GetCapabilities(context.Context) (*pb.Capabilities, error)
+	// GetPeers returns the agent's view of its gRPC peers and their health
+	GetPeers(context.Context) ([]*pb.PeerInfo, error)
+	// TailOperationLog follows the operation log on the remote node.
+	// Returns a channel that receives new log lines as they are written
+	TailOperationLog(ctx context.Context, operationID string, trailLines int) (<-chan string, error)
 	// CheckPorts executes a network port test
 	CheckPorts(context.Context, *validationpb.CheckPortsRequest) (*validationpb.CheckPortsResponse, error)
 	// CheckBandwidth executes a network bandwidth test
 	CheckBandwidth(context.Context, *validationpb.CheckBandwidthRequest) (*validationpb.CheckBandwidthResponse, error)
 	// CheckDisks executes disk performance test
 	CheckDisks(context.Context, *validationpb.CheckDisksRequest) (*validationpb.CheckDisksResponse, error)
+	// CancelCommand cancels the in-flight remote commands tagged with the
+	// given sequence number. Returns true if the agent found at least one
+	// such command
+	CancelCommand(ctx context.Context, seq int32) (bool, error)
 	// Shutdown requests remote agent to shut down
 	Shutdown(context.Context, *pb.ShutdownRequest) error
 	// Abort requests remote agent to uninstall
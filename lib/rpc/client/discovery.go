@@ -18,21 +18,27 @@ package client
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
 	"time"
 
+	"github.com/gravitational/gravity/lib/constants"
 	pb "github.com/gravitational/gravity/lib/rpc/proto"
 	"github.com/gravitational/gravity/lib/storage"
 
 	"github.com/gogo/protobuf/types"
 	"github.com/gravitational/trace"
 	"github.com/gravitational/trace/trail"
+	"github.com/sirupsen/logrus"
 )
 
 // GetSystemInfo queries remote system information
 func (c *client) GetSystemInfo(ctx context.Context) (storage.System, error) {
 	resp, err := c.discovery.GetSystemInfo(ctx, &types.Empty{})
 	if err != nil {
-		return nil, trace.Wrap(err)
+		return nil, trail.FromGRPC(err)
 	}
 
 	system, err := storage.UnmarshalSystemInfo(resp.Payload)
@@ -47,17 +53,49 @@ func (c *client) GetSystemInfo(ctx context.Context) (storage.System, error) {
 func (c *client) GetRuntimeConfig(ctx context.Context) (*pb.RuntimeConfig, error) {
 	config, err := c.discovery.GetRuntimeConfig(ctx, &types.Empty{})
 	if err != nil {
-		return nil, trace.Wrap(err)
+		return nil, trail.FromGRPC(err)
 	}
 
 	return config, nil
 }
 
+// DumpRuntimeConfig fetches the agent's runtime configuration and writes
+// a diagnostic dump of it to w, for inclusion in support bundles.
+// The dump is human-readable text by default or JSON when asJSON is set.
+// Sensitive fields are redacted before writing
+func DumpRuntimeConfig(ctx context.Context, clt Client, w io.Writer, asJSON bool) error {
+	config, err := clt.GetRuntimeConfig(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	config = redactRuntimeConfig(config)
+	if asJSON {
+		data, err := json.MarshalIndent(config, "", "  ")
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return trace.Wrap(err)
+	}
+	_, err = fmt.Fprintln(w, config.String())
+	return trace.Wrap(err)
+}
+
+// redactRuntimeConfig returns a copy of the provided runtime configuration
+// with the sensitive fields masked
+func redactRuntimeConfig(config *pb.RuntimeConfig) *pb.RuntimeConfig {
+	redacted := *config
+	if redacted.Token != "" {
+		redacted.Token = constants.Redacted
+	}
+	return &redacted
+}
+
 // GetCurrentTime returns agent's current time as UTC timestamp
 func (c *client) GetCurrentTime(ctx context.Context) (*time.Time, error) {
 	proto, err := c.discovery.GetCurrentTime(ctx, &types.Empty{})
 	if err != nil {
-		return nil, trace.Wrap(err)
+		return nil, trail.FromGRPC(err)
 	}
 
 	ts, err := types.TimestampFromProto(proto)
@@ -76,3 +114,106 @@ func (c *client) GetVersion(ctx context.Context) (*pb.Version, error) {
 	}
 	return version, nil
 }
+
+// GetCapabilities returns agent's capabilities for feature detection
+func (c *client) GetCapabilities(ctx context.Context) (*pb.Capabilities, error) {
+	capabilities, err := c.discovery.GetCapabilities(ctx, &types.Empty{})
+	if err != nil {
+		return nil, trail.FromGRPC(err)
+	}
+	return capabilities, nil
+}
+
+// GetPeers returns the agent's view of its gRPC peers and their health.
+// It complements GetRuntimeConfig by reflecting the actual peer
+// connections so orchestration can verify full mesh connectivity
+func (c *client) GetPeers(ctx context.Context) ([]*pb.PeerInfo, error) {
+	resp, err := c.discovery.GetPeers(ctx, &types.Empty{})
+	if err != nil {
+		return nil, trail.FromGRPC(err)
+	}
+	return resp.Peers, nil
+}
+
+// EstimateClockOffset estimates the clock offset between this node and the
+// remote agent by taking the specified number of time samples.
+// The slowest samples are discarded as outliers and the offset is computed
+// as the median of the remaining measurements, similar to a lightweight NTP
+// probe. Returns the estimated offset along with the jitter of the
+// measurements
+func (c *client) EstimateClockOffset(ctx context.Context, samples int) (offset, jitter time.Duration, err error) {
+	if samples < 1 {
+		return 0, 0, trace.BadParameter("expected a positive number of samples, got %v", samples)
+	}
+	type sample struct {
+		offset time.Duration
+		rtt    time.Duration
+	}
+	measurements := make([]sample, 0, samples)
+	for i := 0; i < samples; i++ {
+		started := time.Now()
+		remote, err := c.GetCurrentTime(ctx)
+		if err != nil {
+			return 0, 0, trace.Wrap(err)
+		}
+		rtt := time.Since(started)
+		// assume symmetric network delay: the remote timestamp is taken
+		// mid-way through the round-trip
+		measurements = append(measurements, sample{
+			offset: remote.Sub(started.Add(rtt / 2)),
+			rtt:    rtt,
+		})
+	}
+	// discard the slowest half of the samples as outliers
+	sort.Slice(measurements, func(i, j int) bool {
+		return measurements[i].rtt < measurements[j].rtt
+	})
+	measurements = measurements[:(len(measurements)+1)/2]
+	offsets := make([]time.Duration, 0, len(measurements))
+	for _, measurement := range measurements {
+		offsets = append(offsets, measurement.offset)
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	offset = offsets[len(offsets)/2]
+	for _, o := range offsets {
+		diff := o - offset
+		if diff < 0 {
+			diff = -diff
+		}
+		jitter += diff / time.Duration(len(offsets))
+	}
+	return offset, jitter, nil
+}
+
+// TailOperationLog follows the operation log on the remote node starting
+// with the specified number of trailing lines.
+// Returns a channel that receives new log lines as they are written.
+// The channel is closed when the stream terminates or ctx is cancelled
+func (c *client) TailOperationLog(ctx context.Context, operationID string, trailLines int) (<-chan string, error) {
+	stream, err := c.discovery.TailOperationLog(ctx, &pb.TailOperationLogRequest{
+		OperationId: operationID,
+		TrailLines:  int32(trailLines),
+	})
+	if err != nil {
+		return nil, trail.FromGRPC(err)
+	}
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		for {
+			msg, err := stream.Recv()
+			if err != nil {
+				if err != io.EOF && ctx.Err() == nil {
+					logrus.WithError(err).Warn("Failed to receive operation log line.")
+				}
+				return
+			}
+			select {
+			case lines <- msg.Text:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return lines, nil
+}
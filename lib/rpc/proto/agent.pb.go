@@ -48,7 +48,7 @@ func (x ExecOutput_FD) String() string {
 }
 
 func (ExecOutput_FD) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_56ede974c0020f77, []int{6, 0}
+	return fileDescriptor_56ede974c0020f77, []int{10, 0}
 }
 
 type LogEntry_Level int32
@@ -79,7 +79,7 @@ func (x LogEntry_Level) String() string {
 }
 
 func (LogEntry_Level) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_56ede974c0020f77, []int{7, 0}
+	return fileDescriptor_56ede974c0020f77, []int{11, 0}
 }
 
 // ShutdownRequest describes a request to shut down a report RPC agent
@@ -134,7 +134,72 @@ type CommandArgs struct {
 	// Env sets the environment for the command
 	Env map[string]string `protobuf:"bytes,3,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 	// WorkingDir specifies the working directory for the process
-	WorkingDir           string   `protobuf:"bytes,4,opt,name=working_dir,json=workingDir,proto3" json:"working_dir,omitempty"`
+	WorkingDir string `protobuf:"bytes,4,opt,name=working_dir,json=workingDir,proto3" json:"working_dir,omitempty"`
+	// CompressOutput specifies whether the command's output is compressed
+	// on the wire. Individual output messages are marked as compressed
+	CompressOutput bool `protobuf:"varint,5,opt,name=compress_output,json=compressOutput,proto3" json:"compress_output,omitempty"`
+	// ChecksumOutput specifies whether the command's output is checksummed.
+	// With checksumming enabled, the completion event carries the SHA-256
+	// digest and the total size of the output so the client can detect
+	// mid-stream truncation
+	ChecksumOutput bool `protobuf:"varint,6,opt,name=checksum_output,json=checksumOutput,proto3" json:"checksum_output,omitempty"`
+	// AllocatePty specifies whether the command is run attached
+	// to a pseudo-terminal with the combined output streamed back.
+	// Only supported on Linux/Unix agents
+	AllocatePty bool `protobuf:"varint,7,opt,name=allocate_pty,json=allocatePty,proto3" json:"allocate_pty,omitempty"`
+	// PtyColumns specifies the initial terminal width in PTY mode
+	PtyColumns uint32 `protobuf:"varint,8,opt,name=pty_columns,json=ptyColumns,proto3" json:"pty_columns,omitempty"`
+	// PtyRows specifies the initial terminal height in PTY mode
+	PtyRows uint32 `protobuf:"varint,9,opt,name=pty_rows,json=ptyRows,proto3" json:"pty_rows,omitempty"`
+	// HeartbeatIntervalMillis specifies how often the agent emits
+	// heartbeat messages while the command is running to keep the
+	// stream from going idle. 0 disables heartbeats
+	HeartbeatIntervalMillis int64 `protobuf:"varint,10,opt,name=heartbeat_interval_millis,json=heartbeatIntervalMillis,proto3" json:"heartbeat_interval_millis,omitempty"`
+	// Seq optionally tags the command with a client-assigned sequence
+	// number so it can be cancelled later with CancelCommand.
+	// 0 means the agent assigns the sequence number itself
+	Seq int32 `protobuf:"varint,11,opt,name=seq,proto3" json:"seq,omitempty"`
+	// OutputBufferSize specifies the maximum number of output bytes to
+	// coalesce into a single ExecOutput message. 0 sends every write
+	// immediately without buffering
+	OutputBufferSize int32 `protobuf:"varint,12,opt,name=output_buffer_size,json=outputBufferSize,proto3" json:"output_buffer_size,omitempty"`
+	// OutputFlushIntervalMillis specifies how often buffered output is
+	// flushed regardless of the buffer fill so the stream stays responsive.
+	// Only effective with a non-zero output_buffer_size
+	OutputFlushIntervalMillis int64 `protobuf:"varint,13,opt,name=output_flush_interval_millis,json=outputFlushIntervalMillis,proto3" json:"output_flush_interval_millis,omitempty"`
+	// ClearEnv starts the command with only the variables given in env
+	// instead of extending the agent's own environment, so sensitive agent
+	// variables do not leak into hooks
+	ClearEnv bool `protobuf:"varint,14,opt,name=clear_env,json=clearEnv,proto3" json:"clear_env,omitempty"`
+	// TimeoutMillis specifies the command's own execution timeout.
+	// The server may shorten the effective timeout to the time remaining
+	// until the operation deadline, if it has been told one.
+	// 0 means no timeout
+	TimeoutMillis int64 `protobuf:"varint,15,opt,name=timeout_millis,json=timeoutMillis,proto3" json:"timeout_millis,omitempty"`
+	// ClassifyOutput makes the server tag each output chunk as text or
+	// binary so clients rendering the output can treat binary data
+	// specially
+	ClassifyOutput bool `protobuf:"varint,16,opt,name=classify_output,json=classifyOutput,proto3" json:"classify_output,omitempty"`
+	// StripAnsiOutput makes the server remove ANSI terminal escape
+	// sequences from the output before it is streamed, so colored command
+	// output captured to log files is free of the escape noise
+	StripAnsiOutput bool `protobuf:"varint,17,opt,name=strip_ansi_output,json=stripAnsiOutput,proto3" json:"strip_ansi_output,omitempty"`
+	// OperationType optionally names the type of the operation the command
+	// is executed as part of (e.g. operation_install, operation_update) so
+	// the server can label its execution metrics accordingly
+	OperationType string `protobuf:"bytes,18,opt,name=operation_type,json=operationType,proto3" json:"operation_type,omitempty"`
+	// MaxOutputBytes optionally caps the amount of output forwarded for
+	// this command. Once the cap is reached the command keeps running but
+	// the remaining output is dropped and an ExecTruncated event reports
+	// the total bytes produced. 0 means no cap beyond the one the server
+	// may have been configured with
+	MaxOutputBytes int64 `protobuf:"varint,19,opt,name=max_output_bytes,json=maxOutputBytes,proto3" json:"max_output_bytes,omitempty"`
+	// OperationSeq optionally tags the command with an operation-wide
+	// sequence number assigned by the orchestrating client. The server
+	// echoes it in ExecStarted/ExecCompleted so the output of a batch of
+	// commands can be correlated with the steps that produced it. Unlike
+	// seq it is never assigned by the server. 0 means unset
+	OperationSeq         int64    `protobuf:"varint,20,opt,name=operation_seq,json=operationSeq,proto3" json:"operation_seq,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -192,6 +257,199 @@ func (m *CommandArgs) GetWorkingDir() string {
 	return ""
 }
 
+func (m *CommandArgs) GetCompressOutput() bool {
+	if m != nil {
+		return m.CompressOutput
+	}
+	return false
+}
+
+func (m *CommandArgs) GetChecksumOutput() bool {
+	if m != nil {
+		return m.ChecksumOutput
+	}
+	return false
+}
+
+func (m *CommandArgs) GetAllocatePty() bool {
+	if m != nil {
+		return m.AllocatePty
+	}
+	return false
+}
+
+func (m *CommandArgs) GetPtyColumns() uint32 {
+	if m != nil {
+		return m.PtyColumns
+	}
+	return 0
+}
+
+func (m *CommandArgs) GetPtyRows() uint32 {
+	if m != nil {
+		return m.PtyRows
+	}
+	return 0
+}
+
+func (m *CommandArgs) GetHeartbeatIntervalMillis() int64 {
+	if m != nil {
+		return m.HeartbeatIntervalMillis
+	}
+	return 0
+}
+
+func (m *CommandArgs) GetOutputBufferSize() int32 {
+	if m != nil {
+		return m.OutputBufferSize
+	}
+	return 0
+}
+
+func (m *CommandArgs) GetOutputFlushIntervalMillis() int64 {
+	if m != nil {
+		return m.OutputFlushIntervalMillis
+	}
+	return 0
+}
+
+func (m *CommandArgs) GetClearEnv() bool {
+	if m != nil {
+		return m.ClearEnv
+	}
+	return false
+}
+
+func (m *CommandArgs) GetTimeoutMillis() int64 {
+	if m != nil {
+		return m.TimeoutMillis
+	}
+	return 0
+}
+
+func (m *CommandArgs) GetClassifyOutput() bool {
+	if m != nil {
+		return m.ClassifyOutput
+	}
+	return false
+}
+
+func (m *CommandArgs) GetStripAnsiOutput() bool {
+	if m != nil {
+		return m.StripAnsiOutput
+	}
+	return false
+}
+
+func (m *CommandArgs) GetOperationType() string {
+	if m != nil {
+		return m.OperationType
+	}
+	return ""
+}
+
+func (m *CommandArgs) GetMaxOutputBytes() int64 {
+	if m != nil {
+		return m.MaxOutputBytes
+	}
+	return 0
+}
+
+func (m *CommandArgs) GetOperationSeq() int64 {
+	if m != nil {
+		return m.OperationSeq
+	}
+	return 0
+}
+
+func (m *CommandArgs) GetSeq() int32 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+// CancelCommandRequest describes a request to cancel in-flight commands
+type CancelCommandRequest struct {
+	// Seq specifies the sequence number of the commands to cancel
+	Seq                  int32    `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CancelCommandRequest) Reset()         { *m = CancelCommandRequest{} }
+func (m *CancelCommandRequest) String() string { return proto.CompactTextString(m) }
+func (*CancelCommandRequest) ProtoMessage()    {}
+func (*CancelCommandRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_56ede974c0020f77, []int{2}
+}
+func (m *CancelCommandRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CancelCommandRequest.Unmarshal(m, b)
+}
+func (m *CancelCommandRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CancelCommandRequest.Marshal(b, m, deterministic)
+}
+func (m *CancelCommandRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CancelCommandRequest.Merge(m, src)
+}
+func (m *CancelCommandRequest) XXX_Size() int {
+	return xxx_messageInfo_CancelCommandRequest.Size(m)
+}
+func (m *CancelCommandRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CancelCommandRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CancelCommandRequest proto.InternalMessageInfo
+
+func (m *CancelCommandRequest) GetSeq() int32 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+// CancelCommandResponse describes the result of a cancellation request
+type CancelCommandResponse struct {
+	// Found indicates whether any in-flight command matched the given
+	// sequence number and has been signalled to stop
+	Found                bool     `protobuf:"varint,1,opt,name=found,proto3" json:"found,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CancelCommandResponse) Reset()         { *m = CancelCommandResponse{} }
+func (m *CancelCommandResponse) String() string { return proto.CompactTextString(m) }
+func (*CancelCommandResponse) ProtoMessage()    {}
+func (*CancelCommandResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_56ede974c0020f77, []int{3}
+}
+func (m *CancelCommandResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CancelCommandResponse.Unmarshal(m, b)
+}
+func (m *CancelCommandResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CancelCommandResponse.Marshal(b, m, deterministic)
+}
+func (m *CancelCommandResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CancelCommandResponse.Merge(m, src)
+}
+func (m *CancelCommandResponse) XXX_Size() int {
+	return xxx_messageInfo_CancelCommandResponse.Size(m)
+}
+func (m *CancelCommandResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CancelCommandResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CancelCommandResponse proto.InternalMessageInfo
+
+func (m *CancelCommandResponse) GetFound() bool {
+	if m != nil {
+		return m.Found
+	}
+	return false
+}
+
 // Message is a union of various subtypes of event stream
 type Message struct {
 	// Types that are valid to be assigned to Element:
@@ -200,6 +458,8 @@ type Message struct {
 	//	*Message_ExecOutput
 	//	*Message_LogEntry
 	//	*Message_Error
+	//	*Message_ExecHeartbeat
+	//	*Message_ExecTruncated
 	Element              isMessage_Element `protobuf_oneof:"element"`
 	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
 	XXX_unrecognized     []byte            `json:"-"`
@@ -210,7 +470,7 @@ func (m *Message) Reset()         { *m = Message{} }
 func (m *Message) String() string { return proto.CompactTextString(m) }
 func (*Message) ProtoMessage()    {}
 func (*Message) Descriptor() ([]byte, []int) {
-	return fileDescriptor_56ede974c0020f77, []int{2}
+	return fileDescriptor_56ede974c0020f77, []int{4}
 }
 func (m *Message) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Message.Unmarshal(m, b)
@@ -249,12 +509,20 @@ type Message_LogEntry struct {
 type Message_Error struct {
 	Error *Error `protobuf:"bytes,5,opt,name=error,proto3,oneof"`
 }
+type Message_ExecHeartbeat struct {
+	ExecHeartbeat *ExecHeartbeat `protobuf:"bytes,6,opt,name=exec_heartbeat,json=execHeartbeat,proto3,oneof"`
+}
+type Message_ExecTruncated struct {
+	ExecTruncated *ExecTruncated `protobuf:"bytes,7,opt,name=exec_truncated,json=execTruncated,proto3,oneof"`
+}
 
 func (*Message_ExecStarted) isMessage_Element()   {}
 func (*Message_ExecCompleted) isMessage_Element() {}
 func (*Message_ExecOutput) isMessage_Element()    {}
 func (*Message_LogEntry) isMessage_Element()      {}
 func (*Message_Error) isMessage_Element()         {}
+func (*Message_ExecHeartbeat) isMessage_Element() {}
+func (*Message_ExecTruncated) isMessage_Element() {}
 
 func (m *Message) GetElement() isMessage_Element {
 	if m != nil {
@@ -298,6 +566,20 @@ func (m *Message) GetError() *Error {
 	return nil
 }
 
+func (m *Message) GetExecHeartbeat() *ExecHeartbeat {
+	if x, ok := m.GetElement().(*Message_ExecHeartbeat); ok {
+		return x.ExecHeartbeat
+	}
+	return nil
+}
+
+func (m *Message) GetExecTruncated() *ExecTruncated {
+	if x, ok := m.GetElement().(*Message_ExecTruncated); ok {
+		return x.ExecTruncated
+	}
+	return nil
+}
+
 // XXX_OneofWrappers is for the internal use of the proto package.
 func (*Message) XXX_OneofWrappers() []interface{} {
 	return []interface{}{
@@ -306,6 +588,8 @@ func (*Message) XXX_OneofWrappers() []interface{} {
 		(*Message_ExecOutput)(nil),
 		(*Message_LogEntry)(nil),
 		(*Message_Error)(nil),
+		(*Message_ExecHeartbeat)(nil),
+		(*Message_ExecTruncated)(nil),
 	}
 }
 
@@ -316,7 +600,11 @@ type ExecStarted struct {
 	// Args describes the running command
 	Args []string `protobuf:"bytes,2,rep,name=args,proto3" json:"args,omitempty"`
 	// Env defines the environment of the running command
-	Env                  []string `protobuf:"bytes,3,rep,name=env,proto3" json:"env,omitempty"`
+	Env []string `protobuf:"bytes,3,rep,name=env,proto3" json:"env,omitempty"`
+	// OperationSeq echoes the operation-wide sequence number the command
+	// was tagged with, see CommandArgs.operation_seq. 0 means the command
+	// was not tagged
+	OperationSeq         int64    `protobuf:"varint,4,opt,name=operation_seq,json=operationSeq,proto3" json:"operation_seq,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -326,7 +614,7 @@ func (m *ExecStarted) Reset()         { *m = ExecStarted{} }
 func (m *ExecStarted) String() string { return proto.CompactTextString(m) }
 func (*ExecStarted) ProtoMessage()    {}
 func (*ExecStarted) Descriptor() ([]byte, []int) {
-	return fileDescriptor_56ede974c0020f77, []int{3}
+	return fileDescriptor_56ede974c0020f77, []int{5}
 }
 func (m *ExecStarted) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ExecStarted.Unmarshal(m, b)
@@ -367,6 +655,13 @@ func (m *ExecStarted) GetEnv() []string {
 	return nil
 }
 
+func (m *ExecStarted) GetOperationSeq() int64 {
+	if m != nil {
+		return m.OperationSeq
+	}
+	return 0
+}
+
 // ExecComplete is sent when command completes
 type ExecCompleted struct {
 	// Seq specifies the command ID. Unique only in the current call scope
@@ -374,7 +669,22 @@ type ExecCompleted struct {
 	// ExitCode is the exit code command exited with
 	ExitCode int32 `protobuf:"varint,2,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
 	// Error specifies the command execution error
-	Error                *Error   `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	Error *Error `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	// OutputDigest is the SHA-256 digest of the command's output.
+	// Only set if checksumming has been requested
+	OutputDigest []byte `protobuf:"bytes,4,opt,name=output_digest,json=outputDigest,proto3" json:"output_digest,omitempty"`
+	// OutputBytes is the total size of the command's output in bytes.
+	// Only set if checksumming has been requested
+	OutputBytes int64 `protobuf:"varint,5,opt,name=output_bytes,json=outputBytes,proto3" json:"output_bytes,omitempty"`
+	// DurationMillis is the wall-clock command execution time in milliseconds
+	DurationMillis int64 `protobuf:"varint,6,opt,name=duration_millis,json=durationMillis,proto3" json:"duration_millis,omitempty"`
+	// TruncatedBytes is the number of output bytes suppressed by the
+	// output size cap. Only set when the output was actually truncated
+	TruncatedBytes int64 `protobuf:"varint,7,opt,name=truncated_bytes,json=truncatedBytes,proto3" json:"truncated_bytes,omitempty"`
+	// OperationSeq echoes the operation-wide sequence number the command
+	// was tagged with, see CommandArgs.operation_seq. 0 means the command
+	// was not tagged
+	OperationSeq         int64    `protobuf:"varint,8,opt,name=operation_seq,json=operationSeq,proto3" json:"operation_seq,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -384,7 +694,7 @@ func (m *ExecCompleted) Reset()         { *m = ExecCompleted{} }
 func (m *ExecCompleted) String() string { return proto.CompactTextString(m) }
 func (*ExecCompleted) ProtoMessage()    {}
 func (*ExecCompleted) Descriptor() ([]byte, []int) {
-	return fileDescriptor_56ede974c0020f77, []int{4}
+	return fileDescriptor_56ede974c0020f77, []int{6}
 }
 func (m *ExecCompleted) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ExecCompleted.Unmarshal(m, b)
@@ -425,6 +735,144 @@ func (m *ExecCompleted) GetError() *Error {
 	return nil
 }
 
+func (m *ExecCompleted) GetOutputDigest() []byte {
+	if m != nil {
+		return m.OutputDigest
+	}
+	return nil
+}
+
+func (m *ExecCompleted) GetOutputBytes() int64 {
+	if m != nil {
+		return m.OutputBytes
+	}
+	return 0
+}
+
+func (m *ExecCompleted) GetDurationMillis() int64 {
+	if m != nil {
+		return m.DurationMillis
+	}
+	return 0
+}
+
+func (m *ExecCompleted) GetTruncatedBytes() int64 {
+	if m != nil {
+		return m.TruncatedBytes
+	}
+	return 0
+}
+
+func (m *ExecCompleted) GetOperationSeq() int64 {
+	if m != nil {
+		return m.OperationSeq
+	}
+	return 0
+}
+
+// ExecHeartbeat is sent periodically while a command is running to keep
+// the stream warm during quiet phases and let the client detect liveness.
+// The client is expected to otherwise ignore it
+type ExecHeartbeat struct {
+	// Seq specifies the command ID. Unique only in the current call scope
+	Seq                  int32    `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ExecHeartbeat) Reset()         { *m = ExecHeartbeat{} }
+func (m *ExecHeartbeat) String() string { return proto.CompactTextString(m) }
+func (*ExecHeartbeat) ProtoMessage()    {}
+func (*ExecHeartbeat) Descriptor() ([]byte, []int) {
+	return fileDescriptor_56ede974c0020f77, []int{7}
+}
+func (m *ExecHeartbeat) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExecHeartbeat.Unmarshal(m, b)
+}
+func (m *ExecHeartbeat) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExecHeartbeat.Marshal(b, m, deterministic)
+}
+func (m *ExecHeartbeat) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExecHeartbeat.Merge(m, src)
+}
+func (m *ExecHeartbeat) XXX_Size() int {
+	return xxx_messageInfo_ExecHeartbeat.Size(m)
+}
+func (m *ExecHeartbeat) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExecHeartbeat.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExecHeartbeat proto.InternalMessageInfo
+
+func (m *ExecHeartbeat) GetSeq() int32 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+// ExecTruncated indicates that the command's output exceeded the requested
+// cap and the agent stopped forwarding it. The command itself was left to
+// run to completion
+type ExecTruncated struct {
+	// Seq specifies the command ID. Unique only in the current call scope
+	Seq int32 `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	// Limit is the output cap in bytes the command ran into
+	Limit int64 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	// TotalBytes is the total number of output bytes the command produced,
+	// including the suppressed ones
+	TotalBytes           int64    `protobuf:"varint,3,opt,name=total_bytes,json=totalBytes,proto3" json:"total_bytes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ExecTruncated) Reset()         { *m = ExecTruncated{} }
+func (m *ExecTruncated) String() string { return proto.CompactTextString(m) }
+func (*ExecTruncated) ProtoMessage()    {}
+func (*ExecTruncated) Descriptor() ([]byte, []int) {
+	return fileDescriptor_56ede974c0020f77, []int{8}
+}
+func (m *ExecTruncated) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExecTruncated.Unmarshal(m, b)
+}
+func (m *ExecTruncated) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExecTruncated.Marshal(b, m, deterministic)
+}
+func (m *ExecTruncated) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExecTruncated.Merge(m, src)
+}
+func (m *ExecTruncated) XXX_Size() int {
+	return xxx_messageInfo_ExecTruncated.Size(m)
+}
+func (m *ExecTruncated) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExecTruncated.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExecTruncated proto.InternalMessageInfo
+
+func (m *ExecTruncated) GetSeq() int32 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+func (m *ExecTruncated) GetLimit() int64 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *ExecTruncated) GetTotalBytes() int64 {
+	if m != nil {
+		return m.TotalBytes
+	}
+	return 0
+}
+
 // Error encapsulates error stack
 type Error struct {
 	// Messages specifies the error message
@@ -440,7 +888,7 @@ func (m *Error) Reset()         { *m = Error{} }
 func (m *Error) String() string { return proto.CompactTextString(m) }
 func (*Error) ProtoMessage()    {}
 func (*Error) Descriptor() ([]byte, []int) {
-	return fileDescriptor_56ede974c0020f77, []int{5}
+	return fileDescriptor_56ede974c0020f77, []int{9}
 }
 func (m *Error) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Error.Unmarshal(m, b)
@@ -476,19 +924,25 @@ func (m *Error) GetTraces() []string {
 
 // ExecOutput represents output of a running command
 type ExecOutput struct {
-	Seq                  int32         `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
-	Fd                   ExecOutput_FD `protobuf:"varint,2,opt,name=fd,proto3,enum=proto.ExecOutput_FD" json:"fd,omitempty"`
-	Data                 []byte        `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
-	XXX_unrecognized     []byte        `json:"-"`
-	XXX_sizecache        int32         `json:"-"`
+	Seq  int32         `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	Fd   ExecOutput_FD `protobuf:"varint,2,opt,name=fd,proto3,enum=proto.ExecOutput_FD" json:"fd,omitempty"`
+	Data []byte        `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	// Compressed specifies whether data is gzip-compressed
+	Compressed bool `protobuf:"varint,4,opt,name=compressed,proto3" json:"compressed,omitempty"`
+	// Binary marks the chunk as binary rather than text so clients can
+	// hex-dump or suppress it instead of corrupting the terminal.
+	// Only set when output classification has been requested
+	Binary               bool     `protobuf:"varint,5,opt,name=binary,proto3" json:"binary,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *ExecOutput) Reset()         { *m = ExecOutput{} }
 func (m *ExecOutput) String() string { return proto.CompactTextString(m) }
 func (*ExecOutput) ProtoMessage()    {}
 func (*ExecOutput) Descriptor() ([]byte, []int) {
-	return fileDescriptor_56ede974c0020f77, []int{6}
+	return fileDescriptor_56ede974c0020f77, []int{10}
 }
 func (m *ExecOutput) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ExecOutput.Unmarshal(m, b)
@@ -529,6 +983,20 @@ func (m *ExecOutput) GetData() []byte {
 	return nil
 }
 
+func (m *ExecOutput) GetCompressed() bool {
+	if m != nil {
+		return m.Compressed
+	}
+	return false
+}
+
+func (m *ExecOutput) GetBinary() bool {
+	if m != nil {
+		return m.Binary
+	}
+	return false
+}
+
 type LogEntry struct {
 	Message              string            `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
 	Fields               map[string]string `protobuf:"bytes,2,rep,name=fields,proto3" json:"fields,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
@@ -543,7 +1011,7 @@ func (m *LogEntry) Reset()         { *m = LogEntry{} }
 func (m *LogEntry) String() string { return proto.CompactTextString(m) }
 func (*LogEntry) ProtoMessage()    {}
 func (*LogEntry) Descriptor() ([]byte, []int) {
-	return fileDescriptor_56ede974c0020f77, []int{7}
+	return fileDescriptor_56ede974c0020f77, []int{11}
 }
 func (m *LogEntry) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_LogEntry.Unmarshal(m, b)
@@ -602,7 +1070,7 @@ func (m *UninstallRequest) Reset()         { *m = UninstallRequest{} }
 func (m *UninstallRequest) String() string { return proto.CompactTextString(m) }
 func (*UninstallRequest) ProtoMessage()    {}
 func (*UninstallRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_56ede974c0020f77, []int{8}
+	return fileDescriptor_56ede974c0020f77, []int{12}
 }
 func (m *UninstallRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_UninstallRequest.Unmarshal(m, b)
@@ -638,7 +1106,7 @@ type PeerJoinRequest struct {
 func (m *PeerJoinRequest) Reset()      { *m = PeerJoinRequest{} }
 func (*PeerJoinRequest) ProtoMessage() {}
 func (*PeerJoinRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_56ede974c0020f77, []int{9}
+	return fileDescriptor_56ede974c0020f77, []int{13}
 }
 func (m *PeerJoinRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_PeerJoinRequest.Unmarshal(m, b)
@@ -695,7 +1163,7 @@ type PeerLeaveRequest struct {
 func (m *PeerLeaveRequest) Reset()      { *m = PeerLeaveRequest{} }
 func (*PeerLeaveRequest) ProtoMessage() {}
 func (*PeerLeaveRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_56ede974c0020f77, []int{10}
+	return fileDescriptor_56ede974c0020f77, []int{14}
 }
 func (m *PeerLeaveRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_PeerLeaveRequest.Unmarshal(m, b)
@@ -742,9 +1210,13 @@ func init() {
 	proto.RegisterType((*ShutdownRequest)(nil), "proto.ShutdownRequest")
 	proto.RegisterType((*CommandArgs)(nil), "proto.CommandArgs")
 	proto.RegisterMapType((map[string]string)(nil), "proto.CommandArgs.EnvEntry")
+	proto.RegisterType((*CancelCommandRequest)(nil), "proto.CancelCommandRequest")
+	proto.RegisterType((*CancelCommandResponse)(nil), "proto.CancelCommandResponse")
 	proto.RegisterType((*Message)(nil), "proto.Message")
 	proto.RegisterType((*ExecStarted)(nil), "proto.ExecStarted")
 	proto.RegisterType((*ExecCompleted)(nil), "proto.ExecCompleted")
+	proto.RegisterType((*ExecHeartbeat)(nil), "proto.ExecHeartbeat")
+	proto.RegisterType((*ExecTruncated)(nil), "proto.ExecTruncated")
 	proto.RegisterType((*Error)(nil), "proto.Error")
 	proto.RegisterType((*ExecOutput)(nil), "proto.ExecOutput")
 	proto.RegisterType((*LogEntry)(nil), "proto.LogEntry")
@@ -757,59 +1229,93 @@ func init() {
 func init() { proto.RegisterFile("agent.proto", fileDescriptor_56ede974c0020f77) }
 
 var fileDescriptor_56ede974c0020f77 = []byte{
-	// 823 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x54, 0xcd, 0x6e, 0xdb, 0x46,
-	0x10, 0x16, 0x29, 0x51, 0x16, 0x87, 0x8e, 0xcd, 0x2e, 0x52, 0x57, 0x90, 0x03, 0xc4, 0x25, 0x72,
-	0x30, 0xd0, 0x96, 0x6e, 0x95, 0xa2, 0x69, 0x8c, 0xf6, 0xe0, 0x5a, 0x32, 0xd4, 0xc2, 0x45, 0x8a,
-	0x75, 0x82, 0x1e, 0x05, 0x4a, 0x1c, 0xb2, 0x44, 0x48, 0xae, 0xb3, 0x5c, 0x2a, 0x76, 0xfb, 0x0a,
-	0x7d, 0x80, 0x1c, 0x7b, 0xeb, 0xcb, 0xf4, 0xa1, 0x8a, 0xfd, 0xa1, 0xc4, 0x38, 0x51, 0x81, 0x5e,
-	0x72, 0xe2, 0xfc, 0x7d, 0xb3, 0xdf, 0xce, 0xc7, 0x1d, 0xf0, 0xa2, 0x14, 0x4b, 0x11, 0x5e, 0x73,
-	0x26, 0x18, 0x71, 0xd4, 0x67, 0x74, 0x98, 0x32, 0x96, 0xe6, 0x78, 0xa2, 0xbc, 0x45, 0x9d, 0x9c,
-	0x60, 0x71, 0x2d, 0x6e, 0x75, 0xcd, 0x68, 0x3f, 0xce, 0xaa, 0x25, 0x5b, 0x21, 0x6f, 0x02, 0x90,
-	0xb2, 0x94, 0x69, 0x3b, 0x38, 0x81, 0xfd, 0xab, 0xdf, 0x6a, 0x11, 0xb3, 0xd7, 0x25, 0xc5, 0x57,
-	0x35, 0x56, 0x82, 0x3c, 0x00, 0x77, 0xc9, 0x8a, 0xeb, 0x1c, 0x05, 0xc6, 0x43, 0xeb, 0xc8, 0x3a,
-	0x1e, 0xd0, 0x4d, 0x20, 0xf8, 0xc7, 0x02, 0xef, 0x9c, 0x15, 0x45, 0x54, 0xc6, 0x67, 0x3c, 0xad,
-	0x08, 0x81, 0x5e, 0xc4, 0xd3, 0x6a, 0x68, 0x1d, 0x75, 0x8f, 0x5d, 0xaa, 0x6c, 0xf2, 0x29, 0xec,
-	0x56, 0x98, 0x27, 0xf3, 0xa5, 0xae, 0x1b, 0xda, 0xaa, 0x89, 0x27, 0x63, 0x06, 0x4a, 0xbe, 0x80,
-	0x2e, 0x96, 0xab, 0x61, 0xf7, 0xa8, 0x7b, 0xec, 0x8d, 0x0f, 0x35, 0x99, 0xb0, 0xd5, 0x37, 0x9c,
-	0x96, 0xab, 0x69, 0x29, 0xf8, 0x2d, 0x95, 0x75, 0xe4, 0x21, 0x78, 0xaf, 0x19, 0x7f, 0x99, 0x95,
-	0xe9, 0x3c, 0xce, 0xf8, 0xb0, 0x77, 0x64, 0x1d, 0xbb, 0x14, 0x4c, 0x68, 0x92, 0xf1, 0xd1, 0x37,
-	0x30, 0x68, 0x10, 0xc4, 0x87, 0xee, 0x4b, 0xbc, 0x55, 0xd4, 0x5d, 0x2a, 0x4d, 0x72, 0x1f, 0x9c,
-	0x55, 0x94, 0xd7, 0xa8, 0x98, 0xb8, 0x54, 0x3b, 0xa7, 0xf6, 0xb7, 0x56, 0xf0, 0xc6, 0x86, 0x9d,
-	0x9f, 0xb1, 0xaa, 0xa2, 0x14, 0xc9, 0x13, 0xd8, 0xc5, 0x1b, 0x5c, 0xce, 0x2b, 0x11, 0xf1, 0xe6,
-	0xee, 0xde, 0x98, 0x18, 0x72, 0xd3, 0x1b, 0x5c, 0x5e, 0xe9, 0xcc, 0xac, 0x43, 0x3d, 0xdc, 0xb8,
-	0xe4, 0x7b, 0xd8, 0x53, 0xc0, 0xcd, 0xd8, 0x6c, 0x05, 0xbd, 0xdf, 0x82, 0x9e, 0x37, 0xb9, 0x59,
-	0x87, 0xde, 0xc3, 0x76, 0x80, 0x7c, 0x0d, 0xaa, 0xdb, 0x9c, 0xd5, 0xe2, 0xba, 0x16, 0xc3, 0xae,
-	0xc2, 0x7e, 0xd4, 0xc2, 0x3e, 0x53, 0x89, 0x59, 0x87, 0x02, 0xae, 0x3d, 0x12, 0x82, 0x9b, 0xb3,
-	0x74, 0x8e, 0xf2, 0xca, 0x6a, 0x20, 0xde, 0x78, 0xdf, 0x60, 0x2e, 0x59, 0xaa, 0x26, 0x31, 0xeb,
-	0xd0, 0x41, 0x6e, 0x6c, 0xf2, 0x08, 0x1c, 0xe4, 0x9c, 0xf1, 0xa1, 0xa3, 0x6a, 0x77, 0x9b, 0xfe,
-	0x32, 0x36, 0xeb, 0x50, 0x9d, 0xfc, 0xc1, 0x85, 0x1d, 0xcc, 0xb1, 0xc0, 0x52, 0x04, 0x53, 0xf0,
-	0x5a, 0x77, 0x96, 0x53, 0xad, 0xf0, 0x95, 0x1a, 0x8a, 0x43, 0xa5, 0xb9, 0x96, 0xde, 0x6e, 0x49,
-	0xef, 0x6f, 0x74, 0x75, 0x95, 0x74, 0xc1, 0x02, 0xee, 0xbd, 0x75, 0xff, 0xf7, 0x34, 0x3a, 0x04,
-	0x17, 0x6f, 0x32, 0x31, 0x5f, 0xb2, 0x58, 0x4b, 0xe4, 0xd0, 0x81, 0x0c, 0x9c, 0xb3, 0x18, 0x49,
-	0xd0, 0xf0, 0xee, 0xbe, 0xcb, 0xdb, 0xb0, 0x0e, 0x9e, 0x82, 0xa3, 0x7c, 0x32, 0x84, 0x9d, 0x42,
-	0xab, 0x69, 0xe4, 0x6f, 0x5c, 0x72, 0x00, 0x7d, 0xc1, 0xa3, 0x25, 0x36, 0x74, 0x8d, 0x17, 0xac,
-	0x00, 0x36, 0x23, 0x7e, 0x0f, 0xb7, 0x47, 0x60, 0x27, 0x5a, 0xcf, 0xbd, 0xb7, 0xf4, 0xd4, 0x80,
-	0xf0, 0x62, 0x42, 0xed, 0x24, 0x96, 0xa3, 0x88, 0x23, 0x11, 0x29, 0x8e, 0xbb, 0x54, 0xd9, 0xc1,
-	0x03, 0xb0, 0x2f, 0x26, 0x04, 0xa0, 0x7f, 0xf5, 0x7c, 0xf2, 0xec, 0xc5, 0x73, 0xbf, 0x63, 0xec,
-	0x29, 0xa5, 0xbe, 0x15, 0xfc, 0x69, 0xc3, 0xa0, 0xd1, 0xe9, 0x3f, 0x68, 0x3f, 0x86, 0x7e, 0x92,
-	0x61, 0x1e, 0x6b, 0xda, 0x9b, 0xa7, 0xd2, 0x40, 0xc3, 0x0b, 0x95, 0xd5, 0x4f, 0xc5, 0x94, 0x92,
-	0xcf, 0xc0, 0xc9, 0x71, 0x85, 0xb9, 0xa2, 0xb3, 0x37, 0xfe, 0xf8, 0x2e, 0xe6, 0x52, 0x26, 0xa9,
-	0xae, 0x69, 0x0d, 0xa6, 0xd7, 0x1e, 0xcc, 0xe8, 0x29, 0x78, 0xad, 0xde, 0xff, 0xeb, 0x51, 0x7d,
-	0x05, 0x8e, 0x3a, 0x82, 0xb8, 0xe0, 0x4c, 0x70, 0x51, 0xa7, 0x7e, 0x87, 0x0c, 0xa0, 0xf7, 0x63,
-	0x99, 0x30, 0xdf, 0x92, 0xd6, 0xaf, 0x11, 0x2f, 0x7d, 0x5b, 0xa6, 0x95, 0x6c, 0x7e, 0x37, 0x20,
-	0xe0, 0xbf, 0x28, 0xb3, 0xb2, 0x12, 0x51, 0x9e, 0x9b, 0x45, 0x14, 0xfc, 0x0e, 0xfb, 0xbf, 0x20,
-	0xf2, 0x9f, 0x58, 0xb6, 0xde, 0x4d, 0xf2, 0x97, 0x8b, 0x63, 0x6e, 0x68, 0x28, 0x9b, 0x7c, 0x0e,
-	0xfd, 0x25, 0x2b, 0x93, 0x2c, 0xbd, 0xf3, 0xea, 0x68, 0x5d, 0x8a, 0xac, 0xc0, 0x73, 0x95, 0xa3,
-	0xa6, 0x46, 0x6e, 0x92, 0xea, 0xb6, 0x12, 0x58, 0xcc, 0xb3, 0x32, 0x61, 0x46, 0x30, 0xd0, 0x21,
-	0x49, 0xf0, 0xb4, 0xf7, 0xe6, 0xaf, 0x87, 0x9d, 0xe0, 0x0f, 0xf0, 0xe5, 0xd9, 0x97, 0x18, 0xad,
-	0xf0, 0x43, 0x1f, 0x3e, 0xfe, 0xdb, 0x06, 0xe7, 0x4c, 0x6e, 0x79, 0x72, 0x0a, 0x83, 0x66, 0x3d,
-	0x93, 0x03, 0xd3, 0xfa, 0xce, 0xbe, 0x1e, 0x1d, 0x84, 0x7a, 0xfb, 0x87, 0xcd, 0xf6, 0x0f, 0xa7,
-	0x72, 0xfb, 0x93, 0x27, 0xe0, 0x9c, 0x2d, 0x18, 0x17, 0x64, 0x4b, 0xc1, 0x56, 0xe0, 0x09, 0xec,
-	0x34, 0x6b, 0x9a, 0xbc, 0xbb, 0x99, 0x47, 0x7b, 0x26, 0x66, 0xd6, 0xe6, 0x97, 0x96, 0x64, 0xd9,
-	0x08, 0xb5, 0x66, 0x79, 0x47, 0xb9, 0xad, 0x87, 0x7d, 0x07, 0xee, 0x7a, 0xd0, 0xe4, 0x93, 0x16,
-	0xb8, 0x3d, 0xfa, 0x6d, 0xe8, 0x45, 0x5f, 0xf9, 0x8f, 0xff, 0x0d, 0x00, 0x00, 0xff, 0xff, 0xdb,
-	0xf4, 0xaf, 0x5c, 0x15, 0x07, 0x00, 0x00,
+	// 1367 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x56, 0xdb, 0x6e, 0xdb, 0x46,
+	0x13, 0xd6, 0xc1, 0x94, 0xa5, 0x91, 0x2c, 0x29, 0xfb, 0x3b, 0x09, 0x63, 0x1b, 0x7f, 0x1c, 0x36,
+	0x41, 0x84, 0x36, 0x91, 0x5b, 0xa7, 0x68, 0x1a, 0xa3, 0x45, 0xe1, 0xf8, 0x00, 0x27, 0x48, 0x90,
+	0x80, 0x76, 0xd0, 0xde, 0x11, 0xb4, 0x38, 0xa2, 0xd9, 0x90, 0x5c, 0x85, 0xbb, 0x94, 0xcd, 0xf4,
+	0x15, 0x7a, 0x5d, 0xf4, 0xb2, 0x7d, 0x81, 0xbe, 0x47, 0x9f, 0xa7, 0x2f, 0x50, 0xec, 0x89, 0x92,
+	0x4f, 0x05, 0x7a, 0xd3, 0x2b, 0xee, 0x7e, 0xf3, 0xcd, 0xec, 0xec, 0xcc, 0xce, 0x70, 0xa0, 0xed,
+	0x87, 0x98, 0xf2, 0xe1, 0x24, 0xa3, 0x9c, 0x12, 0x4b, 0x7e, 0x56, 0x56, 0x43, 0x4a, 0xc3, 0x18,
+	0x37, 0xe4, 0xee, 0x38, 0x1f, 0x6f, 0x60, 0x32, 0xe1, 0x85, 0xe2, 0xac, 0xf4, 0x82, 0x88, 0x8d,
+	0xe8, 0x14, 0x33, 0x03, 0x40, 0x48, 0x43, 0xaa, 0xd6, 0xce, 0x06, 0xf4, 0x0e, 0x4f, 0x72, 0x1e,
+	0xd0, 0xd3, 0xd4, 0xc5, 0x0f, 0x39, 0x32, 0x4e, 0xd6, 0xa0, 0x35, 0xa2, 0xc9, 0x24, 0x46, 0x8e,
+	0x81, 0x5d, 0x5d, 0xaf, 0x0e, 0x9a, 0xee, 0x0c, 0x70, 0xfe, 0x6c, 0x40, 0x7b, 0x87, 0x26, 0x89,
+	0x9f, 0x06, 0xdb, 0x59, 0xc8, 0x08, 0x81, 0x05, 0x3f, 0x0b, 0x99, 0x5d, 0x5d, 0xaf, 0x0f, 0x5a,
+	0xae, 0x5c, 0x93, 0x7b, 0xd0, 0x61, 0x18, 0x8f, 0xbd, 0x91, 0xe2, 0xd9, 0x35, 0x69, 0xa4, 0x2d,
+	0x30, 0xad, 0x4a, 0x1e, 0x43, 0x1d, 0xd3, 0xa9, 0x5d, 0x5f, 0xaf, 0x0f, 0xda, 0x9b, 0xab, 0xca,
+	0x99, 0xe1, 0x9c, 0xdd, 0xe1, 0x5e, 0x3a, 0xdd, 0x4b, 0x79, 0x56, 0xb8, 0x82, 0x47, 0xee, 0x42,
+	0xfb, 0x94, 0x66, 0xef, 0xa3, 0x34, 0xf4, 0x82, 0x28, 0xb3, 0x17, 0xd6, 0xab, 0x83, 0x96, 0x0b,
+	0x1a, 0xda, 0x8d, 0x32, 0xf2, 0x10, 0x7a, 0xc2, 0xc7, 0x0c, 0x19, 0xf3, 0x68, 0xce, 0x27, 0x39,
+	0xb7, 0x2d, 0x79, 0x6a, 0xd7, 0xc0, 0x6f, 0x24, 0x2a, 0x89, 0x27, 0x38, 0x7a, 0xcf, 0xf2, 0xc4,
+	0x10, 0x1b, 0x9a, 0xa8, 0x61, 0x4d, 0xbc, 0x07, 0x1d, 0x3f, 0x8e, 0xe9, 0xc8, 0xe7, 0xe8, 0x4d,
+	0x78, 0x61, 0x2f, 0xaa, 0x4b, 0x18, 0xec, 0x2d, 0x2f, 0x84, 0x57, 0x13, 0x5e, 0x78, 0x23, 0x1a,
+	0xe7, 0x49, 0xca, 0xec, 0xe6, 0x7a, 0x75, 0xb0, 0xe4, 0xc2, 0x84, 0x17, 0x3b, 0x0a, 0x21, 0x77,
+	0xa0, 0x29, 0x08, 0x19, 0x3d, 0x65, 0x76, 0x4b, 0x4a, 0x17, 0x27, 0xbc, 0x70, 0xe9, 0x29, 0x23,
+	0x5b, 0x70, 0xe7, 0x04, 0xfd, 0x8c, 0x1f, 0xa3, 0xcf, 0xbd, 0x28, 0xe5, 0x98, 0x4d, 0xfd, 0xd8,
+	0x4b, 0xa2, 0x38, 0x8e, 0x98, 0x0d, 0xeb, 0xd5, 0x41, 0xdd, 0xbd, 0x5d, 0x12, 0x5e, 0x68, 0xf9,
+	0x6b, 0x29, 0x26, 0x7d, 0xa8, 0x33, 0xfc, 0x60, 0xb7, 0xd7, 0xab, 0x03, 0xcb, 0x15, 0x4b, 0xf2,
+	0x08, 0x88, 0xba, 0x8c, 0x77, 0x9c, 0x8f, 0xc7, 0x98, 0x79, 0x2c, 0xfa, 0x88, 0x76, 0x47, 0x12,
+	0xfa, 0x4a, 0xf2, 0x5c, 0x0a, 0x0e, 0xa3, 0x8f, 0x48, 0xbe, 0x83, 0x35, 0xcd, 0x1e, 0xc7, 0x39,
+	0x3b, 0xb9, 0x74, 0xfc, 0x92, 0x3c, 0xfe, 0x8e, 0xe2, 0xec, 0x0b, 0xca, 0x05, 0x07, 0x56, 0xa1,
+	0x35, 0x8a, 0xd1, 0xcf, 0x3c, 0x91, 0xc3, 0xae, 0x0c, 0x4c, 0x53, 0x02, 0x7b, 0xe9, 0x94, 0x3c,
+	0x80, 0x2e, 0x8f, 0x12, 0xa4, 0x39, 0x37, 0xf6, 0x7a, 0xd2, 0xde, 0x92, 0x46, 0xb5, 0x0d, 0x91,
+	0x88, 0xd8, 0x67, 0x2c, 0x1a, 0x17, 0x26, 0x11, 0x7d, 0x9d, 0x08, 0x0d, 0xeb, 0x44, 0x7c, 0x0a,
+	0x37, 0x18, 0xcf, 0xa2, 0x89, 0xe7, 0xa7, 0x2c, 0x32, 0xd4, 0x1b, 0x92, 0xda, 0x93, 0x82, 0xed,
+	0x94, 0x45, 0x9a, 0xfb, 0x00, 0xba, 0x74, 0x82, 0x99, 0xcf, 0x23, 0x9a, 0x7a, 0xbc, 0x98, 0xa0,
+	0x4d, 0xe4, 0x53, 0x59, 0x2a, 0xd1, 0xa3, 0x62, 0x82, 0x64, 0x00, 0xfd, 0xc4, 0x3f, 0xf3, 0x4c,
+	0xc8, 0x0a, 0x8e, 0xcc, 0xfe, 0x9f, 0x74, 0xb2, 0x9b, 0xf8, 0x67, 0xca, 0xd6, 0x73, 0x81, 0x92,
+	0x4f, 0x60, 0xa6, 0xea, 0x89, 0xa0, 0x2f, 0x4b, 0x5a, 0xa7, 0x04, 0x0f, 0xf1, 0xc3, 0xca, 0x57,
+	0xd0, 0x34, 0xcf, 0x55, 0xe4, 0xe6, 0x3d, 0x16, 0xb2, 0x6e, 0x5a, 0xae, 0x58, 0x92, 0x65, 0xb0,
+	0xa6, 0x7e, 0x9c, 0xa3, 0x2c, 0x83, 0x96, 0xab, 0x36, 0x5b, 0xb5, 0xaf, 0xab, 0xce, 0x00, 0x96,
+	0x77, 0xfc, 0x74, 0x84, 0xb1, 0x7e, 0xf8, 0xa6, 0x02, 0x75, 0x7e, 0xab, 0x65, 0x7e, 0x9d, 0xc7,
+	0x70, 0xf3, 0x02, 0x93, 0x4d, 0x68, 0xca, 0x50, 0x18, 0x1f, 0xd3, 0x3c, 0x35, 0x85, 0xaa, 0x36,
+	0xce, 0x2f, 0x75, 0x58, 0x7c, 0x8d, 0x8c, 0xf9, 0x21, 0x92, 0xa7, 0xd0, 0xc1, 0x33, 0x1c, 0x79,
+	0x8c, 0xfb, 0x99, 0xa9, 0xe8, 0xf6, 0x26, 0xd1, 0x25, 0xb7, 0x77, 0x86, 0xa3, 0x43, 0x25, 0x39,
+	0xa8, 0xb8, 0x6d, 0x9c, 0x6d, 0xc9, 0xb7, 0xd0, 0x95, 0x8a, 0xb3, 0x66, 0x50, 0x93, 0xaa, 0xcb,
+	0x73, 0xaa, 0x3b, 0x46, 0x76, 0x50, 0x71, 0x97, 0x70, 0x1e, 0x20, 0x5f, 0x82, 0xb4, 0x66, 0x12,
+	0x56, 0x97, 0xba, 0x37, 0xe6, 0x74, 0x55, 0x98, 0x0f, 0x2a, 0x2e, 0x60, 0xb9, 0x23, 0x43, 0x68,
+	0xc5, 0x34, 0xf4, 0x50, 0xc4, 0x52, 0x96, 0x79, 0x7b, 0xb3, 0xa7, 0x75, 0x5e, 0xd1, 0x50, 0x86,
+	0xf8, 0xa0, 0xe2, 0x36, 0x63, 0xbd, 0x26, 0xf7, 0xc1, 0xc2, 0x2c, 0xa3, 0x99, 0xac, 0xf6, 0xf6,
+	0x66, 0xc7, 0xd8, 0x17, 0xd8, 0x41, 0xc5, 0x55, 0xc2, 0xf2, 0x2a, 0x65, 0x41, 0xc9, 0x9a, 0x3f,
+	0x7f, 0x95, 0x03, 0x23, 0x33, 0x57, 0x29, 0x81, 0x52, 0x9d, 0x67, 0x79, 0x2a, 0x6a, 0x3f, 0x90,
+	0xcd, 0xe0, 0xbc, 0xfa, 0x91, 0x91, 0x19, 0xf5, 0x12, 0x78, 0xde, 0x82, 0x45, 0x8c, 0x31, 0xc1,
+	0x94, 0x3b, 0x3f, 0x42, 0x7b, 0x2e, 0xe2, 0x97, 0x13, 0x5d, 0xb6, 0xd3, 0xda, 0x5c, 0x3b, 0xed,
+	0xcf, 0x7a, 0x65, 0x4b, 0xb5, 0xc3, 0x4b, 0xaf, 0x72, 0xe1, 0xf2, 0xab, 0x74, 0x7e, 0xaf, 0xc1,
+	0xd2, 0xb9, 0x1c, 0x5d, 0x71, 0xdc, 0x2a, 0xb4, 0xf0, 0x2c, 0xe2, 0xde, 0x88, 0x06, 0xea, 0x7d,
+	0x5a, 0x6e, 0x53, 0x00, 0x3b, 0x34, 0x40, 0xe2, 0x98, 0xd8, 0xd6, 0x2f, 0xc7, 0xd6, 0x44, 0x56,
+	0x78, 0xa2, 0xaa, 0x28, 0x88, 0x42, 0x64, 0x5c, 0x7a, 0xd2, 0x71, 0x3b, 0x0a, 0xdc, 0x95, 0x98,
+	0x68, 0xa5, 0xe7, 0x4a, 0xcd, 0x92, 0xde, 0xb6, 0xe9, 0x5c, 0x9d, 0x3d, 0x84, 0x5e, 0x90, 0xeb,
+	0x0b, 0xe9, 0xae, 0xd1, 0x50, 0x05, 0x69, 0xe0, 0x59, 0xdb, 0x28, 0xd3, 0xa0, 0xcd, 0x2d, 0x2a,
+	0x62, 0x09, 0x5f, 0x53, 0xb9, 0xcd, 0x2b, 0x62, 0x74, 0x4f, 0x85, 0x68, 0x96, 0xea, 0xcb, 0xa5,
+	0xf7, 0x83, 0xa2, 0x94, 0xe9, 0xbc, 0x22, 0x8a, 0xcb, 0x60, 0xc5, 0x51, 0x12, 0x71, 0x19, 0xc1,
+	0xba, 0xab, 0x36, 0xe2, 0xef, 0xc0, 0x29, 0xf7, 0x63, 0xed, 0x65, 0x5d, 0xca, 0x40, 0x42, 0xd2,
+	0x43, 0xe7, 0x19, 0x58, 0x32, 0x96, 0xc4, 0x86, 0xc5, 0x44, 0x55, 0xab, 0xee, 0x1b, 0x66, 0x4b,
+	0x6e, 0x41, 0x83, 0x67, 0xfe, 0x08, 0xcd, 0x83, 0xd0, 0x3b, 0xe7, 0x8f, 0x2a, 0xc0, 0xac, 0x86,
+	0xae, 0x70, 0xe9, 0x3e, 0xd4, 0xc6, 0xaa, 0x60, 0xbb, 0xe7, 0x9e, 0xa9, 0x52, 0x18, 0xee, 0xef,
+	0xba, 0xb5, 0x71, 0x20, 0x5e, 0x5b, 0xe0, 0x73, 0x5f, 0xfa, 0xd6, 0x71, 0xe5, 0x9a, 0xfc, 0x1f,
+	0xc0, 0xfc, 0x32, 0x31, 0x90, 0xe9, 0x6c, 0xba, 0x73, 0x88, 0x70, 0xe9, 0x38, 0x4a, 0xfd, 0xac,
+	0xd0, 0x3f, 0x58, 0xbd, 0x73, 0xd6, 0xa0, 0xb6, 0xbf, 0x4b, 0x00, 0x1a, 0x87, 0x47, 0xbb, 0x6f,
+	0xde, 0x1d, 0xf5, 0x2b, 0x7a, 0xbd, 0xe7, 0xba, 0xfd, 0xaa, 0xf3, 0x73, 0x0d, 0x9a, 0xa6, 0x80,
+	0xff, 0xe1, 0xbe, 0x4f, 0xa0, 0x31, 0x8e, 0x30, 0x0e, 0xd4, 0x7d, 0x67, 0x93, 0x81, 0x51, 0x1d,
+	0xee, 0x4b, 0xa9, 0x9a, 0x0c, 0x34, 0x95, 0x7c, 0x06, 0x56, 0x8c, 0x53, 0x8c, 0xe5, 0x35, 0xba,
+	0x9b, 0x37, 0x2f, 0xea, 0xbc, 0x12, 0x42, 0x57, 0x71, 0xe6, 0x22, 0xba, 0x30, 0x1f, 0xd1, 0x95,
+	0x67, 0xd0, 0x9e, 0xb3, 0xfd, 0xaf, 0xda, 0xf8, 0x17, 0x60, 0xc9, 0x23, 0x48, 0x0b, 0xac, 0x5d,
+	0x3c, 0xce, 0xc3, 0x7e, 0x85, 0x34, 0x61, 0xe1, 0x45, 0x3a, 0xa6, 0xfd, 0xaa, 0x58, 0x7d, 0xef,
+	0x67, 0x69, 0xbf, 0x26, 0xc4, 0x32, 0xdf, 0xfd, 0xba, 0x43, 0xa0, 0xff, 0x2e, 0x8d, 0x52, 0xc6,
+	0xfd, 0x38, 0xd6, 0x5d, 0xdf, 0xf9, 0x08, 0xbd, 0xb7, 0x88, 0xd9, 0x4b, 0x1a, 0x95, 0xa3, 0x98,
+	0xe8, 0x06, 0x41, 0x90, 0x69, 0x37, 0xe4, 0x9a, 0x3c, 0x82, 0xc6, 0x88, 0xa6, 0xe3, 0x28, 0xbc,
+	0xd0, 0x8e, 0xdd, 0x3c, 0x15, 0x3f, 0xd8, 0x1d, 0x29, 0x73, 0x35, 0x47, 0x3c, 0x42, 0x56, 0x30,
+	0x8e, 0x89, 0x17, 0xa5, 0x63, 0xaa, 0x13, 0x0d, 0x0a, 0x12, 0x0e, 0x6e, 0x2d, 0xfc, 0xfa, 0xdb,
+	0xdd, 0x8a, 0xf3, 0x13, 0xf4, 0xc5, 0xd9, 0xaf, 0xd0, 0x9f, 0xe2, 0x7f, 0x7d, 0xf8, 0xe6, 0x5f,
+	0x35, 0xb0, 0xb6, 0xc5, 0x50, 0x4b, 0xb6, 0xa0, 0x69, 0xa6, 0x51, 0x72, 0x4b, 0x9b, 0xbe, 0x30,
+	0x9e, 0xae, 0xdc, 0x1a, 0xaa, 0x61, 0x77, 0x68, 0x86, 0xdd, 0xe1, 0x9e, 0x18, 0x76, 0xc9, 0x53,
+	0xb0, 0xb6, 0x8f, 0x69, 0xc6, 0xc9, 0x35, 0x84, 0x6b, 0x15, 0x37, 0x60, 0xd1, 0x4c, 0xa5, 0xe4,
+	0xf2, 0x20, 0xba, 0xd2, 0xd5, 0x98, 0xfe, 0x9f, 0x7e, 0x5e, 0x15, 0x5e, 0x9a, 0x44, 0x95, 0x5e,
+	0x5e, 0xc8, 0xdc, 0xb5, 0x87, 0x7d, 0x03, 0xad, 0x32, 0xd0, 0xe4, 0xf6, 0x9c, 0xf2, 0x7c, 0xe8,
+	0xaf, 0xd5, 0x7e, 0x09, 0x4b, 0xe7, 0xc6, 0x00, 0x52, 0x4e, 0xce, 0x57, 0x8c, 0x11, 0x2b, 0x6b,
+	0x57, 0x0b, 0xd5, 0xe4, 0x70, 0xdc, 0x90, 0xc2, 0x27, 0x7f, 0x07, 0x00, 0x00, 0xff, 0xff, 0xda,
+	0x2f, 0xbb, 0x8f, 0x50, 0x0c, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -837,6 +1343,9 @@ type AgentClient interface {
 	PeerJoin(ctx context.Context, in *PeerJoinRequest, opts ...grpc.CallOption) (*types.Empty, error)
 	// PeerLeave receives a "leave" request from a peer and initiates its shutdown
 	PeerLeave(ctx context.Context, in *PeerLeaveRequest, opts ...grpc.CallOption) (*types.Empty, error)
+	// CancelCommand cancels the in-flight commands tagged with the given
+	// sequence number
+	CancelCommand(ctx context.Context, in *CancelCommandRequest, opts ...grpc.CallOption) (*CancelCommandResponse, error)
 }
 
 type agentClient struct {
@@ -915,6 +1424,15 @@ func (c *agentClient) PeerLeave(ctx context.Context, in *PeerLeaveRequest, opts
 	return out, nil
 }
 
+func (c *agentClient) CancelCommand(ctx context.Context, in *CancelCommandRequest, opts ...grpc.CallOption) (*CancelCommandResponse, error) {
+	out := new(CancelCommandResponse)
+	err := c.cc.Invoke(ctx, "/proto.Agent/CancelCommand", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AgentServer is the server API for Agent service.
 type AgentServer interface {
 	// Shutdown requests the agent to shut down
@@ -930,6 +1448,9 @@ type AgentServer interface {
 	PeerJoin(context.Context, *PeerJoinRequest) (*types.Empty, error)
 	// PeerLeave receives a "leave" request from a peer and initiates its shutdown
 	PeerLeave(context.Context, *PeerLeaveRequest) (*types.Empty, error)
+	// CancelCommand cancels the in-flight commands tagged with the given
+	// sequence number
+	CancelCommand(context.Context, *CancelCommandRequest) (*CancelCommandResponse, error)
 }
 
 // UnimplementedAgentServer can be embedded to have forward compatible implementations.
@@ -951,6 +1472,9 @@ func (*UnimplementedAgentServer) PeerJoin(ctx context.Context, req *PeerJoinRequ
 func (*UnimplementedAgentServer) PeerLeave(ctx context.Context, req *PeerLeaveRequest) (*types.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method PeerLeave not implemented")
 }
+func (*UnimplementedAgentServer) CancelCommand(ctx context.Context, req *CancelCommandRequest) (*CancelCommandResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelCommand not implemented")
+}
 
 func RegisterAgentServer(s *grpc.Server, srv AgentServer) {
 	s.RegisterService(&_Agent_serviceDesc, srv)
@@ -1049,6 +1573,24 @@ func _Agent_PeerLeave_Handler(srv interface{}, ctx context.Context, dec func(int
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Agent_CancelCommand_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelCommandRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServer).CancelCommand(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Agent/CancelCommand",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServer).CancelCommand(ctx, req.(*CancelCommandRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Agent_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "proto.Agent",
 	HandlerType: (*AgentServer)(nil),
@@ -1069,6 +1611,10 @@ var _Agent_serviceDesc = grpc.ServiceDesc{
 			MethodName: "PeerLeave",
 			Handler:    _Agent_PeerLeave_Handler,
 		},
+		{
+			MethodName: "CancelCommand",
+			Handler:    _Agent_CancelCommand_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -44,7 +44,7 @@ type Version struct {
 func (m *Version) Reset()      { *m = Version{} }
 func (*Version) ProtoMessage() {}
 func (*Version) Descriptor() ([]byte, []int) {
-	return fileDescriptor_1e7ff60feb39c8d0, []int{0}
+	return fileDescriptor_1e7ff60feb39c8d0, []int{5}
 }
 func (m *Version) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Version.Unmarshal(m, b)
@@ -105,7 +105,7 @@ func (m *SystemInfo) Reset()         { *m = SystemInfo{} }
 func (m *SystemInfo) String() string { return proto.CompactTextString(m) }
 func (*SystemInfo) ProtoMessage()    {}
 func (*SystemInfo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_1e7ff60feb39c8d0, []int{1}
+	return fileDescriptor_1e7ff60feb39c8d0, []int{6}
 }
 func (m *SystemInfo) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SystemInfo.Unmarshal(m, b)
@@ -165,7 +165,7 @@ type RuntimeConfig struct {
 func (m *RuntimeConfig) Reset()      { *m = RuntimeConfig{} }
 func (*RuntimeConfig) ProtoMessage() {}
 func (*RuntimeConfig) Descriptor() ([]byte, []int) {
-	return fileDescriptor_1e7ff60feb39c8d0, []int{2}
+	return fileDescriptor_1e7ff60feb39c8d0, []int{7}
 }
 func (m *RuntimeConfig) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_RuntimeConfig.Unmarshal(m, b)
@@ -268,7 +268,7 @@ func (m *Device) Reset()         { *m = Device{} }
 func (m *Device) String() string { return proto.CompactTextString(m) }
 func (*Device) ProtoMessage()    {}
 func (*Device) Descriptor() ([]byte, []int) {
-	return fileDescriptor_1e7ff60feb39c8d0, []int{3}
+	return fileDescriptor_1e7ff60feb39c8d0, []int{8}
 }
 func (m *Device) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Device.Unmarshal(m, b)
@@ -309,7 +309,7 @@ type Mount struct {
 func (m *Mount) Reset()      { *m = Mount{} }
 func (*Mount) ProtoMessage() {}
 func (*Mount) Descriptor() ([]byte, []int) {
-	return fileDescriptor_1e7ff60feb39c8d0, []int{4}
+	return fileDescriptor_1e7ff60feb39c8d0, []int{9}
 }
 func (m *Mount) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Mount.Unmarshal(m, b)
@@ -360,7 +360,7 @@ type CloudMetadata struct {
 func (m *CloudMetadata) Reset()      { *m = CloudMetadata{} }
 func (*CloudMetadata) ProtoMessage() {}
 func (*CloudMetadata) Descriptor() ([]byte, []int) {
-	return fileDescriptor_1e7ff60feb39c8d0, []int{5}
+	return fileDescriptor_1e7ff60feb39c8d0, []int{10}
 }
 func (m *CloudMetadata) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CloudMetadata.Unmarshal(m, b)
@@ -401,7 +401,248 @@ func (m *CloudMetadata) GetInstanceId() string {
 	return ""
 }
 
+// TailOperationLogRequest describes a request to tail the operation log
+type TailOperationLogRequest struct {
+	// OperationId optionally identifies the operation the log is requested for
+	OperationId string `protobuf:"bytes,1,opt,name=operation_id,json=operationId,proto3" json:"operation_id,omitempty"`
+	// TrailLines specifies the number of trailing lines to start the stream with
+	TrailLines           int32    `protobuf:"varint,2,opt,name=trail_lines,json=trailLines,proto3" json:"trail_lines,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TailOperationLogRequest) Reset()         { *m = TailOperationLogRequest{} }
+func (m *TailOperationLogRequest) String() string { return proto.CompactTextString(m) }
+func (*TailOperationLogRequest) ProtoMessage()    {}
+func (*TailOperationLogRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_1e7ff60feb39c8d0, []int{3}
+}
+func (m *TailOperationLogRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TailOperationLogRequest.Unmarshal(m, b)
+}
+func (m *TailOperationLogRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TailOperationLogRequest.Marshal(b, m, deterministic)
+}
+func (m *TailOperationLogRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TailOperationLogRequest.Merge(m, src)
+}
+func (m *TailOperationLogRequest) XXX_Size() int {
+	return xxx_messageInfo_TailOperationLogRequest.Size(m)
+}
+func (m *TailOperationLogRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TailOperationLogRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TailOperationLogRequest proto.InternalMessageInfo
+
+func (m *TailOperationLogRequest) GetOperationId() string {
+	if m != nil {
+		return m.OperationId
+	}
+	return ""
+}
+
+func (m *TailOperationLogRequest) GetTrailLines() int32 {
+	if m != nil {
+		return m.TrailLines
+	}
+	return 0
+}
+
+// LogLine is a single line of the operation log
+type LogLine struct {
+	// Text is the log line text without the trailing newline
+	Text                 string   `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LogLine) Reset()         { *m = LogLine{} }
+func (m *LogLine) String() string { return proto.CompactTextString(m) }
+func (*LogLine) ProtoMessage()    {}
+func (*LogLine) Descriptor() ([]byte, []int) {
+	return fileDescriptor_1e7ff60feb39c8d0, []int{4}
+}
+func (m *LogLine) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LogLine.Unmarshal(m, b)
+}
+func (m *LogLine) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LogLine.Marshal(b, m, deterministic)
+}
+func (m *LogLine) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LogLine.Merge(m, src)
+}
+func (m *LogLine) XXX_Size() int {
+	return xxx_messageInfo_LogLine.Size(m)
+}
+func (m *LogLine) XXX_DiscardUnknown() {
+	xxx_messageInfo_LogLine.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LogLine proto.InternalMessageInfo
+
+func (m *LogLine) GetText() string {
+	if m != nil {
+		return m.Text
+	}
+	return ""
+}
+
+// Capabilities describes the agent's feature set
+type Capabilities struct {
+	// ProtocolVersion is the version of the agent's RPC protocol
+	ProtocolVersion int32 `protobuf:"varint,1,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version,omitempty"`
+	// Capabilities lists the capability identifiers supported by the agent
+	Capabilities []string `protobuf:"bytes,2,rep,name=capabilities,proto3" json:"capabilities,omitempty"`
+	// Version describes the agent's build version
+	Version              *Version `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Capabilities) Reset()         { *m = Capabilities{} }
+func (m *Capabilities) String() string { return proto.CompactTextString(m) }
+func (*Capabilities) ProtoMessage()    {}
+func (*Capabilities) Descriptor() ([]byte, []int) {
+	return fileDescriptor_1e7ff60feb39c8d0, []int{0}
+}
+func (m *Capabilities) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Capabilities.Unmarshal(m, b)
+}
+func (m *Capabilities) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Capabilities.Marshal(b, m, deterministic)
+}
+func (m *Capabilities) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Capabilities.Merge(m, src)
+}
+func (m *Capabilities) XXX_Size() int {
+	return xxx_messageInfo_Capabilities.Size(m)
+}
+func (m *Capabilities) XXX_DiscardUnknown() {
+	xxx_messageInfo_Capabilities.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Capabilities proto.InternalMessageInfo
+
+func (m *Capabilities) GetProtocolVersion() int32 {
+	if m != nil {
+		return m.ProtocolVersion
+	}
+	return 0
+}
+
+func (m *Capabilities) GetCapabilities() []string {
+	if m != nil {
+		return m.Capabilities
+	}
+	return nil
+}
+
+func (m *Capabilities) GetVersion() *Version {
+	if m != nil {
+		return m.Version
+	}
+	return nil
+}
+
+// PeerInfo describes a single peer as seen by the agent
+type PeerInfo struct {
+	// Addr is the peer's advertised address
+	Addr string `protobuf:"bytes,1,opt,name=addr,proto3" json:"addr,omitempty"`
+	// Healthy indicates whether the peer is currently connected
+	Healthy              bool     `protobuf:"varint,2,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PeerInfo) Reset()         { *m = PeerInfo{} }
+func (m *PeerInfo) String() string { return proto.CompactTextString(m) }
+func (*PeerInfo) ProtoMessage()    {}
+func (*PeerInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_1e7ff60feb39c8d0, []int{1}
+}
+func (m *PeerInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PeerInfo.Unmarshal(m, b)
+}
+func (m *PeerInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PeerInfo.Marshal(b, m, deterministic)
+}
+func (m *PeerInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PeerInfo.Merge(m, src)
+}
+func (m *PeerInfo) XXX_Size() int {
+	return xxx_messageInfo_PeerInfo.Size(m)
+}
+func (m *PeerInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_PeerInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PeerInfo proto.InternalMessageInfo
+
+func (m *PeerInfo) GetAddr() string {
+	if m != nil {
+		return m.Addr
+	}
+	return ""
+}
+
+func (m *PeerInfo) GetHealthy() bool {
+	if m != nil {
+		return m.Healthy
+	}
+	return false
+}
+
+// PeersList describes the agent's view of its gRPC peers
+type PeersList struct {
+	// Peers lists the peers known to the agent
+	Peers                []*PeerInfo `protobuf:"bytes,1,rep,name=peers,proto3" json:"peers,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *PeersList) Reset()         { *m = PeersList{} }
+func (m *PeersList) String() string { return proto.CompactTextString(m) }
+func (*PeersList) ProtoMessage()    {}
+func (*PeersList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_1e7ff60feb39c8d0, []int{2}
+}
+func (m *PeersList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PeersList.Unmarshal(m, b)
+}
+func (m *PeersList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PeersList.Marshal(b, m, deterministic)
+}
+func (m *PeersList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PeersList.Merge(m, src)
+}
+func (m *PeersList) XXX_Size() int {
+	return xxx_messageInfo_PeersList.Size(m)
+}
+func (m *PeersList) XXX_DiscardUnknown() {
+	xxx_messageInfo_PeersList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PeersList proto.InternalMessageInfo
+
+func (m *PeersList) GetPeers() []*PeerInfo {
+	if m != nil {
+		return m.Peers
+	}
+	return nil
+}
+
 func init() {
+	proto.RegisterType((*Capabilities)(nil), "proto.Capabilities")
+	proto.RegisterType((*PeerInfo)(nil), "proto.PeerInfo")
+	proto.RegisterType((*PeersList)(nil), "proto.PeersList")
+	proto.RegisterType((*TailOperationLogRequest)(nil), "proto.TailOperationLogRequest")
+	proto.RegisterType((*LogLine)(nil), "proto.LogLine")
 	proto.RegisterType((*Version)(nil), "proto.Version")
 	proto.RegisterType((*SystemInfo)(nil), "proto.SystemInfo")
 	proto.RegisterType((*RuntimeConfig)(nil), "proto.RuntimeConfig")
@@ -414,47 +655,62 @@ func init() {
 func init() { proto.RegisterFile("discovery.proto", fileDescriptor_1e7ff60feb39c8d0) }
 
 var fileDescriptor_1e7ff60feb39c8d0 = []byte{
-	// 635 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x54, 0xcd, 0x6e, 0xd3, 0x40,
-	0x10, 0x26, 0xcd, 0xff, 0xe4, 0x87, 0xb2, 0xaa, 0x2a, 0x93, 0x82, 0x12, 0xa5, 0x14, 0xe5, 0x94,
-	0x4a, 0x85, 0x03, 0x14, 0x0e, 0xd0, 0xa4, 0x8a, 0x0a, 0x94, 0x83, 0x5b, 0xf5, 0x6a, 0xb9, 0xde,
-	0xa9, 0x59, 0xc5, 0xde, 0xb5, 0xd6, 0xeb, 0xa8, 0x7e, 0x00, 0xde, 0x81, 0x23, 0x2f, 0xc5, 0x95,
-	0x03, 0x4f, 0x82, 0x76, 0xbd, 0xae, 0x12, 0x68, 0x4f, 0x9e, 0xef, 0xfb, 0x66, 0x67, 0xc6, 0x33,
-	0x3b, 0x0b, 0x8f, 0x29, 0x4b, 0x03, 0xb1, 0x42, 0x99, 0x4f, 0x13, 0x29, 0x94, 0x20, 0x75, 0xf3,
-	0x19, 0xec, 0x85, 0x42, 0x84, 0x11, 0x1e, 0x1a, 0x74, 0x9d, 0xdd, 0x1c, 0x62, 0x9c, 0x28, 0xeb,
-	0x33, 0x18, 0xfe, 0x2b, 0x2a, 0x16, 0x63, 0xaa, 0xfc, 0x38, 0xb1, 0x0e, 0x10, 0x8a, 0x50, 0x14,
-	0xf6, 0x78, 0x05, 0xcd, 0x2b, 0x94, 0x29, 0x13, 0x9c, 0x38, 0xd0, 0x44, 0xca, 0x14, 0x13, 0xdc,
-	0xa9, 0x8c, 0x2a, 0x93, 0xb6, 0x5b, 0x42, 0xad, 0xac, 0x0a, 0x27, 0x67, 0xab, 0x50, 0x2c, 0x24,
-	0xcf, 0x01, 0x42, 0xa6, 0xbc, 0x40, 0xc4, 0x31, 0x53, 0x4e, 0xd5, 0x88, 0xed, 0x90, 0xa9, 0x99,
-	0x21, 0x08, 0x81, 0xda, 0x37, 0x8c, 0x62, 0xa7, 0x66, 0x04, 0x63, 0x1f, 0xd7, 0x7e, 0xfc, 0x1c,
-	0x3e, 0x1a, 0xbf, 0x04, 0xb8, 0xc8, 0x53, 0x85, 0xf1, 0x19, 0xbf, 0x11, 0x3a, 0x41, 0xe2, 0xe7,
-	0x91, 0xf0, 0xa9, 0x49, 0xdd, 0x75, 0x4b, 0x38, 0xfe, 0x55, 0x85, 0x9e, 0x9b, 0x71, 0xfd, 0x0b,
-	0x33, 0xc1, 0x6f, 0x58, 0xa8, 0x63, 0x4a, 0x11, 0xa1, 0xad, 0xd1, 0xd8, 0xe4, 0x00, 0xfa, 0x3e,
-	0x5d, 0xa1, 0x54, 0x2c, 0x45, 0xcf, 0xa7, 0x54, 0xda, 0x3a, 0x7b, 0x77, 0xec, 0x47, 0x4a, 0x25,
-	0xd9, 0x87, 0x5e, 0x6a, 0x92, 0x7a, 0x14, 0x57, 0x2c, 0x40, 0x5b, 0x57, 0xb7, 0x20, 0xe7, 0x86,
-	0x23, 0x2f, 0xa0, 0x11, 0x8b, 0x8c, 0xab, 0xd4, 0xa9, 0x8f, 0xaa, 0x93, 0xce, 0x51, 0xb7, 0xe8,
-	0xd4, 0xf4, 0x5c, 0x93, 0xae, 0xd5, 0xc8, 0x1e, 0xb4, 0x53, 0xe5, 0x2b, 0xf4, 0x28, 0x93, 0x4e,
-	0xc3, 0x84, 0x69, 0x19, 0x62, 0xce, 0x24, 0x79, 0x0a, 0x2d, 0x85, 0x71, 0x62, 0xb4, 0x66, 0xd1,
-	0x30, 0x8d, 0xb5, 0xb4, 0x03, 0x75, 0x25, 0x96, 0xc8, 0x9d, 0x96, 0xe1, 0x0b, 0x40, 0xde, 0x41,
-	0x3f, 0x88, 0x44, 0x46, 0xbd, 0x18, 0x95, 0x4f, 0x7d, 0xe5, 0x3b, 0xed, 0x51, 0x65, 0xd2, 0x39,
-	0xda, 0xb1, 0xb9, 0x67, 0x5a, 0x3c, 0xb7, 0x9a, 0xdb, 0x0b, 0xd6, 0x21, 0x39, 0x01, 0x58, 0x62,
-	0xee, 0xad, 0xfc, 0x28, 0xc3, 0xd4, 0x01, 0x53, 0xf4, 0xbe, 0x3d, 0xb8, 0xd1, 0xba, 0xe9, 0x67,
-	0xcc, 0xaf, 0x8c, 0xd7, 0x29, 0x57, 0x32, 0x77, 0xdb, 0xcb, 0x12, 0x93, 0x03, 0x68, 0xa6, 0x18,
-	0x31, 0x9e, 0xdd, 0x3a, 0x9d, 0x51, 0x65, 0xd2, 0x3a, 0xe9, 0xfc, 0xf9, 0x3d, 0x6c, 0x5e, 0x9c,
-	0x7e, 0xd1, 0x94, 0x5b, 0x6a, 0x83, 0xf7, 0xd0, 0xdf, 0x8c, 0x41, 0xb6, 0xa1, 0xba, 0xc4, 0xdc,
-	0x0e, 0x43, 0x9b, 0xfa, 0x0f, 0x4d, 0x29, 0x76, 0x04, 0x05, 0x38, 0xde, 0x7a, 0x53, 0x29, 0x26,
-	0xff, 0xa9, 0xd6, 0xaa, 0x6e, 0xd7, 0xc6, 0xcf, 0xa0, 0x61, 0xfb, 0x4d, 0xa0, 0xc6, 0xfd, 0xf8,
-	0x6e, 0x9e, 0xda, 0x1e, 0xbf, 0x85, 0xba, 0x69, 0xf7, 0x7d, 0x22, 0xd9, 0x85, 0x46, 0x2a, 0x32,
-	0x19, 0x94, 0x19, 0x2c, 0xb2, 0x17, 0xeb, 0x16, 0x7a, 0x1b, 0xdd, 0xd2, 0x93, 0xe2, 0x82, 0xa2,
-	0xb7, 0x16, 0xa7, 0xa5, 0x89, 0xaf, 0x3a, 0xd6, 0x3e, 0xf4, 0x18, 0x4f, 0x95, 0xcf, 0x03, 0xf4,
-	0x54, 0x9e, 0x94, 0x21, 0xbb, 0x25, 0x79, 0x99, 0x27, 0x48, 0x86, 0xd0, 0xb9, 0x73, 0x62, 0xd4,
-	0xde, 0x72, 0x28, 0xa9, 0x33, 0x5a, 0x64, 0x3e, 0xfa, 0xbe, 0x05, 0xed, 0x79, 0xb9, 0xaf, 0xe4,
-	0x03, 0x6c, 0x2f, 0x50, 0x6d, 0x5e, 0xdd, 0xdd, 0x69, 0xb1, 0x9a, 0xd3, 0x72, 0x35, 0xa7, 0xa7,
-	0x7a, 0x6f, 0x07, 0x3b, 0xf7, 0x4d, 0x8b, 0x1c, 0x43, 0x6f, 0x81, 0x6a, 0x6d, 0x4b, 0x1e, 0x3a,
-	0xfe, 0xc4, 0x1e, 0x5f, 0x73, 0x9d, 0x43, 0x7f, 0x81, 0x6a, 0x96, 0x49, 0x89, 0x5c, 0x5d, 0x32,
-	0xdd, 0xb5, 0x07, 0x0e, 0x0f, 0xfe, 0xe3, 0x2f, 0xcb, 0xe7, 0x82, 0xbc, 0x06, 0x58, 0xa0, 0x2a,
-	0xdf, 0x87, 0x87, 0x22, 0xf4, 0x6d, 0x7a, 0xeb, 0x77, 0xdd, 0x30, 0xf0, 0xd5, 0xdf, 0x00, 0x00,
-	0x00, 0xff, 0xff, 0xce, 0xe0, 0xf7, 0x71, 0xbd, 0x04, 0x00, 0x00,
+	// 868 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x54, 0xcd, 0x72, 0xe3, 0x44,
+	0x10, 0xc6, 0xf1, 0x8f, 0xac, 0xf6, 0x4f, 0xcc, 0x90, 0x5a, 0x84, 0x97, 0x25, 0x46, 0x21, 0x94,
+	0xb9, 0x78, 0xa9, 0xb0, 0x87, 0x25, 0x50, 0x14, 0xac, 0x9d, 0x72, 0x05, 0xbc, 0x40, 0x69, 0x53,
+	0x7b, 0xa3, 0x54, 0x13, 0xa9, 0xa3, 0x4c, 0x45, 0xd2, 0x08, 0x69, 0xe4, 0x8a, 0x9e, 0x80, 0x57,
+	0xe0, 0xc8, 0xab, 0xf0, 0x10, 0x5c, 0x39, 0xf0, 0x24, 0xd4, 0x8c, 0x66, 0x5c, 0x76, 0x36, 0x3e,
+	0xa9, 0xfb, 0xfb, 0xa6, 0xbb, 0xa7, 0xbb, 0x47, 0x1f, 0x1c, 0x86, 0xac, 0x08, 0xf8, 0x1a, 0xf3,
+	0x6a, 0x96, 0xe5, 0x5c, 0x70, 0xd2, 0x56, 0x9f, 0xf1, 0xd3, 0x88, 0xf3, 0x28, 0xc6, 0xe7, 0xca,
+	0xbb, 0x2e, 0x6f, 0x9e, 0x63, 0x92, 0x09, 0x7d, 0x66, 0x7c, 0xfc, 0x90, 0x14, 0x2c, 0xc1, 0x42,
+	0xd0, 0x24, 0xd3, 0x07, 0x20, 0xe2, 0x11, 0xaf, 0x6d, 0xf7, 0x8f, 0x06, 0xf4, 0xe7, 0x34, 0xa3,
+	0xd7, 0x2c, 0x66, 0x82, 0x61, 0x41, 0xbe, 0x80, 0x91, 0x62, 0x02, 0x1e, 0xfb, 0x6b, 0xcc, 0x0b,
+	0xc6, 0x53, 0xa7, 0x31, 0x69, 0x4c, 0xdb, 0xde, 0xa1, 0xc1, 0xdf, 0xd6, 0x30, 0x71, 0xa1, 0x1f,
+	0x6c, 0x85, 0x3a, 0x07, 0x93, 0xe6, 0xd4, 0xf6, 0x76, 0x30, 0x32, 0x05, 0xcb, 0x64, 0x69, 0x4e,
+	0x1a, 0xd3, 0xde, 0xd9, 0xb0, 0x2e, 0x3c, 0xd3, 0x49, 0x3c, 0x43, 0xbb, 0x2f, 0xa1, 0xfb, 0x2b,
+	0x62, 0x7e, 0x99, 0xde, 0x70, 0x42, 0xa0, 0x45, 0xc3, 0x30, 0x57, 0x85, 0x6d, 0x4f, 0xd9, 0xc4,
+	0x01, 0xeb, 0x16, 0x69, 0x2c, 0x6e, 0x2b, 0xe7, 0x60, 0xd2, 0x98, 0x76, 0x3d, 0xe3, 0xba, 0x67,
+	0x60, 0xcb, 0xc8, 0x62, 0xc5, 0x0a, 0x41, 0x4e, 0xa1, 0x9d, 0x49, 0xc7, 0x69, 0x4c, 0x9a, 0xd3,
+	0xde, 0xd9, 0xa1, 0x2e, 0x67, 0x52, 0x7b, 0x35, 0xeb, 0xfe, 0x06, 0x1f, 0x5e, 0x51, 0x16, 0xff,
+	0x92, 0x61, 0x4e, 0x05, 0xe3, 0xe9, 0x8a, 0x47, 0x1e, 0xfe, 0x5e, 0x62, 0x21, 0xc8, 0xa7, 0xd0,
+	0xe7, 0x06, 0xf6, 0x59, 0xa8, 0x2f, 0xd1, 0xdb, 0x60, 0x97, 0x21, 0x39, 0x86, 0x9e, 0xc8, 0x29,
+	0x8b, 0xfd, 0x98, 0xa5, 0xaa, 0x71, 0x39, 0x1f, 0x50, 0xd0, 0x4a, 0x22, 0xee, 0x33, 0xb0, 0x56,
+	0x3c, 0x92, 0xb6, 0xec, 0x45, 0xe0, 0xbd, 0x30, 0xbd, 0x48, 0xdb, 0x5d, 0x83, 0x65, 0x86, 0xe8,
+	0x80, 0x85, 0x21, 0x13, 0x66, 0xcc, 0xb6, 0x67, 0x5c, 0xc9, 0x98, 0xd1, 0x1d, 0xd4, 0x8c, 0x76,
+	0xc9, 0x33, 0x80, 0x88, 0x09, 0x3f, 0xe0, 0x49, 0xc2, 0x84, 0x9a, 0xab, 0xed, 0xd9, 0x11, 0x13,
+	0x73, 0x05, 0xc8, 0x8a, 0xb7, 0x18, 0x27, 0x4e, 0xab, 0xae, 0x28, 0xed, 0xf3, 0xd6, 0x9f, 0x7f,
+	0x1d, 0xbf, 0xe7, 0x7e, 0x0e, 0xf0, 0xa6, 0x2a, 0x04, 0x26, 0x6a, 0xca, 0x0e, 0x58, 0x19, 0xad,
+	0x62, 0x4e, 0xeb, 0x1e, 0xfb, 0x9e, 0x71, 0xdd, 0x7f, 0x9a, 0x30, 0xf0, 0xca, 0x54, 0x3e, 0x9c,
+	0x39, 0x4f, 0x6f, 0x58, 0x24, 0x73, 0xe6, 0x3c, 0x46, 0xd3, 0x85, 0xb4, 0xc9, 0x29, 0x0c, 0x69,
+	0xb8, 0xc6, 0x5c, 0xb0, 0x02, 0x7d, 0xb5, 0xaf, 0xfa, 0x9e, 0x83, 0x0d, 0xfa, 0x83, 0x5c, 0xdc,
+	0x09, 0x0c, 0x0a, 0x55, 0xd4, 0x0f, 0x71, 0xcd, 0x02, 0xd4, 0xf7, 0xea, 0xd7, 0xe0, 0x42, 0x61,
+	0xe4, 0x33, 0xe8, 0x24, 0xbc, 0x4c, 0x45, 0xe1, 0xb4, 0xd5, 0xde, 0xfa, 0x7a, 0x6f, 0xaf, 0x25,
+	0xe8, 0x69, 0x8e, 0x3c, 0x05, 0xbb, 0x10, 0x54, 0xa0, 0x1f, 0xb2, 0xdc, 0xe9, 0xa8, 0x34, 0x5d,
+	0x05, 0x2c, 0x58, 0x4e, 0x3e, 0x82, 0xae, 0xc0, 0x24, 0x53, 0x9c, 0x55, 0x0f, 0x4c, 0xfa, 0x92,
+	0x3a, 0x82, 0xb6, 0xe0, 0x77, 0x98, 0x3a, 0x5d, 0x85, 0xd7, 0x0e, 0xf9, 0x06, 0x86, 0x41, 0xcc,
+	0xcb, 0xd0, 0x4f, 0x50, 0xd0, 0x90, 0x0a, 0xea, 0xd8, 0xea, 0x89, 0x1e, 0xe9, 0xda, 0x73, 0x49,
+	0xbe, 0xd6, 0x9c, 0x37, 0x08, 0xb6, 0x5d, 0xf2, 0x0a, 0xe0, 0x0e, 0x2b, 0x7f, 0x4d, 0xe3, 0x12,
+	0x0b, 0x07, 0xd4, 0xa5, 0x4f, 0x74, 0xe0, 0xce, 0xe8, 0x66, 0x3f, 0x61, 0xf5, 0x56, 0x9d, 0xba,
+	0x48, 0x45, 0x5e, 0x79, 0xf6, 0x9d, 0xf1, 0xc9, 0x29, 0x58, 0x05, 0xc6, 0x2c, 0x2d, 0xef, 0x9d,
+	0x9e, 0x7c, 0xd2, 0xaf, 0x7a, 0xff, 0xfd, 0x7b, 0x6c, 0xbd, 0xb9, 0x58, 0x49, 0xc8, 0x33, 0xdc,
+	0xf8, 0x5b, 0x18, 0xee, 0xe6, 0x20, 0x23, 0x68, 0xde, 0x61, 0xa5, 0x97, 0x21, 0x4d, 0xd9, 0xa1,
+	0xba, 0x8a, 0x5e, 0x41, 0xed, 0x9c, 0x1f, 0xbc, 0x6c, 0xd4, 0x9b, 0xff, 0xb1, 0xd5, 0x6d, 0x8e,
+	0x5a, 0xee, 0xc7, 0xd0, 0xd1, 0xf3, 0x26, 0xd0, 0x4a, 0x69, 0xb2, 0xd9, 0xa7, 0xb4, 0xdd, 0xaf,
+	0xa1, 0xad, 0xc6, 0xfd, 0x18, 0x49, 0x9e, 0x40, 0xa7, 0xe0, 0x65, 0x1e, 0x98, 0x0a, 0xda, 0xd3,
+	0x0f, 0xeb, 0x1e, 0x06, 0x3b, 0xd3, 0x92, 0x9b, 0x4a, 0x79, 0x88, 0xfe, 0x56, 0x9e, 0xae, 0x04,
+	0x7e, 0x96, 0xb9, 0x4e, 0x60, 0xc0, 0xd2, 0x42, 0xd0, 0x34, 0x40, 0x5f, 0x54, 0x99, 0x49, 0xd9,
+	0x37, 0xe0, 0x55, 0x95, 0xa1, 0xfc, 0xc7, 0x36, 0x87, 0x58, 0xa8, 0x5f, 0x39, 0x18, 0xe8, 0x32,
+	0xac, 0x2b, 0x9f, 0xfd, 0xdd, 0x04, 0x7b, 0x61, 0x54, 0x92, 0x7c, 0x0f, 0xa3, 0x25, 0x8a, 0xdd,
+	0xa7, 0xfb, 0x64, 0x56, 0x0b, 0xe2, 0xcc, 0x08, 0xe2, 0xec, 0x42, 0xaa, 0xe5, 0xf8, 0xe8, 0xb1,
+	0x6d, 0x91, 0x73, 0x18, 0x2c, 0x51, 0x6c, 0xfd, 0x25, 0xfb, 0xc2, 0xdf, 0xd7, 0xe1, 0x5b, 0x47,
+	0x17, 0x30, 0x5c, 0xa2, 0x98, 0x97, 0x79, 0x8e, 0xa9, 0xb8, 0x62, 0x72, 0x6a, 0x7b, 0x82, 0xc7,
+	0xef, 0xe0, 0x57, 0x46, 0xa4, 0xc9, 0x0b, 0x80, 0x25, 0x0a, 0xa3, 0x0f, 0xfb, 0x32, 0x3c, 0xd0,
+	0x51, 0xb2, 0x80, 0xd1, 0x43, 0x41, 0x23, 0x9f, 0xe8, 0x33, 0x7b, 0x94, 0x6e, 0x93, 0x43, 0x4b,
+	0xd5, 0x97, 0x0d, 0xf2, 0x1d, 0x1c, 0xca, 0x0e, 0xb6, 0x15, 0x7c, 0xdf, 0x05, 0x3e, 0x30, 0x7f,
+	0xc9, 0xf6, 0xe1, 0x17, 0xd0, 0x5d, 0xa2, 0x50, 0x6a, 0xbc, 0x37, 0x70, 0xb4, 0x25, 0xc9, 0x4a,
+	0xb3, 0xaf, 0x3b, 0x0a, 0xf8, 0xea, 0xff, 0x00, 0x00, 0x00, 0xff, 0xff, 0xdf, 0x6e, 0xf4, 0x1b,
+	0xef, 0x06, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -477,6 +733,14 @@ type DiscoveryClient interface {
 	GetCurrentTime(ctx context.Context, in *types.Empty, opts ...grpc.CallOption) (*types.Timestamp, error)
 	// GetVersion returns the agent's version information.
 	GetVersion(ctx context.Context, in *types.Empty, opts ...grpc.CallOption) (*Version, error)
+	// TailOperationLog follows the node-local operation log file
+	// and streams new lines as they are written
+	TailOperationLog(ctx context.Context, in *TailOperationLogRequest, opts ...grpc.CallOption) (Discovery_TailOperationLogClient, error)
+	// GetCapabilities returns the agent's capabilities so newer clients
+	// can feature-detect instead of failing on missing methods
+	GetCapabilities(ctx context.Context, in *types.Empty, opts ...grpc.CallOption) (*Capabilities, error)
+	// GetPeers returns the agent's view of its gRPC peers and their health
+	GetPeers(ctx context.Context, in *types.Empty, opts ...grpc.CallOption) (*PeersList, error)
 }
 
 type discoveryClient struct {
@@ -523,6 +787,56 @@ func (c *discoveryClient) GetVersion(ctx context.Context, in *types.Empty, opts
 	return out, nil
 }
 
+func (c *discoveryClient) GetCapabilities(ctx context.Context, in *types.Empty, opts ...grpc.CallOption) (*Capabilities, error) {
+	out := new(Capabilities)
+	err := c.cc.Invoke(ctx, "/proto.Discovery/GetCapabilities", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *discoveryClient) GetPeers(ctx context.Context, in *types.Empty, opts ...grpc.CallOption) (*PeersList, error) {
+	out := new(PeersList)
+	err := c.cc.Invoke(ctx, "/proto.Discovery/GetPeers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *discoveryClient) TailOperationLog(ctx context.Context, in *TailOperationLogRequest, opts ...grpc.CallOption) (Discovery_TailOperationLogClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Discovery_serviceDesc.Streams[0], "/proto.Discovery/TailOperationLog", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &discoveryTailOperationLogClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Discovery_TailOperationLogClient interface {
+	Recv() (*LogLine, error)
+	grpc.ClientStream
+}
+
+type discoveryTailOperationLogClient struct {
+	grpc.ClientStream
+}
+
+func (x *discoveryTailOperationLogClient) Recv() (*LogLine, error) {
+	m := new(LogLine)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // DiscoveryServer is the server API for Discovery service.
 type DiscoveryServer interface {
 	// GetRuntimeConfig returns the agent's runtime configuration
@@ -533,6 +847,14 @@ type DiscoveryServer interface {
 	GetCurrentTime(context.Context, *types.Empty) (*types.Timestamp, error)
 	// GetVersion returns the agent's version information.
 	GetVersion(context.Context, *types.Empty) (*Version, error)
+	// TailOperationLog follows the node-local operation log file
+	// and streams new lines as they are written
+	TailOperationLog(*TailOperationLogRequest, Discovery_TailOperationLogServer) error
+	// GetCapabilities returns the agent's capabilities so newer clients
+	// can feature-detect instead of failing on missing methods
+	GetCapabilities(context.Context, *types.Empty) (*Capabilities, error)
+	// GetPeers returns the agent's view of its gRPC peers and their health
+	GetPeers(context.Context, *types.Empty) (*PeersList, error)
 }
 
 // UnimplementedDiscoveryServer can be embedded to have forward compatible implementations.
@@ -551,6 +873,15 @@ func (*UnimplementedDiscoveryServer) GetCurrentTime(ctx context.Context, req *ty
 func (*UnimplementedDiscoveryServer) GetVersion(ctx context.Context, req *types.Empty) (*Version, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetVersion not implemented")
 }
+func (*UnimplementedDiscoveryServer) TailOperationLog(req *TailOperationLogRequest, srv Discovery_TailOperationLogServer) error {
+	return status.Errorf(codes.Unimplemented, "method TailOperationLog not implemented")
+}
+func (*UnimplementedDiscoveryServer) GetCapabilities(ctx context.Context, req *types.Empty) (*Capabilities, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCapabilities not implemented")
+}
+func (*UnimplementedDiscoveryServer) GetPeers(ctx context.Context, req *types.Empty) (*PeersList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPeers not implemented")
+}
 
 func RegisterDiscoveryServer(s *grpc.Server, srv DiscoveryServer) {
 	s.RegisterService(&_Discovery_serviceDesc, srv)
@@ -628,6 +959,63 @@ func _Discovery_GetVersion_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Discovery_GetCapabilities_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(types.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DiscoveryServer).GetCapabilities(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Discovery/GetCapabilities",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DiscoveryServer).GetCapabilities(ctx, req.(*types.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Discovery_GetPeers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(types.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DiscoveryServer).GetPeers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Discovery/GetPeers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DiscoveryServer).GetPeers(ctx, req.(*types.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Discovery_TailOperationLog_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TailOperationLogRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DiscoveryServer).TailOperationLog(m, &discoveryTailOperationLogServer{stream})
+}
+
+type Discovery_TailOperationLogServer interface {
+	Send(*LogLine) error
+	grpc.ServerStream
+}
+
+type discoveryTailOperationLogServer struct {
+	grpc.ServerStream
+}
+
+func (x *discoveryTailOperationLogServer) Send(m *LogLine) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _Discovery_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "proto.Discovery",
 	HandlerType: (*DiscoveryServer)(nil),
@@ -648,7 +1036,21 @@ var _Discovery_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetVersion",
 			Handler:    _Discovery_GetVersion_Handler,
 		},
+		{
+			MethodName: "GetCapabilities",
+			Handler:    _Discovery_GetCapabilities_Handler,
+		},
+		{
+			MethodName: "GetPeers",
+			Handler:    _Discovery_GetPeers_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "TailOperationLog",
+			Handler:       _Discovery_TailOperationLog_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "discovery.proto",
 }
@@ -17,8 +17,12 @@ limitations under the License.
 package proto
 
 import (
+	"bytes"
+	"compress/gzip"
 	"fmt"
+	"io/ioutil"
 	"strings"
+	"time"
 
 	"github.com/gravitational/gravity/lib/storage"
 
@@ -71,6 +75,53 @@ func EncodeError(err error) *Error {
 	}
 }
 
+// CompressData gzip-compresses the provided payload.
+// It is used to compress command output messages on the wire
+func CompressData(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return buf.Bytes(), nil
+}
+
+// UncompressData gzip-uncompresses the provided payload
+func UncompressData(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer r.Close()
+	uncompressed, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return uncompressed, nil
+}
+
+// HeartbeatInterval returns the heartbeat interval for this command
+// as a duration. Returns 0 if heartbeats have not been requested
+func (r *CommandArgs) HeartbeatInterval() time.Duration {
+	return time.Duration(r.HeartbeatIntervalMillis) * time.Millisecond
+}
+
+// OutputFlushInterval returns the interval at which buffered command
+// output is flushed as a duration. Returns 0 if no interval has been
+// configured
+func (r *CommandArgs) OutputFlushInterval() time.Duration {
+	return time.Duration(r.OutputFlushIntervalMillis) * time.Millisecond
+}
+
+// Timeout returns the command's own execution timeout as a duration.
+// Returns 0 if no timeout has been set
+func (r *CommandArgs) Timeout() time.Duration {
+	return time.Duration(r.TimeoutMillis) * time.Millisecond
+}
+
 // ErrorToMessage returns a new message using the specified error
 func ErrorToMessage(err error) *Message {
 	return &Message{Element: &Message_Error{EncodeError(err)}}
@@ -159,3 +210,34 @@ func (v *Version) String() string {
 	return fmt.Sprintf("Edition:\t%v\nVersion:\t%v\nGit Commit:\t%v\nHelm Version:\t%v",
 		v.Edition, v.Version, v.GitCommit, v.Helm)
 }
+
+// ProtocolVersion is the version of the agent RPC protocol.
+// Bumped whenever the protocol changes in a way clients need to detect
+const ProtocolVersion = 1
+
+const (
+	// CapabilityExecCompression indicates support for compressed command output
+	CapabilityExecCompression = "exec.compress-output"
+	// CapabilityExecChecksum indicates support for command output checksums
+	CapabilityExecChecksum = "exec.checksum-output"
+	// CapabilityExecHeartbeat indicates support for command heartbeat messages
+	CapabilityExecHeartbeat = "exec.heartbeat"
+	// CapabilityExecCancel indicates support for cancelling in-flight commands
+	CapabilityExecCancel = "exec.cancel"
+	// CapabilityTailOperationLog indicates support for streaming the operation log
+	CapabilityTailOperationLog = "discovery.tail-operation-log"
+	// CapabilityPeerDiscovery indicates support for querying the agent's peers
+	CapabilityPeerDiscovery = "discovery.peers"
+)
+
+// AgentCapabilities returns the list of capabilities supported by this agent
+func AgentCapabilities() []string {
+	return []string{
+		CapabilityExecCompression,
+		CapabilityExecChecksum,
+		CapabilityExecHeartbeat,
+		CapabilityExecCancel,
+		CapabilityTailOperationLog,
+		CapabilityPeerDiscovery,
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rpc
+
+import (
+	"sort"
+)
+
+// NodeExecResult describes the outcome of a command executed on a single
+// node of the fleet
+type NodeExecResult struct {
+	// ExitCode is the command's exit code. A negative value (e.g.
+	// server.ExitCodeUndefined) indicates the command never ran or its
+	// exit status is unknown
+	ExitCode int
+	// Error is set if the command failed or the node could not be reached
+	Error error
+}
+
+// ExecSummary categorizes the nodes of a fleet-wide command run by outcome
+type ExecSummary struct {
+	// Succeeded lists the nodes the command exited on with code 0
+	Succeeded []string
+	// Failed lists the nodes the command exited on with a non-zero code
+	Failed []string
+	// Unreachable lists the nodes the command could not be run on, e.g.
+	// because the agent was down
+	Unreachable []string
+}
+
+// AllSucceeded returns true if the command exited with code 0 on every node
+func (r ExecSummary) AllSucceeded() bool {
+	return len(r.Failed) == 0 && len(r.Unreachable) == 0
+}
+
+// SummarizeExecResults categorizes the per-node results of a fleet-wide
+// command run: nodes the command succeeded on, nodes it failed on with an
+// exit code and nodes it could not be run on at all. The node lists are
+// sorted for deterministic output
+func SummarizeExecResults(results map[string]NodeExecResult) ExecSummary {
+	var summary ExecSummary
+	for node, result := range results {
+		switch {
+		case result.ExitCode == 0 && result.Error == nil:
+			summary.Succeeded = append(summary.Succeeded, node)
+		case result.ExitCode > 0:
+			// the command ran and exited on its own, even if the run is
+			// also reported as an error
+			summary.Failed = append(summary.Failed, node)
+		default:
+			summary.Unreachable = append(summary.Unreachable, node)
+		}
+	}
+	sort.Strings(summary.Succeeded)
+	sort.Strings(summary.Failed)
+	sort.Strings(summary.Unreachable)
+	return summary
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rpc
+
+import (
+	"testing"
+
+	"github.com/gravitational/trace"
+	. "gopkg.in/check.v1"
+)
+
+func TestRPC(t *testing.T) { TestingT(t) }
+
+type ResultsSuite struct{}
+
+var _ = Suite(&ResultsSuite{})
+
+func (s *ResultsSuite) TestSummarizeExecResults(c *C) {
+	summary := SummarizeExecResults(map[string]NodeExecResult{
+		"node-1": {},
+		"node-2": {ExitCode: 1, Error: trace.BadParameter("exit code 1")},
+		"node-3": {ExitCode: -1, Error: trace.ConnectionProblem(nil, "agent down")},
+		"node-4": {ExitCode: 2},
+		"node-5": {},
+		// the command never ran so the exit code is meaningless
+		"node-6": {Error: trace.ConnectionProblem(nil, "connection refused")},
+	})
+	c.Assert(summary, DeepEquals, ExecSummary{
+		Succeeded:   []string{"node-1", "node-5"},
+		Failed:      []string{"node-2", "node-4"},
+		Unreachable: []string{"node-3", "node-6"},
+	})
+	c.Assert(summary.AllSucceeded(), Equals, false)
+}
+
+func (s *ResultsSuite) TestSummarizeExecResultsAllSucceeded(c *C) {
+	summary := SummarizeExecResults(map[string]NodeExecResult{
+		"node-1": {},
+		"node-2": {},
+	})
+	c.Assert(summary, DeepEquals, ExecSummary{
+		Succeeded: []string{"node-1", "node-2"},
+	})
+	c.Assert(summary.AllSucceeded(), Equals, true)
+
+	c.Assert(SummarizeExecResults(nil).AllSucceeded(), Equals, true)
+}
+
+func (s *ResultsSuite) TestSummarizeExecResultsPartialOutcomes(c *C) {
+	// a failed run still counts as unreachable unless the command is known
+	// to have exited on its own
+	summary := SummarizeExecResults(map[string]NodeExecResult{
+		"node-1": {ExitCode: 0, Error: trace.ConnectionProblem(nil, "lost connection mid-run")},
+	})
+	c.Assert(summary, DeepEquals, ExecSummary{
+		Unreachable: []string{"node-1"},
+	})
+	c.Assert(summary.AllSucceeded(), Equals, false)
+}
@@ -18,6 +18,7 @@ package server
 
 import (
 	"os"
+	"sync"
 	"time"
 
 	"github.com/gravitational/gravity/lib/defaults"
@@ -134,6 +135,33 @@ func (srv *agentServer) GetVersion(ctx context.Context, _ *types.Empty) (*pb.Ver
 	return &ver, nil
 }
 
+// GetCapabilities queries the agent's capabilities
+func (srv *agentServer) GetCapabilities(ctx context.Context, _ *types.Empty) (*pb.Capabilities, error) {
+	ver := modules.Get().Version()
+	return &pb.Capabilities{
+		ProtocolVersion: pb.ProtocolVersion,
+		Capabilities:    pb.AgentCapabilities(),
+		Version:         &ver,
+	}, nil
+}
+
+// GetPeers returns the agent's view of its gRPC peers and their health.
+// Agents whose peer store cannot enumerate peers report an empty list
+func (srv *agentServer) GetPeers(ctx context.Context, _ *types.Empty) (*pb.PeersList, error) {
+	lister, ok := srv.PeerStore.(PeerLister)
+	if !ok {
+		return &pb.PeersList{}, nil
+	}
+	result := &pb.PeersList{}
+	for _, peer := range lister.ListPeers() {
+		result.Peers = append(result.Peers, &pb.PeerInfo{
+			Addr:    peer.Addr,
+			Healthy: peer.Healthy,
+		})
+	}
+	return result, nil
+}
+
 // Shutdown requests agent to shut down
 func (srv *agentServer) Shutdown(ctx context.Context, req *pb.ShutdownRequest) (resp *types.Empty, err error) {
 	srv.WithField("req", req).Info("Shutdown.")
@@ -170,9 +198,28 @@ func (srv *agentServer) Abort(ctx context.Context, req *types.Empty) (resp *type
 	return &types.Empty{}, trace.Wrap(err)
 }
 
+// CancelCommand cancels the in-flight commands tagged with the given
+// sequence number
+func (srv *agentServer) CancelCommand(ctx context.Context, req *pb.CancelCommandRequest) (*pb.CancelCommandResponse, error) {
+	srv.WithField("seq", req.Seq).Info("CancelCommand.")
+	return &pb.CancelCommandResponse{Found: srv.commands.cancel(req.Seq)}, nil
+}
+
 func (srv *agentServer) command(req pb.CommandArgs, stream pb.Agent_CommandServer, log *log.Entry) (err error) {
-	err = srv.commandExecutor.exec(stream.Context(), stream, req, makeRemoteLogger(stream, srv.FieldLogger))
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	ctx, cancelTimeout, limitedByOperation := commandContext(ctx, req, srv.getOperationDeadline())
+	defer cancelTimeout()
+	if req.Seq == 0 {
+		req.Seq = srv.commands.nextSeq()
+	}
+	id := srv.commands.add(req.Seq, cancel)
+	defer srv.commands.remove(req.Seq, id)
+	err = srv.commandExecutor.exec(ctx, stream, req, makeRemoteLogger(stream, srv.FieldLogger))
 	if err != nil {
+		if limitedByOperation && ctx.Err() == context.DeadlineExceeded {
+			err = operationDeadlineError(err)
+		}
 		stream.Send(pb.ErrorToMessage(err)) //nolint:errcheck
 		log.WithError(err).Warn("Command completed with error.")
 		return trace.Wrap(err)
@@ -180,3 +227,65 @@ func (srv *agentServer) command(req pb.CommandArgs, stream pb.Agent_CommandServe
 	log.Debug("Command completed OK.")
 	return nil
 }
+
+// commandRegistry keeps track of the commands currently executing on the
+// agent keyed by their sequence number so they can be cancelled via the
+// CancelCommand API. Multiple commands can share a sequence number, e.g.
+// when an operation tags all of its commands with the same value
+type commandRegistry struct {
+	// mu guards the fields below
+	mu  sync.Mutex
+	seq int32
+	id  int
+	// running maps the sequence number to the cancel functions of the
+	// commands executing under it, keyed by registration ID
+	running map[int32]map[int]context.CancelFunc
+}
+
+// nextSeq returns the next agent-assigned sequence number.
+// Used for commands the client did not tag itself
+func (r *commandRegistry) nextSeq() int32 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seq++
+	return r.seq
+}
+
+// add registers the cancel function of a command executing under the given
+// sequence number and returns the registration ID to remove it with
+func (r *commandRegistry) add(seq int32, cancel context.CancelFunc) (id int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.running == nil {
+		r.running = make(map[int32]map[int]context.CancelFunc)
+	}
+	if r.running[seq] == nil {
+		r.running[seq] = make(map[int]context.CancelFunc)
+	}
+	r.id++
+	r.running[seq][r.id] = cancel
+	return r.id
+}
+
+// remove unregisters the command identified with the given sequence number
+// and registration ID
+func (r *commandRegistry) remove(seq int32, id int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.running[seq], id)
+	if len(r.running[seq]) == 0 {
+		delete(r.running, seq)
+	}
+}
+
+// cancel signals all commands executing under the given sequence number
+// to stop. Returns true if there was at least one such command
+func (r *commandRegistry) cancel(seq int32) (found bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, cancel := range r.running[seq] {
+		cancel()
+		found = true
+	}
+	return found
+}
@@ -210,6 +210,12 @@ func (r *AgentGroup) GetPeers() []Peer {
 	return r.peers.getPeers()
 }
 
+// ListPeers returns the monitored peers and their connection health.
+// Implements PeerLister
+func (r *AgentGroup) ListPeers() []PeerStatus {
+	return r.peers.listPeers()
+}
+
 func (r *AgentGroup) updateLoop() {
 	for {
 		select {
@@ -259,6 +265,22 @@ func (r errorPeer) GetVersion(context.Context) (*pb.Version, error) {
 	return nil, trace.Wrap(r.error)
 }
 
+func (r errorPeer) GetCapabilities(context.Context) (*pb.Capabilities, error) {
+	return nil, trace.Wrap(r.error)
+}
+
+func (r errorPeer) GetPeers(context.Context) ([]*pb.PeerInfo, error) {
+	return nil, trace.Wrap(r.error)
+}
+
+func (r errorPeer) EstimateClockOffset(context.Context, int) (time.Duration, time.Duration, error) {
+	return 0, 0, trace.Wrap(r.error)
+}
+
+func (r errorPeer) TailOperationLog(context.Context, string, int) (<-chan string, error) {
+	return nil, trace.Wrap(r.error)
+}
+
 func (r errorPeer) CheckPorts(context.Context, *validationpb.CheckPortsRequest) (*validationpb.CheckPortsResponse, error) {
 	return nil, trace.Wrap(r.error)
 }
@@ -271,6 +293,10 @@ func (r errorPeer) CheckDisks(context.Context, *validationpb.CheckDisksRequest)
 	return nil, trace.Wrap(r.error)
 }
 
+func (r errorPeer) CancelCommand(context.Context, int32) (bool, error) {
+	return false, trace.Wrap(r.error)
+}
+
 func (r errorPeer) Shutdown(context.Context, *pb.ShutdownRequest) error {
 	return trace.Wrap(r.error)
 }
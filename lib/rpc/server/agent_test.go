@@ -341,6 +341,230 @@ func (r *S) TestQueriesSystemInfo(c *C) {
 	compare.DeepCompare(c, obtained, sysinfo)
 }
 
+func (r *S) TestEstimatesClockOffset(c *C) {
+	creds := TestCredentials(c)
+	log := r.WithField("test", "EstimatesClockOffset")
+	listener := listen(c)
+	srv, err := New(Config{
+		FieldLogger: log.WithField("server", listener.Addr()),
+		Listener:    listener,
+		Credentials: creds,
+	})
+	c.Assert(err, IsNil)
+
+	go func() {
+		c.Assert(srv.Serve(), IsNil)
+	}()
+	defer withTestCtx(srv.Stop, c)
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+	clt, err := client.New(ctx,
+		client.Config{
+			ServerAddr:  srv.Addr().String(),
+			Credentials: creds.Client,
+		})
+	c.Assert(err, IsNil)
+	defer clt.Close()
+
+	offset, jitter, err := clt.EstimateClockOffset(ctx, 5)
+	c.Assert(err, IsNil)
+	// both ends share the same clock so the estimate must be small
+	c.Assert(offset < time.Second && offset > -time.Second, Equals, true,
+		Commentf("unexpected offset: %v", offset))
+	c.Assert(jitter >= 0, Equals, true, Commentf("unexpected jitter: %v", jitter))
+
+	_, _, err = clt.EstimateClockOffset(ctx, 0)
+	c.Assert(err, NotNil)
+}
+
+func (r *S) TestQueriesCapabilities(c *C) {
+	creds := TestCredentials(c)
+	log := r.WithField("test", "QueriesCapabilities")
+	listener := listen(c)
+	srv, err := New(Config{
+		FieldLogger: log.WithField("server", listener.Addr()),
+		Listener:    listener,
+		Credentials: creds,
+	})
+	c.Assert(err, IsNil)
+
+	go func() {
+		c.Assert(srv.Serve(), IsNil)
+	}()
+	defer withTestCtx(srv.Stop, c)
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+	clt, err := client.New(ctx,
+		client.Config{
+			ServerAddr:  srv.Addr().String(),
+			Credentials: creds.Client,
+		})
+	c.Assert(err, IsNil)
+	defer clt.Close()
+
+	capabilities, err := clt.GetCapabilities(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(capabilities.ProtocolVersion, Equals, int32(pb.ProtocolVersion))
+	c.Assert(capabilities.Capabilities, DeepEquals, pb.AgentCapabilities())
+	c.Assert(capabilities.Version, NotNil)
+}
+
+func (r *S) TestQueriesPeers(c *C) {
+	creds := TestCredentials(c)
+	log := r.WithField("test", "QueriesPeers")
+	listener := listen(c)
+	store := fakePeerLister{peers: []PeerStatus{
+		{Addr: "192.168.1.1:3012", Healthy: true},
+		{Addr: "192.168.1.2:3012", Healthy: false},
+	}}
+	srv, err := New(Config{
+		FieldLogger: log.WithField("server", listener.Addr()),
+		Listener:    listener,
+		Credentials: creds,
+		PeerStore:   store,
+	})
+	c.Assert(err, IsNil)
+
+	go func() {
+		c.Assert(srv.Serve(), IsNil)
+	}()
+	defer withTestCtx(srv.Stop, c)
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+	clt, err := client.New(ctx,
+		client.Config{
+			ServerAddr:  srv.Addr().String(),
+			Credentials: creds.Client,
+		})
+	c.Assert(err, IsNil)
+	defer clt.Close()
+
+	peers, err := clt.GetPeers(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(peers, DeepEquals, []*pb.PeerInfo{
+		{Addr: "192.168.1.1:3012", Healthy: true},
+		{Addr: "192.168.1.2:3012", Healthy: false},
+	})
+}
+
+func (r *S) TestQueriesPeersWithoutPeerLister(c *C) {
+	creds := TestCredentials(c)
+	log := r.WithField("test", "QueriesPeersWithoutPeerLister")
+	listener := listen(c)
+	srv, err := New(Config{
+		FieldLogger: log.WithField("server", listener.Addr()),
+		Listener:    listener,
+		Credentials: creds,
+	})
+	c.Assert(err, IsNil)
+
+	go func() {
+		c.Assert(srv.Serve(), IsNil)
+	}()
+	defer withTestCtx(srv.Stop, c)
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+	clt, err := client.New(ctx,
+		client.Config{
+			ServerAddr:  srv.Addr().String(),
+			Credentials: creds.Client,
+		})
+	c.Assert(err, IsNil)
+	defer clt.Close()
+
+	// a store that cannot enumerate peers reports an empty list
+	peers, err := clt.GetPeers(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(peers, HasLen, 0)
+}
+
+func (r *S) TestCancelsCommand(c *C) {
+	creds := TestCredentials(c)
+	log := r.WithField("test", "CancelsCommand")
+	listener := listen(c)
+	cmd := &blockingCommand{started: make(chan int32, 1)}
+	srv, err := New(Config{
+		FieldLogger:     log.WithField("server", listener.Addr()),
+		Listener:        listener,
+		Credentials:     creds,
+		commandExecutor: cmd,
+	})
+	c.Assert(err, IsNil)
+
+	go func() {
+		c.Assert(srv.Serve(), IsNil)
+	}()
+	defer withTestCtx(srv.Stop, c)
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+	clt, err := client.New(ctx,
+		client.Config{
+			ServerAddr:  srv.Addr().String(),
+			Credentials: creds.Client,
+		})
+	c.Assert(err, IsNil)
+	defer clt.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- clt.Command(ctx, log, nil, nil, "test")
+	}()
+
+	// the command blocks until its execution context is cancelled
+	var seq int32
+	select {
+	case seq = <-cmd.started:
+	case <-ctx.Done():
+		c.Fatal("timeout waiting for the command to start")
+	}
+
+	// cancelling an unknown sequence number is not acknowledged
+	found, err := clt.CancelCommand(ctx, seq+1)
+	c.Assert(err, IsNil)
+	c.Assert(found, Equals, false)
+
+	found, err = clt.CancelCommand(ctx, seq)
+	c.Assert(err, IsNil)
+	c.Assert(found, Equals, true)
+
+	select {
+	case err = <-done:
+		c.Assert(err, NotNil)
+	case <-ctx.Done():
+		c.Fatal("timeout waiting for the command to stop")
+	}
+}
+
+// blockingCommand is a command executor that blocks until its execution
+// context is cancelled, reporting the sequence number of each command.
+// Implements commandExecutor
+type blockingCommand struct {
+	started chan int32
+}
+
+func (r *blockingCommand) exec(ctx context.Context, stream pb.OutgoingMessageStream, req pb.CommandArgs, log log.FieldLogger) error {
+	r.started <- req.Seq
+	<-ctx.Done()
+	return trace.Wrap(ctx.Err())
+}
+
+// fakePeerLister is a peer store reporting a static list of peers.
+// Implements PeerStore and PeerLister
+type fakePeerLister struct {
+	discardStore
+	peers []PeerStatus
+}
+
+// ListPeers returns the static list of peers
+func (r fakePeerLister) ListPeers() []PeerStatus {
+	return r.peers
+}
+
 func (r *S) clientExecutesCommandsWithClient(c *C, clt client.Client, srv *agentServer, expectedOutput string) {
 	defer withTestCtx(srv.Stop, c)
 
@@ -17,9 +17,21 @@ limitations under the License.
 package server
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"syscall"
+	"time"
+	"unicode/utf8"
 
 	pb "github.com/gravitational/gravity/lib/rpc/proto"
 
@@ -40,21 +52,56 @@ func osExec(ctx context.Context, stream pb.OutgoingMessageStream, req pb.Command
 
 // exec executes the command specified with args streaming stdout/stderr to stream
 func (c *osCommand) exec(ctx context.Context, stream pb.OutgoingMessageStream, req pb.CommandArgs, log log.FieldLogger) error {
-	seq := atomic.AddInt32(&c.seq, 1)
+	if err := validateCommandArgs(req); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := c.policy.checkWorkingDir(req.WorkingDir); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := c.policy.checkCommand(req.Args[0]); err != nil {
+		return trace.Wrap(err)
+	}
+	seq := req.Seq
+	if seq == 0 {
+		seq = atomic.AddInt32(&c.seq, 1)
+	}
+	var checksum *outputChecksum
+	if req.ChecksumOutput {
+		checksum = newOutputChecksum()
+	}
+	var limiter *outputLimiter
+	if limit := effectiveOutputLimit(c.maxOutputBytes, req.MaxOutputBytes); limit > 0 {
+		limiter = newOutputLimiter(limit)
+	}
+	if req.AllocatePty {
+		return trace.Wrap(c.execPTY(ctx, stream, req, seq, checksum, limiter, log))
+	}
 	cmd := exec.CommandContext(ctx, req.Args[0], req.Args[1:]...)
-	cmd.Stdout = &streamWriter{stream, pb.ExecOutput_STDOUT, seq}
-	cmd.Stderr = &streamWriter{stream, pb.ExecOutput_STDERR, seq}
+	stdout := newOutputWriter(stream, pb.ExecOutput_STDOUT, seq, req, checksum, limiter)
+	stderr := newOutputWriter(stream, pb.ExecOutput_STDERR, seq, req, checksum, limiter)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
 	cmd.Dir = req.WorkingDir
+	cmd.Env = commandEnv(req)
 
+	started := time.Now()
 	err := cmd.Start()
 	if err != nil {
 		return trace.Wrap(err, "failed to start").AddField("path", cmd.Path)
 	}
 
-	notifyAndLogError(stream, newCommandStartedEvent(seq, req.Args))
+	notifyAndLogError(stream, newCommandStartedEvent(seq, req))
+	stopHeartbeat := startHeartbeat(stream, seq, req.HeartbeatInterval())
 	err = cmd.Wait()
+	stopHeartbeat()
+	// flush any output still buffered by the writers before the completion
+	// event is emitted
+	closeOutput(stdout, stderr)
+	duration := time.Since(started)
+	observeCommandDuration(req, duration)
+	notifyTruncated(stream, seq, limiter)
 	if err == nil {
-		notifyAndLogError(stream, newCommandCompletedEvent(seq))
+		notifyAndLogError(stream, newCommandCompletedEvent(seq, req, checksum, limiter, duration))
 		return nil
 	}
 
@@ -65,75 +112,688 @@ func (c *osCommand) exec(ctx context.Context, stream pb.OutgoingMessageStream, r
 		}
 	}
 
-	notifyAndLogError(stream, newCommandCompletedWithErrorEvent(seq, int32(exitCode), err))
+	notifyAndLogError(stream, newCommandCompletedWithErrorEvent(seq, int32(exitCode), err, req, limiter, duration))
 	return trace.Wrap(err)
 }
 
+// validateCommandArgs ensures the request specifies a command that can
+// actually be started. A malformed request would otherwise panic indexing
+// into the arguments or fail with an unhelpful start error
+func validateCommandArgs(req pb.CommandArgs) error {
+	if len(req.Args) == 0 {
+		return trace.BadParameter("at least one command argument is required")
+	}
+	command := req.Args[0]
+	if strings.TrimSpace(command) == "" {
+		return trace.BadParameter("command name cannot be blank")
+	}
+	if !filepath.IsAbs(command) {
+		if _, err := exec.LookPath(command); err != nil {
+			return trace.BadParameter("command %q could not be resolved: %v",
+				command, err)
+		}
+	}
+	return nil
+}
+
+// commandContext derives the execution context for the command: the
+// command's own timeout is capped by the time remaining until the
+// operation deadline, if the server has been told one. Returns
+// limitedByOperation=true when the operation deadline rather than the
+// command's own timeout is the effective limit
+func commandContext(ctx context.Context, req pb.CommandArgs, operationDeadline time.Time) (context.Context, context.CancelFunc, bool) {
+	timeout := req.Timeout()
+	limitedByOperation := false
+	if !operationDeadline.IsZero() {
+		remaining := time.Until(operationDeadline)
+		if timeout == 0 || remaining < timeout {
+			timeout = remaining
+			limitedByOperation = true
+		}
+	}
+	if timeout == 0 && !limitedByOperation {
+		return ctx, func() {}, false
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, cancel, limitedByOperation
+}
+
+// operationDeadlineError marks the command error as caused by the
+// expiration of the operation deadline rather than the command's own
+// timeout
+func operationDeadlineError(err error) error {
+	return trace.LimitExceeded("operation deadline exceeded: %v", err)
+}
+
+// IsOperationDeadlineError returns true if the error indicates that the
+// command was terminated because the operation deadline expired
+func IsOperationDeadlineError(err error) bool {
+	return trace.IsLimitExceeded(err) && strings.Contains(err.Error(), "operation deadline exceeded")
+}
+
+// startHeartbeat periodically notifies the stream that the command
+// specified with seq is still running so the connection does not go idle
+// during quiet phases. The returned stop function terminates the
+// heartbeats and does not return until the last one has been sent.
+// A non-positive interval disables heartbeats
+func startHeartbeat(stream pb.OutgoingMessageStream, seq int32, interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		return func() {}
+	}
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				notifyAndLogError(stream, newCommandHeartbeatEvent(seq))
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
 func notifyAndLogError(stream pb.OutgoingMessageStream, msg *pb.Message) {
 	if err := stream.Send(msg); err != nil {
 		log.WithError(err).Warnf("Failed to notify stream: %v.", msg)
 	}
 }
 
-func newCommandStartedEvent(seq int32, args []string) *pb.Message {
+func newCommandStartedEvent(seq int32, req pb.CommandArgs) *pb.Message {
 	return &pb.Message{
 		Element: &pb.Message_ExecStarted{
 			ExecStarted: &pb.ExecStarted{
-				Args: args,
-				Seq:  seq,
+				Args:         req.Args,
+				Seq:          seq,
+				OperationSeq: req.OperationSeq,
 			},
 		},
 	}
 }
 
-func newCommandCompletedEvent(seq int32) *pb.Message {
+func newCommandHeartbeatEvent(seq int32) *pb.Message {
 	return &pb.Message{
-		Element: &pb.Message_ExecCompleted{
-			ExecCompleted: &pb.ExecCompleted{
+		Element: &pb.Message_ExecHeartbeat{
+			ExecHeartbeat: &pb.ExecHeartbeat{
 				Seq: seq,
 			},
 		},
 	}
 }
 
-func newCommandCompletedWithErrorEvent(seq, exitCode int32, err error) *pb.Message {
+// effectiveOutputLimit combines the server-wide output cap with the cap
+// requested for a single command: the smaller of the two non-zero values
+// wins. Returns 0 if neither caps the output
+func effectiveOutputLimit(serverLimit, requestLimit int64) int64 {
+	switch {
+	case serverLimit <= 0:
+		return requestLimit
+	case requestLimit <= 0:
+		return serverLimit
+	case requestLimit < serverLimit:
+		return requestLimit
+	default:
+		return serverLimit
+	}
+}
+
+// notifyTruncated emits the truncation event for the command if its output
+// was actually truncated by the cap
+func notifyTruncated(stream pb.OutgoingMessageStream, seq int32, limiter *outputLimiter) {
+	if limiter == nil || limiter.truncatedBytes() == 0 {
+		return
+	}
+	notifyAndLogError(stream, &pb.Message{
+		Element: &pb.Message_ExecTruncated{
+			ExecTruncated: &pb.ExecTruncated{
+				Seq:        seq,
+				Limit:      limiter.limit,
+				TotalBytes: limiter.totalBytes(),
+			},
+		},
+	})
+}
+
+func newCommandCompletedEvent(seq int32, req pb.CommandArgs, checksum *outputChecksum, limiter *outputLimiter, duration time.Duration) *pb.Message {
+	completed := &pb.ExecCompleted{
+		Seq:            seq,
+		OperationSeq:   req.OperationSeq,
+		DurationMillis: duration.Milliseconds(),
+	}
+	if checksum != nil {
+		completed.OutputDigest, completed.OutputBytes = checksum.sum()
+	}
+	if limiter != nil {
+		completed.TruncatedBytes = limiter.truncatedBytes()
+	}
 	return &pb.Message{
 		Element: &pb.Message_ExecCompleted{
-			ExecCompleted: &pb.ExecCompleted{
-				Seq:      seq,
-				ExitCode: exitCode,
-				Error:    pb.EncodeError(err),
-			},
+			ExecCompleted: completed,
+		},
+	}
+}
+
+func newCommandCompletedWithErrorEvent(seq, exitCode int32, err error, req pb.CommandArgs, limiter *outputLimiter, duration time.Duration) *pb.Message {
+	completed := &pb.ExecCompleted{
+		Seq:            seq,
+		OperationSeq:   req.OperationSeq,
+		ExitCode:       exitCode,
+		Error:          pb.EncodeError(err),
+		DurationMillis: duration.Milliseconds(),
+	}
+	if limiter != nil {
+		completed.TruncatedBytes = limiter.truncatedBytes()
+	}
+	return &pb.Message{
+		Element: &pb.Message_ExecCompleted{
+			ExecCompleted: completed,
 		},
 	}
 }
 
 type osCommand struct {
 	seq int32
+	// policy restricts the working directories commands may execute in
+	policy execPolicy
+	// maxOutputBytes optionally caps the amount of output a single
+	// command may stream. Output past the cap is dropped and reported
+	// as truncated in the completion event. Zero places no cap
+	maxOutputBytes int64
+}
+
+// execPolicy restricts what remote commands the agent will execute
+type execPolicy struct {
+	// allowedDirs lists the path prefixes the command's working directory
+	// is allowed under. An empty list is permissive
+	allowedDirs []string
+	// allowedCommands lists the executables commands may invoke, as
+	// absolute paths or basenames. An empty list is permissive
+	allowedCommands []string
+	// deniedCommands lists the executables commands may never invoke, as
+	// absolute paths or basenames. Takes precedence over allowedCommands
+	deniedCommands []string
+}
+
+// checkWorkingDir validates the command's working directory against the
+// policy. An empty working directory (inherit the agent's) is always allowed
+func (r execPolicy) checkWorkingDir(dir string) error {
+	if dir == "" || len(r.allowedDirs) == 0 {
+		return nil
+	}
+	path := filepath.Clean(dir)
+	for _, allowed := range r.allowedDirs {
+		prefix := filepath.Clean(allowed)
+		if path == prefix || strings.HasPrefix(path, prefix+string(filepath.Separator)) {
+			return nil
+		}
+	}
+	return trace.AccessDenied(
+		"working directory %q is not allowed by the agent policy", dir)
+}
+
+// checkCommand validates the executable the command invokes against the
+// policy. The denylist is consulted first, then the allowlist if one has
+// been configured
+func (r execPolicy) checkCommand(command string) error {
+	for _, denied := range r.deniedCommands {
+		if commandMatches(command, denied) {
+			return trace.AccessDenied(
+				"command %q is denied by the agent policy", command)
+		}
+	}
+	if len(r.allowedCommands) == 0 {
+		return nil
+	}
+	for _, allowed := range r.allowedCommands {
+		if commandMatches(command, allowed) {
+			return nil
+		}
+	}
+	return trace.AccessDenied(
+		"command %q is not allowed by the agent policy", command)
+}
+
+// commandMatches determines whether the invoked command matches the policy
+// pattern. An absolute pattern matches the full path only while a basename
+// pattern matches regardless of the directory the executable resides in
+func commandMatches(command, pattern string) bool {
+	if filepath.IsAbs(pattern) {
+		return filepath.Clean(command) == filepath.Clean(pattern)
+	}
+	return filepath.Base(command) == pattern
+}
+
+// commandEnv computes the environment for the command: the agent's own
+// environment extended with the explicitly supplied variables, or only
+// the supplied variables when ClearEnv is set. Returns nil when there is
+// nothing to add so the command inherits the agent's environment as-is
+func commandEnv(req pb.CommandArgs) []string {
+	var env []string
+	switch {
+	case req.ClearEnv:
+		env = make([]string, 0, len(req.Env))
+	case len(req.Env) == 0:
+		return nil
+	default:
+		env = os.Environ()
+	}
+	// sort the keys so the environment is stable between runs
+	keys := make([]string, 0, len(req.Env))
+	for key := range req.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		env = append(env, fmt.Sprintf("%v=%v", key, req.Env[key]))
+	}
+	return env
+}
+
+// newOutputWriter returns a writer that forwards command output to the
+// stream, coalescing small writes into fewer ExecOutput messages if
+// buffering has been requested in req. Without an explicit buffer size
+// every write is sent immediately to preserve latency-sensitive streaming
+func newOutputWriter(stream pb.OutgoingMessageStream, fd pb.ExecOutput_FD, seq int32, req pb.CommandArgs, checksum *outputChecksum, limiter *outputLimiter) outputWriter {
+	w := &streamWriter{
+		stream:   stream,
+		fd:       fd,
+		seq:      seq,
+		compress: req.CompressOutput,
+		classify: req.ClassifyOutput,
+		checksum: checksum,
+		limiter:  limiter,
+	}
+	if req.StripAnsiOutput {
+		w.stripper = &ansiStripper{}
+	}
+	if req.OutputBufferSize <= 0 {
+		return w
+	}
+	return newBufferedStreamWriter(w, int(req.OutputBufferSize), req.OutputFlushInterval())
+}
+
+// closeOutput closes the command's output writers flushing any output
+// they still hold
+func closeOutput(writers ...outputWriter) {
+	for _, w := range writers {
+		if err := w.close(); err != nil {
+			log.WithError(err).Warn("Failed to flush command output.")
+		}
+	}
+}
+
+// outputWriter sinks command output into the message stream
+type outputWriter interface {
+	io.Writer
+	// close flushes any buffered output and releases the writer's resources
+	close() error
 }
 
 // streamWriter implements io.Writer and forwards the data to the underlying stream
 type streamWriter struct {
-	stream pb.OutgoingMessageStream
-	fd     pb.ExecOutput_FD
-	seq    int32
+	stream   pb.OutgoingMessageStream
+	fd       pb.ExecOutput_FD
+	seq      int32
+	compress bool
+	// classify makes every emitted chunk carry a text-vs-binary tag
+	classify bool
+	// stripper optionally removes ANSI escape sequences from the output.
+	// The stdout/stderr writers of the same command each have their own
+	// stripper as the sequences of the two streams interleave
+	stripper *ansiStripper
+	// checksum optionally accumulates the digest of the emitted bytes.
+	// It is shared between the stdout/stderr writers of the same command
+	checksum *outputChecksum
+	// limiter optionally caps the emitted bytes, counting what has been
+	// dropped. It is shared between the stdout/stderr writers of the
+	// same command
+	limiter *outputLimiter
+}
+
+// isBinaryOutput reports whether the output chunk looks like binary
+// rather than text: it contains NUL bytes or invalid UTF-8. An
+// incomplete UTF-8 sequence at the very end of the chunk is tolerated
+// since chunk boundaries can split multi-byte runes
+func isBinaryOutput(p []byte) bool {
+	if bytes.IndexByte(p, 0) >= 0 {
+		return true
+	}
+	for len(p) > 0 {
+		r, size := utf8.DecodeRune(p)
+		if r == utf8.RuneError && size == 1 {
+			// an invalid encoding decodes as a full error rune while a
+			// trailing prefix of a multi-byte rune does not
+			return utf8.FullRune(p) || len(p) >= utf8.UTFMax
+		}
+		p = p[size:]
+	}
+	return false
 }
 
+// compressThreshold is the minimum output chunk size to be compressed.
+// Smaller chunks are sent as is since compression would mostly add overhead
+const compressThreshold = 1024
+
 func (s *streamWriter) Write(p []byte) (n int, err error) {
+	n = len(p)
+	if s.stripper != nil {
+		p = s.stripper.strip(p)
+		if len(p) == 0 {
+			return n, nil
+		}
+	}
+	if s.limiter != nil {
+		// writes are reported complete to the command even when the
+		// cap drops (part of) them so the command keeps running
+		p = s.limiter.admit(p)
+		if len(p) == 0 {
+			return n, nil
+		}
+	}
+	if s.checksum != nil {
+		s.checksum.update(p)
+	}
 	data := &pb.ExecOutput{
 		Fd:   s.fd,
 		Data: p,
 		Seq:  s.seq,
 	}
+	if s.classify {
+		data.Binary = isBinaryOutput(p)
+	}
+	if s.compress && len(p) >= compressThreshold {
+		compressed, err := pb.CompressData(p)
+		if err != nil {
+			return 0, trace.Wrap(err)
+		}
+		data.Data = compressed
+		data.Compressed = true
+	}
 
 	err = s.stream.Send(&pb.Message{Element: &pb.Message_ExecOutput{ExecOutput: data}})
 	if err != nil {
 		return 0, err
 	}
 
+	return n, nil
+}
+
+// close is a no-op as the writer does not buffer
+func (s *streamWriter) close() error {
+	return nil
+}
+
+// newBufferedStreamWriter returns a writer that coalesces writes into
+// chunks of up to size bytes on top of the specified writer. A positive
+// interval additionally flushes the buffer periodically so the output
+// does not sit in the buffer indefinitely during quiet phases
+func newBufferedStreamWriter(out *streamWriter, size int, interval time.Duration) *bufferedStreamWriter {
+	w := &bufferedStreamWriter{
+		out:     out,
+		buf:     make([]byte, 0, size),
+		size:    size,
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go w.flushLoop(interval)
+	return w
+}
+
+// bufferedStreamWriter coalesces small writes into fewer ExecOutput
+// messages, flushing when the buffer fills up, when the flush interval
+// expires or when the writer is closed on command completion
+type bufferedStreamWriter struct {
+	// mu guards the buffer against concurrent writes and timer flushes
+	mu      sync.Mutex
+	out     *streamWriter
+	buf     []byte
+	size    int
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+func (w *bufferedStreamWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	// writes that fill the buffer on their own are sent directly
+	// to avoid the extra copy
+	if len(p) >= w.size {
+		if err := w.flush(); err != nil {
+			return 0, trace.Wrap(err)
+		}
+		return w.out.Write(p)
+	}
+	if len(w.buf)+len(p) > w.size {
+		if err := w.flush(); err != nil {
+			return 0, trace.Wrap(err)
+		}
+	}
+	w.buf = append(w.buf, p...)
 	return len(p), nil
 }
 
+// close flushes the remaining buffered output and stops the flush timer
+func (w *bufferedStreamWriter) close() error {
+	close(w.done)
+	<-w.stopped
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return trace.Wrap(w.flush())
+}
+
+// flush sends the buffered output downstream. Callers must hold w.mu
+func (w *bufferedStreamWriter) flush() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	// copy the buffer as the stream may retain the slice past Send
+	data := make([]byte, len(w.buf))
+	copy(data, w.buf)
+	w.buf = w.buf[:0]
+	_, err := w.out.Write(data)
+	return trace.Wrap(err)
+}
+
+func (w *bufferedStreamWriter) flushLoop(interval time.Duration) {
+	defer close(w.stopped)
+	if interval <= 0 {
+		<-w.done
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.mu.Lock()
+			err := w.flush()
+			w.mu.Unlock()
+			if err != nil {
+				log.WithError(err).Warn("Failed to flush command output.")
+			}
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// newOutputChecksum creates a checksum accumulator for command output
+func newOutputChecksum() *outputChecksum {
+	return &outputChecksum{hash: sha256.New()}
+}
+
+// outputChecksum maintains a running digest and total byte count
+// of the command's output
+type outputChecksum struct {
+	// mu guards concurrent updates from the stdout/stderr writers
+	mu    sync.Mutex
+	hash  hash.Hash
+	total int64
+}
+
+func (r *outputChecksum) update(p []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hash.Write(p)
+	r.total += int64(len(p))
+}
+
+func (r *outputChecksum) sum() (digest []byte, total int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.hash.Sum(nil), r.total
+}
+
+// newOutputLimiter creates an output cap for a single command limiting
+// the emitted output to limit bytes
+func newOutputLimiter(limit int64) *outputLimiter {
+	return &outputLimiter{limit: limit}
+}
+
+// outputLimiter caps the command's output at a fixed number of bytes,
+// keeping count of the produced vs emitted bytes so the completion event
+// can report how much was dropped
+type outputLimiter struct {
+	// mu guards concurrent updates from the stdout/stderr writers
+	mu      sync.Mutex
+	limit   int64
+	total   int64
+	emitted int64
+}
+
+// admit accounts for the write and returns the portion of it that still
+// fits under the cap. Returns an empty slice once the cap is exhausted
+func (r *outputLimiter) admit(p []byte) []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.total += int64(len(p))
+	remaining := r.limit - r.emitted
+	if remaining <= 0 {
+		return nil
+	}
+	if remaining < int64(len(p)) {
+		p = p[:remaining]
+	}
+	r.emitted += int64(len(p))
+	return p
+}
+
+// truncatedBytes returns the number of output bytes dropped by the cap.
+// Returns 0 if the output was not truncated
+func (r *outputLimiter) truncatedBytes() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.total - r.emitted
+}
+
+// totalBytes returns the total number of output bytes the command produced,
+// including the ones dropped by the cap
+func (r *outputLimiter) totalBytes() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.total
+}
+
+// asciiEsc is the escape character that starts ANSI terminal sequences
+const asciiEsc = 0x1b
+
+// maxPendingSequence caps how many bytes of an unterminated escape
+// sequence the stripper buffers: real terminal sequences are short and
+// anything longer is passed through verbatim
+const maxPendingSequence = 64
+
+// ansiStripper removes ANSI terminal escape sequences from a stream of
+// output chunks. A sequence split across chunk boundaries is buffered
+// until the chunk carrying its terminator arrives; an unterminated
+// sequence at the end of the stream is dropped
+type ansiStripper struct {
+	pending []byte
+}
+
+// strip returns p with the ANSI escape sequences removed
+func (r *ansiStripper) strip(p []byte) []byte {
+	if len(r.pending) == 0 && bytes.IndexByte(p, asciiEsc) < 0 {
+		return p
+	}
+	p = append(r.pending, p...)
+	r.pending = nil
+	var out []byte
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, asciiEsc)
+		if i < 0 {
+			out = append(out, p...)
+			break
+		}
+		out = append(out, p[:i]...)
+		p = p[i:]
+		size, complete := escapeSequenceSize(p)
+		if !complete {
+			if len(p) < maxPendingSequence {
+				// wait for the rest of the sequence
+				r.pending = append([]byte(nil), p...)
+				break
+			}
+			// too long to be a terminal sequence, pass it through
+			out = append(out, p...)
+			break
+		}
+		p = p[size:]
+	}
+	return out
+}
+
+// escapeSequenceSize returns the length of the ANSI escape sequence at the
+// beginning of p (p[0] is the escape character). Returns complete=false
+// if the sequence continues past the end of p
+func escapeSequenceSize(p []byte) (size int, complete bool) {
+	if len(p) < 2 {
+		return 0, false
+	}
+	switch p[1] {
+	case '[':
+		// CSI: parameter/intermediate bytes until a final byte in @-~
+		for i := 2; i < len(p); i++ {
+			if p[i] >= 0x40 && p[i] <= 0x7e {
+				return i + 1, true
+			}
+			if p[i] < 0x20 || p[i] > 0x3f {
+				// malformed sequence, strip what was consumed so far
+				return i, true
+			}
+		}
+		return 0, false
+	case ']':
+		// OSC: terminated by BEL or the two-byte string terminator ESC \
+		for i := 2; i < len(p); i++ {
+			if p[i] == 0x07 {
+				return i + 1, true
+			}
+			if p[i] == asciiEsc {
+				if i+1 >= len(p) {
+					return 0, false
+				}
+				if p[i+1] == '\\' {
+					return i + 2, true
+				}
+				// a new escape starts: the sequence was never terminated
+				return i, true
+			}
+		}
+		return 0, false
+	default:
+		// two-character sequence, e.g. ESC c or ESC 7
+		return 2, true
+	}
+}
+
 func (r execFunc) exec(ctx context.Context, stream pb.OutgoingMessageStream, req pb.CommandArgs, logger log.FieldLogger) error {
 	return r(ctx, stream, req, logger)
 }
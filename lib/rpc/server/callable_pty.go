@@ -0,0 +1,99 @@
+// +build !windows
+
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"io"
+	"os/exec"
+	"syscall"
+	"time"
+
+	pb "github.com/gravitational/gravity/lib/rpc/proto"
+
+	"github.com/gravitational/trace"
+	"github.com/kr/pty"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"golang.org/x/sys/unix"
+)
+
+// execPTY executes the command specified with args attached to a pseudo-terminal
+// and streams the combined terminal output to stream
+func (c *osCommand) execPTY(ctx context.Context, stream pb.OutgoingMessageStream, req pb.CommandArgs, seq int32, checksum *outputChecksum, limiter *outputLimiter, log log.FieldLogger) error {
+	cmd := exec.CommandContext(ctx, req.Args[0], req.Args[1:]...)
+	cmd.Dir = req.WorkingDir
+	cmd.Env = commandEnv(req)
+
+	started := time.Now()
+	terminal, err := pty.Start(cmd)
+	if err != nil {
+		return trace.Wrap(err, "failed to start").AddField("path", cmd.Path)
+	}
+	defer terminal.Close()
+
+	if req.PtyColumns != 0 || req.PtyRows != 0 {
+		err = unix.IoctlSetWinsize(int(terminal.Fd()), unix.TIOCSWINSZ, &unix.Winsize{
+			Col: uint16(req.PtyColumns),
+			Row: uint16(req.PtyRows),
+		})
+		if err != nil {
+			log.WithError(err).Warn("Failed to set terminal window size.")
+		}
+	}
+
+	notifyAndLogError(stream, newCommandStartedEvent(seq, req))
+	stopHeartbeat := startHeartbeat(stream, seq, req.HeartbeatInterval())
+	w := &streamWriter{
+		stream:   stream,
+		fd:       pb.ExecOutput_STDOUT,
+		seq:      seq,
+		compress: req.CompressOutput,
+		classify: req.ClassifyOutput,
+		checksum: checksum,
+		limiter:  limiter,
+	}
+	if req.StripAnsiOutput {
+		w.stripper = &ansiStripper{}
+	}
+	// the copy terminates with EIO once the command releases the terminal -
+	// the command's exit status below is authoritative
+	if _, err := io.Copy(w, terminal); err != nil {
+		log.WithError(err).Debug("Terminal output copy loop terminated.")
+	}
+
+	err = cmd.Wait()
+	stopHeartbeat()
+	duration := time.Since(started)
+	observeCommandDuration(req, duration)
+	notifyTruncated(stream, seq, limiter)
+	if err == nil {
+		notifyAndLogError(stream, newCommandCompletedEvent(seq, req, checksum, limiter, duration))
+		return nil
+	}
+
+	exitCode := ExitCodeUndefined
+	if errExit, ok := err.(*exec.ExitError); ok {
+		if status, ok := errExit.Sys().(syscall.WaitStatus); ok {
+			exitCode = status.ExitStatus()
+		}
+	}
+
+	notifyAndLogError(stream, newCommandCompletedWithErrorEvent(seq, int32(exitCode), err, req, limiter, duration))
+	return trace.Wrap(err)
+}
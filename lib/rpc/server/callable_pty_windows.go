@@ -0,0 +1,32 @@
+// +build windows
+
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	pb "github.com/gravitational/gravity/lib/rpc/proto"
+
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+)
+
+// execPTY is not supported on this platform
+func (c *osCommand) execPTY(ctx context.Context, stream pb.OutgoingMessageStream, req pb.CommandArgs, seq int32, checksum *outputChecksum, limiter *outputLimiter, log log.FieldLogger) error {
+	return trace.NotImplemented("PTY mode is not supported on this platform")
+}
@@ -0,0 +1,836 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"os"
+	"sync"
+	"time"
+
+	pb "github.com/gravitational/gravity/lib/rpc/proto"
+
+	"github.com/gravitational/trace"
+	"golang.org/x/net/context"
+	. "gopkg.in/check.v1"
+)
+
+func (r *S) TestStreamWriterSendsOutputUncompressed(c *C) {
+	stream := &captureStream{}
+	w := &streamWriter{stream: stream, fd: pb.ExecOutput_STDOUT, seq: 1}
+
+	payload := []byte("plain command output")
+	n, err := w.Write(payload)
+	c.Assert(err, IsNil)
+	c.Assert(n, Equals, len(payload))
+
+	c.Assert(stream.messages, HasLen, 1)
+	output := stream.messages[0].GetExecOutput()
+	c.Assert(output, NotNil)
+	c.Assert(output.Compressed, Equals, false)
+	c.Assert(output.Data, DeepEquals, payload)
+}
+
+func (r *S) TestStreamWriterCompressesOutput(c *C) {
+	stream := &captureStream{}
+	w := &streamWriter{stream: stream, fd: pb.ExecOutput_STDERR, seq: 1, compress: true}
+
+	payload := bytes.Repeat([]byte("compressed command output\n"), compressThreshold)
+	n, err := w.Write(payload)
+	c.Assert(err, IsNil)
+	c.Assert(n, Equals, len(payload))
+
+	c.Assert(stream.messages, HasLen, 1)
+	output := stream.messages[0].GetExecOutput()
+	c.Assert(output, NotNil)
+	c.Assert(output.Compressed, Equals, true)
+
+	// the payload must round-trip byte-exact through compression
+	uncompressed, err := pb.UncompressData(output.Data)
+	c.Assert(err, IsNil)
+	c.Assert(uncompressed, DeepEquals, payload)
+}
+
+func (r *S) TestStreamWriterSkipsCompressionForSmallOutput(c *C) {
+	stream := &captureStream{}
+	w := &streamWriter{stream: stream, fd: pb.ExecOutput_STDOUT, seq: 1, compress: true}
+
+	// chunks below the threshold are sent uncompressed even with
+	// compression enabled
+	payload := []byte("short output")
+	n, err := w.Write(payload)
+	c.Assert(err, IsNil)
+	c.Assert(n, Equals, len(payload))
+
+	c.Assert(stream.messages, HasLen, 1)
+	output := stream.messages[0].GetExecOutput()
+	c.Assert(output, NotNil)
+	c.Assert(output.Compressed, Equals, false)
+	c.Assert(output.Data, DeepEquals, payload)
+}
+
+func (r *S) TestBufferedWriterCoalescesSmallWrites(c *C) {
+	stream := &captureStream{}
+	w := newBufferedStreamWriter(
+		&streamWriter{stream: stream, fd: pb.ExecOutput_STDOUT, seq: 1},
+		64, 0)
+
+	for i := 0; i < 4; i++ {
+		_, err := w.Write([]byte("chunk "))
+		c.Assert(err, IsNil)
+	}
+	// nothing is sent while the writes fit in the buffer
+	c.Assert(stream.messages, HasLen, 0)
+
+	c.Assert(w.close(), IsNil)
+	c.Assert(stream.messages, HasLen, 1)
+	c.Assert(string(execOutput(stream)), Equals, "chunk chunk chunk chunk ")
+}
+
+func (r *S) TestBufferedWriterFlushesOnSize(c *C) {
+	stream := &captureStream{}
+	w := newBufferedStreamWriter(
+		&streamWriter{stream: stream, fd: pb.ExecOutput_STDOUT, seq: 1},
+		10, 0)
+
+	_, err := w.Write([]byte("1234567"))
+	c.Assert(err, IsNil)
+	c.Assert(stream.messages, HasLen, 0)
+	// the second write overflows the buffer and forces a flush
+	_, err = w.Write([]byte("89abc"))
+	c.Assert(err, IsNil)
+	c.Assert(stream.messages, HasLen, 1)
+
+	c.Assert(w.close(), IsNil)
+	c.Assert(string(execOutput(stream)), Equals, "123456789abc")
+}
+
+func (r *S) TestBufferedWriterPassesLargeWritesThrough(c *C) {
+	stream := &captureStream{}
+	w := newBufferedStreamWriter(
+		&streamWriter{stream: stream, fd: pb.ExecOutput_STDOUT, seq: 1},
+		10, 0)
+
+	payload := bytes.Repeat([]byte("x"), 32)
+	n, err := w.Write(payload)
+	c.Assert(err, IsNil)
+	c.Assert(n, Equals, len(payload))
+	c.Assert(stream.messages, HasLen, 1)
+	c.Assert(w.close(), IsNil)
+	c.Assert(execOutput(stream), DeepEquals, payload)
+}
+
+func (r *S) TestBufferedWriterFlushesOnInterval(c *C) {
+	stream := &captureStream{}
+	w := newBufferedStreamWriter(
+		&streamWriter{stream: stream, fd: pb.ExecOutput_STDOUT, seq: 1},
+		1024, 10*time.Millisecond)
+	defer w.close()
+
+	_, err := w.Write([]byte("buffered"))
+	c.Assert(err, IsNil)
+
+	// the flush timer delivers the output without the buffer filling up
+	for i := 0; i < 100; i++ {
+		if len(execOutput(stream)) != 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	c.Assert(string(execOutput(stream)), Equals, "buffered")
+}
+
+func (r *S) TestExecBuffersOutput(c *C) {
+	stream := &captureStream{}
+	err := osExec(context.TODO(), stream, pb.CommandArgs{
+		Args:             []string{"/bin/sh", "-c", "for i in 1 2 3 4 5; do echo line$i; done"},
+		OutputBufferSize: 1024,
+	}, r.Logger)
+	c.Assert(err, IsNil)
+
+	var outputs int
+	for _, msg := range stream.messages {
+		if msg.GetExecOutput() != nil {
+			outputs++
+		}
+	}
+	// the individual lines are coalesced into a single message flushed
+	// on command completion
+	c.Assert(outputs, Equals, 1)
+	c.Assert(string(execOutput(stream)), Equals, "line1\nline2\nline3\nline4\nline5\n")
+	// the completion event is sent after the buffered output
+	last := stream.messages[len(stream.messages)-1]
+	c.Assert(last.GetExecCompleted(), NotNil)
+}
+
+func (r *S) TestExecPolicyValidatesWorkingDir(c *C) {
+	permissive := execPolicy{}
+	c.Assert(permissive.checkWorkingDir("/anywhere"), IsNil)
+
+	policy := execPolicy{allowedDirs: []string{"/opt", "/var/lib"}}
+	// an empty working directory never trips the policy
+	c.Assert(policy.checkWorkingDir(""), IsNil)
+	c.Assert(policy.checkWorkingDir("/opt"), IsNil)
+	c.Assert(policy.checkWorkingDir("/opt/bin"), IsNil)
+	c.Assert(policy.checkWorkingDir("/var/lib/gravity"), IsNil)
+	// prefixes match on path boundaries only
+	c.Assert(trace.IsAccessDenied(policy.checkWorkingDir("/optimus")), Equals, true)
+	c.Assert(trace.IsAccessDenied(policy.checkWorkingDir("/etc")), Equals, true)
+}
+
+func (r *S) TestExecPolicyValidatesCommand(c *C) {
+	permissive := execPolicy{}
+	c.Assert(permissive.checkCommand("/usr/bin/anything"), IsNil)
+
+	policy := execPolicy{allowedCommands: []string{"/bin/sh", "systemctl"}}
+	// absolute patterns match the full path only
+	c.Assert(policy.checkCommand("/bin/sh"), IsNil)
+	c.Assert(trace.IsAccessDenied(policy.checkCommand("/sbin/sh")), Equals, true)
+	c.Assert(trace.IsAccessDenied(policy.checkCommand("sh")), Equals, true)
+	// basename patterns match regardless of directory
+	c.Assert(policy.checkCommand("systemctl"), IsNil)
+	c.Assert(policy.checkCommand("/usr/bin/systemctl"), IsNil)
+	c.Assert(trace.IsAccessDenied(policy.checkCommand("/usr/bin/reboot")), Equals, true)
+
+	// the denylist wins over the allowlist
+	policy = execPolicy{
+		allowedCommands: []string{"sh", "rm"},
+		deniedCommands:  []string{"rm"},
+	}
+	c.Assert(policy.checkCommand("/bin/sh"), IsNil)
+	c.Assert(trace.IsAccessDenied(policy.checkCommand("/bin/rm")), Equals, true)
+}
+
+func (r *S) TestExecValidatesCommandArgs(c *C) {
+	cmd := &osCommand{}
+
+	// empty argument list
+	stream := &captureStream{}
+	err := cmd.exec(context.TODO(), stream, pb.CommandArgs{}, r.Logger)
+	c.Assert(trace.IsBadParameter(err), Equals, true,
+		Commentf("unexpected error: %v", err))
+	// the request is rejected before any execution takes place
+	c.Assert(stream.messages, HasLen, 0)
+
+	// whitespace-only command name
+	stream = &captureStream{}
+	err = cmd.exec(context.TODO(), stream, pb.CommandArgs{
+		Args: []string{"  \t"},
+	}, r.Logger)
+	c.Assert(trace.IsBadParameter(err), Equals, true,
+		Commentf("unexpected error: %v", err))
+	c.Assert(stream.messages, HasLen, 0)
+
+	// relative command that does not resolve in PATH
+	stream = &captureStream{}
+	err = cmd.exec(context.TODO(), stream, pb.CommandArgs{
+		Args: []string{"no-such-binary-for-sure"},
+	}, r.Logger)
+	c.Assert(trace.IsBadParameter(err), Equals, true,
+		Commentf("unexpected error: %v", err))
+	c.Assert(stream.messages, HasLen, 0)
+}
+
+func (r *S) TestExecRejectsDeniedCommand(c *C) {
+	cmd := &osCommand{policy: execPolicy{deniedCommands: []string{"reboot"}}}
+	stream := &captureStream{}
+	err := cmd.exec(context.TODO(), stream, pb.CommandArgs{
+		Args: []string{"/sbin/reboot", "now"},
+	}, r.Logger)
+	c.Assert(trace.IsAccessDenied(err), Equals, true,
+		Commentf("unexpected error: %v", err))
+	// the command is rejected before any execution takes place
+	c.Assert(stream.messages, HasLen, 0)
+}
+
+func (r *S) TestExecAllowsWhitelistedCommand(c *C) {
+	cmd := &osCommand{policy: execPolicy{allowedCommands: []string{"/bin/echo"}}}
+	stream := &captureStream{}
+	err := cmd.exec(context.TODO(), stream, pb.CommandArgs{
+		Args: []string{"/bin/echo", "allowed"},
+	}, r.Logger)
+	c.Assert(err, IsNil)
+	c.Assert(string(execOutput(stream)), Equals, "allowed\n")
+
+	stream = &captureStream{}
+	err = cmd.exec(context.TODO(), stream, pb.CommandArgs{
+		Args: []string{"/bin/cat", "/etc/hostname"},
+	}, r.Logger)
+	c.Assert(trace.IsAccessDenied(err), Equals, true,
+		Commentf("unexpected error: %v", err))
+}
+
+func (r *S) TestExecRejectsDisallowedWorkingDir(c *C) {
+	cmd := &osCommand{policy: execPolicy{allowedDirs: []string{c.MkDir()}}}
+	stream := &captureStream{}
+	err := cmd.exec(context.TODO(), stream, pb.CommandArgs{
+		Args:       []string{"/bin/pwd"},
+		WorkingDir: "/etc",
+	}, r.Logger)
+	c.Assert(trace.IsAccessDenied(err), Equals, true,
+		Commentf("unexpected error: %v", err))
+	// the command is rejected before any execution takes place
+	c.Assert(stream.messages, HasLen, 0)
+}
+
+func (r *S) TestExecAllowsWhitelistedWorkingDir(c *C) {
+	dir := c.MkDir()
+	cmd := &osCommand{policy: execPolicy{allowedDirs: []string{dir}}}
+	stream := &captureStream{}
+	err := cmd.exec(context.TODO(), stream, pb.CommandArgs{
+		Args:       []string{"/bin/pwd"},
+		WorkingDir: dir,
+	}, r.Logger)
+	c.Assert(err, IsNil)
+	c.Assert(string(execOutput(stream)), Equals, dir+"\n")
+}
+
+func (r *S) TestCommandEnv(c *C) {
+	// without explicit variables the agent's environment is inherited
+	c.Assert(commandEnv(pb.CommandArgs{}), IsNil)
+
+	// explicit variables extend the agent's environment
+	env := commandEnv(pb.CommandArgs{Env: map[string]string{"FOO": "bar"}})
+	c.Assert(len(env), Equals, len(os.Environ())+1)
+	c.Assert(env[len(env)-1], Equals, "FOO=bar")
+
+	// with ClearEnv only the explicit variables remain, in stable order
+	env = commandEnv(pb.CommandArgs{
+		ClearEnv: true,
+		Env:      map[string]string{"FOO": "bar", "BAR": "baz"},
+	})
+	c.Assert(env, DeepEquals, []string{"BAR=baz", "FOO=bar"})
+
+	// ClearEnv without variables yields an empty (non-nil) environment so
+	// the command does not fall back to inheriting the agent's
+	env = commandEnv(pb.CommandArgs{ClearEnv: true})
+	c.Assert(env, NotNil)
+	c.Assert(env, HasLen, 0)
+}
+
+func (r *S) TestExecClearsEnvironment(c *C) {
+	os.Setenv("RPC_TEST_SENTINEL", "leak")
+	defer os.Unsetenv("RPC_TEST_SENTINEL")
+
+	stream := &captureStream{}
+	err := osExec(context.TODO(), stream, pb.CommandArgs{
+		Args: []string{"/bin/sh", "-c", `echo "$FOO:$RPC_TEST_SENTINEL"`},
+		Env:  map[string]string{"FOO": "bar"},
+	}, r.Logger)
+	c.Assert(err, IsNil)
+	// by default the agent's environment leaks through
+	c.Assert(string(execOutput(stream)), Equals, "bar:leak\n")
+
+	stream = &captureStream{}
+	err = osExec(context.TODO(), stream, pb.CommandArgs{
+		Args:     []string{"/bin/sh", "-c", `echo "$FOO:$RPC_TEST_SENTINEL"`},
+		Env:      map[string]string{"FOO": "bar"},
+		ClearEnv: true,
+	}, r.Logger)
+	c.Assert(err, IsNil)
+	// with ClearEnv only the explicitly supplied variables are visible
+	c.Assert(string(execOutput(stream)), Equals, "bar:\n")
+}
+
+func (r *S) TestStreamWriterChecksumsOutput(c *C) {
+	stream := &captureStream{}
+	checksum := newOutputChecksum()
+	stdout := &streamWriter{stream: stream, fd: pb.ExecOutput_STDOUT, seq: 1, checksum: checksum}
+	stderr := &streamWriter{stream: stream, fd: pb.ExecOutput_STDERR, seq: 1, checksum: checksum}
+
+	_, err := stdout.Write([]byte("stdout output"))
+	c.Assert(err, IsNil)
+	_, err = stderr.Write([]byte("stderr output"))
+	c.Assert(err, IsNil)
+
+	completed := newCommandCompletedEvent(1, pb.CommandArgs{}, checksum, nil, 0).GetExecCompleted()
+	c.Assert(completed, NotNil)
+	c.Assert(completed.OutputBytes, Equals, int64(len("stdout output")+len("stderr output")))
+	expected := sha256.New()
+	expected.Write([]byte("stdout output"))
+	expected.Write([]byte("stderr output"))
+	c.Assert(completed.OutputDigest, DeepEquals, expected.Sum(nil))
+}
+
+func (r *S) TestIsBinaryOutput(c *C) {
+	testCases := []struct {
+		payload []byte
+		binary  bool
+		desc    string
+	}{
+		{[]byte("plain ascii output\n"), false, "ASCII text"},
+		{[]byte("unicode soupçon 日本語\n"), false, "multi-byte UTF-8 text"},
+		{[]byte("header\x00payload"), true, "NUL byte"},
+		{[]byte{0xff, 0xfe, 0x00, 0x01}, true, "invalid UTF-8"},
+		{append([]byte("chunked 日本"), []byte("語")[:1]...), false, "rune split at the chunk boundary"},
+		{[]byte{}, false, "empty chunk"},
+	}
+	for _, tc := range testCases {
+		c.Assert(isBinaryOutput(tc.payload), Equals, tc.binary,
+			Commentf("Test case %q failed", tc.desc))
+	}
+}
+
+func (r *S) TestStreamWriterClassifiesOutput(c *C) {
+	stream := &captureStream{}
+	w := &streamWriter{stream: stream, fd: pb.ExecOutput_STDOUT, seq: 1, classify: true}
+
+	_, err := w.Write([]byte("text output"))
+	c.Assert(err, IsNil)
+	_, err = w.Write([]byte{0x7f, 0x45, 0x4c, 0x46, 0x00, 0x01})
+	c.Assert(err, IsNil)
+
+	c.Assert(stream.messages, HasLen, 2)
+	c.Assert(stream.messages[0].GetExecOutput().Binary, Equals, false)
+	c.Assert(stream.messages[1].GetExecOutput().Binary, Equals, true)
+
+	// without classification requested chunks are never tagged
+	stream = &captureStream{}
+	w = &streamWriter{stream: stream, fd: pb.ExecOutput_STDOUT, seq: 1}
+	_, err = w.Write([]byte{0x7f, 0x45, 0x4c, 0x46, 0x00, 0x01})
+	c.Assert(err, IsNil)
+	c.Assert(stream.messages[0].GetExecOutput().Binary, Equals, false)
+}
+
+func (r *S) TestExecClassifiesOutput(c *C) {
+	stream := &captureStream{}
+	err := osExec(context.TODO(), stream, pb.CommandArgs{
+		Args:           []string{"/bin/sh", "-c", `printf 'binary\0output'`},
+		ClassifyOutput: true,
+	}, r.Logger)
+	c.Assert(err, IsNil)
+
+	var outputs int
+	for _, msg := range stream.messages {
+		if output := msg.GetExecOutput(); output != nil {
+			outputs++
+			c.Assert(output.Binary, Equals, true)
+		}
+	}
+	c.Assert(outputs > 0, Equals, true,
+		Commentf("expected at least one output chunk"))
+}
+
+func (r *S) TestStreamWriterCapsOutput(c *C) {
+	stream := &captureStream{}
+	limiter := newOutputLimiter(10)
+	stdout := &streamWriter{stream: stream, fd: pb.ExecOutput_STDOUT, seq: 1, limiter: limiter}
+	stderr := &streamWriter{stream: stream, fd: pb.ExecOutput_STDERR, seq: 1, limiter: limiter}
+
+	n, err := stdout.Write([]byte("1234567"))
+	c.Assert(err, IsNil)
+	c.Assert(n, Equals, 7)
+	// the write that crosses the cap is emitted up to the cap but
+	// reported complete so the command keeps running
+	n, err = stderr.Write([]byte("89abcde"))
+	c.Assert(err, IsNil)
+	c.Assert(n, Equals, 7)
+	// writes past the cap are dropped entirely
+	n, err = stdout.Write([]byte("fghij"))
+	c.Assert(err, IsNil)
+	c.Assert(n, Equals, 5)
+
+	c.Assert(string(execOutput(stream)), Equals, "123456789a")
+	completed := newCommandCompletedEvent(1, pb.CommandArgs{}, nil, limiter, 0).GetExecCompleted()
+	c.Assert(completed.TruncatedBytes, Equals, int64(9))
+}
+
+func (r *S) TestExecReportsTruncatedOutput(c *C) {
+	stream := &captureStream{}
+	cmd := &osCommand{maxOutputBytes: 16}
+	err := cmd.exec(context.TODO(), stream, pb.CommandArgs{
+		Args: []string{"/bin/sh", "-c", "echo 0123456789abcdefghijklmnopqrstuv"},
+	}, r.Logger)
+	c.Assert(err, IsNil)
+
+	c.Assert(string(execOutput(stream)), Equals, "0123456789abcdef")
+	completed := execCompleted(stream)
+	c.Assert(completed, NotNil)
+	// 32 characters and the trailing newline were produced, 16 emitted
+	c.Assert(completed.TruncatedBytes, Equals, int64(17))
+}
+
+func (r *S) TestExecLeavesTruncatedBytesUnsetWithoutTruncation(c *C) {
+	stream := &captureStream{}
+	cmd := &osCommand{maxOutputBytes: 1024}
+	err := cmd.exec(context.TODO(), stream, pb.CommandArgs{
+		Args: []string{"/bin/sh", "-c", "echo fits"},
+	}, r.Logger)
+	c.Assert(err, IsNil)
+
+	c.Assert(string(execOutput(stream)), Equals, "fits\n")
+	completed := execCompleted(stream)
+	c.Assert(completed, NotNil)
+	c.Assert(completed.TruncatedBytes, Equals, int64(0))
+}
+
+func (r *S) TestExecEmitsTruncationEventForRequestCap(c *C) {
+	stream := &captureStream{}
+	err := osExec(context.TODO(), stream, pb.CommandArgs{
+		Args:           []string{"/bin/sh", "-c", "echo 0123456789abcdefghijklmnopqrstuv"},
+		MaxOutputBytes: 16,
+	}, r.Logger)
+	c.Assert(err, IsNil)
+
+	c.Assert(string(execOutput(stream)), Equals, "0123456789abcdef")
+	truncated := execTruncated(stream)
+	c.Assert(truncated, NotNil)
+	c.Assert(truncated.Limit, Equals, int64(16))
+	// 32 characters plus the trailing newline were produced
+	c.Assert(truncated.TotalBytes, Equals, int64(33))
+	completed := execCompleted(stream)
+	c.Assert(completed, NotNil)
+	c.Assert(completed.TruncatedBytes, Equals, int64(17))
+}
+
+func (r *S) TestExecOmitsTruncationEventUnderCap(c *C) {
+	stream := &captureStream{}
+	err := osExec(context.TODO(), stream, pb.CommandArgs{
+		Args:           []string{"/bin/sh", "-c", "echo fits"},
+		MaxOutputBytes: 1024,
+	}, r.Logger)
+	c.Assert(err, IsNil)
+
+	c.Assert(string(execOutput(stream)), Equals, "fits\n")
+	c.Assert(execTruncated(stream), IsNil)
+}
+
+func (r *S) TestExecEchoesOperationSeq(c *C) {
+	cmd := &osCommand{}
+	stream := &captureStream{}
+	err := cmd.exec(context.TODO(), stream, pb.CommandArgs{
+		Args:         []string{"/bin/echo", "step output"},
+		OperationSeq: 42,
+	}, r.Logger)
+	c.Assert(err, IsNil)
+
+	started := execStarted(stream)
+	c.Assert(started, NotNil)
+	c.Assert(started.OperationSeq, Equals, int64(42))
+
+	completed := execCompleted(stream)
+	c.Assert(completed, NotNil)
+	c.Assert(completed.OperationSeq, Equals, int64(42))
+
+	// the operation sequence number is echoed on failure as well
+	stream = &captureStream{}
+	err = cmd.exec(context.TODO(), stream, pb.CommandArgs{
+		Args:         []string{"/bin/false"},
+		OperationSeq: 43,
+	}, r.Logger)
+	c.Assert(err, NotNil)
+	completed = execCompleted(stream)
+	c.Assert(completed, NotNil)
+	c.Assert(completed.ExitCode, Equals, int32(1))
+	c.Assert(completed.OperationSeq, Equals, int64(43))
+
+	// an untagged command leaves the field unset
+	stream = &captureStream{}
+	err = cmd.exec(context.TODO(), stream, pb.CommandArgs{
+		Args: []string{"/bin/echo", "untagged"},
+	}, r.Logger)
+	c.Assert(err, IsNil)
+	c.Assert(execStarted(stream).OperationSeq, Equals, int64(0))
+	c.Assert(execCompleted(stream).OperationSeq, Equals, int64(0))
+}
+
+func (r *S) TestEffectiveOutputLimit(c *C) {
+	testCases := []struct {
+		server   int64
+		request  int64
+		expected int64
+		comment  string
+	}{
+		{0, 0, 0, "unlimited by default"},
+		{1024, 0, 1024, "server cap applies without a request cap"},
+		{0, 512, 512, "request cap applies without a server cap"},
+		{1024, 512, 512, "the tighter request cap wins"},
+		{256, 512, 256, "the request cannot relax the server cap"},
+	}
+	for _, testCase := range testCases {
+		c.Assert(effectiveOutputLimit(testCase.server, testCase.request),
+			Equals, testCase.expected, Commentf(testCase.comment))
+	}
+}
+
+func (r *S) TestAnsiStripper(c *C) {
+	testCases := []struct {
+		input    string
+		expected string
+		desc     string
+	}{
+		{"plain output\n", "plain output\n", "no escapes"},
+		{"\x1b[31mred\x1b[0m\n", "red\n", "color sequences"},
+		{"\x1b[1;32;40mstyled\x1b[m text", "styled text", "multi-parameter sequence"},
+		{"\x1b[2J\x1b[H", "", "only escapes"},
+		{"\x1b]0;window title\x07prompt$ ", "prompt$ ", "OSC terminated by BEL"},
+		{"\x1b]0;window title\x1b\\prompt$ ", "prompt$ ", "OSC terminated by ST"},
+		{"\x1b7saved\x1b8", "saved", "two-character sequences"},
+		{"", "", "empty chunk"},
+	}
+	for _, tc := range testCases {
+		stripper := &ansiStripper{}
+		c.Assert(string(stripper.strip([]byte(tc.input))), Equals, tc.expected,
+			Commentf("Test case %q failed", tc.desc))
+	}
+}
+
+func (r *S) TestAnsiStripperBuffersSplitSequences(c *C) {
+	stripper := &ansiStripper{}
+	// the color sequence is split across three chunks: the tail of the
+	// first chunk holds only its beginning
+	var out []byte
+	out = append(out, stripper.strip([]byte("before \x1b"))...)
+	out = append(out, stripper.strip([]byte("[3"))...)
+	out = append(out, stripper.strip([]byte("1mred\x1b[0m after"))...)
+	c.Assert(string(out), Equals, "before red after")
+
+	// a sequence that never terminates within a reasonable length is
+	// passed through verbatim
+	stripper = &ansiStripper{}
+	long := append([]byte("\x1b["), bytes.Repeat([]byte("1;"), maxPendingSequence)...)
+	c.Assert(string(stripper.strip(long)), Equals, string(long))
+}
+
+func (r *S) TestStreamWriterStripsAnsiOutput(c *C) {
+	stream := &captureStream{}
+	w := &streamWriter{stream: stream, fd: pb.ExecOutput_STDOUT, seq: 1, stripper: &ansiStripper{}}
+
+	_, err := w.Write([]byte("\x1b[32mok\x1b"))
+	c.Assert(err, IsNil)
+	_, err = w.Write([]byte("[0m done\n"))
+	c.Assert(err, IsNil)
+
+	// writes are reported complete even when stripping leaves nothing
+	// to send for a chunk
+	c.Assert(string(execOutput(stream)), Equals, "ok done\n")
+}
+
+func (r *S) TestExecStripsAnsiOutput(c *C) {
+	stream := &captureStream{}
+	err := osExec(context.TODO(), stream, pb.CommandArgs{
+		Args:            []string{"/bin/sh", "-c", `printf '\033[31mcolored\033[0m output\n'`},
+		StripAnsiOutput: true,
+	}, r.Logger)
+	c.Assert(err, IsNil)
+	c.Assert(string(execOutput(stream)), Equals, "colored output\n")
+}
+
+func (r *S) TestExecAllocatesPTY(c *C) {
+	stream := &captureStream{}
+	err := osExec(context.TODO(), stream, pb.CommandArgs{
+		Args:        []string{"/bin/sh", "-c", "test -t 0 && echo is-a-tty || echo not-a-tty"},
+		AllocatePty: true,
+	}, r.Logger)
+	c.Assert(err, IsNil)
+	c.Assert(string(execOutput(stream)), Matches, `(?s).*is-a-tty.*`)
+
+	stream = &captureStream{}
+	err = osExec(context.TODO(), stream, pb.CommandArgs{
+		Args: []string{"/bin/sh", "-c", "test -t 0 && echo is-a-tty || echo not-a-tty"},
+	}, r.Logger)
+	c.Assert(err, IsNil)
+	c.Assert(string(execOutput(stream)), Matches, `(?s).*not-a-tty.*`)
+}
+
+func (r *S) TestExecReportsDuration(c *C) {
+	stream := &captureStream{}
+	err := osExec(context.TODO(), stream, pb.CommandArgs{
+		Args: []string{"/bin/sleep", "0.25"},
+	}, r.Logger)
+	c.Assert(err, IsNil)
+
+	completed := execCompleted(stream)
+	c.Assert(completed, NotNil)
+	// the reported duration covers at least the sleep but allows for
+	// scheduling overhead
+	c.Assert(completed.DurationMillis >= 250, Equals, true,
+		Commentf("unexpected duration: %vms", completed.DurationMillis))
+	c.Assert(completed.DurationMillis < 10000, Equals, true,
+		Commentf("unexpected duration: %vms", completed.DurationMillis))
+}
+
+func (r *S) TestExecEmitsHeartbeats(c *C) {
+	stream := &captureStream{}
+	err := osExec(context.TODO(), stream, pb.CommandArgs{
+		Args:                    []string{"/bin/sleep", "0.5"},
+		HeartbeatIntervalMillis: 50,
+	}, r.Logger)
+	c.Assert(err, IsNil)
+
+	var heartbeats int
+	for _, msg := range stream.messages {
+		if msg.GetExecHeartbeat() != nil {
+			heartbeats++
+		}
+	}
+	c.Assert(heartbeats > 0, Equals, true,
+		Commentf("expected at least one heartbeat"))
+	// heartbeats stop before the completion event is sent
+	last := stream.messages[len(stream.messages)-1]
+	c.Assert(last.GetExecCompleted(), NotNil)
+}
+
+func (r *S) TestExecSkipsHeartbeatsWhenDisabled(c *C) {
+	stream := &captureStream{}
+	err := osExec(context.TODO(), stream, pb.CommandArgs{
+		Args: []string{"/bin/sleep", "0.1"},
+	}, r.Logger)
+	c.Assert(err, IsNil)
+
+	for _, msg := range stream.messages {
+		c.Assert(msg.GetExecHeartbeat(), IsNil)
+	}
+}
+
+func (r *S) TestHeartbeatStopsImmediately(c *C) {
+	stream := &captureStream{}
+	stop := startHeartbeat(stream, 1, time.Millisecond)
+	// let at least one heartbeat through
+	time.Sleep(50 * time.Millisecond)
+	stop()
+
+	sent := len(stream.messages)
+	c.Assert(sent > 0, Equals, true,
+		Commentf("expected at least one heartbeat"))
+	// no heartbeats arrive after stop has returned
+	time.Sleep(50 * time.Millisecond)
+	c.Assert(stream.messages, HasLen, sent)
+}
+
+// execCompleted returns the completed event captured by stream
+func execCompleted(stream *captureStream) *pb.ExecCompleted {
+	for _, msg := range stream.messages {
+		if completed := msg.GetExecCompleted(); completed != nil {
+			return completed
+		}
+	}
+	return nil
+}
+
+// execStarted returns the started event captured by stream
+func execStarted(stream *captureStream) *pb.ExecStarted {
+	for _, msg := range stream.messages {
+		if started := msg.GetExecStarted(); started != nil {
+			return started
+		}
+	}
+	return nil
+}
+
+// execTruncated returns the truncation event captured by stream
+func execTruncated(stream *captureStream) *pb.ExecTruncated {
+	for _, msg := range stream.messages {
+		if truncated := msg.GetExecTruncated(); truncated != nil {
+			return truncated
+		}
+	}
+	return nil
+}
+
+func execOutput(stream *captureStream) (output []byte) {
+	for _, msg := range stream.messages {
+		if out := msg.GetExecOutput(); out != nil {
+			output = append(output, out.Data...)
+		}
+	}
+	return output
+}
+
+// captureStream records outgoing messages for test assertions.
+// It implements pb.OutgoingMessageStream
+type captureStream struct {
+	// mu guards concurrent sends from the output and heartbeat goroutines
+	mu       sync.Mutex
+	messages []*pb.Message
+}
+
+func (r *captureStream) Send(msg *pb.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, msg)
+	return nil
+}
+
+func (r *captureStream) Context() context.Context {
+	return context.TODO()
+}
+
+func (r *S) TestCommandTimeoutCappedByOperationDeadline(c *C) {
+	req := pb.CommandArgs{TimeoutMillis: int64(time.Minute / time.Millisecond)}
+
+	// without an operation deadline the command timeout is the limit
+	ctx, cancel, limited := commandContext(context.TODO(), req, time.Time{})
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	c.Assert(ok, Equals, true)
+	c.Assert(limited, Equals, false)
+	c.Assert(deadline.Sub(time.Now()) <= time.Minute, Equals, true)
+
+	// an operation deadline further out than the command timeout does not
+	// shorten it
+	ctx, cancel, limited = commandContext(context.TODO(), req, time.Now().Add(time.Hour))
+	defer cancel()
+	deadline, ok = ctx.Deadline()
+	c.Assert(ok, Equals, true)
+	c.Assert(limited, Equals, false)
+	c.Assert(deadline.Sub(time.Now()) <= time.Minute, Equals, true)
+
+	// a closer operation deadline wins over the command timeout
+	ctx, cancel, limited = commandContext(context.TODO(), req, time.Now().Add(time.Second))
+	defer cancel()
+	deadline, ok = ctx.Deadline()
+	c.Assert(ok, Equals, true)
+	c.Assert(limited, Equals, true)
+	c.Assert(deadline.Sub(time.Now()) <= time.Second, Equals, true)
+
+	// the operation deadline applies to commands without a timeout of
+	// their own
+	ctx, cancel, limited = commandContext(context.TODO(), pb.CommandArgs{}, time.Now().Add(time.Second))
+	defer cancel()
+	_, ok = ctx.Deadline()
+	c.Assert(ok, Equals, true)
+	c.Assert(limited, Equals, true)
+
+	// no limits at all leave the context untouched
+	ctx, cancel, limited = commandContext(context.TODO(), pb.CommandArgs{}, time.Time{})
+	defer cancel()
+	_, ok = ctx.Deadline()
+	c.Assert(ok, Equals, false)
+	c.Assert(limited, Equals, false)
+}
+
+func (r *S) TestExecTerminatedByOperationDeadline(c *C) {
+	req := pb.CommandArgs{
+		Args:          []string{"/bin/sh", "-c", "sleep 10"},
+		TimeoutMillis: int64(time.Minute / time.Millisecond),
+	}
+	ctx, cancel, limited := commandContext(context.TODO(), req, time.Now().Add(100*time.Millisecond))
+	defer cancel()
+	c.Assert(limited, Equals, true)
+
+	stream := &captureStream{}
+	err := osExec(ctx, stream, req, r.Logger)
+	c.Assert(err, NotNil)
+	c.Assert(ctx.Err(), Equals, context.DeadlineExceeded)
+
+	// the server reports the termination distinctly from a command timeout
+	err = operationDeadlineError(err)
+	c.Assert(IsOperationDeadlineError(err), Equals, true)
+	c.Assert(IsOperationDeadlineError(trace.LimitExceeded("command timed out")), Equals, false)
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"path/filepath"
+	"time"
+
+	pb "github.com/gravitational/gravity/lib/rpc/proto"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// commandDurations tracks the duration of executed commands by the
+	// basename of the invoked binary
+	commandDurations = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "gravity",
+		Subsystem: "agent",
+		Name:      "exec_duration_seconds",
+		Help:      "Duration of the commands executed by the agent by binary name.",
+	}, []string{"binary"})
+	// operationCommandDurations tracks the duration of executed commands
+	// by the type of the operation they are part of, so dashboards can
+	// answer how long e.g. update commands take on average
+	operationCommandDurations = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "gravity",
+		Subsystem: "agent",
+		Name:      "operation_exec_duration_seconds",
+		Help:      "Duration of the commands executed by the agent by operation type.",
+	}, []string{"operation"})
+)
+
+func init() {
+	prometheus.MustRegister(commandDurations, operationCommandDurations)
+}
+
+// observeCommandDuration records the duration of a finished command in the
+// execution metrics: always under the binary name and, if the client has
+// tagged the command with an operation type, under that type as well
+func observeCommandDuration(req pb.CommandArgs, duration time.Duration) {
+	if len(req.Args) != 0 {
+		commandDurations.WithLabelValues(filepath.Base(req.Args[0])).
+			Observe(duration.Seconds())
+	}
+	if req.OperationType != "" {
+		operationCommandDurations.WithLabelValues(req.OperationType).
+			Observe(duration.Seconds())
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"time"
+
+	pb "github.com/gravitational/gravity/lib/rpc/proto"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"golang.org/x/net/context"
+	. "gopkg.in/check.v1"
+)
+
+func (r *S) TestExecRecordsDurationMetrics(c *C) {
+	binaryBefore := histogramSampleCount(c, commandDurations, "sh")
+	operationBefore := histogramSampleCount(c, operationCommandDurations, "operation_update")
+
+	stream := &captureStream{}
+	err := osExec(context.TODO(), stream, pb.CommandArgs{
+		Args:          []string{"/bin/sh", "-c", "true"},
+		OperationType: "operation_update",
+	}, r.Logger)
+	c.Assert(err, IsNil)
+
+	c.Assert(histogramSampleCount(c, commandDurations, "sh"),
+		Equals, binaryBefore+1)
+	c.Assert(histogramSampleCount(c, operationCommandDurations, "operation_update"),
+		Equals, operationBefore+1)
+}
+
+func (r *S) TestObserveCommandDurationWithoutOperationType(c *C) {
+	binaryBefore := histogramSampleCount(c, commandDurations, "gravity")
+	operationSeriesBefore := metricCount(operationCommandDurations)
+
+	// a command not tagged with an operation type only counts towards the
+	// binary-name metric
+	observeCommandDuration(pb.CommandArgs{
+		Args: []string{"/usr/bin/gravity", "status"},
+	}, time.Second)
+
+	c.Assert(histogramSampleCount(c, commandDurations, "gravity"),
+		Equals, binaryBefore+1)
+	c.Assert(metricCount(operationCommandDurations), Equals, operationSeriesBefore)
+}
+
+// histogramSampleCount returns the number of observations the histogram
+// with the given label has recorded
+func histogramSampleCount(c *C, vec *prometheus.HistogramVec, label string) uint64 {
+	observer, err := vec.GetMetricWithLabelValues(label)
+	c.Assert(err, IsNil)
+	var metric dto.Metric
+	c.Assert(observer.(prometheus.Metric).Write(&metric), IsNil)
+	return metric.GetHistogram().GetSampleCount()
+}
+
+// metricCount returns the number of label combinations the vector is
+// tracking
+func metricCount(vec *prometheus.HistogramVec) int {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		vec.Collect(ch)
+		close(ch)
+	}()
+	var count int
+	for range ch {
+		count++
+	}
+	return count
+}
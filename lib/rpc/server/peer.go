@@ -153,6 +153,21 @@ type PeerStore interface {
 	RemovePeer(context.Context, pb.PeerLeaveRequest, Peer) error
 }
 
+// PeerLister is implemented by peer stores that can enumerate their peers
+// along with the peers' connection health
+type PeerLister interface {
+	// ListPeers returns the known peers and their health
+	ListPeers() []PeerStatus
+}
+
+// PeerStatus describes a peer and its connection health
+type PeerStatus struct {
+	// Addr is the peer's advertised address
+	Addr string
+	// Healthy indicates whether the peer is currently connected
+	Healthy bool
+}
+
 // String formats this event for logging
 func (r WatchEvent) String() string {
 	return fmt.Sprintf("watchEvent(%v, client=%v, error=%v)",
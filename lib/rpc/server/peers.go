@@ -320,6 +320,20 @@ func (r *peers) len() int {
 	return len(r.peers)
 }
 
+// listPeers returns the monitored peers along with their connection health.
+// A peer is considered healthy when its client connection is established
+func (r *peers) listPeers() (statuses []PeerStatus) {
+	r.RLock()
+	defer r.RUnlock()
+	for _, p := range r.peers {
+		statuses = append(statuses, PeerStatus{
+			Addr:    p.Addr(),
+			Healthy: p.Client != nil,
+		})
+	}
+	return statuses
+}
+
 func (r *peers) getPeers() (peers []Peer) {
 	r.RLock()
 	defer r.RUnlock()
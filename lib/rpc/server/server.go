@@ -20,6 +20,7 @@ import (
 	"context"
 	"net"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/gravitational/gravity/lib/defaults"
@@ -155,6 +156,23 @@ type Config struct {
 	// StopHandler specifies an optional handler for when the agent is stopped.
 	// completed indicates whether the agent is stopped after a successfully completed operation
 	StopHandler func(ctx context.Context, completed bool) error
+	// AllowedWorkingDirs optionally restricts the working directories
+	// remote commands may execute in to the listed path prefixes.
+	// An empty list places no restriction
+	AllowedWorkingDirs []string
+	// AllowedCommands optionally restricts the executables remote commands
+	// may invoke to the listed absolute paths or basenames.
+	// An empty list places no restriction
+	AllowedCommands []string
+	// DeniedCommands optionally lists the executables remote commands may
+	// never invoke, as absolute paths or basenames.
+	// Takes precedence over AllowedCommands
+	DeniedCommands []string
+	// MaxCommandOutputBytes optionally caps the amount of output a single
+	// remote command may stream. Output past the cap is dropped and the
+	// completion event reports the number of truncated bytes.
+	// Zero places no cap
+	MaxCommandOutputBytes int64
 	// systemInfo queries system information
 	systemInfo
 	// commandExecutor is a system command executor.
@@ -184,7 +202,14 @@ func (r *Config) CheckAndSetDefaults() error {
 	}
 
 	if r.commandExecutor == nil {
-		r.commandExecutor = execFunc(osExec)
+		r.commandExecutor = &osCommand{
+			policy: execPolicy{
+				allowedDirs:     r.AllowedWorkingDirs,
+				allowedCommands: r.AllowedCommands,
+				deniedCommands:  r.DeniedCommands,
+			},
+			maxOutputBytes: r.MaxCommandOutputBytes,
+		}
 	}
 
 	return nil
@@ -218,6 +243,30 @@ type agentServer struct {
 	grpcServer *grpc.Server
 	ctx        context.Context
 	cancel     context.CancelFunc
+	// commands tracks the commands currently executing on this agent
+	// so they can be cancelled via the CancelCommand API
+	commands commandRegistry
+	// operationMutex guards operationDeadline
+	operationMutex sync.Mutex
+	// operationDeadline is the deadline of the operation the commands
+	// execute as part of, zero if no deadline has been set
+	operationDeadline time.Time
+}
+
+// SetOperationDeadline tells the server the deadline of the operation its
+// commands execute as part of. The effective timeout of each command is
+// then derived as the smaller of the command's own timeout and the time
+// remaining until the deadline. A zero deadline removes the limit
+func (srv *agentServer) SetOperationDeadline(deadline time.Time) {
+	srv.operationMutex.Lock()
+	defer srv.operationMutex.Unlock()
+	srv.operationDeadline = deadline
+}
+
+func (srv *agentServer) getOperationDeadline() time.Time {
+	srv.operationMutex.Lock()
+	defer srv.operationMutex.Unlock()
+	return srv.operationDeadline
 }
 
 type closer interface {
@@ -0,0 +1,140 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	pb "github.com/gravitational/gravity/lib/rpc/proto"
+
+	"github.com/gravitational/trace"
+	"golang.org/x/net/context"
+)
+
+// tailPollInterval is how often the tailed file is polled for new data
+const tailPollInterval = time.Second
+
+// TailOperationLog follows the node-local operation log file and streams
+// new lines to the client until the stream's context is cancelled
+func (srv *agentServer) TailOperationLog(req *pb.TailOperationLogRequest, stream pb.Discovery_TailOperationLogServer) error {
+	err := tailFile(stream.Context(), defaults.GravityUserLogPath,
+		int(req.TrailLines), tailPollInterval, func(line string) error {
+			return stream.Send(&pb.LogLine{Text: line})
+		})
+	return trace.Wrap(err)
+}
+
+// tailFile streams lines of the file at path to send, starting with at most
+// trailLines existing trailing lines and following the file until ctx is
+// cancelled. The file is reopened if it gets rotated from under the tail
+func tailFile(ctx context.Context, path string, trailLines int, interval time.Duration, send func(line string) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer func() {
+		file.Close()
+	}()
+
+	// partial accumulates the last incomplete line until it is terminated
+	var partial []byte
+	emitLines := func(data []byte) error {
+		partial = append(partial, data...)
+		for {
+			index := bytes.IndexByte(partial, '\n')
+			if index < 0 {
+				return nil
+			}
+			line := string(partial[:index])
+			partial = partial[index+1:]
+			if err := send(line); err != nil {
+				return trace.Wrap(err)
+			}
+		}
+	}
+
+	// send the requested number of existing trailing lines
+	contents, err := ioutil.ReadAll(file)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	lines := strings.Split(string(contents), "\n")
+	partial = []byte(lines[len(lines)-1])
+	lines = lines[:len(lines)-1]
+	if len(lines) > trailLines {
+		lines = lines[len(lines)-trailLines:]
+	}
+	for _, line := range lines {
+		if err := send(line); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		data, err := ioutil.ReadAll(file)
+		if err != nil {
+			return trace.ConvertSystemError(err)
+		}
+		if err := emitLines(data); err != nil {
+			return trace.Wrap(err)
+		}
+		file, err = maybeReopen(file, path)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+}
+
+// maybeReopen reopens the tailed file if it has been rotated or truncated
+// from under the tail
+func maybeReopen(file *os.File, path string) (*os.File, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		// the file is gone, possibly mid-rotation - keep the current handle
+		return file, nil
+	}
+	current, err := file.Stat()
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	offset, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	if os.SameFile(info, current) && info.Size() >= offset {
+		return file, nil
+	}
+	reopened, err := os.Open(path)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	file.Close()
+	return reopened, nil
+}
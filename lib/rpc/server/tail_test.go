@@ -0,0 +1,81 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/net/context"
+	. "gopkg.in/check.v1"
+)
+
+func (r *S) TestTailFileFollowsFile(c *C) {
+	dir := c.MkDir()
+	path := filepath.Join(dir, "operation.log")
+	err := ioutil.WriteFile(path, []byte("line 1\nline 2\nline 3\n"), 0644)
+	c.Assert(err, IsNil)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	lines := make(chan string, 100)
+	done := make(chan error, 1)
+	go func() {
+		done <- tailFile(ctx, path, 2, 10*time.Millisecond, func(line string) error {
+			lines <- line
+			return nil
+		})
+	}()
+
+	// only the requested number of trailing lines is replayed
+	c.Assert(recvLine(c, lines), Equals, "line 2")
+	c.Assert(recvLine(c, lines), Equals, "line 3")
+
+	// new lines are picked up as they are appended
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	c.Assert(err, IsNil)
+	_, err = file.WriteString("line 4\n")
+	c.Assert(err, IsNil)
+	c.Assert(file.Close(), IsNil)
+	c.Assert(recvLine(c, lines), Equals, "line 4")
+
+	// rotate the file from under the tail
+	c.Assert(os.Rename(path, path+".1"), IsNil)
+	err = ioutil.WriteFile(path, []byte("line 5\n"), 0644)
+	c.Assert(err, IsNil)
+	c.Assert(recvLine(c, lines), Equals, "line 5")
+
+	cancel()
+	select {
+	case err := <-done:
+		c.Assert(err, IsNil)
+	case <-time.After(5 * time.Second):
+		c.Error("timeout waiting for tail to stop")
+	}
+}
+
+func recvLine(c *C, lines <-chan string) string {
+	select {
+	case line := <-lines:
+		return line
+	case <-time.After(5 * time.Second):
+		c.Fatal("timeout waiting for log line")
+	}
+	return ""
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/rpc/proto"
 	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/version"
 	"github.com/gravitational/gravity/tool/common"
 
 	"github.com/buger/goterm"
@@ -109,6 +110,50 @@ func getVersion(ctx context.Context, addr string, rpc AgentRepository) (*proto.V
 	return version, nil
 }
 
+// ClusterVersionReport describes agent version skew across the cluster nodes.
+type ClusterVersionReport struct {
+	// Expected specifies the version every agent is expected to run.
+	Expected string
+	// Nodes lists the collected agent status of every node.
+	Nodes StatusList
+}
+
+// CollectClusterVersionReport queries the agent version of every node in a
+// single pass and compares it against the expected version.
+func CollectClusterVersionReport(ctx context.Context, expected string, servers storage.Servers, rpc AgentRepository) ClusterVersionReport {
+	return ClusterVersionReport{
+		Expected: expected,
+		Nodes:    CollectAgentStatus(ctx, servers, rpc),
+	}
+}
+
+// OutOfDate returns the nodes that run an agent version different from the
+// expected one, including the nodes that failed to report a version.
+// Versions that differ only in build metadata are considered up-to-date
+func (r ClusterVersionReport) OutOfDate() StatusList {
+	var outdated StatusList
+	// fall back to literal comparison if the expected version does not parse
+	expected, _ := version.Parse(r.Expected)
+	for _, status := range r.Nodes {
+		if status.Version == r.Expected {
+			continue
+		}
+		if expected != nil {
+			actual, err := version.Parse(status.Version)
+			if err == nil && version.Compare(*actual, *expected) == 0 {
+				continue
+			}
+		}
+		outdated = append(outdated, status)
+	}
+	return outdated
+}
+
+// HasSkew returns true if at least one node runs an unexpected agent version.
+func (r ClusterVersionReport) HasSkew() bool {
+	return len(r.OutOfDate()) != 0
+}
+
 // StatusList is a list of AgentStatus.
 type StatusList []AgentStatus
 
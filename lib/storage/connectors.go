@@ -0,0 +1,43 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	teleservices "github.com/gravitational/teleport/lib/services"
+)
+
+// StripConnectorSecrets removes the secrets - client secrets and private
+// signing keys - from the provided auth connector unless withSecrets is
+// set. All connector getters go through this single helper so the
+// withSecrets behavior cannot drift between the connector types.
+// The connector is modified in place; unrecognized types are left unchanged
+func StripConnectorSecrets(connector interface{}, withSecrets bool) {
+	if withSecrets {
+		return
+	}
+	switch connector := connector.(type) {
+	case teleservices.OIDCConnector:
+		connector.SetClientSecret("")
+	case teleservices.GithubConnector:
+		connector.SetClientSecret("")
+	case teleservices.SAMLConnector:
+		if keyPair := connector.GetSigningKeyPair(); keyPair != nil {
+			keyPair.PrivateKey = ""
+			connector.SetSigningKeyPair(keyPair)
+		}
+	}
+}
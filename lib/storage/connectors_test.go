@@ -0,0 +1,76 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	teleservices "github.com/gravitational/teleport/lib/services"
+	"gopkg.in/check.v1"
+)
+
+type ConnectorsSuite struct{}
+
+var _ = check.Suite(&ConnectorsSuite{})
+
+func (s *ConnectorsSuite) TestStripConnectorSecrets(c *check.C) {
+	oidc := NewOIDCConnector("google", teleservices.OIDCConnectorSpecV2{
+		IssuerURL:    "https://accounts.google.com",
+		ClientSecret: "oidc-secret",
+	})
+	StripConnectorSecrets(oidc, false)
+	c.Assert(oidc.GetClientSecret(), check.Equals, "")
+
+	github := NewGithubConnector("github", teleservices.GithubConnectorSpecV3{
+		RedirectURL:  "https://localhost/callback",
+		ClientSecret: "github-secret",
+	})
+	StripConnectorSecrets(github, false)
+	c.Assert(github.GetClientSecret(), check.Equals, "")
+
+	saml := NewSAMLConnector("okta", teleservices.SAMLConnectorSpecV2{
+		AssertionConsumerService: "https://localhost/acs",
+		SigningKeyPair: &teleservices.SigningKeyPair{
+			PrivateKey: "signing-key",
+			Cert:       "signing-cert",
+		},
+	})
+	StripConnectorSecrets(saml, false)
+	c.Assert(saml.GetSigningKeyPair().PrivateKey, check.Equals, "")
+	// the public part of the key pair is retained
+	c.Assert(saml.GetSigningKeyPair().Cert, check.Equals, "signing-cert")
+}
+
+func (s *ConnectorsSuite) TestKeepsConnectorSecretsWhenRequested(c *check.C) {
+	oidc := NewOIDCConnector("google", teleservices.OIDCConnectorSpecV2{
+		IssuerURL:    "https://accounts.google.com",
+		ClientSecret: "oidc-secret",
+	})
+	StripConnectorSecrets(oidc, true)
+	c.Assert(oidc.GetClientSecret(), check.Equals, "oidc-secret")
+
+	saml := NewSAMLConnector("okta", teleservices.SAMLConnectorSpecV2{
+		AssertionConsumerService: "https://localhost/acs",
+		SigningKeyPair: &teleservices.SigningKeyPair{
+			PrivateKey: "signing-key",
+		},
+	})
+	StripConnectorSecrets(saml, true)
+	c.Assert(saml.GetSigningKeyPair().PrivateKey, check.Equals, "signing-key")
+
+	// a SAML connector without a signing key pair passes through unchanged
+	StripConnectorSecrets(NewSAMLConnector("bare",
+		teleservices.SAMLConnectorSpecV2{}), false)
+}
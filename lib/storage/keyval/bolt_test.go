@@ -129,6 +129,10 @@ func (s *BSuite) TestProvisioningTokensCRUD(c *C) {
 	s.suite.ProvisioningTokensCRUD(c)
 }
 
+func (s *BSuite) TestProvisioningTokenUses(c *C) {
+	s.suite.ProvisioningTokenUses(c)
+}
+
 func (s *BSuite) TestAPIKeys(c *C) {
 	s.suite.APIKeysCRUD(c)
 }
@@ -197,6 +201,10 @@ func (s *BSuite) TestPeersCRUD(c *C) {
 	s.suite.PeersCRUD(c)
 }
 
+func (s *BSuite) TestRevokedLicensesCRUD(c *C) {
+	s.suite.RevokedLicensesCRUD(c)
+}
+
 func (s *BSuite) TestObjectsCRUD(c *C) {
 	s.suite.ObjectsCRUD(c)
 }
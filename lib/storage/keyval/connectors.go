@@ -19,6 +19,8 @@ package keyval
 import (
 	"time"
 
+	"github.com/gravitational/gravity/lib/storage"
+
 	teleservices "github.com/gravitational/teleport/lib/services"
 	"github.com/gravitational/trace"
 )
@@ -54,9 +56,7 @@ func (b *backend) GetOIDCConnector(connectorID string, withSecrets bool) (telese
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
-	if !withSecrets {
-		conn.SetClientSecret("")
-	}
+	storage.StripConnectorSecrets(conn, withSecrets)
 	return conn, nil
 }
 
@@ -150,13 +150,7 @@ func (b *backend) GetSAMLConnector(id string, withSecrets bool) (teleservices.SA
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
-	if !withSecrets {
-		keyPair := conn.GetSigningKeyPair()
-		if keyPair != nil {
-			keyPair.PrivateKey = ""
-			conn.SetSigningKeyPair(keyPair)
-		}
-	}
+	storage.StripConnectorSecrets(conn, withSecrets)
 	return conn, nil
 }
 
@@ -266,9 +260,7 @@ func (b *backend) GetGithubConnector(name string, withSecrets bool) (teleservice
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
-	if !withSecrets {
-		conn.SetClientSecret("")
-	}
+	storage.StripConnectorSecrets(conn, withSecrets)
 	return conn, nil
 }
 
@@ -60,6 +60,7 @@ const (
 	nodesP                      = "nodes"
 	tunnelsP                    = "tunnels"
 	peersP                      = "peers"
+	revokedLicensesP            = "revokedlicenses"
 	objectsP                    = "objects"
 	linksP                      = "links"
 	rolesP                      = "roles"
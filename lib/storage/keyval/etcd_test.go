@@ -153,6 +153,10 @@ func (s *ESuite) TestProvisioningTokensCRUD(c *C) {
 	s.suite.ProvisioningTokensCRUD(c)
 }
 
+func (s *ESuite) TestProvisioningTokenUses(c *C) {
+	s.suite.ProvisioningTokenUses(c)
+}
+
 func (s *ESuite) TestAPIKeys(c *C) {
 	s.suite.APIKeysCRUD(c)
 }
@@ -221,6 +225,10 @@ func (s *ESuite) TestPeersCRUD(c *C) {
 	s.suite.PeersCRUD(c)
 }
 
+func (s *ESuite) TestRevokedLicensesCRUD(c *C) {
+	s.suite.RevokedLicensesCRUD(c)
+}
+
 func (s *ESuite) TestObjectsCRUD(c *C) {
 	s.suite.ObjectsCRUD(c)
 }
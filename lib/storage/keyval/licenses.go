@@ -0,0 +1,67 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/trace"
+)
+
+// UpsertRevokedLicense records the license identifier as revoked
+func (b *backend) UpsertRevokedLicense(l storage.RevokedLicense) error {
+	if err := l.Check(); err != nil {
+		return trace.Wrap(err)
+	}
+	if l.Created.IsZero() {
+		l.Created = b.Now().UTC()
+	}
+	err := b.upsertVal(b.key(revokedLicensesP, l.ID), l, forever)
+	return trace.Wrap(err)
+}
+
+// GetRevokedLicense returns the revocation record for the given identifier
+func (b *backend) GetRevokedLicense(id string) (*storage.RevokedLicense, error) {
+	var l storage.RevokedLicense
+	err := b.getVal(b.key(revokedLicensesP, id), &l)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, trace.NotFound("revoked license %q not found", id)
+		}
+		return nil, trace.Wrap(err)
+	}
+	return &l, nil
+}
+
+// GetRevokedLicenses returns all revocation records
+func (b *backend) GetRevokedLicenses() ([]storage.RevokedLicense, error) {
+	ids, err := b.getKeys(b.key(revokedLicensesP))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var out []storage.RevokedLicense
+	for _, id := range ids {
+		l, err := b.GetRevokedLicense(id)
+		if err != nil {
+			if trace.IsNotFound(err) {
+				continue
+			}
+			return nil, trace.Wrap(err)
+		}
+		out = append(out, *l)
+	}
+	return out, nil
+}
@@ -17,12 +17,18 @@ limitations under the License.
 package keyval
 
 import (
+	"encoding/json"
+
 	"github.com/gravitational/gravity/lib/storage"
 	"github.com/gravitational/gravity/lib/utils"
 
 	"github.com/gravitational/trace"
 )
 
+// tokenUsesRetries caps how many times an increment of a token's use count
+// is retried when racing with concurrent increments
+const tokenUsesRetries = 5
+
 func (b *backend) CreateProvisioningToken(t storage.ProvisioningToken) (*storage.ProvisioningToken, error) {
 	if err := t.Check(); err != nil {
 		return nil, trace.Wrap(err)
@@ -64,6 +70,49 @@ func (b *backend) GetProvisioningToken(token string) (*storage.ProvisioningToken
 	return &t, nil
 }
 
+// IncrementProvisioningTokenUses atomically increments the use count of the
+// specified token. The increment is a compare-and-swap so concurrent
+// registrations cannot exceed the token's MaxUses limit; returns
+// LimitExceeded once the limit has been reached
+func (b *backend) IncrementProvisioningTokenUses(token string) (*storage.ProvisioningToken, error) {
+	if token == "" {
+		return nil, trace.BadParameter("missing token")
+	}
+	for i := 0; i < tokenUsesRetries; i++ {
+		data, err := b.getValBytes(b.key(provisioningTokensP, token))
+		if err != nil {
+			if trace.IsNotFound(err) {
+				return nil, trace.NotFound("provisioning token(%v) not found", token)
+			}
+			return nil, trace.Wrap(err)
+		}
+		var t storage.ProvisioningToken
+		if err := json.Unmarshal(data, &t); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if t.MaxUses != 0 && t.Uses >= t.MaxUses {
+			return nil, trace.LimitExceeded(
+				"token has exceeded its limit of %v uses", t.MaxUses)
+		}
+		t.Uses++
+		newData, err := json.Marshal(t)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		var out []byte
+		err = b.compareAndSwapBytes(b.key(provisioningTokensP, token), newData, data, &out, b.ttl(t.Expires))
+		if err == nil {
+			utils.UTC(&t.Expires)
+			return &t, nil
+		}
+		if !trace.IsCompareFailed(err) {
+			return nil, trace.Wrap(err)
+		}
+		// the token was updated concurrently, retry
+	}
+	return nil, trace.CompareFailed("provisioning token(%v) is being updated concurrently, try again", token)
+}
+
 func (b *backend) GetOperationProvisioningToken(clusterName, operationID string) (*storage.ProvisioningToken, error) {
 	tokens, err := b.getKeys(b.key(provisioningTokensP))
 	if err != nil {
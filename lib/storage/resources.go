@@ -45,6 +45,8 @@ const (
 	// VerbRegister is used to allow registering new clusters
 	// within an Ops Center
 	VerbRegister = "register"
+	// VerbRevoke is used to allow revoking issued licenses
+	VerbRevoke = "revoke"
 	// VerbConnect is used to allow users to connect to clusters
 	VerbConnect = "connect"
 	// VerbReadSecrets is used to allow reading secrets
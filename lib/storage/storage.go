@@ -840,6 +840,12 @@ type ProgressEntry struct {
 	State string `json:"state"`
 	// Message is a text message describing the operation
 	Message string `json:"message"`
+	// Code optionally categorizes the operation outcome in a
+	// machine-readable way, e.g. "PreflightFailed" or "Timeout"
+	Code string `json:"code,omitempty"`
+	// FailureReason optionally records the raw cause of an operation
+	// failure without any display formatting applied to Message
+	FailureReason string `json:"failure_reason,omitempty"`
 }
 
 func (p *ProgressEntry) Check() error {
@@ -1228,6 +1234,12 @@ type ProvisioningToken struct {
 	// UserEmail links this token to the user with permissions,
 	// usually it's a site agent user
 	UserEmail string `json:"user_email"`
+	// MaxUses optionally caps how many times the token can be used
+	// to register an agent, 0 means unlimited
+	MaxUses int `json:"max_uses,omitempty"`
+	// Uses counts how many times the token has been used to register
+	// an agent, see MaxUses
+	Uses int `json:"uses,omitempty"`
 }
 
 func (p *ProvisioningToken) Check() error {
@@ -1298,6 +1310,10 @@ type Tokens interface {
 	// GetSiteProvisioningTokens returns a list of tokens for the site specified with siteDomain
 	// that have not expired yet
 	GetSiteProvisioningTokens(siteDomain string) ([]ProvisioningToken, error)
+	// IncrementProvisioningTokenUses atomically increments the use count
+	// of the specified token, refusing to exceed the token's MaxUses
+	// limit. Returns the updated token
+	IncrementProvisioningTokenUses(token string) (*ProvisioningToken, error)
 	// CreateInstallToken creates a token for a one-time install operation
 	CreateInstallToken(InstallToken) (*InstallToken, error)
 	// GetInstallToken returns an active install token with the specified ID
@@ -1372,6 +1388,36 @@ type Peers interface {
 	DeletePeer(id string) error
 }
 
+// RevokedLicense is a record of a license identifier that has been revoked
+type RevokedLicense struct {
+	// ID is the identifier the license was revoked by, e.g. its
+	// certificate serial number, cluster ID or signature
+	ID string `json:"id"`
+	// Reason optionally describes why the license was revoked
+	Reason string `json:"reason,omitempty"`
+	// Created is when the revocation was recorded
+	Created time.Time `json:"created"`
+}
+
+// Check makes sure the revocation record is valid
+func (r RevokedLicense) Check() error {
+	if r.ID == "" {
+		return trace.BadParameter("missing parameter ID")
+	}
+	return nil
+}
+
+// RevokedLicenses stores the identifiers of revoked licenses
+type RevokedLicenses interface {
+	// UpsertRevokedLicense records the license identifier as revoked
+	UpsertRevokedLicense(RevokedLicense) error
+	// GetRevokedLicense returns the revocation record for the given
+	// identifier, or NotFound if the identifier has not been revoked
+	GetRevokedLicense(id string) (*RevokedLicense, error)
+	// GetRevokedLicenses returns all revocation records
+	GetRevokedLicenses() ([]RevokedLicense, error)
+}
+
 // Objects stores binary objects metadata
 type Objects interface {
 	GetObjects() ([]string, error)
@@ -1525,6 +1571,7 @@ type Backend interface {
 	LoginEntries
 	Migrations
 	Peers
+	RevokedLicenses
 	Objects
 	PackageChangesets
 	Links
@@ -2067,6 +2114,54 @@ func (r Servers) IsEqualTo(other Servers) bool {
 	return true
 }
 
+// ServersDiff describes the difference between two lists of servers
+type ServersDiff struct {
+	// Added is the servers present only in the new list
+	Added Servers
+	// Removed is the servers present only in the old list
+	Removed Servers
+	// Common is the servers present in both lists
+	Common Servers
+}
+
+// DiffServers compares the new list of servers against the old one and
+// returns the servers that were added, removed and are present in both
+// lists. Servers are identified by the advertise IP, falling back to the
+// hostname (compared case-insensitively) when the IP is not set.
+// Common servers are returned as they appear in the new list
+func DiffServers(old, new Servers) (diff ServersDiff) {
+	oldIDs := make(map[string]struct{}, len(old))
+	for _, server := range old {
+		oldIDs[serverID(server)] = struct{}{}
+	}
+	newIDs := make(map[string]struct{}, len(new))
+	for _, server := range new {
+		newIDs[serverID(server)] = struct{}{}
+	}
+	for _, server := range new {
+		if _, exists := oldIDs[serverID(server)]; exists {
+			diff.Common = append(diff.Common, server)
+		} else {
+			diff.Added = append(diff.Added, server)
+		}
+	}
+	for _, server := range old {
+		if _, exists := newIDs[serverID(server)]; !exists {
+			diff.Removed = append(diff.Removed, server)
+		}
+	}
+	return diff
+}
+
+// serverID returns a stable identity for the provided server used when
+// comparing server lists
+func serverID(server Server) string {
+	if server.AdvertiseIP != "" {
+		return server.AdvertiseIP
+	}
+	return strings.ToLower(server.Hostname)
+}
+
 // FindByIP returns a server with the specified IP
 func (r Servers) FindByIP(ip string) *Server {
 	for _, server := range r {
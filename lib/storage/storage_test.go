@@ -92,3 +92,52 @@ func (s *StorageSuite) TestServersEquality(c *check.C) {
 			check.Commentf(tc.comment))
 	}
 }
+
+// TestServersDiff verifies the computation of the difference between two
+// server lists.
+func (s *StorageSuite) TestServersDiff(c *check.C) {
+	node1 := Server{AdvertiseIP: "192.168.1.1", Hostname: "node-1"}
+	node2 := Server{AdvertiseIP: "192.168.1.2", Hostname: "node-2"}
+	node3 := Server{AdvertiseIP: "192.168.1.3", Hostname: "node-3"}
+	testCases := []struct {
+		old     Servers
+		new     Servers
+		diff    ServersDiff
+		comment string
+	}{
+		{
+			old:     Servers{node1, node2},
+			new:     Servers{node2, node3},
+			diff:    ServersDiff{Added: Servers{node3}, Removed: Servers{node1}, Common: Servers{node2}},
+			comment: "Overlapping server lists",
+		},
+		{
+			old:     Servers{node1},
+			new:     Servers{node2, node3},
+			diff:    ServersDiff{Added: Servers{node2, node3}, Removed: Servers{node1}},
+			comment: "Disjoint server lists",
+		},
+		{
+			old:     Servers{node1, node2},
+			new:     Servers{node1, node2},
+			diff:    ServersDiff{Common: Servers{node1, node2}},
+			comment: "Equal server lists",
+		},
+		{
+			old:     Servers{{Hostname: "Node-1"}},
+			new:     Servers{{Hostname: "node-1"}},
+			diff:    ServersDiff{Common: Servers{{Hostname: "node-1"}}},
+			comment: "Hostnames are compared case-insensitively",
+		},
+		{
+			old:     nil,
+			new:     Servers{node1},
+			diff:    ServersDiff{Added: Servers{node1}},
+			comment: "Empty old list",
+		},
+	}
+	for _, tc := range testCases {
+		c.Assert(DiffServers(tc.old, tc.new), check.DeepEquals, tc.diff,
+			check.Commentf(tc.comment))
+	}
+}
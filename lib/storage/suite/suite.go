@@ -1628,6 +1628,87 @@ func (s *StorageSuite) PeersCRUD(c *C) {
 	c.Assert(len(out), Equals, 0)
 }
 
+// ProvisioningTokenUses tests the transactional token use counting
+func (s *StorageSuite) ProvisioningTokenUses(c *C) {
+	token := storage.ProvisioningToken{
+		Token:      "capped",
+		Type:       storage.ProvisioningTokenTypeExpand,
+		AccountID:  "account",
+		SiteDomain: "a.example.com",
+		MaxUses:    2,
+	}
+	_, err := s.Backend.CreateProvisioningToken(token)
+	c.Assert(err, IsNil)
+
+	out, err := s.Backend.IncrementProvisioningTokenUses(token.Token)
+	c.Assert(err, IsNil)
+	c.Assert(out.Uses, Equals, 1)
+
+	// the incremented count survives a re-read
+	stored, err := s.Backend.GetProvisioningToken(token.Token)
+	c.Assert(err, IsNil)
+	c.Assert(stored.Uses, Equals, 1)
+
+	out, err = s.Backend.IncrementProvisioningTokenUses(token.Token)
+	c.Assert(err, IsNil)
+	c.Assert(out.Uses, Equals, 2)
+
+	// the exhausted token cannot be used again
+	_, err = s.Backend.IncrementProvisioningTokenUses(token.Token)
+	c.Assert(trace.IsLimitExceeded(err), Equals, true,
+		Commentf("unexpected error: %v", err))
+
+	// a token without a use limit can be incremented indefinitely
+	unlimited := storage.ProvisioningToken{
+		Token:      "unlimited",
+		Type:       storage.ProvisioningTokenTypeExpand,
+		AccountID:  "account",
+		SiteDomain: "a.example.com",
+	}
+	_, err = s.Backend.CreateProvisioningToken(unlimited)
+	c.Assert(err, IsNil)
+	for i := 1; i <= 3; i++ {
+		out, err = s.Backend.IncrementProvisioningTokenUses(unlimited.Token)
+		c.Assert(err, IsNil)
+		c.Assert(out.Uses, Equals, i)
+	}
+
+	_, err = s.Backend.IncrementProvisioningTokenUses("no-such-token")
+	c.Assert(trace.IsNotFound(err), Equals, true,
+		Commentf("unexpected error: %v", err))
+}
+
+// RevokedLicensesCRUD tests revoked license operations
+func (s *StorageSuite) RevokedLicensesCRUD(c *C) {
+	out, err := s.Backend.GetRevokedLicenses()
+	c.Assert(err, IsNil)
+	c.Assert(len(out), Equals, 0)
+
+	revoked := storage.RevokedLicense{
+		ID:      "serial-1",
+		Reason:  "compromised",
+		Created: s.Clock.Now().UTC(),
+	}
+	err = s.Backend.UpsertRevokedLicense(revoked)
+	c.Assert(err, IsNil)
+
+	record, err := s.Backend.GetRevokedLicense(revoked.ID)
+	c.Assert(err, IsNil)
+	c.Assert(*record, DeepEquals, revoked)
+
+	out, err = s.Backend.GetRevokedLicenses()
+	c.Assert(err, IsNil)
+	c.Assert(out, DeepEquals, []storage.RevokedLicense{revoked})
+
+	_, err = s.Backend.GetRevokedLicense("serial-2")
+	c.Assert(trace.IsNotFound(err), Equals, true,
+		Commentf("unexpected error: %v", err))
+
+	err = s.Backend.UpsertRevokedLicense(storage.RevokedLicense{})
+	c.Assert(trace.IsBadParameter(err), Equals, true,
+		Commentf("unexpected error: %v", err))
+}
+
 // ObjectsCRUD tests objects peers operations
 func (s *StorageSuite) ObjectsCRUD(c *C) {
 	out, err := s.Backend.GetObjects()
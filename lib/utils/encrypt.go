@@ -48,7 +48,15 @@ func EncryptPGP(data io.Reader, passphrase string) (io.ReadCloser, error) {
 
 // DecryptPGP returns a stream with "data" decrypted by the provided passphrase
 func DecryptPGP(data io.Reader, passphrase string) (io.Reader, error) {
+	// openpgp keeps re-invoking the prompt after a failed attempt, so a
+	// wrong passphrase must be reported as an error or the read would
+	// loop forever
+	var attempted bool
 	promptFn := func(_ []openpgp.Key, _ bool) ([]byte, error) {
+		if attempted {
+			return nil, trace.AccessDenied("invalid passphrase")
+		}
+		attempted = true
 		return []byte(passphrase), nil
 	}
 
@@ -0,0 +1,130 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"sort"
+
+	semver "github.com/Masterminds/semver/v3"
+	"github.com/gravitational/trace"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+)
+
+// Dependency describes a single chart in the dependency closure of a chart
+type Dependency struct {
+	// Name is the subchart name
+	Name string
+	// Version is the version of the bundled subchart
+	Version string
+}
+
+// ResolveChartDependencies loads the chart at chartPath and returns its
+// dependency closure as a flattened list sorted by name, with subcharts
+// required by multiple charts de-duplicated. Returns an error if the same
+// subchart is bundled at conflicting versions in different parts of the
+// closure, or if a chart declares a version constraint the bundled
+// subchart does not satisfy.
+func ResolveChartDependencies(chartPath string) ([]Dependency, error) {
+	chart, err := loader.Load(chartPath)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	resolved := map[string]Dependency{}
+	if err := resolveChartDependencies(chart, resolved); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	dependencies := make([]Dependency, 0, len(resolved))
+	for _, dependency := range resolved {
+		dependencies = append(dependencies, dependency)
+	}
+	sort.Slice(dependencies, func(i, j int) bool {
+		return dependencies[i].Name < dependencies[j].Name
+	})
+	return dependencies, nil
+}
+
+func resolveChartDependencies(c *chart.Chart, resolved map[string]Dependency) error {
+	// validate the declared constraints against the bundled subcharts first
+	for _, declared := range c.Metadata.Dependencies {
+		subchart := findSubchart(c, declared.Name)
+		if subchart == nil {
+			return trace.NotFound(
+				"chart %q declares dependency %q which is not bundled in its charts directory",
+				c.Name(), declared.Name)
+		}
+		if err := checkVersionConstraint(c.Name(), declared, subchart.Metadata.Version); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	for _, subchart := range c.Dependencies() {
+		dependency := Dependency{
+			Name:    subchart.Name(),
+			Version: subchart.Metadata.Version,
+		}
+		if existing, ok := resolved[dependency.Name]; ok {
+			if existing.Version != dependency.Version {
+				return trace.BadParameter(
+					"dependency conflict: subchart %q is required at both version %v and version %v",
+					dependency.Name, existing.Version, dependency.Version)
+			}
+			continue
+		}
+		resolved[dependency.Name] = dependency
+		if err := resolveChartDependencies(subchart, resolved); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// findSubchart returns the subchart bundled in the chart's charts directory
+// with the specified name, or nil if there is none
+func findSubchart(c *chart.Chart, name string) *chart.Chart {
+	for _, subchart := range c.Dependencies() {
+		if subchart.Name() == name {
+			return subchart
+		}
+	}
+	return nil
+}
+
+// checkVersionConstraint validates the bundled subchart version against the
+// version constraint the parent chart declares for it. An empty constraint
+// accepts any version
+func checkVersionConstraint(parent string, declared *chart.Dependency, version string) error {
+	if declared.Version == "" {
+		return nil
+	}
+	constraint, err := semver.NewConstraint(declared.Version)
+	if err != nil {
+		return trace.BadParameter(
+			"chart %q declares an invalid version constraint %q for dependency %q: %v",
+			parent, declared.Version, declared.Name, err)
+	}
+	bundled, err := semver.NewVersion(version)
+	if err != nil {
+		return trace.BadParameter(
+			"subchart %q has an invalid version %q: %v", declared.Name, version, err)
+	}
+	if !constraint.Check(bundled) {
+		return trace.BadParameter(
+			"chart %q requires dependency %q version %q but version %v is bundled",
+			parent, declared.Name, declared.Version, version)
+	}
+	return nil
+}
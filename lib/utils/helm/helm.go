@@ -18,8 +18,11 @@ package helm
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
+	"time"
 
+	"github.com/coreos/go-semver/semver"
 	"github.com/gravitational/trace"
 	"helm.sh/helm/v3/pkg/repo"
 )
@@ -31,25 +34,47 @@ func HasVar(name string, valueFiles valueFiles, values []string) (bool, error) {
 	if err != nil {
 		return false, trace.Wrap(err)
 	}
+	// strip the keys unset with an explicit null
+	allVals = DeepMergeValues(map[string]interface{}{}, allVals)
 	return hasVar(strings.Split(name, "."), allVals), nil
 }
 
 func hasVar(name []string, vals map[string]interface{}) bool {
+	_, ok := getVar(name, vals)
+	return ok
+}
+
+// GetVar takes a slice of values and value files and returns the value of
+// the variable with the provided name after all layers have been merged
+// with DeepMergeValues. Returns ok=false if the variable is not set, e.g.
+// when a later layer has unset it with an explicit null.
+func GetVar(name string, valueFiles valueFiles, values []string) (value interface{}, ok bool, err error) {
+	allVals, err := merge(valueFiles, values, nil, nil, "", "", "")
+	if err != nil {
+		return nil, false, trace.Wrap(err)
+	}
+	// strip the keys unset with an explicit null
+	allVals = DeepMergeValues(map[string]interface{}{}, allVals)
+	value, ok = getVar(strings.Split(name, "."), allVals)
+	return value, ok, nil
+}
+
+func getVar(name []string, vals map[string]interface{}) (interface{}, bool) {
 	if len(name) == 0 {
-		return true
+		return nil, false
 	}
 	v, ok := vals[name[0]]
 	if !ok {
-		return false
+		return nil, false
 	}
 	if len(name) == 1 {
-		return true
+		return v, true
 	}
 	m, ok := v.(map[string]interface{})
 	if !ok {
-		return false
+		return nil, false
 	}
-	return hasVar(name[1:], m)
+	return getVar(name[1:], m)
 }
 
 // ParseChartFilename returns chart name and version from the provided chart
@@ -62,11 +87,54 @@ func ParseChartFilename(filename string) (name, version string, err error) {
 	return strings.Join(parts[:len(parts)-1], "-"), parts[len(parts)-1], nil
 }
 
-// ToChartFilename returns a chart archive filename for the provided name/version.
-func ToChartFilename(name, version string) string {
-	return fmt.Sprintf("%v-%v.tgz", name, version)
+// ValidateChartName checks that the provided chart name is safe to use
+// in filenames and URLs.
+func ValidateChartName(name string) error {
+	if !chartNameRe.MatchString(name) {
+		return trace.BadParameter("invalid chart name %q, expected an "+
+			"alphanumeric name that may contain dashes, dots or underscores",
+			name)
+	}
+	return nil
 }
 
+// ValidateChartVersion checks that the provided chart version is a valid
+// semantic version.
+func ValidateChartVersion(version string) error {
+	if _, err := semver.NewVersion(version); err != nil {
+		return trace.BadParameter("invalid chart version %q: %v", version, err)
+	}
+	return nil
+}
+
+// ToChartFilename returns a chart archive filename for the provided
+// name/version. Returns an error if either part would produce a malformed
+// filename.
+func ToChartFilename(name, version string) (string, error) {
+	if err := ValidateChartName(name); err != nil {
+		return "", trace.Wrap(err)
+	}
+	if err := ValidateChartVersion(version); err != nil {
+		return "", trace.Wrap(err)
+	}
+	return fmt.Sprintf("%v-%v.tgz", name, version), nil
+}
+
+// MustToChartFilename is like ToChartFilename but panics on invalid inputs.
+// It is reserved for internal callers that have pre-validated the chart
+// name/version.
+func MustToChartFilename(name, version string) string {
+	filename, err := ToChartFilename(name, version)
+	if err != nil {
+		panic(err)
+	}
+	return filename
+}
+
+// chartNameRe restricts chart names to characters that are safe on disk
+// and in URLs
+var chartNameRe = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9._-]*[a-zA-Z0-9])?$`)
+
 // CopyIndexFile returns a deep copy of the provided index file.
 func CopyIndexFile(indexFile repo.IndexFile) *repo.IndexFile {
 	newIndex := &repo.IndexFile{
@@ -84,3 +152,23 @@ func CopyIndexFile(indexFile repo.IndexFile) *repo.IndexFile {
 	}
 	return newIndex
 }
+
+// IndexAge returns the age of the provided index file based on its
+// generation timestamp. Returns 0 if the timestamp is not set so the age
+// is unknown.
+func IndexAge(indexFile repo.IndexFile) time.Duration {
+	if indexFile.Generated.IsZero() {
+		return 0
+	}
+	return time.Since(indexFile.Generated)
+}
+
+// IsIndexStale returns true if the provided index file is older than
+// maxAge. An index without a generation timestamp is considered stale
+// since its freshness cannot be established.
+func IsIndexStale(indexFile repo.IndexFile, maxAge time.Duration) bool {
+	if indexFile.Generated.IsZero() {
+		return true
+	}
+	return IndexAge(indexFile) > maxAge
+}
@@ -20,6 +20,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/ghodss/yaml"
@@ -64,7 +66,7 @@ func merge(valueFiles valueFiles, values []string, stringValues []string, fileVa
 			return nil, trace.Wrap(err, "failed to parse %s", filePath)
 		}
 		// Merge with the previous map
-		base = mergeValues(base, currentMap)
+		base = DeepMergeValues(base, currentMap)
 	}
 
 	// User specified a value via --set
@@ -95,39 +97,259 @@ func merge(valueFiles valueFiles, values []string, stringValues []string, fileVa
 	return base, nil
 }
 
-// mergeValues merges source and destination map, preferring values from the
-// source map.
-//
-// This function was copied from Helm:
-//
-// https://github.com/helm/helm/blob/v2.12.0/cmd/helm/install.go#L335
-func mergeValues(dest map[string]interface{}, src map[string]interface{}) map[string]interface{} {
+// MergePolicy controls how MergeValues resolves keys set in multiple layers
+type MergePolicy string
+
+const (
+	// MergePolicyLastWins keeps the value from the last layer that sets
+	// the key. This is the regular helm behavior
+	MergePolicyLastWins MergePolicy = "last-wins"
+	// MergePolicyFirstWins keeps the value from the first layer that sets
+	// the key
+	MergePolicyFirstWins MergePolicy = "first-wins"
+	// MergePolicyErrorOnConflict fails when several layers set the same key
+	MergePolicyErrorOnConflict MergePolicy = "error-on-conflict"
+)
+
+// MergeValues merges values from the provided value files and --set style
+// overrides according to the specified conflict policy. Each value file
+// forms a layer, with the string values forming the final layer. For
+// MergePolicyErrorOnConflict the conflicting keys are returned alongside
+// the error
+func MergeValues(files valueFiles, values []string, policy MergePolicy) (vals map[string]interface{}, conflicts []string, err error) {
+	switch policy {
+	case MergePolicyLastWins, MergePolicyFirstWins, MergePolicyErrorOnConflict:
+	default:
+		return nil, nil, trace.BadParameter("unsupported merge policy %q", policy)
+	}
+	layers, err := parseLayers(files, values)
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	vals = map[string]interface{}{}
+	for _, layer := range layers {
+		if policy == MergePolicyLastWins {
+			vals = DeepMergeValues(vals, layer)
+			continue
+		}
+		conflicts = append(conflicts, mergeWithPolicy(vals, layer, policy, "")...)
+	}
+	if len(conflicts) != 0 {
+		sort.Strings(conflicts)
+		return nil, conflicts, trace.BadParameter("conflicting value keys: %v",
+			strings.Join(conflicts, ", "))
+	}
+	return vals, nil, nil
+}
+
+// mergeWithPolicy merges the source layer into the destination map keeping
+// the first seen value on conflicts. For MergePolicyErrorOnConflict the
+// conflicting key paths are collected and returned
+func mergeWithPolicy(dest, src map[string]interface{}, policy MergePolicy, prefix string) (conflicts []string) {
 	for k, v := range src {
-		// If the key doesn't exist already, then just set the key to that value
-		if _, exists := dest[k]; !exists {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		existing, exists := dest[k]
+		if !exists {
 			dest[k] = v
 			continue
 		}
-		nextMap, ok := v.(map[string]interface{})
-		// If it isn't another map, overwrite the value
+		srcMap, srcIsMap := v.(map[string]interface{})
+		destMap, destIsMap := existing.(map[string]interface{})
+		if srcIsMap && destIsMap {
+			conflicts = append(conflicts, mergeWithPolicy(destMap, srcMap, policy, path)...)
+			continue
+		}
+		if policy == MergePolicyErrorOnConflict {
+			conflicts = append(conflicts, path)
+		}
+		// otherwise the first seen value wins
+	}
+	return conflicts
+}
+
+// parseLayers parses each value file and the string values into separate
+// value maps preserving the layer order
+func parseLayers(files valueFiles, values []string) (layers []map[string]interface{}, err error) {
+	for _, filePath := range files {
+		bytes, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		layer := map[string]interface{}{}
+		if err := yaml.Unmarshal(bytes, &layer); err != nil {
+			return nil, trace.Wrap(err, "failed to parse %s", filePath)
+		}
+		layers = append(layers, layer)
+	}
+	if len(values) != 0 {
+		layer := map[string]interface{}{}
+		for _, value := range values {
+			if err := strvals.ParseInto(value, layer); err != nil {
+				return nil, trace.Wrap(err, "failed parsing --set data")
+			}
+		}
+		layers = append(layers, layer)
+	}
+	return layers, nil
+}
+
+// ValueChange describes a single difference between two merged value sets
+type ValueChange struct {
+	// Key is the dotted path of the value
+	Key string
+	// Old is the value on the old side, nil if the key was added
+	Old interface{}
+	// New is the value on the new side, nil if the key was removed
+	New interface{}
+}
+
+// DiffValues merges the old and the new value layers separately and returns
+// the keys that were added, removed or changed between the two sides, sorted
+// by the dotted key path. Keys unset with an explicit null on either side
+// count as not set
+func DiffValues(oldFiles valueFiles, oldValues []string, newFiles valueFiles, newValues []string) ([]ValueChange, error) {
+	oldVals, err := merge(oldFiles, oldValues, nil, nil, "", "", "")
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	newVals, err := merge(newFiles, newValues, nil, nil, "", "", "")
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	// strip the keys unset with an explicit null
+	oldFlat := flatten(DeepMergeValues(map[string]interface{}{}, oldVals), "")
+	newFlat := flatten(DeepMergeValues(map[string]interface{}{}, newVals), "")
+	var changes []ValueChange
+	for key, oldValue := range oldFlat {
+		newValue, ok := newFlat[key]
 		if !ok {
-			dest[k] = v
+			changes = append(changes, ValueChange{Key: key, Old: oldValue})
 			continue
 		}
-		// If the key doesn't exist already, then just set the key to that value
-		if _, exists := dest[k]; !exists {
-			dest[k] = nextMap
+		if !reflect.DeepEqual(oldValue, newValue) {
+			changes = append(changes, ValueChange{Key: key, Old: oldValue, New: newValue})
+		}
+	}
+	for key, newValue := range newFlat {
+		if _, ok := oldFlat[key]; !ok {
+			changes = append(changes, ValueChange{Key: key, New: newValue})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes, nil
+}
+
+// flatten converts a nested value map into a map of dotted key paths to
+// their leaf values. Slices are not descended into and compare as a whole
+func flatten(vals map[string]interface{}, prefix string) map[string]interface{} {
+	flat := make(map[string]interface{})
+	for k, v := range vals {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		if m, ok := v.(map[string]interface{}); ok {
+			for nestedPath, nestedValue := range flatten(m, path) {
+				flat[nestedPath] = nestedValue
+			}
+			continue
+		}
+		flat[path] = v
+	}
+	return flat
+}
+
+// RedactedPlaceholder replaces the values of sensitive keys in the output
+// of RedactValues
+const RedactedPlaceholder = "<redacted>"
+
+// RedactValues returns a copy of the provided value map with the values of
+// the matching keys replaced by a placeholder so the result is safe to log.
+// Keys are specified as dotted paths, like in hasVar, and may use a "*"
+// wildcard for individual path segments, e.g. "*.password" matches the
+// password key in any top-level section. Redacting a key whose value is
+// a map replaces the whole subtree
+func RedactValues(vals map[string]interface{}, sensitiveKeys []string) map[string]interface{} {
+	return redactValues(vals, "", sensitiveKeys)
+}
+
+func redactValues(vals map[string]interface{}, prefix string, sensitiveKeys []string) map[string]interface{} {
+	result := make(map[string]interface{}, len(vals))
+	for k, v := range vals {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		if matchesSensitiveKey(path, sensitiveKeys) {
+			result[k] = RedactedPlaceholder
+			continue
+		}
+		if m, ok := v.(map[string]interface{}); ok {
+			result[k] = redactValues(m, path, sensitiveKeys)
 			continue
 		}
-		// Edge case: If the key exists in the destination, but isn't a map
-		destMap, isMap := dest[k].(map[string]interface{})
-		// If the source map has a map for this key, prefer it
-		if !isMap {
+		result[k] = v
+	}
+	return result
+}
+
+// matchesSensitiveKey determines whether the dotted key path matches any
+// of the patterns. A "*" pattern segment matches exactly one path segment
+func matchesSensitiveKey(path string, patterns []string) bool {
+	parts := strings.Split(path, ".")
+	for _, pattern := range patterns {
+		if matchKeyPattern(strings.Split(pattern, "."), parts) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchKeyPattern(pattern, path []string) bool {
+	if len(pattern) != len(path) {
+		return false
+	}
+	for i, segment := range pattern {
+		if segment != "*" && segment != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// DeepMergeValues recursively merges the source map into the destination map,
+// preferring values from the source map. Nested maps are merged key by key
+// and an explicit null value in the source deletes the corresponding key from
+// the destination, matching the helm upgrade convention.
+//
+// This function follows the Helm 3 merge semantics:
+//
+// https://github.com/helm/helm/blob/v3.4.2/pkg/cli/values/options.go#L88
+func DeepMergeValues(dest map[string]interface{}, src map[string]interface{}) map[string]interface{} {
+	for k, v := range src {
+		// An explicit null removes the key from the base
+		if v == nil {
+			delete(dest, k)
+			continue
+		}
+		srcMap, ok := v.(map[string]interface{})
+		// If it isn't another map, overwrite the value
+		if !ok {
 			dest[k] = v
 			continue
 		}
-		// If we got to this point, it is a map in both, so merge them
-		dest[k] = mergeValues(destMap, nextMap)
+		destMap, ok := dest[k].(map[string]interface{})
+		// If the destination value isn't a map, the source map wins.
+		// Merge into a fresh map so nulls unset keys at any depth
+		if !ok {
+			destMap = map[string]interface{}{}
+		}
+		dest[k] = DeepMergeValues(destMap, srcMap)
 	}
 	return dest
 }
@@ -18,11 +18,16 @@ package helm
 
 import (
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/gravitational/gravity/lib/defaults"
+
+	"github.com/gravitational/trace"
 	check "gopkg.in/check.v1"
+	"helm.sh/helm/v3/pkg/repo"
 )
 
 func TestHelm(t *testing.T) { check.TestingT(t) }
@@ -40,6 +45,8 @@ func (s *helmUtilsSuite) SetUpSuite(c *check.C) {
 		valuesFile1, defaults.SharedReadMask), check.IsNil)
 	c.Assert(ioutil.WriteFile(filepath.Join(s.dir, "values2.yaml"),
 		valuesFile2, defaults.SharedReadMask), check.IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(s.dir, "values3.yaml"),
+		valuesFile3, defaults.SharedReadMask), check.IsNil)
 }
 
 func (s *helmUtilsSuite) TestHasVar(c *check.C) {
@@ -88,25 +95,336 @@ func (s *helmUtilsSuite) TestHasVar(c *check.C) {
 	}
 }
 
+func (s *helmUtilsSuite) TestDeepMergeValues(c *check.C) {
+	base := map[string]interface{}{
+		"image": map[string]interface{}{
+			"registry": "registry.private:5000",
+			"tag":      "1.0.0",
+		},
+		"replicas": 2,
+	}
+	override := map[string]interface{}{
+		"image": map[string]interface{}{
+			"registry": nil,
+			"tag":      "2.0.0",
+		},
+	}
+	merged := DeepMergeValues(base, override)
+	c.Assert(merged, check.DeepEquals, map[string]interface{}{
+		"image": map[string]interface{}{
+			// null removed the registry key, tag was overridden
+			"tag": "2.0.0",
+		},
+		"replicas": 2,
+	})
+
+	// a scalar override replaces a nested map wholesale
+	merged = DeepMergeValues(merged, map[string]interface{}{
+		"image": "alpine:3.0.0",
+	})
+	c.Assert(merged["image"], check.Equals, "alpine:3.0.0")
+
+	// a top-level null removes the whole subtree
+	merged = DeepMergeValues(merged, map[string]interface{}{
+		"replicas": nil,
+	})
+	_, ok := merged["replicas"]
+	c.Assert(ok, check.Equals, false)
+}
+
+func (s *helmUtilsSuite) TestRedactValues(c *check.C) {
+	vals := map[string]interface{}{
+		"db": map[string]interface{}{
+			"host":     "db.local",
+			"password": "secret1",
+		},
+		"smtp": map[string]interface{}{
+			"password": "secret2",
+		},
+		"license": "key-data",
+		"registry": map[string]interface{}{
+			"auth": map[string]interface{}{
+				"token": "secret3",
+			},
+		},
+	}
+	redacted := RedactValues(vals, []string{
+		"*.password", "license", "registry.auth",
+	})
+	c.Assert(redacted, check.DeepEquals, map[string]interface{}{
+		"db": map[string]interface{}{
+			"host":     "db.local",
+			"password": RedactedPlaceholder,
+		},
+		"smtp": map[string]interface{}{
+			"password": RedactedPlaceholder,
+		},
+		"license": RedactedPlaceholder,
+		// redacting a key with a map value replaces the whole subtree
+		"registry": map[string]interface{}{
+			"auth": RedactedPlaceholder,
+		},
+	})
+
+	// the input is not modified
+	c.Assert(vals["license"], check.Equals, "key-data")
+	c.Assert(vals["db"].(map[string]interface{})["password"],
+		check.Equals, "secret1")
+
+	// a wildcard matches exactly one path segment
+	redacted = RedactValues(map[string]interface{}{
+		"a": map[string]interface{}{
+			"b": map[string]interface{}{
+				"password": "secret",
+			},
+		},
+	}, []string{"*.password"})
+	c.Assert(redacted, check.DeepEquals, map[string]interface{}{
+		"a": map[string]interface{}{
+			"b": map[string]interface{}{
+				"password": "secret",
+			},
+		},
+	})
+}
+
+func (s *helmUtilsSuite) TestResolveChartDependencies(c *check.C) {
+	// parent depends on childa and childb, each of which bundles the
+	// common subchart at the same version
+	dir := c.MkDir()
+	writeChartMetadata(c, dir, `apiVersion: v2
+name: parent
+version: 0.1.0
+dependencies:
+- name: childa
+  version: ">=1.0.0"
+- name: childb
+  version: 1.1.0
+`)
+	writeChartMetadata(c, filepath.Join(dir, "charts", "childa"), `apiVersion: v2
+name: childa
+version: 1.0.0
+`)
+	writeChartMetadata(c, filepath.Join(dir, "charts", "childb"), `apiVersion: v2
+name: childb
+version: 1.1.0
+`)
+	writeChartMetadata(c, filepath.Join(dir, "charts", "childa", "charts", "common"), `apiVersion: v2
+name: common
+version: 2.0.0
+`)
+	writeChartMetadata(c, filepath.Join(dir, "charts", "childb", "charts", "common"), `apiVersion: v2
+name: common
+version: 2.0.0
+`)
+
+	dependencies, err := ResolveChartDependencies(dir)
+	c.Assert(err, check.IsNil)
+	c.Assert(dependencies, check.DeepEquals, []Dependency{
+		{Name: "childa", Version: "1.0.0"},
+		{Name: "childb", Version: "1.1.0"},
+		{Name: "common", Version: "2.0.0"},
+	})
+
+	// bundling common at different versions is a conflict
+	writeChartMetadata(c, filepath.Join(dir, "charts", "childb", "charts", "common"), `apiVersion: v2
+name: common
+version: 2.1.0
+`)
+	_, err = ResolveChartDependencies(dir)
+	c.Assert(trace.IsBadParameter(err), check.Equals, true,
+		check.Commentf("unexpected error: %v", err))
+	c.Assert(err, check.ErrorMatches, `(?s).*dependency conflict.*"common".*`)
+
+	// a declared constraint the bundled subchart does not satisfy
+	dir = c.MkDir()
+	writeChartMetadata(c, dir, `apiVersion: v2
+name: parent
+version: 0.1.0
+dependencies:
+- name: child
+  version: ">=2.0.0"
+`)
+	writeChartMetadata(c, filepath.Join(dir, "charts", "child"), `apiVersion: v2
+name: child
+version: 1.0.0
+`)
+	_, err = ResolveChartDependencies(dir)
+	c.Assert(trace.IsBadParameter(err), check.Equals, true,
+		check.Commentf("unexpected error: %v", err))
+	c.Assert(err, check.ErrorMatches, `(?s).*requires dependency "child" version ">=2.0.0".*`)
+
+	// a declared dependency that is not bundled at all
+	dir = c.MkDir()
+	writeChartMetadata(c, dir, `apiVersion: v2
+name: parent
+version: 0.1.0
+dependencies:
+- name: missing
+  version: 1.0.0
+`)
+	_, err = ResolveChartDependencies(dir)
+	c.Assert(trace.IsNotFound(err), check.Equals, true,
+		check.Commentf("unexpected error: %v", err))
+}
+
+// writeChartMetadata writes a Chart.yaml with the provided contents for the
+// chart rooted at dir
+func writeChartMetadata(c *check.C, dir, metadata string) {
+	c.Assert(os.MkdirAll(dir, defaults.SharedDirMask), check.IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(dir, "Chart.yaml"),
+		[]byte(metadata), defaults.SharedReadMask), check.IsNil)
+}
+
+func (s *helmUtilsSuite) TestGetVar(c *check.C) {
+	valueFiles := []string{
+		filepath.Join(s.dir, "values1.yaml"),
+		filepath.Join(s.dir, "values2.yaml"),
+	}
+
+	value, ok, err := GetVar("image.registry", valueFiles, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(ok, check.Equals, true)
+	c.Assert(value, check.Equals, "registry.private:5000")
+
+	// an override from a later layer wins
+	value, ok, err = GetVar("image.registry", valueFiles,
+		[]string{"image.registry=localhost:5000"})
+	c.Assert(err, check.IsNil)
+	c.Assert(ok, check.Equals, true)
+	c.Assert(value, check.Equals, "localhost:5000")
+
+	// an explicit null unsets the variable
+	_, ok, err = GetVar("image.registry", valueFiles,
+		[]string{"image.registry=null"})
+	c.Assert(err, check.IsNil)
+	c.Assert(ok, check.Equals, false)
+
+	ok, err = HasVar("image.registry", valueFiles,
+		[]string{"image.registry=null"})
+	c.Assert(err, check.IsNil)
+	c.Assert(ok, check.Equals, false)
+}
+
+func (s *helmUtilsSuite) TestDiffValues(c *check.C) {
+	dir := c.MkDir()
+	oldPath := filepath.Join(dir, "old.yaml")
+	newPath := filepath.Join(dir, "new.yaml")
+	c.Assert(ioutil.WriteFile(oldPath, oldValuesFile,
+		defaults.SharedReadMask), check.IsNil)
+	c.Assert(ioutil.WriteFile(newPath, newValuesFile,
+		defaults.SharedReadMask), check.IsNil)
+
+	changes, err := DiffValues(valueFiles{oldPath}, nil, valueFiles{newPath}, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(changes, check.DeepEquals, []ValueChange{
+		{Key: "env.mode", New: "prod"},
+		{Key: "image.registry", Old: "registry.private:5000", New: "registry.other:5000"},
+		{Key: "ports", Old: []interface{}{float64(80), float64(443)},
+			New: []interface{}{float64(80), float64(8443)}},
+		{Key: "replicas", Old: float64(2)},
+	})
+
+	// identical sides produce no changes
+	changes, err = DiffValues(valueFiles{oldPath}, nil, valueFiles{oldPath}, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(changes, check.HasLen, 0)
+
+	// string values form the final layer on each side and an explicit
+	// null counts as a removal
+	changes, err = DiffValues(valueFiles{oldPath}, nil, valueFiles{oldPath},
+		[]string{"image.tag=2.0.0", "replicas=null"})
+	c.Assert(err, check.IsNil)
+	c.Assert(changes, check.DeepEquals, []ValueChange{
+		{Key: "image.tag", Old: "1.0.0", New: "2.0.0"},
+		{Key: "replicas", Old: float64(2)},
+	})
+}
+
 func (s *helmUtilsSuite) TestChartFilename(c *check.C) {
-	filename := ToChartFilename("alpine", "0.1.0")
+	filename, err := ToChartFilename("alpine", "0.1.0")
+	c.Assert(err, check.IsNil)
 	c.Assert(filename, check.Equals, "alpine-0.1.0.tgz")
 	name, version, err := ParseChartFilename(filename)
 	c.Assert(err, check.IsNil)
 	c.Assert(name, check.Equals, "alpine")
 	c.Assert(version, check.Equals, "0.1.0")
 
-	filename = ToChartFilename("nginx-ingress", "1.0.0")
+	filename, err = ToChartFilename("nginx-ingress", "1.0.0")
+	c.Assert(err, check.IsNil)
 	c.Assert(filename, check.Equals, "nginx-ingress-1.0.0.tgz")
 	name, version, err = ParseChartFilename(filename)
 	c.Assert(err, check.IsNil)
 	c.Assert(name, check.Equals, "nginx-ingress")
 	c.Assert(version, check.Equals, "1.0.0")
 
+	c.Assert(MustToChartFilename("alpine", "0.1.0"), check.Equals,
+		"alpine-0.1.0.tgz")
+
 	_, _, err = ParseChartFilename("nginx.tgz")
 	c.Assert(err, check.NotNil)
 }
 
+func (s *helmUtilsSuite) TestChartFilenameValidation(c *check.C) {
+	testCases := []struct {
+		name    string
+		version string
+		desc    string
+	}{
+		{name: "../evil", version: "0.1.0", desc: "Name with a path separator"},
+		{name: "my chart", version: "0.1.0", desc: "Name with whitespace"},
+		{name: "", version: "0.1.0", desc: "Empty name"},
+		{name: "alpine", version: "not-semver", desc: "Version is not semver"},
+		{name: "alpine", version: "0.1.0/../2", desc: "Version with a path separator"},
+		{name: "alpine", version: "", desc: "Empty version"},
+	}
+	for _, tc := range testCases {
+		_, err := ToChartFilename(tc.name, tc.version)
+		c.Assert(err, check.NotNil, check.Commentf(
+			"Test case %q failed", tc.desc))
+		comment := check.Commentf("Test case %q failed", tc.desc)
+		c.Assert(trace.IsBadParameter(err), check.Equals, true, comment)
+	}
+}
+
+func (s *helmUtilsSuite) TestMergeValuesPolicies(c *check.C) {
+	files := []string{
+		filepath.Join(s.dir, "values1.yaml"),
+		filepath.Join(s.dir, "values3.yaml"),
+	}
+
+	vals, conflicts, err := MergeValues(files, nil, MergePolicyLastWins)
+	c.Assert(err, check.IsNil)
+	c.Assert(conflicts, check.HasLen, 0)
+	image := vals["image"].(map[string]interface{})
+	c.Assert(image["registry"], check.Equals, "registry.other:5000")
+	c.Assert(image["tag"], check.Equals, "1.0.0")
+
+	vals, conflicts, err = MergeValues(files, nil, MergePolicyFirstWins)
+	c.Assert(err, check.IsNil)
+	c.Assert(conflicts, check.HasLen, 0)
+	image = vals["image"].(map[string]interface{})
+	// the value from the first layer is kept
+	c.Assert(image["registry"], check.Equals, "registry.private:5000")
+	c.Assert(image["tag"], check.Equals, "1.0.0")
+
+	_, conflicts, err = MergeValues(files, nil, MergePolicyErrorOnConflict)
+	c.Assert(trace.IsBadParameter(err), check.Equals, true,
+		check.Commentf("unexpected error: %v", err))
+	c.Assert(conflicts, check.DeepEquals, []string{"image.registry"})
+
+	// string values form the final layer
+	_, conflicts, err = MergeValues(files[:1], []string{"image.registry=localhost:5000"},
+		MergePolicyErrorOnConflict)
+	c.Assert(trace.IsBadParameter(err), check.Equals, true,
+		check.Commentf("unexpected error: %v", err))
+	c.Assert(conflicts, check.DeepEquals, []string{"image.registry"})
+
+	_, _, err = MergeValues(files[:1], nil, MergePolicy("bogus"))
+	c.Assert(trace.IsBadParameter(err), check.Equals, true,
+		check.Commentf("unexpected error: %v", err))
+}
+
 var (
 	valuesFile1 = []byte(`image:
   registry:
@@ -114,4 +432,42 @@ var (
 	valuesFile2 = []byte(`image:
   name:
     alpine:1.0.0`)
+	valuesFile3 = []byte(`image:
+  registry: registry.other:5000
+  tag: 1.0.0`)
+	oldValuesFile = []byte(`image:
+  registry: registry.private:5000
+  tag: 1.0.0
+ports:
+  - 80
+  - 443
+replicas: 2`)
+	newValuesFile = []byte(`image:
+  registry: registry.other:5000
+  tag: 1.0.0
+ports:
+  - 80
+  - 8443
+env:
+  mode: prod`)
 )
+
+func (s *helmUtilsSuite) TestIndexFileStaleness(c *check.C) {
+	// a freshly generated index is not stale
+	indexFile := repo.NewIndexFile()
+	age := IndexAge(*indexFile)
+	c.Assert(age >= 0 && age < time.Minute, check.Equals, true,
+		check.Commentf("unexpected age %v", age))
+	c.Assert(IsIndexStale(*indexFile, time.Hour), check.Equals, false)
+
+	// an index past its maximum age is stale
+	indexFile.Generated = time.Now().Add(-2 * time.Hour)
+	c.Assert(IndexAge(*indexFile) >= 2*time.Hour, check.Equals, true)
+	c.Assert(IsIndexStale(*indexFile, time.Hour), check.Equals, true)
+
+	// an index without a generation timestamp has unknown age and is
+	// considered stale
+	indexFile.Generated = time.Time{}
+	c.Assert(IndexAge(*indexFile), check.Equals, time.Duration(0))
+	c.Assert(IsIndexStale(*indexFile, time.Hour), check.Equals, true)
+}
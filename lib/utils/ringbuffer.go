@@ -0,0 +1,106 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"sync"
+)
+
+// NewTailBuffer returns a writer that retains only the last capacity bytes
+// written to it, evicting the oldest bytes as new ones arrive.
+//
+// It is intended as a bounded sink for command output that could grow
+// without limit, e.g. when tailing a runaway command over the agent RPC:
+// memory stays capped while the most recent output and the number of
+// evicted bytes remain available.
+//
+// The buffer is safe for concurrent use
+func NewTailBuffer(capacity int) *TailBuffer {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &TailBuffer{buf: make([]byte, capacity)}
+}
+
+// TailBuffer is a fixed-size ring buffer that keeps the tail of the
+// written data, counting the bytes it has evicted
+type TailBuffer struct {
+	mu sync.Mutex
+	// buf is the ring storage
+	buf []byte
+	// start is the index of the oldest retained byte
+	start int
+	// size is the number of retained bytes
+	size int
+	// dropped counts the bytes evicted from the buffer
+	dropped int64
+}
+
+// Write appends p to the buffer evicting the oldest bytes if it does not
+// fit. Writes always succeed reporting the full length of p
+func (b *TailBuffer) Write(p []byte) (n int, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n = len(p)
+	if len(p) >= len(b.buf) {
+		// only the tail of the write itself fits: everything previously
+		// retained and the head of the write are dropped
+		b.dropped += int64(b.size + len(p) - len(b.buf))
+		copy(b.buf, p[len(p)-len(b.buf):])
+		b.start = 0
+		b.size = len(b.buf)
+		return n, nil
+	}
+	// evict the oldest bytes to make room for the write
+	if overflow := b.size + len(p) - len(b.buf); overflow > 0 {
+		b.start = (b.start + overflow) % len(b.buf)
+		b.size -= overflow
+		b.dropped += int64(overflow)
+	}
+	end := (b.start + b.size) % len(b.buf)
+	copied := copy(b.buf[end:], p)
+	copy(b.buf, p[copied:])
+	b.size += len(p)
+	return n, nil
+}
+
+// Bytes returns a copy of the retained tail of the written data
+func (b *TailBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make([]byte, b.size)
+	if b.start+b.size <= len(b.buf) {
+		copy(out, b.buf[b.start:b.start+b.size])
+		return out
+	}
+	copied := copy(out, b.buf[b.start:])
+	copy(out[copied:], b.buf[:b.size-copied])
+	return out
+}
+
+// String returns the retained tail of the written data as a string
+func (b *TailBuffer) String() string {
+	return string(b.Bytes())
+}
+
+// Dropped returns the number of bytes evicted to keep the data within
+// the buffer capacity. Returns 0 if all written data has been retained
+func (b *TailBuffer) Dropped() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.dropped
+}
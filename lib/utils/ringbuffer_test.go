@@ -0,0 +1,74 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *UtilsSuite) TestTailBufferRetainsDataUnderCapacity(c *C) {
+	buf := NewTailBuffer(16)
+	for _, chunk := range []string{"hello", " ", "world"} {
+		n, err := buf.Write([]byte(chunk))
+		c.Assert(err, IsNil)
+		c.Assert(n, Equals, len(chunk))
+	}
+	c.Assert(buf.String(), Equals, "hello world")
+	c.Assert(buf.Dropped(), Equals, int64(0))
+}
+
+func (s *UtilsSuite) TestTailBufferKeepsTailAndCountsDropped(c *C) {
+	buf := NewTailBuffer(8)
+	for _, chunk := range []string{"abc", "def", "ghi", "jkl"} {
+		n, err := buf.Write([]byte(chunk))
+		c.Assert(err, IsNil)
+		c.Assert(n, Equals, len(chunk))
+	}
+	// 12 bytes written, the buffer keeps the last 8
+	c.Assert(buf.String(), Equals, "efghijkl")
+	c.Assert(buf.Dropped(), Equals, int64(4))
+}
+
+func (s *UtilsSuite) TestTailBufferHandlesOversizedWrite(c *C) {
+	buf := NewTailBuffer(4)
+	_, err := buf.Write([]byte("old"))
+	c.Assert(err, IsNil)
+	n, err := buf.Write([]byte("0123456789"))
+	c.Assert(err, IsNil)
+	c.Assert(n, Equals, 10)
+	// the previously retained bytes and the head of the oversized write
+	// are all dropped
+	c.Assert(buf.String(), Equals, "6789")
+	c.Assert(buf.Dropped(), Equals, int64(9))
+}
+
+func (s *UtilsSuite) TestTailBufferKeepsTailOfLongStream(c *C) {
+	buf := NewTailBuffer(10)
+	stream := strings.Repeat("0123456789abcdef", 100)
+	for i := 0; i < len(stream); i += 7 {
+		end := i + 7
+		if end > len(stream) {
+			end = len(stream)
+		}
+		_, err := buf.Write([]byte(stream[i:end]))
+		c.Assert(err, IsNil)
+	}
+	c.Assert(buf.String(), Equals, stream[len(stream)-10:])
+	c.Assert(buf.Dropped(), Equals, int64(len(stream)-10))
+}
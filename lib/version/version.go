@@ -0,0 +1,57 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package version centralizes parsing and comparison of gravity and
+// application versions so callers do not reimplement the semver ordering
+// rules (prereleases sort before the release, build metadata is ignored)
+package version
+
+import (
+	"strings"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/gravitational/trace"
+)
+
+// Version represents a parsed gravity or application version,
+// e.g. "7.0.12", "7.1.0-beta.2" or "7.1.0+build.1"
+type Version struct {
+	semver.Version
+}
+
+// Parse parses the provided version string.
+// An optional leading "v" and surrounding whitespace are tolerated
+func Parse(v string) (*Version, error) {
+	parsed, err := semver.NewVersion(strings.TrimPrefix(strings.TrimSpace(v), "v"))
+	if err != nil {
+		return nil, trace.BadParameter(
+			"unsupported version format %q, need semver format, e.g. 1.0.0: %v", v, err)
+	}
+	return &Version{Version: *parsed}, nil
+}
+
+// Compare returns -1 if version a is older than b, 0 if the versions are
+// equal and 1 if a is newer than b.
+// Prereleases sort before the corresponding release and build metadata
+// does not participate in the ordering as per the semver specification
+func Compare(a, b Version) int {
+	return a.Version.Compare(b.Version)
+}
+
+// IsNewer returns true if version a is strictly newer than version b
+func IsNewer(a, b Version) bool {
+	return Compare(a, b) > 0
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package version
+
+import (
+	"testing"
+
+	"github.com/gravitational/trace"
+	"gopkg.in/check.v1"
+)
+
+func TestVersion(t *testing.T) { check.TestingT(t) }
+
+type VersionSuite struct{}
+
+var _ = check.Suite(&VersionSuite{})
+
+func (s *VersionSuite) TestParse(c *check.C) {
+	testCases := []struct {
+		input      string
+		version    string
+		prerelease string
+		metadata   string
+		err        bool
+		comment    string
+	}{
+		{
+			input:   "7.0.12",
+			version: "7.0.12",
+			comment: "plain release version",
+		},
+		{
+			input:      "7.1.0-beta.2",
+			version:    "7.1.0-beta.2",
+			prerelease: "beta.2",
+			comment:    "prerelease version",
+		},
+		{
+			input:    "7.1.0+build.1",
+			version:  "7.1.0+build.1",
+			metadata: "build.1",
+			comment:  "version with build metadata",
+		},
+		{
+			input:      "7.1.0-alpha.1+build.1",
+			version:    "7.1.0-alpha.1+build.1",
+			prerelease: "alpha.1",
+			metadata:   "build.1",
+			comment:    "prerelease with build metadata",
+		},
+		{
+			input:   "v7.0.12",
+			version: "7.0.12",
+			comment: "leading v is tolerated",
+		},
+		{
+			input:   " 7.0.12\n",
+			version: "7.0.12",
+			comment: "surrounding whitespace is tolerated",
+		},
+		{
+			input:   "7.0",
+			err:     true,
+			comment: "incomplete version",
+		},
+		{
+			input:   "not-a-version",
+			err:     true,
+			comment: "garbage input",
+		},
+		{
+			input:   "",
+			err:     true,
+			comment: "empty input",
+		},
+	}
+	for _, tc := range testCases {
+		comment := check.Commentf(tc.comment)
+		version, err := Parse(tc.input)
+		if tc.err {
+			c.Assert(err, check.NotNil, comment)
+			c.Assert(trace.IsBadParameter(err), check.Equals, true, comment)
+			continue
+		}
+		c.Assert(err, check.IsNil, comment)
+		c.Assert(version.String(), check.Equals, tc.version, comment)
+		c.Assert(string(version.PreRelease), check.Equals, tc.prerelease, comment)
+		c.Assert(version.Metadata, check.Equals, tc.metadata, comment)
+	}
+}
+
+func (s *VersionSuite) TestCompare(c *check.C) {
+	testCases := []struct {
+		a       string
+		b       string
+		result  int
+		comment string
+	}{
+		{
+			a:       "7.0.12",
+			b:       "7.0.12",
+			result:  0,
+			comment: "equal versions",
+		},
+		{
+			a:       "7.0.11",
+			b:       "7.0.12",
+			result:  -1,
+			comment: "older patch version",
+		},
+		{
+			a:       "7.1.0",
+			b:       "7.0.30",
+			result:  1,
+			comment: "newer minor version",
+		},
+		{
+			a:       "8.0.0",
+			b:       "7.9.9",
+			result:  1,
+			comment: "newer major version",
+		},
+		{
+			a:       "7.1.0-beta.1",
+			b:       "7.1.0",
+			result:  -1,
+			comment: "prerelease sorts before the release",
+		},
+		{
+			a:       "7.1.0-beta.2",
+			b:       "7.1.0-beta.1",
+			result:  1,
+			comment: "prereleases are ordered between themselves",
+		},
+		{
+			a:       "7.1.0-alpha.1",
+			b:       "7.1.0-beta.1",
+			result:  -1,
+			comment: "prereleases compare lexicographically",
+		},
+		{
+			a:       "7.1.0+build.1",
+			b:       "7.1.0+build.2",
+			result:  0,
+			comment: "build metadata does not participate in ordering",
+		},
+		{
+			a:       "7.1.0-beta.1+build.1",
+			b:       "7.1.0-beta.1",
+			result:  0,
+			comment: "build metadata is ignored on prereleases too",
+		},
+	}
+	for _, tc := range testCases {
+		comment := check.Commentf(tc.comment)
+		a, err := Parse(tc.a)
+		c.Assert(err, check.IsNil, comment)
+		b, err := Parse(tc.b)
+		c.Assert(err, check.IsNil, comment)
+		c.Assert(Compare(*a, *b), check.Equals, tc.result, comment)
+		c.Assert(Compare(*b, *a), check.Equals, -tc.result, comment)
+		c.Assert(IsNewer(*a, *b), check.Equals, tc.result > 0, comment)
+		c.Assert(IsNewer(*b, *a), check.Equals, tc.result < 0, comment)
+	}
+}
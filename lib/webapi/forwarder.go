@@ -124,13 +124,9 @@ func getSessionContext(clusterName string, ctx context.Context) (*sessionContext
 	if !ok {
 		return nil, trace.BadParameter("unsupported web session type")
 	}
-	operatorI := ctx.Value(constants.OperatorContext)
-	if webSessionI == nil {
-		return nil, trace.NotFound("missing operator context")
-	}
-	operator, ok := operatorI.(ops.Operator)
-	if !ok {
-		return nil, trace.BadParameter("unsupported web session type")
+	operator, err := ops.OperatorFromContext(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
 	}
 	return &sessionContext{operator: operator, webSession: webSession, clusterName: clusterName}, nil
 }
@@ -1856,6 +1856,9 @@ type ResourceCreateCmd struct {
 	Manual *bool
 	// Confirmed suppresses confirmation prompt
 	Confirmed *bool
+	// ContinueOnError continues applying a multi-document bundle after
+	// a document fails instead of aborting
+	ContinueOnError *bool
 }
 
 // ResourceRemoveCmd removes specified resource
@@ -1865,6 +1868,8 @@ type ResourceRemoveCmd struct {
 	Kind *string
 	// Name is resource name
 	Name *string
+	// Labels is a label selector matching resources to remove in bulk
+	Labels *string
 	// Force suppresses not found errors
 	Force *bool
 	// User is resource owner
@@ -250,7 +250,7 @@ func RegisterCommands(app *kingpin.Application) *Application {
 	g.StatusClusterCmd.Tail = g.StatusClusterCmd.Flag("tail", "Tail logs of the currently running operation until it completes.").Bool()
 	g.StatusClusterCmd.OperationID = g.StatusClusterCmd.Flag("operation-id", "Check status of the operation with the given ID.").Short('o').String()
 	g.StatusClusterCmd.Seconds = g.StatusClusterCmd.Flag("seconds", "Continuously display status every N seconds.").Short('s').Int()
-	g.StatusClusterCmd.Output = common.Format(g.StatusClusterCmd.Flag("output", "Output format: json or text.").Default(string(constants.EncodingText)))
+	g.StatusClusterCmd.Output = common.Format(g.StatusClusterCmd.Flag("output", "Output format: json, jsonl or text. The jsonl format streams operation progress as newline-delimited JSON.").Default(string(constants.EncodingText)))
 
 	// Display cluster status history
 	g.StatusHistoryCmd.CmdClause = g.StatusCmd.Command("history", "Display cluster status history.")
@@ -805,12 +805,14 @@ func RegisterCommands(app *kingpin.Application) *Application {
 	g.ResourceCreateCmd.User = g.ResourceCreateCmd.Flag("user", "User to create the resource for. Defaults to the currently logged in user.").String()
 	g.ResourceCreateCmd.Manual = g.ResourceCreateCmd.Flag("manual", "Manually execute operation phases for resource which trigger an operation.").Short('m').Bool()
 	g.ResourceCreateCmd.Confirmed = g.ResourceCreateCmd.Flag("confirm", "Do not ask for confirmation.").Bool()
+	g.ResourceCreateCmd.ContinueOnError = g.ResourceCreateCmd.Flag("continue-on-error", "Continue applying the remaining documents of a multi-document bundle when one fails. Exits non-zero if any document failed.").Bool()
 
 	// remove one or many resources
 	g.ResourceRemoveCmd.CmdClause = g.ResourceCmd.Command("rm", fmt.Sprintf("Remove a configuration resource, e.g. gravity resource rm oidc google. Supported resources are: %v.", modules.GetResources().SupportedResourcesToRemove()))
 	g.ResourceRemoveCmd.Kind = g.ResourceRemoveCmd.Arg("kind", fmt.Sprintf("Resource kind. One of: %v.",
 		modules.GetResources().SupportedResourcesToRemove())).Required().String()
 	g.ResourceRemoveCmd.Name = g.ResourceRemoveCmd.Arg("name", "Resource name.").String()
+	g.ResourceRemoveCmd.Labels = g.ResourceRemoveCmd.Flag("labels", `Remove all resources of the kind matching the label selector, e.g. "env=dev,team=ops". Takes precedence over the name argument.`).String()
 	g.ResourceRemoveCmd.Force = g.ResourceRemoveCmd.Flag("force", "Suppress resource not found errors.").Short('f').Bool()
 	g.ResourceRemoveCmd.User = g.ResourceRemoveCmd.Flag("user", "User to remove the resource for. Defaults to the currently logged in user.").String()
 	g.ResourceRemoveCmd.Manual = g.ResourceRemoveCmd.Flag("manual", "Manually execute operation phases for resources which trigger an operation.").Short('m').Bool()
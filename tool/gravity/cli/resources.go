@@ -27,8 +27,10 @@ import (
 	"github.com/gravitational/gravity/lib/ops/resources/gravity"
 	"github.com/gravitational/gravity/lib/storage"
 	"github.com/gravitational/gravity/lib/storage/clusterconfig"
+	"github.com/gravitational/gravity/lib/utils"
 	"github.com/gravitational/gravity/tool/common"
 
+	teleservices "github.com/gravitational/teleport/lib/services"
 	"github.com/gravitational/trace"
 )
 
@@ -36,8 +38,10 @@ import (
 // upsert controls whether the resource is expected to exist.
 // manual controls whether the operation is created in manual mode if resource creation is implemented
 // as a cluster operation.
-// confirmed specifies if the user has explicitly approved the operation
-func createResource(env *localenv.LocalEnvironment, factory LocalEnvironmentFactory, filename string, upsert bool, user string, manual, confirmed bool) error {
+// confirmed specifies if the user has explicitly approved the operation.
+// continueOnError makes a failure to create one document of a multi-document
+// bundle not abort the remaining documents
+func createResource(env *localenv.LocalEnvironment, factory LocalEnvironmentFactory, filename string, upsert bool, user string, manual, confirmed, continueOnError bool) error {
 	operator, err := env.SiteOperator()
 	if err != nil {
 		return trace.Wrap(err)
@@ -62,6 +66,8 @@ func createResource(env *localenv.LocalEnvironment, factory LocalEnvironmentFact
 	}
 	defer reader.Close()
 	control := resources.NewControl(gravityResources)
+	var succeeded, failed int
+	var errors []error
 	err = resources.ForEach(reader, func(resource storage.UnknownResource) error {
 		req := resources.CreateRequest{
 			SiteKey:   cluster.Key(),
@@ -70,9 +76,27 @@ func createResource(env *localenv.LocalEnvironment, factory LocalEnvironmentFact
 			Manual:    manual,
 			Confirmed: confirmed,
 		}
-		return trace.Wrap(control.Create(context.TODO(), bytes.NewReader(resource.Raw), req))
+		err := control.Create(context.TODO(), bytes.NewReader(resource.Raw), req)
+		if err == nil {
+			succeeded++
+			return nil
+		}
+		if !continueOnError {
+			return trace.Wrap(err)
+		}
+		failed++
+		env.Printf("Failed to create %v/%v: %v\n", resource.Kind,
+			resource.Metadata.Name, trace.UserMessage(err))
+		errors = append(errors, err)
+		return nil
 	})
-	return trace.Wrap(err)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if continueOnError {
+		env.Printf("Created %v resources, %v failed\n", succeeded, failed)
+	}
+	return trace.NewAggregate(errors...)
 }
 
 // removeResource deletes resource by name
@@ -116,6 +140,104 @@ func removeResource(
 
 }
 
+// removeResources deletes all resources of the given kind matching the label
+// selector. Unless confirmed is set, the resources to be removed are listed
+// and the user is asked for confirmation
+func removeResources(
+	env *localenv.LocalEnvironment,
+	factory LocalEnvironmentFactory,
+	kind, labels string,
+	force bool,
+	user string,
+	manual, confirmed bool,
+) error {
+	operator, err := env.SiteOperator()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	cluster, err := env.LocalCluster()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	clusterHandler := NewDefaultClusterOperationHandler(factory)
+	gravityResources, err := gravity.New(gravity.Config{
+		Operator:                operator,
+		CurrentUser:             env.CurrentUser(),
+		Silent:                  env.Silent,
+		ClusterOperationHandler: clusterHandler,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	collection, err := gravityResources.GetCollection(resources.ListRequest{
+		SiteKey: cluster.Key(),
+		Kind:    kind,
+		User:    user,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	items, err := collection.Resources()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	selector := utils.ParseLabels(labels)
+	var matched []teleservices.UnknownResource
+	for _, item := range items {
+		if matchLabels(item.Metadata.Labels, selector) {
+			matched = append(matched, item)
+		}
+	}
+	if len(matched) == 0 {
+		if force {
+			return nil
+		}
+		return trace.NotFound("no %v resources match label selector %q",
+			kind, labels)
+	}
+	if !confirmed {
+		env.Println("The following resources will be removed:")
+		for _, item := range matched {
+			env.Printf("    %v/%v\n", item.Kind, item.Metadata.Name)
+		}
+		if err := enforceConfirmation("Proceed?"); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	control := resources.NewControl(gravityResources)
+	var errors []error
+	for _, item := range matched {
+		err := control.Remove(context.TODO(), resources.RemoveRequest{
+			SiteKey:   cluster.Key(),
+			Kind:      kind,
+			Name:      item.Metadata.Name,
+			Force:     force,
+			Owner:     user,
+			Manual:    manual,
+			Confirmed: true,
+		})
+		if err != nil {
+			env.Printf("Failed to remove %v/%v: %v\n", item.Kind,
+				item.Metadata.Name, trace.UserMessage(err))
+			errors = append(errors, err)
+			continue
+		}
+		env.Printf("%v/%v has been removed\n", item.Kind, item.Metadata.Name)
+	}
+	return trace.NewAggregate(errors...)
+}
+
+// matchLabels returns true if the resource labels contain all key/value
+// pairs from the selector
+func matchLabels(labels, selector map[string]string) bool {
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
 func getResources(env *localenv.LocalEnvironment, kind string, name string, withSecrets bool, format constants.Format, user string) error {
 	operator, err := env.SiteOperator()
 	if err != nil {
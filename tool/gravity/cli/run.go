@@ -528,6 +528,9 @@ func Execute(g *Application, cmd string, extraArgs []string) (err error) {
 		if *g.StatusClusterCmd.Tail {
 			return tailStatus(localEnv, *g.StatusClusterCmd.OperationID)
 		}
+		if printOptions.format == constants.EncodingJSONL {
+			return streamStatus(localEnv, *g.StatusClusterCmd.OperationID)
+		}
 		if *g.StatusClusterCmd.Seconds != 0 {
 			return statusPeriodic(localEnv, printOptions, *g.StatusClusterCmd.Seconds)
 		} else {
@@ -991,8 +994,18 @@ func Execute(g *Application, cmd string, extraArgs []string) (err error) {
 			*g.ResourceCreateCmd.Upsert,
 			*g.ResourceCreateCmd.User,
 			*g.ResourceCreateCmd.Manual,
-			*g.ResourceCreateCmd.Confirmed)
+			*g.ResourceCreateCmd.Confirmed,
+			*g.ResourceCreateCmd.ContinueOnError)
 	case g.ResourceRemoveCmd.FullCommand():
+		if *g.ResourceRemoveCmd.Labels != "" {
+			return removeResources(localEnv, g,
+				*g.ResourceRemoveCmd.Kind,
+				*g.ResourceRemoveCmd.Labels,
+				*g.ResourceRemoveCmd.Force,
+				*g.ResourceRemoveCmd.User,
+				*g.ResourceRemoveCmd.Manual,
+				*g.ResourceRemoveCmd.Confirmed)
+		}
 		return removeResource(localEnv, g,
 			*g.ResourceRemoveCmd.Kind,
 			*g.ResourceRemoveCmd.Name,
@@ -17,6 +17,7 @@ limitations under the License.
 package cli
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -137,6 +138,111 @@ func tailStatus(env *localenv.LocalEnvironment, operationID string) error {
 	return trace.Wrap(tailOperationLogs(operator, opKey))
 }
 
+// streamStatus follows the progress of the currently ongoing operation,
+// emitting every progress update as a single line of JSON until the
+// operation completes
+func streamStatus(env *localenv.LocalEnvironment, operationID string) error {
+	operator, err := env.SiteOperator()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	status, err := statusOnce(context.TODO(), operator, operationID, env)
+	if err != nil {
+		log.Warnf("Failed to determine cluster status: %v.", trace.DebugReport(err))
+		if status == nil || status.Cluster == nil {
+			return trace.BadParameter("unknown cluster state")
+		}
+	}
+
+	if status.Cluster.Operation == nil && len(status.Cluster.ActiveOperations) == 0 {
+		return trace.NotFound("there is no operation in progress")
+	}
+
+	var opKey ops.SiteOperationKey
+	switch {
+	case operationID != "" && status.Cluster.Operation != nil:
+		opKey = status.Operation.Key()
+	case len(status.Cluster.ActiveOperations) != 0:
+		if len(status.Cluster.ActiveOperations) > 1 {
+			return trace.BadParameter("multiple active operations in progress. " +
+				"Please specify the operation with --operation-id")
+		}
+		opKey = status.Cluster.ActiveOperations[0].Key()
+	default:
+		return nil
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	return trace.Wrap(printProgressJSONL(
+		followOperationProgress(context.TODO(), operator, opKey), out))
+}
+
+// followOperationProgress polls the progress of the specified operation and
+// sends every new update on the returned channel. The channel is closed
+// once the operation completes or the provided context is cancelled
+func followOperationProgress(ctx context.Context, operator ops.Operator, operationKey ops.SiteOperationKey) <-chan ops.ProgressEntry {
+	updates := make(chan ops.ProgressEntry)
+	go func() {
+		defer close(updates)
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		var last ops.ProgressEntry
+		for {
+			select {
+			case <-ticker.C:
+				progress, err := operator.GetSiteOperationProgress(operationKey)
+				if err != nil {
+					log.WithError(err).Warn("Failed to query operation progress.")
+					continue
+				}
+				if progress.IsEqual(last) {
+					continue
+				}
+				last = *progress
+				select {
+				case updates <- *progress:
+				case <-ctx.Done():
+					return
+				}
+				if progress.IsCompleted() {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return updates
+}
+
+// printProgressJSONL writes each received progress update as a single
+// JSON-encoded line, flushing the output after every line so the updates
+// can be ingested in real time
+func printProgressJSONL(updates <-chan ops.ProgressEntry, out io.Writer) error {
+	var last *ops.ProgressEntry
+	for update := range updates {
+		update := update
+		bytes, err := json.Marshal(&update)
+		if err != nil {
+			return trace.Wrap(err, "failed to marshal")
+		}
+		if _, err := fmt.Fprintf(out, "%s\n", bytes); err != nil {
+			return trace.Wrap(err)
+		}
+		if flusher, ok := out.(interface{ Flush() error }); ok {
+			if err := flusher.Flush(); err != nil {
+				return trace.Wrap(err)
+			}
+		}
+		last = &update
+	}
+	if last != nil && last.State == ops.ProgressStateFailed {
+		return trace.Errorf(last.Message)
+	}
+	return nil
+}
+
 // statusPeriodic continuously polls for site status with the provided interval and prints it
 func statusPeriodic(env *localenv.LocalEnvironment, printOptions printOptions, seconds int) error {
 	operator, err := env.SiteOperator()
@@ -0,0 +1,86 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/gravitational/gravity/lib/ops"
+
+	"gopkg.in/check.v1"
+)
+
+type StatusSuite struct{}
+
+var _ = check.Suite(&StatusSuite{})
+
+func (s *StatusSuite) TestPrintsProgressAsJSONLines(c *check.C) {
+	updates := make(chan ops.ProgressEntry, 3)
+	updates <- ops.ProgressEntry{Completion: 10, Message: "initializing"}
+	updates <- ops.ProgressEntry{Completion: 50, Message: "configuring"}
+	updates <- ops.ProgressEntry{Completion: 100, State: ops.ProgressStateCompleted, Message: "done"}
+	close(updates)
+
+	out := &flushRecorder{}
+	err := printProgressJSONL(updates, out)
+	c.Assert(err, check.IsNil)
+
+	lines := strings.Split(strings.TrimRight(out.String(), "\n"), "\n")
+	c.Assert(lines, check.HasLen, 3)
+	completions := []int{10, 50, 100}
+	for i, line := range lines {
+		var entry ops.ProgressEntry
+		err := json.Unmarshal([]byte(line), &entry)
+		c.Assert(err, check.IsNil, check.Commentf("line %d: %q", i, line))
+		c.Assert(entry.Completion, check.Equals, completions[i])
+	}
+	// the output is flushed after every line so each update is visible
+	// to the consumer as soon as it is emitted
+	c.Assert(out.flushes, check.Equals, 3)
+}
+
+func (s *StatusSuite) TestProgressJSONLReportsFailedOperation(c *check.C) {
+	updates := make(chan ops.ProgressEntry, 2)
+	updates <- ops.ProgressEntry{Completion: 10, Message: "initializing"}
+	updates <- ops.ProgressEntry{Completion: 100, State: ops.ProgressStateFailed, Message: "operation failed"}
+	close(updates)
+
+	var out bytes.Buffer
+	err := printProgressJSONL(updates, &out)
+	c.Assert(err, check.ErrorMatches, "operation failed")
+
+	lines := strings.Split(strings.TrimRight(out.String(), "\n"), "\n")
+	c.Assert(lines, check.HasLen, 2)
+	for i, line := range lines {
+		var entry ops.ProgressEntry
+		err := json.Unmarshal([]byte(line), &entry)
+		c.Assert(err, check.IsNil, check.Commentf("line %d: %q", i, line))
+	}
+}
+
+// flushRecorder counts how many times the output has been flushed
+type flushRecorder struct {
+	bytes.Buffer
+	flushes int
+}
+
+func (r *flushRecorder) Flush() error {
+	r.flushes++
+	return nil
+}